@@ -74,6 +74,18 @@ export type TemplateItem = {
   id_prefix: string
 }
 
+// RemoteTask is a project:TaskID parent or blocker resolved in another project.
+export type RemoteTask = {
+  ref: string
+  project: string
+  id: string
+  short_id: string
+  title?: string
+  status?: string
+  completed: boolean
+  problem?: string
+}
+
 export type TemplateDetail = TemplateItem & {
   body: string
 }
@@ -119,6 +131,7 @@ type TaskDetail = {
   date_created: string
   date_edited: string
   body: string
+  remote?: RemoteTask[]
 }
 
 async function fetchJSON<T>(path: string, init?: RequestInit): Promise<T> {
@@ -353,6 +366,11 @@ export default function App() {
     void loadTask(id, rel, orig)
   }
 
+  const onOpenRemoteTask = (project: string, id: string) => {
+    setCurrentProject(project)
+    void loadTask(id)
+  }
+
   const saveTask = async () => {
     const task = activeTaskDetail()
     if (!task) return
@@ -1032,6 +1050,8 @@ export default function App() {
           onSave={tab() === "tasks" ? saveTask : tab() === "roles" ? saveRole : saveTemplate}
           onAddSubtask={handleAddSubtask}
           onSelectTask={onSelectTask}
+          onOpenRemoteTask={onOpenRemoteTask}
+          projects={projects().map((p) => p.name)}
         />
       </section>
 
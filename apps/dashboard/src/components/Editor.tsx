@@ -1,5 +1,5 @@
 import { Show, For, createSignal, onCleanup, createEffect } from "solid-js"
-import type { RoleDetail, TemplateDetail } from "../App"
+import type { RemoteTask, RoleDetail, TemplateDetail } from "../App"
 import { EditorView, basicSetup } from "codemirror"
 import { markdown } from "@codemirror/lang-markdown"
 import { oneDark } from "@codemirror/theme-one-dark"
@@ -21,6 +21,7 @@ type TaskDetail = {
   date_created: string
   date_edited: string
   body: string
+  remote?: RemoteTask[]
 }
 
 type EditorProps = {
@@ -40,6 +41,8 @@ type EditorProps = {
   onSave: () => void
   onAddSubtask?: () => void
   onSelectTask?: (id: string, rel?: string, orig?: string) => void
+  onOpenRemoteTask?: (project: string, id: string) => void
+  projects?: string[]
 }
 
 export default function Editor(props: EditorProps) {
@@ -52,6 +55,25 @@ export default function Editor(props: EditorProps) {
     return t ? `${t.short_id} - ${t.title}` : id
   }
 
+  // Blockers and parents written project:TaskID live in another project.
+  const isRemote = (id: string) => id.includes(":")
+
+  const remoteInfo = (ref: string) => props.task?.remote?.find((r) => r.ref === ref)
+
+  const remoteLabel = (ref: string) => {
+    const info = remoteInfo(ref)
+    if (!info || info.problem) return ref
+    const status = info.completed ? "done" : info.status || "open"
+    return `${info.project}:${info.short_id} - ${info.title} (${status})`
+  }
+
+  const openRemote = (ref: string) => {
+    const info = remoteInfo(ref)
+    if (!info || info.problem) return
+    if (props.projects && !props.projects.includes(info.project)) return
+    props.onOpenRemoteTask?.(info.project, info.id)
+  }
+
   let taskEditorContainer: HTMLDivElement | undefined
   let roleEditorContainer: HTMLDivElement | undefined
   let templateEditorContainer: HTMLDivElement | undefined
@@ -310,6 +332,9 @@ export default function Editor(props: EditorProps) {
                 onChange={(e) => updateTaskField("parent", e.currentTarget.value)}
               >
                 <option value="">No Parent</option>
+                <Show when={isRemote(task()?.parent || "")}>
+                  <option value={task()!.parent}>{remoteLabel(task()!.parent)}</option>
+                </Show>
                 <For each={props.tasks}>
                   {(t) => (
                     <Show when={t.id !== task()?.id}>
@@ -369,12 +394,27 @@ export default function Editor(props: EditorProps) {
               <div class="tag-list">
                 <For each={task()?.blockers || []}>
                   {(blocker) => (
-                    <span class={`tag ${props.originId === blocker && props.relationship === "blocked-by" ? "origin" : ""}`}>
-                      <button class="tag-link" onClick={() => props.onSelectTask?.(blocker, "blocking", task()?.id)}>
-                        {getTaskTitle(blocker)}
-                      </button>
-                      <button class="tag-remove" onClick={() => removeBlocker(blocker)}>×</button>
-                    </span>
+                    <Show
+                      when={isRemote(blocker)}
+                      fallback={
+                        <span class={`tag ${props.originId === blocker && props.relationship === "blocked-by" ? "origin" : ""}`}>
+                          <button class="tag-link" onClick={() => props.onSelectTask?.(blocker, "blocking", task()?.id)}>
+                            {getTaskTitle(blocker)}
+                          </button>
+                          <button class="tag-remove" onClick={() => removeBlocker(blocker)}>×</button>
+                        </span>
+                      }
+                    >
+                      <span
+                        class={`tag remote ${remoteInfo(blocker)?.problem ? "broken" : ""}`}
+                        title={remoteInfo(blocker)?.problem || `Task in project ${remoteInfo(blocker)?.project ?? ""}`}
+                      >
+                        <button class="tag-link" onClick={() => openRemote(blocker)}>
+                          {remoteLabel(blocker)}
+                        </button>
+                        <button class="tag-remove" onClick={() => removeBlocker(blocker)}>×</button>
+                      </span>
+                    </Show>
                   )}
                 </For>
               </div>
@@ -382,7 +422,7 @@ export default function Editor(props: EditorProps) {
                 <input
                   type="text"
                   class="editor-input"
-                  placeholder="Task ID..."
+                  placeholder="Task ID or project:TaskID..."
                   value={newBlocker()}
                   onInput={(e) => setNewBlocker(e.currentTarget.value)}
                   onKeyDown={(e) => e.key === "Enter" && addBlocker()}
@@ -1,30 +1,26 @@
 package cmd
 
 import (
-	"bytes"
+	"bufio"
+	"errors"
 	"fmt"
 	"io"
 	"os"
-	"path/filepath"
 	"sort"
-	"strconv"
 	"strings"
-	"time"
 
-	"github.com/ricochet1k/strandyard/pkg/activity"
-	"github.com/ricochet1k/strandyard/pkg/idgen"
-	rPkg "github.com/ricochet1k/strandyard/pkg/role"
+	"github.com/ricochet1k/strandyard/pkg/create"
 	"github.com/ricochet1k/strandyard/pkg/task"
 	"github.com/ricochet1k/strandyard/pkg/template"
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v3"
+	"golang.org/x/term"
 )
 
 // addCmd groups task creation commands.
 var addCmd = &cobra.Command{
 	Use:   "add <type> [title]",
 	Short: "Create tasks from templates",
-	Long:  "Create a task using a template in templates/. Types correspond to template filenames (without .md). Templates define default roles and priorities. Provide a detailed body on stdin (pipe or heredoc); it will be inserted where the template uses {{ .Body }} or appended to the end.",
+	Long:  "Create a task using a template in templates/. Types correspond to template filenames (without .md). Templates define default roles and priorities. Provide a detailed body on stdin (pipe or heredoc); it will be inserted where the template uses {{ .Body }} or appended to the end. Templates are Go text/templates; pass the variables a template declares with --var key=value.",
 	Args:  cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		body, err := readStdin()
@@ -35,6 +31,9 @@ var addCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
+		if term.IsTerminal(int(os.Stdin.Fd())) {
+			opts.Prompt = os.Stdin
+		}
 		return runAdd(cmd.OutOrStdout(), opts)
 	},
 }
@@ -50,6 +49,8 @@ func init() {
 	addCmd.Flags().StringSliceVar(&addEvery, "every", nil, `recurrence rule: "<amount> <metric> [from <anchor>]" (repeatable)
 metrics: days, weeks, months, commits, lines_changed, tasks_completed
 examples: "10 days", "50 commits from HEAD", "20 tasks_completed from T1a1a"`)
+	addCmd.Flags().StringArrayVar(&addVars, "var", nil, "template variable as key=value (repeatable); see the template's vars")
+	addCmd.Flags().StringArrayVar(&addFields, "field", nil, "custom field declared in config as key=value (repeatable)")
 }
 
 var (
@@ -60,6 +61,8 @@ var (
 	addBlockers []string
 	addBlocks   []string
 	addEvery    []string
+	addVars     []string
+	addFields   []string
 )
 
 type addOptions struct {
@@ -72,9 +75,14 @@ type addOptions struct {
 	Blockers          []string
 	Blocks            []string
 	Every             []string
+	Vars              map[string]string
+	Fields            map[string]string
 	RoleSpecified     bool
 	PrioritySpecified bool
 	Body              string
+	// Prompt, when set, is read for required template vars that have no
+	// value; prompts are written to stderr.
+	Prompt io.Reader
 }
 
 func addOptionsFromFlags(cmd *cobra.Command, args []string, body string) (addOptions, error) {
@@ -85,6 +93,14 @@ func addOptionsFromFlags(cmd *cobra.Command, args []string, body string) (addOpt
 	if title == "" && len(args) > 1 {
 		title = strings.TrimSpace(strings.Join(args[1:], " "))
 	}
+	vars, err := parseVarFlags(addVars)
+	if err != nil {
+		return addOptions{}, err
+	}
+	fields, err := parseKeyValueFlags("field", addFields)
+	if err != nil {
+		return addOptions{}, err
+	}
 	return addOptions{
 		ProjectName:       projectName,
 		TemplateName:      strings.TrimSpace(args[0]),
@@ -95,320 +111,109 @@ func addOptionsFromFlags(cmd *cobra.Command, args []string, body string) (addOpt
 		Blockers:          addBlockers,
 		Blocks:            addBlocks,
 		Every:             addEvery,
+		Vars:              vars,
+		Fields:            fields,
 		RoleSpecified:     cmd.Flags().Changed("role"),
 		PrioritySpecified: cmd.Flags().Changed("priority"),
 		Body:              body,
 	}, nil
 }
 
-// validateEvery validates --every flag values and returns resolved recurrence rules
-func validateEvery(every []string, repoPath string, tasks map[string]*task.Task) ([]string, error) {
-	if len(every) == 0 {
-		return nil, nil // --every is optional
-	}
-
-	resolvedEvery := make([]string, 0, len(every))
-	for _, value := range every {
-		value = strings.TrimSpace(value)
-		if value == "" {
-			continue
-		}
-
-		// Parse format: <amount> <metric> [from <anchor>]
-		parts := strings.Fields(value)
-		if len(parts) < 2 {
-			fmt.Fprintf(os.Stderr, "strand: error: invalid --every value: expected format \"<amount> <metric> [from <anchor>]\"\n")
-			fmt.Fprintf(os.Stderr, "hint: --every \"10 days\"\n")
-			return nil, fmt.Errorf("invalid --every format")
-		}
-
-		// Validate amount (must be integer)
-		amount := parts[0]
-		if _, err := strconv.Atoi(amount); err != nil {
-			fmt.Fprintf(os.Stderr, "strand: error: invalid --every value: amount must be an integer\n")
-			fmt.Fprintf(os.Stderr, "hint: --every \"10 days\"\n")
-			return nil, fmt.Errorf("invalid --every amount")
-		}
-
-		// Validate metric
-		metric := parts[1]
-		validMetrics := map[string]bool{
-			"days":            true,
-			"weeks":           true,
-			"months":          true,
-			"commits":         true,
-			"lines_changed":   true,
-			"tasks_completed": true,
-		}
-		if !validMetrics[metric] {
-			fmt.Fprintf(os.Stderr, "strand: error: invalid --every value: unsupported metric \"%s\"\n", metric)
-			fmt.Fprintf(os.Stderr, "hint: --every \"10 days\"\n")
-			return nil, fmt.Errorf("invalid --every metric")
-		}
-
-		// Validate anchor if present
-		if len(parts) >= 4 && (parts[2] == "from" || parts[2] == "after") {
-			keyword := parts[2]
-			anchor := strings.Join(parts[3:], " ")
-			resolved, err := task.ValidateAnchor(metric, anchor, repoPath, tasks)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "strand: error: invalid --every value: %v\n", err)
-				if metric == "commits" || metric == "lines_changed" {
-					fmt.Fprintf(os.Stderr, "hint: --every \"50 commits from HEAD\"\n")
-				} else if metric == "days" || metric == "weeks" || metric == "months" {
-					fmt.Fprintf(os.Stderr, "hint: --every \"10 days from Jan 28 2026 09:00 UTC\"\n")
-				} else if metric == "tasks_completed" {
-					fmt.Fprintf(os.Stderr, "hint: --every \"20 tasks_completed from T1a1a\"\n")
-				}
-				return nil, err
-			}
-
-			// If keyword is 'after', resolve to one interval later
-			if keyword == "after" {
-				amountInt, _ := strconv.Atoi(amount)
-				resolved, err = task.UpdateAnchor(repoPath, repoPath, metric, resolved, amountInt)
-				if err != nil {
-					return nil, fmt.Errorf("failed to calculate 'after' anchor: %w", err)
-				}
-			}
-
-			// Update rule with resolved anchor
-			value = fmt.Sprintf("%s %s from %s", amount, metric, resolved)
-		}
-		resolvedEvery = append(resolvedEvery, value)
-	}
-
-	return resolvedEvery, nil // Validation passed
-}
-
 func runAdd(w io.Writer, opts addOptions) error {
 	paths, err := resolveProjectPaths(opts.ProjectName)
 	if err != nil {
 		return err
 	}
-
-	db := task.NewTaskDB(paths.TasksDir)
-	if err := db.LoadAllIfEmpty(); err != nil {
-		return err
-	}
-
-	resolvedEvery, err := validateEvery(opts.Every, paths.BaseDir, db.GetAll())
-	if err != nil {
-		os.Exit(2)
-	}
-	opts.Every = resolvedEvery
-
-	tmplName := strings.TrimSpace(opts.TemplateName)
-	if tmplName == "" {
+	if strings.TrimSpace(opts.TemplateName) == "" {
 		return fmt.Errorf("type is required")
 	}
-
-	templates, err := template.LoadTemplates(paths.TemplatesDir)
-	if err != nil {
-		return err
-	}
-
-	tmpl, ok := templates[tmplName]
-	if !ok {
-		fmt.Fprintln(w, "Unknown type. Available templates:")
-		var names []string
-		for name := range templates {
-			names = append(names, name)
-		}
-		sort.Strings(names)
-		for _, name := range names {
-			desc := templates[name].Meta.Description
-			if desc == "" {
-				desc = "(no description found)"
-			}
-			fmt.Fprintf(w, "  %-15s %s\n", name, desc)
-		}
-		return fmt.Errorf("unknown type %q", tmplName)
-	}
-
-	title := strings.TrimSpace(opts.Title)
-	if title == "" {
+	if strings.TrimSpace(opts.Title) == "" {
 		return fmt.Errorf("title is required (use --title or provide it as an argument)")
 	}
-
-	// Reject placeholder titles that indicate the template wasn't properly filled in
-	invalidTitles := []string{"description", "task title", "new task", "title", "summary", "todo"}
-	lowerTitle := strings.ToLower(title)
-	for _, invalid := range invalidTitles {
-		if lowerTitle == invalid {
-			return fmt.Errorf("title %q looks like a placeholder; please provide a descriptive title", title)
-		}
-	}
-
-	roleName := strings.TrimSpace(opts.Role)
-	if !opts.RoleSpecified {
-		roleName = strings.TrimSpace(tmpl.Meta.Role)
-	}
-	if roleName == "" {
+	if opts.RoleSpecified && strings.TrimSpace(opts.Role) == "" {
 		return fmt.Errorf("role is required (use --role or set role in template frontmatter)")
 	}
 
-	roles, err := rPkg.LoadRoles(paths.RolesDir)
+	cfg, err := loadConfig(paths.BaseDir)
 	if err != nil {
 		return err
 	}
 
-	if _, ok := roles[roleName]; !ok {
-		fmt.Fprintln(w, "Invalid role. Available roles:")
-		var names []string
-		for name := range roles {
-			names = append(names, name)
-		}
-		sort.Strings(names)
-		for _, name := range names {
-			desc := roles[name].Meta.Description
-			if desc == "" {
-				desc = "(no description found)"
-			}
-			fmt.Fprintf(w, "  %-15s %s\n", name, desc)
-		}
-		return fmt.Errorf("invalid role %q", roleName)
-	}
-
-	priority := task.NormalizePriority(opts.Priority)
-	if !opts.PrioritySpecified {
-		if pStr, ok := tmpl.Meta.Priority.(string); ok && pStr != "" {
-			priority = task.NormalizePriority(pStr)
+	req := create.Request{
+		TemplateName: opts.TemplateName,
+		Title:        opts.Title,
+		Parent:       opts.Parent,
+		Blockers:     opts.Blockers,
+		Blocks:       opts.Blocks,
+		Every:        opts.Every,
+		Vars:         opts.Vars,
+		Fields:       opts.Fields,
+		Body:         opts.Body,
+	}
+	if opts.RoleSpecified {
+		req.Role = opts.Role
+	}
+	if opts.PrioritySpecified {
+		req.Priority = opts.Priority
+	}
+
+	project := create.Project{
+		BaseDir:      paths.BaseDir,
+		TasksDir:     paths.TasksDir,
+		TemplatesDir: paths.TemplatesDir,
+		RolesDir:     paths.RolesDir,
+		Remote:       remoteTasks(),
+	}
+	result, err := create.Task(project, cfg, req)
+	var missingErr *template.MissingVarsError
+	if errors.As(err, &missingErr) && opts.Prompt != nil {
+		answers, perr := promptVars(opts.Prompt, os.Stderr, missingErr.Vars)
+		if perr != nil {
+			return perr
 		}
+		req.Vars = mergeVars(req.Vars, answers)
+		result, err = create.Task(project, cfg, req)
 	}
-	if !task.IsValidPriority(priority) {
-		return fmt.Errorf("invalid priority: %s", priority)
-	}
-
-	parent := strings.TrimSpace(opts.Parent)
-	if parent != "" {
-		resolvedParent, err := db.ResolveID(parent)
-		if err != nil {
-			return fmt.Errorf("parent task %s does not exist: %w", parent, err)
-		}
-		parent = resolvedParent
-		_, err = db.Get(parent)
-		if err != nil {
-			return fmt.Errorf("parent task %s does not exist: %w", parent, err)
-		}
-	}
-
-	prefix := "T"
-	if strings.Contains(strings.ToLower(tmplName), "epic") {
-		prefix = "E"
-	}
-
-	id, err := idgen.GenerateID(prefix, title)
 	if err != nil {
-		return err
-	}
-
-	taskFile := filepath.Join(paths.TasksDir, id+".md")
-	if _, err := os.Stat(taskFile); err == nil {
-		return fmt.Errorf("task file already exists: %s", taskFile)
-	}
-
-	blockers, err := db.ResolveIDs(normalizeTaskIDs(opts.Blockers))
-	if err != nil {
-		return err
-	}
-	blocks, err := db.ResolveIDs(normalizeTaskIDs(opts.Blocks))
-	if err != nil {
-		return err
-	}
-	now := time.Now().UTC()
-	meta := task.Metadata{
-		Type:          tmplName,
-		Role:          roleName,
-		Priority:      priority,
-		Parent:        parent,
-		Blockers:      []string{},
-		Blocks:        []string{},
-		DateCreated:   now,
-		DateEdited:    now,
-		OwnerApproval: false,
-		Completed:     false,
-		Every:         opts.Every,
-	}
-
-	body := renderTemplateBody(tmpl.BodyContent, map[string]string{
-		"Title":               title,
-		"SuggestedSubtaskDir": fmt.Sprintf("%s-subtask", id),
-		"Body":                opts.Body,
-	})
-	if opts.Body != "" && !strings.Contains(tmpl.BodyContent, "{{ .Body }}") {
-		if strings.TrimSpace(body) != "" {
-			body += "\n\n"
-		}
-		body += opts.Body
-	}
-	if err := writeTaskFile(taskFile, meta, body); err != nil {
-		return err
-	}
-
-	fmt.Fprintf(w, "✓ Task created: %s\n", id)
-
-	// Load the new task and set up blocker/blocks relationships via TaskDB
-	if len(blockers) > 0 || len(blocks) > 0 {
-		if _, err := db.Load(id); err != nil {
-			return fmt.Errorf("failed to load new task: %w", err)
-		}
-		for _, blockerID := range blockers {
-			if err := db.AddBlocker(id, blockerID); err != nil {
-				return fmt.Errorf("failed to add blocker %s: %w", blockerID, err)
+		var ruleErr *task.RecurrenceRuleError
+		var tmplErr *create.UnknownTemplateError
+		var roleErr *create.UnknownRoleError
+		switch {
+		case errors.As(err, &ruleErr):
+			exitInvalidEvery(err)
+		case errors.As(err, &tmplErr):
+			fmt.Fprintln(w, "Unknown type. Available templates:")
+			var names []string
+			for name := range tmplErr.Templates {
+				names = append(names, name)
 			}
-		}
-		for _, blockedID := range blocks {
-			if err := db.AddBlocked(id, blockedID); err != nil {
-				return fmt.Errorf("failed to add blocked %s: %w", blockedID, err)
+			sort.Strings(names)
+			for _, name := range names {
+				desc := tmplErr.Templates[name].Meta.Description
+				if desc == "" {
+					desc = "(no description found)"
+				}
+				fmt.Fprintf(w, "  %-15s %s\n", name, desc)
 			}
-		}
-		if _, err := db.SaveDirty(); err != nil {
-			return fmt.Errorf("failed to write blocker updates: %w", err)
-		}
-	}
-
-	if len(opts.Every) > 0 {
-		activeLog, err := activity.Open(paths.BaseDir)
-		if err == nil {
-			defer activeLog.Close()
-			for _, rule := range opts.Every {
-				parts := strings.Fields(rule)
-				if len(parts) >= 2 {
-					metric := parts[1]
-					anchor := ""
-					if len(parts) >= 4 && parts[2] == "from" {
-						anchor = strings.Join(parts[3:], " ")
-					}
-
-					if metric == "commits" || metric == "lines_changed" {
-						if anchor == "HEAD" || anchor == "" {
-							if resolved, err := task.ResolveGitHash(paths.BaseDir, "HEAD"); err == nil {
-								_ = activeLog.WriteRecurrenceAnchorResolution(id, "HEAD", resolved)
-							}
-						}
-					} else {
-						if anchor == "now" || anchor == "" {
-							_ = activeLog.WriteRecurrenceAnchorResolution(id, "now", now.Format("Jan 2 2006 15:04 MST"))
-						}
-					}
+		case errors.As(err, &roleErr):
+			fmt.Fprintln(w, "Invalid role. Available roles:")
+			var names []string
+			for name := range roleErr.Roles {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				desc := roleErr.Roles[name].Meta.Description
+				if desc == "" {
+					desc = "(no description found)"
 				}
+				fmt.Fprintf(w, "  %-15s %s\n", name, desc)
 			}
 		}
+		return err
 	}
 
-	if parent != "" {
-		// Load the newly created task into the DB
-		if _, err := db.Load(id); err != nil {
-			return fmt.Errorf("failed to load new task: %w", err)
-		}
-		if _, err := db.UpdateParentTodos(parent); err != nil {
-			return fmt.Errorf("failed to update parent task TODO entries: %w", err)
-		}
-		if _, err := db.SaveDirty(); err != nil {
-			return fmt.Errorf("failed to write parent task updates: %w", err)
-		}
-	}
+	fmt.Fprintf(w, "✓ Task created: %s\n", result.ID)
 
 	// TODO: This should not be necessary
 	if err := runRepair(w, paths.TasksDir, paths.RootTasksFile, paths.FreeTasksFile, "text"); err != nil {
@@ -418,52 +223,82 @@ func runAdd(w io.Writer, opts addOptions) error {
 	return nil
 }
 
-func normalizeTaskIDs(items []string) []string {
-	seen := map[string]struct{}{}
-	var out []string
+// parseVarFlags turns repeated --var key=value flags into a map.
+func parseVarFlags(items []string) (map[string]string, error) {
+	return parseKeyValueFlags("var", items)
+}
+
+// parseKeyValueFlags turns repeated --<flag> key=value flags into a map.
+func parseKeyValueFlags(flag string, items []string) (map[string]string, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+	values := make(map[string]string, len(items))
 	for _, item := range items {
-		parts := strings.Split(item, ",")
-		for _, part := range parts {
-			trimmed := strings.TrimSpace(part)
-			if trimmed == "" {
-				continue
+		key, value, ok := strings.Cut(item, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid --%s %q (expected key=value)", flag, item)
+		}
+		values[key] = value
+	}
+	return values, nil
+}
+
+// promptVars asks for each var on w and reads answers from r, repeating the
+// question until a valid answer is given.
+func promptVars(r io.Reader, w io.Writer, vars []template.Var) (map[string]string, error) {
+	reader := bufio.NewReader(r)
+	answers := make(map[string]string, len(vars))
+	for _, v := range vars {
+		for {
+			fmt.Fprintf(w, "%s", v.Name)
+			if v.Kind() != template.VarString {
+				fmt.Fprintf(w, " %s", v.Placeholder())
+			}
+			if v.Help != "" {
+				fmt.Fprintf(w, " - %s", v.Help)
+			}
+			fmt.Fprint(w, ": ")
+			line, err := reader.ReadString('\n')
+			answer := strings.TrimSpace(line)
+			if answer != "" {
+				if cerr := v.Validate(answer); cerr != nil {
+					fmt.Fprintln(w, cerr)
+				} else {
+					answers[v.Name] = answer
+					break
+				}
 			}
-			if _, ok := seen[trimmed]; ok {
-				continue
+			if err != nil {
+				return nil, fmt.Errorf("no value for required var %s", v.Name)
 			}
-			seen[trimmed] = struct{}{}
-			out = append(out, trimmed)
 		}
 	}
-	sort.Strings(out)
-	return out
+	return answers, nil
 }
 
-func renderTemplateBody(body string, data map[string]string) string {
-	out := body
-	for key, value := range data {
-		out = strings.ReplaceAll(out, "{{ ."+key+" }}", value)
+func mergeVars(base, extra map[string]string) map[string]string {
+	out := make(map[string]string, len(base)+len(extra))
+	for k, v := range base {
+		out[k] = v
+	}
+	for k, v := range extra {
+		out[k] = v
 	}
 	return out
 }
 
-func writeTaskFile(path string, meta task.Metadata, body string) error {
-	frontmatterBytes, err := yaml.Marshal(&meta)
-	if err != nil {
-		return fmt.Errorf("failed to marshal frontmatter: %w", err)
+// exitInvalidEvery reports a rejected --every value and exits with status 2.
+func exitInvalidEvery(err error) {
+	var ruleErr *task.RecurrenceRuleError
+	if errors.As(err, &ruleErr) {
+		fmt.Fprintf(os.Stderr, "strand: error: invalid --every value: %v\n", ruleErr.Err)
+		fmt.Fprintf(os.Stderr, "hint: --every %q\n", ruleErr.Hint)
+	} else {
+		fmt.Fprintf(os.Stderr, "strand: error: %v\n", err)
 	}
-	frontmatterBytes = bytes.TrimSpace(frontmatterBytes)
-
-	var sb strings.Builder
-	sb.WriteString("---\n")
-	sb.Write(frontmatterBytes)
-	sb.WriteString("\n---\n\n")
-	sb.WriteString(body)
-	if !strings.HasSuffix(body, "\n") {
-		sb.WriteString("\n")
-	}
-
-	return os.WriteFile(path, []byte(sb.String()), 0o644)
+	os.Exit(2)
 }
 
 func readStdin() (string, error) {
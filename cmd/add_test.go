@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ricochet1k/strandyard/pkg/task"
+)
+
+func TestAddUsesConfiguredPrefixesAndPlaceholders(t *testing.T) {
+	paths := setupTestProject(t, initOptions{ProjectName: "", StorageMode: storageLocal})
+	roleName := testRoleName(t, "config-add")
+	writeRoleFile(t, filepath.Join(paths.RolesDir, roleName+".md"), roleName)
+	tmpl := "---\nrole: " + roleName + "\npriority: medium\n---\n\n# {{ .Title }}\n\n{{ .Body }}\n"
+	if err := os.WriteFile(filepath.Join(paths.TemplatesDir, "bug.md"), []byte(tmpl), 0o644); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+	config := "add:\n  id_prefixes:\n    bug: B\n  placeholder_titles: [tbd]\n"
+	if err := os.WriteFile(filepath.Join(paths.BaseDir, "strand.yaml"), []byte(config), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	err := runAdd(io.Discard, addOptions{TemplateName: "bug", Title: "TBD"})
+	if err == nil || !strings.Contains(err.Error(), "placeholder") {
+		t.Fatalf("expected placeholder title error, got %v", err)
+	}
+	if err := runAdd(io.Discard, addOptions{TemplateName: "bug", Title: "Crash on start"}); err != nil {
+		t.Fatalf("runAdd failed: %v", err)
+	}
+	matches, err := filepath.Glob(filepath.Join(paths.TasksDir, "B*-crash-on-start.md"))
+	if err != nil || len(matches) != 1 {
+		t.Fatalf("expected one B-prefixed task, got %v (%v)", matches, err)
+	}
+}
+
+func TestAddUsesTemplateIDPrefix(t *testing.T) {
+	paths := setupTestProject(t, initOptions{ProjectName: "", StorageMode: storageLocal})
+	roleName := testRoleName(t, "prefix-add")
+	writeRoleFile(t, filepath.Join(paths.RolesDir, roleName+".md"), roleName)
+	tmpl := "---\nrole: " + roleName + "\npriority: medium\nid_prefix: BUG\n---\n\n# {{ .Title }}\n\n{{ .Body }}\n"
+	if err := os.WriteFile(filepath.Join(paths.TemplatesDir, "defect.md"), []byte(tmpl), 0o644); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+
+	if err := runAdd(io.Discard, addOptions{TemplateName: "defect", Title: "Crash on save"}); err != nil {
+		t.Fatalf("runAdd failed: %v", err)
+	}
+	matches, err := filepath.Glob(filepath.Join(paths.TasksDir, "BUG*-crash-on-save.md"))
+	if err != nil || len(matches) != 1 {
+		t.Fatalf("expected one BUG-prefixed task, got %v (%v)", matches, err)
+	}
+
+	tasks, err := task.NewParser().LoadTasks(paths.TasksDir)
+	if err != nil {
+		t.Fatalf("LoadTasks failed: %v", err)
+	}
+	validator := task.NewValidatorWithRoles(tasks, paths.RolesDir)
+	if errs := validator.ValidateAndRepair(); len(errs) != 0 {
+		t.Fatalf("expected multi-letter ID to validate, got %v", errs)
+	}
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/ricochet1k/strandyard/pkg/task"
+	"github.com/ricochet1k/strandyard/pkg/template"
+)
+
+func TestAddRendersTemplateVars(t *testing.T) {
+	paths := setupTestProject(t, initOptions{ProjectName: "", StorageMode: storageLocal})
+	roleName := testRoleName(t, "vars-add")
+	writeRoleFile(t, filepath.Join(paths.RolesDir, roleName+".md"), roleName)
+	tmpl := "---\nrole: " + roleName + "\nvars:\n  - name: component\n---\n\n# {{ .Title }}\n\nComponent: {{ .Vars.component | default \"none\" }}\n"
+	if err := os.WriteFile(filepath.Join(paths.TemplatesDir, "scoped.md"), []byte(tmpl), 0o644); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+
+	vars, err := parseVarFlags([]string{"component=storage"})
+	if err != nil {
+		t.Fatalf("parseVarFlags: %v", err)
+	}
+	if _, err := parseVarFlags([]string{"novalue"}); err == nil {
+		t.Fatal("expected error for --var without '='")
+	}
+	if err := runAdd(io.Discard, addOptions{TemplateName: "scoped", Title: "Scoped work", Vars: map[string]string{"other": "x"}}); err == nil {
+		t.Fatal("expected undeclared var to be rejected")
+	}
+	if err := runAdd(io.Discard, addOptions{TemplateName: "scoped", Title: "Scoped work", Vars: vars}); err != nil {
+		t.Fatalf("runAdd failed: %v", err)
+	}
+	matches, err := filepath.Glob(filepath.Join(paths.TasksDir, "T*-scoped-work.md"))
+	if err != nil || len(matches) != 1 {
+		t.Fatalf("expected one task, got %v (%v)", matches, err)
+	}
+	data, err := os.ReadFile(matches[0])
+	if err != nil {
+		t.Fatalf("read task: %v", err)
+	}
+	if !strings.Contains(string(data), "Component: storage") {
+		t.Fatalf("expected rendered var in task body:\n%s", data)
+	}
+}
+
+const severityTemplate = "---\nrole: %s\nvars:\n  - name: severity\n    type: enum\n    values: [low, high]\n    required: true\n  - name: flaky\n    type: bool\n---\n\n# {{ .Title }}\n\nSeverity: {{ .Vars.severity }}\n"
+
+func writeSeverityTemplate(t *testing.T, paths projectPaths, roleName string) {
+	t.Helper()
+	tmpl := strings.Replace(severityTemplate, "%s", roleName, 1)
+	if err := os.WriteFile(filepath.Join(paths.TemplatesDir, "bug.md"), []byte(tmpl), 0o644); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+}
+
+func TestAddRequiredVarsPromptStoreAndFilter(t *testing.T) {
+	paths := setupTestProject(t, initOptions{ProjectName: "", StorageMode: storageLocal})
+	roleName := testRoleName(t, "typed-vars")
+	writeRoleFile(t, filepath.Join(paths.RolesDir, roleName+".md"), roleName)
+	writeSeverityTemplate(t, paths, roleName)
+
+	err := runAdd(io.Discard, addOptions{TemplateName: "bug", Title: "Crash on save"})
+	var missing *template.MissingVarsError
+	if !errors.As(err, &missing) || !strings.Contains(err.Error(), "--var severity=<low|high>") {
+		t.Fatalf("expected usage listing for missing var, got %v", err)
+	}
+
+	// An invalid answer is asked again.
+	prompt := strings.NewReader("medium\nhigh\n")
+	if err := runAdd(io.Discard, addOptions{TemplateName: "bug", Title: "Crash on save", Prompt: prompt}); err != nil {
+		t.Fatalf("runAdd with prompt failed: %v", err)
+	}
+	if err := runAdd(io.Discard, addOptions{TemplateName: "bug", Title: "Slow start", Vars: map[string]string{"severity": "low", "flaky": "yes"}}); err == nil {
+		t.Fatal("expected invalid bool to be rejected")
+	}
+	if err := runAdd(io.Discard, addOptions{TemplateName: "bug", Title: "Slow start", Vars: map[string]string{"severity": "low"}}); err != nil {
+		t.Fatalf("runAdd failed: %v", err)
+	}
+
+	tasks, _, err := task.ListTasks(paths.TasksDir, task.ListOptions{Vars: map[string]string{"severity": "high"}})
+	if err != nil {
+		t.Fatalf("ListTasks: %v", err)
+	}
+	if len(tasks) != 1 || !strings.HasSuffix(tasks[0].ID, "-crash-on-save") {
+		t.Fatalf("expected only the high-severity task, got %v", tasks)
+	}
+	if tasks[0].Meta.Vars["severity"] != "high" {
+		t.Fatalf("expected severity stored in metadata, got %v", tasks[0].Meta.Vars)
+	}
+}
+
+func TestPromptVarsRequiresAnswer(t *testing.T) {
+	vars := []template.Var{{Name: "severity", Type: template.VarEnum, Values: []string{"low", "high"}, Help: "impact"}}
+	var out bytes.Buffer
+	if _, err := promptVars(strings.NewReader("\n"), &out, vars); err == nil {
+		t.Fatal("expected error when input ends without an answer")
+	}
+	if !strings.Contains(out.String(), "severity <low|high> - impact: ") {
+		t.Fatalf("unexpected prompt %q", out.String())
+	}
+}
+
+func TestAddToolSchemaListsTemplateVars(t *testing.T) {
+	paths := setupTestProject(t, initOptions{ProjectName: "", StorageMode: storageLocal})
+	writeSeverityTemplate(t, paths, "developer")
+	templates, err := template.LoadTemplates(paths.TemplatesDir)
+	if err != nil {
+		t.Fatalf("LoadTemplates: %v", err)
+	}
+
+	s := server.NewMCPServer("strand", "test")
+	registerMCPTools(s)
+	addTool, ok := s.ListTools()["strand_add"]
+	if !ok {
+		t.Fatal("strand_add tool not registered")
+	}
+	schema, err := addToolSchema(addTool.Tool.RawInputSchema, templates)
+	if err != nil {
+		t.Fatalf("addToolSchema: %v", err)
+	}
+	got := string(schema)
+	for _, want := range []string{`"const":"bug"`, `"required":["severity"]`, `"enum":["low","high"]`, `"enum":["true","false"]`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %s in schema:\n%s", want, got)
+		}
+	}
+}
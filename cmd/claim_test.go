@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"bytes"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -22,7 +23,7 @@ func TestClaimMarksTaskInProgress(t *testing.T) {
 	writeClaimTaskFile(t, paths.TasksDir, taskID, roleName)
 
 	var out bytes.Buffer
-	if err := runClaim(&out, taskID); err != nil {
+	if err := runClaim(&out, taskID, ""); err != nil {
 		t.Fatalf("runClaim failed: %v", err)
 	}
 	if !strings.Contains(out.String(), "status set to in_progress") {
@@ -42,6 +43,68 @@ func TestClaimMarksTaskInProgress(t *testing.T) {
 	}
 }
 
+func TestClaimRespectsWIPLimits(t *testing.T) {
+	paths := setupTestProject(t, initOptions{ProjectName: "", StorageMode: storageLocal})
+	roleName := testRoleName(t, "claim-wip")
+	if err := os.WriteFile(filepath.Join(paths.RolesDir, roleName+".md"), []byte("# "+roleName+"\n"), 0o644); err != nil {
+		t.Fatalf("write role file: %v", err)
+	}
+	config := "wip:\n  agent: 1\n  roles:\n    " + roleName + ": 2\n"
+	if err := os.WriteFile(filepath.Join(paths.BaseDir, "strand.yaml"), []byte(config), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	taskA := "T1a1a-first"
+	taskB := "T2a1a-second"
+	taskC := "T3a1a-third"
+	for _, id := range []string{taskA, taskB, taskC} {
+		writeClaimTaskFile(t, paths.TasksDir, id, roleName)
+	}
+
+	var out bytes.Buffer
+	if err := runClaim(&out, taskA, "alice"); err != nil {
+		t.Fatalf("runClaim failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "WIP role "+roleName+": 1/2") || !strings.Contains(out.String(), "WIP agent alice: 1/1") {
+		t.Fatalf("expected WIP usage in output, got: %s", out.String())
+	}
+
+	err := runClaim(io.Discard, taskB, "alice")
+	if err == nil || !strings.Contains(err.Error(), "WIP limit reached for agent alice") {
+		t.Fatalf("expected agent limit error, got %v", err)
+	}
+	if err := runClaim(io.Discard, taskB, "bob"); err != nil {
+		t.Fatalf("runClaim for second agent failed: %v", err)
+	}
+	err = runClaim(io.Discard, taskC, "carol")
+	if err == nil || !strings.Contains(err.Error(), "WIP limit reached for role "+roleName) {
+		t.Fatalf("expected role limit error, got %v", err)
+	}
+
+	db := task.NewTaskDB(paths.TasksDir)
+	if err := db.LoadAllIfEmpty(); err != nil {
+		t.Fatalf("load tasks: %v", err)
+	}
+	tk, err := db.Get(taskB)
+	if err != nil {
+		t.Fatalf("get task: %v", err)
+	}
+	if tk.Meta.ClaimedBy != "bob" {
+		t.Fatalf("expected claimed_by bob, got %q", tk.Meta.ClaimedBy)
+	}
+
+	if err := os.WriteFile(filepath.Join(paths.BaseDir, "strand.yaml"), []byte("wip:\n  roles:\n    "+roleName+": 1\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	var repairOut bytes.Buffer
+	if err := runRepair(&repairOut, paths.TasksDir, paths.RootTasksFile, paths.FreeTasksFile, "text"); err != nil {
+		t.Fatalf("runRepair failed: %v", err)
+	}
+	if !strings.Contains(repairOut.String(), "WARNING: WIP limit exceeded for role "+roleName+" (2/1 in progress)") {
+		t.Fatalf("expected WIP violation in repair output, got: %s", repairOut.String())
+	}
+}
+
 func writeClaimTaskFile(t *testing.T, tasksDir, id, roleName string) {
 	t.Helper()
 	now := time.Date(2026, 2, 7, 12, 0, 0, 0, time.UTC).Format(time.RFC3339)
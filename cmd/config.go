@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ricochet1k/strandyard/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+var configUser bool
+
+// configCmd groups commands that inspect and edit strand.yaml.
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect and edit strand configuration",
+	Long: `Inspect and edit strand configuration.
+
+Settings are layered, later layers overriding earlier ones:
+  1. built-in defaults
+  2. strand.yaml in the strand config dir (user level)
+  3. strand.yaml in the project storage root
+  4. STRAND_* environment variables (e.g. STRAND_NEXT_POLICY)
+Command-line flags override all of them.`,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print the effective value of a config key",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runConfigGet(cmd.OutOrStdout(), projectName, args[0])
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Set a config key in the project (or user) strand.yaml",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runConfigSet(cmd.OutOrStdout(), projectName, args[0], args[1], configUser)
+	},
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List every effective config value and the layer it comes from",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runConfigList(cmd.OutOrStdout(), projectName)
+	},
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check every config layer against the schema",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runConfigValidate(cmd.OutOrStdout(), projectName)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configGetCmd, configSetCmd, configListCmd, configValidateCmd)
+	configSetCmd.Flags().BoolVar(&configUser, "user", false, "write the user-level strand.yaml instead of the project one")
+}
+
+// loadConfig returns the effective configuration for the project stored in
+// baseDir. An empty baseDir loads only the user and environment layers.
+func loadConfig(baseDir string) (config.Config, error) {
+	userDir, err := configDir()
+	if err != nil {
+		return config.Config{}, err
+	}
+	return config.Load(userDir, baseDir)
+}
+
+// configProjectDir returns the current project's storage root, or "" when
+// no project is selected and none can be found from the working directory.
+func configProjectDir(projectName string) (string, error) {
+	paths, err := resolveProjectPaths(projectName)
+	if err != nil {
+		if strings.TrimSpace(projectName) != "" {
+			return "", err
+		}
+		return "", nil
+	}
+	return paths.BaseDir, nil
+}
+
+func loadConfigLayers(projectName string) ([]config.Layer, error) {
+	userDir, err := configDir()
+	if err != nil {
+		return nil, err
+	}
+	projectDir, err := configProjectDir(projectName)
+	if err != nil {
+		return nil, err
+	}
+	return config.LoadLayers(userDir, projectDir)
+}
+
+func runConfigGet(w io.Writer, projectName, key string) error {
+	layers, err := loadConfigLayers(projectName)
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Merge(layers)
+	if err != nil {
+		return err
+	}
+	value, err := config.Get(cfg, key)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(w, value)
+	return nil
+}
+
+func runConfigSet(w io.Writer, projectName, key, value string, user bool) error {
+	var dir string
+	if user {
+		userDir, err := configDir()
+		if err != nil {
+			return err
+		}
+		dir = userDir
+	} else {
+		paths, err := resolveProjectPaths(projectName)
+		if err != nil {
+			return err
+		}
+		dir = paths.BaseDir
+	}
+	if err := config.Set(dir, key, value); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "✓ Set %s = %s in %s/%s\n", key, value, dir, config.FileName)
+	return nil
+}
+
+func runConfigList(w io.Writer, projectName string) error {
+	layers, err := loadConfigLayers(projectName)
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Merge(layers)
+	if err != nil {
+		return err
+	}
+	values, keys, err := config.Flatten(cfg)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		source := config.LayerDefault
+		for _, layer := range layers {
+			if layer.Has(key) {
+				source = layer.Name
+			}
+		}
+		fmt.Fprintf(w, "%s = %s (%s)\n", key, values[key], source)
+	}
+	return nil
+}
+
+func runConfigValidate(w io.Writer, projectName string) error {
+	layers, err := loadConfigLayers(projectName)
+	if err != nil {
+		return err
+	}
+	errs := config.ValidateLayers(layers)
+	for _, e := range errs {
+		fmt.Fprintln(w, "ERROR:", e.Error())
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("config invalid: %d error(s)", len(errs))
+	}
+	fmt.Fprintln(w, "config: ok")
+	return nil
+}
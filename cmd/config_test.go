@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfigSetGetListValidate(t *testing.T) {
+	paths := setupTestProject(t, initOptions{ProjectName: "", StorageMode: storageLocal})
+
+	if err := runConfigSet(io.Discard, "", "next.policy", "oldest", false); err != nil {
+		t.Fatalf("runConfigSet failed: %v", err)
+	}
+	if err := runConfigSet(io.Discard, "", "list.format", "md", true); err != nil {
+		t.Fatalf("runConfigSet --user failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(paths.BaseDir, "strand.yaml")); err != nil {
+		t.Fatalf("expected project strand.yaml: %v", err)
+	}
+
+	var got bytes.Buffer
+	if err := runConfigGet(&got, "", "next.policy"); err != nil {
+		t.Fatalf("runConfigGet failed: %v", err)
+	}
+	if strings.TrimSpace(got.String()) != "oldest" {
+		t.Fatalf("expected oldest, got %q", got.String())
+	}
+
+	t.Setenv("STRAND_NEXT_POLICY", "aging")
+	var list bytes.Buffer
+	if err := runConfigList(&list, ""); err != nil {
+		t.Fatalf("runConfigList failed: %v", err)
+	}
+	for _, want := range []string{
+		"next.policy = aging (env)",
+		"list.format = md (user)",
+		"init.storage = global (default)",
+	} {
+		if !strings.Contains(list.String(), want) {
+			t.Errorf("config list missing %q:\n%s", want, list.String())
+		}
+	}
+
+	var valid bytes.Buffer
+	if err := runConfigValidate(&valid, ""); err != nil {
+		t.Fatalf("runConfigValidate failed: %v\n%s", err, valid.String())
+	}
+
+	if err := os.WriteFile(filepath.Join(paths.BaseDir, "strand.yaml"), []byte("next:\n  polcy: oldest\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	var invalid bytes.Buffer
+	if err := runConfigValidate(&invalid, ""); err == nil {
+		t.Fatalf("expected validation failure, got: %s", invalid.String())
+	}
+	if !strings.Contains(invalid.String(), "polcy") {
+		t.Fatalf("expected unknown key in output, got: %s", invalid.String())
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/ricochet1k/strandyard/pkg/create"
 	"github.com/ricochet1k/strandyard/pkg/role"
 	"github.com/ricochet1k/strandyard/pkg/task"
 	"github.com/spf13/cobra"
@@ -22,6 +23,7 @@ var (
 	editBlocks   []string
 	editEvery    []string
 	editStatus   string
+	editFields   []string
 )
 
 // editCmd represents the edit command
@@ -60,6 +62,7 @@ func init() {
 	editCmd.Flags().StringSliceVar(&editEvery, "every", nil, `recurrence rule: "<amount> <metric> [from <anchor>]" (repeatable)
 metrics: days, weeks, months, commits, lines_changed, tasks_completed
 examples: "10 days", "50 commits from HEAD", "20 tasks_completed from T1a1a"`)
+	editCmd.Flags().StringArrayVar(&editFields, "field", nil, "custom field declared in config as key=value (repeatable); an empty value removes it")
 	editCmd.Flags().StringVarP(&editStatus, "status", "s", "", fmt.Sprintf("task status: %s", task.FormatStatusListForUser()))
 }
 
@@ -71,6 +74,7 @@ func runEdit(cmd *cobra.Command, inputID, newBody string) error {
 	}
 
 	db := task.NewTaskDB(paths.TasksDir)
+	db.SetRemote(remoteTasks())
 	t, taskID, err := db.GetResolved(inputID)
 	if err != nil {
 		return err
@@ -110,7 +114,7 @@ func runEdit(cmd *cobra.Command, inputID, newBody string) error {
 	}
 
 	if cmd.Flags().Changed("blocker") {
-		newBlockers, err := db.ResolveIDs(normalizeTaskIDs(editBlockers))
+		newBlockers, err := db.ResolveIDs(create.NormalizeTaskIDs(editBlockers))
 		if err != nil {
 			return err
 		}
@@ -141,7 +145,7 @@ func runEdit(cmd *cobra.Command, inputID, newBody string) error {
 	}
 
 	if cmd.Flags().Changed("blocks") {
-		newBlocks, err := db.ResolveIDs(normalizeTaskIDs(editBlocks))
+		newBlocks, err := db.ResolveIDs(create.NormalizeTaskIDs(editBlocks))
 		if err != nil {
 			return err
 		}
@@ -172,14 +176,20 @@ func runEdit(cmd *cobra.Command, inputID, newBody string) error {
 	}
 
 	if cmd.Flags().Changed("every") {
-		resolvedEvery, err := validateEvery(editEvery, paths.BaseDir, db.GetAll())
+		resolvedEvery, err := task.ValidateEvery(editEvery, paths.BaseDir, db.GetAll())
 		if err != nil {
-			os.Exit(2)
+			exitInvalidEvery(err)
 		}
 		t.Meta.Every = resolvedEvery
 		t.MarkDirty()
 	}
 
+	if cmd.Flags().Changed("field") {
+		if err := editTaskFields(t, paths.BaseDir, editFields); err != nil {
+			return err
+		}
+	}
+
 	if isStdinRedirected() {
 		if err := db.SetBody(taskID, newBody); err != nil {
 			return err
@@ -244,3 +254,32 @@ func isStdinRedirected() bool {
 	}
 	return (info.Mode() & os.ModeCharDevice) == 0
 }
+
+// editTaskFields applies --field key=value flags to t; an empty value removes
+// the field.
+func editTaskFields(t *task.Task, baseDir string, items []string) error {
+	values, err := parseKeyValueFlags("field", items)
+	if err != nil {
+		return err
+	}
+	cfg, err := loadConfig(baseDir)
+	if err != nil {
+		return err
+	}
+	for name, raw := range values {
+		spec, ok := cfg.Fields[name]
+		if !ok {
+			return fmt.Errorf("unknown field %q (declared: %s)", name, strings.Join(cfg.Fields.Names(), ", "))
+		}
+		var value interface{}
+		if strings.TrimSpace(raw) != "" {
+			if value, err = spec.Parse(raw); err != nil {
+				return fmt.Errorf("invalid field %s: %w", name, err)
+			}
+		}
+		if err := t.SetField(name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ricochet1k/strandyard/pkg/task"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportFormat string
+	exportOutput string
+)
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export tasks as JSON Lines, CSV, or a Markdown report",
+	Long: `Export tasks for spreadsheets and other tools.
+
+Formats:
+  jsonl  one JSON object per task: the full task snapshot plus its body
+  csv    one row per task with the columns from --columns (any list column)
+  md     a single Markdown report with one section per epic and one
+         "Other" section for tasks outside any epic
+
+Tasks are filtered and sorted with the same flags as strand list; completed
+tasks are included unless --completed is given.
+
+Examples:
+  strand export > tasks.jsonl
+  strand export --format csv --columns id,title,status,estimate -o tasks.csv
+  strand export --format md --status open -o report.md`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		paths, err := resolveProjectPaths(projectName)
+		if err != nil {
+			return err
+		}
+		cfg, err := loadConfig(paths.BaseDir)
+		if err != nil {
+			return err
+		}
+		opts, err := exportOptionsFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+		opts.FieldSchema = cfg.Fields
+
+		if exportOutput == "" || exportOutput == "-" {
+			return runExport(cmd.OutOrStdout(), paths.TasksDir, opts)
+		}
+		f, err := os.Create(exportOutput)
+		if err != nil {
+			return err
+		}
+		if err := runExport(f, paths.TasksDir, opts); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+
+	addListFilterFlags(exportCmd)
+	exportCmd.Flags().BoolVar(&listCompleted, "completed", false, "filter by completion (default: export completed and uncompleted tasks)")
+	exportCmd.Flags().StringVar(&exportFormat, "format", task.ExportJSONL, "output format: jsonl|csv|md")
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "write to this file instead of stdout")
+	exportCmd.Flags().StringVar(&listColumns, "columns", "", "comma-separated columns for csv, or metadata shown in md")
+}
+
+// exportOptionsFromFlags reads list's filter flags, keeping completed tasks
+// unless --completed is given.
+func exportOptionsFromFlags(cmd *cobra.Command) (task.ListOptions, error) {
+	opts, err := listOptionsFromFlags(cmd)
+	if err != nil {
+		return opts, err
+	}
+	if !cmd.Flags().Changed("completed") {
+		opts.Completed = nil
+	}
+	opts.Format = strings.ToLower(strings.TrimSpace(exportFormat))
+	opts.Color = false
+	return opts, nil
+}
+
+func runExport(w io.Writer, tasksRoot string, opts task.ListOptions) error {
+	return task.ExportTasks(w, tasksRoot, opts)
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ricochet1k/strandyard/pkg/task"
+)
+
+func TestAddEditAndRepairCustomFields(t *testing.T) {
+	paths := setupTestProject(t, initOptions{ProjectName: "", StorageMode: storageLocal})
+	roleName := testRoleName(t, "fields")
+	writeRoleFile(t, filepath.Join(paths.RolesDir, roleName+".md"), roleName)
+	tmpl := "---\nrole: " + roleName + "\npriority: medium\n---\n\n# {{ .Title }}\n"
+	if err := os.WriteFile(filepath.Join(paths.TemplatesDir, "defect.md"), []byte(tmpl), 0o644); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+	cfg := "fields:\n  customer:\n    required_for: [defect]\n  risk:\n    type: enum\n    values: [low, high]\n"
+	if err := os.WriteFile(filepath.Join(paths.BaseDir, "strand.yaml"), []byte(cfg), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	err := runAdd(io.Discard, addOptions{TemplateName: "defect", Title: "Crash on save"})
+	if err == nil || !strings.Contains(err.Error(), "customer") {
+		t.Fatalf("expected missing required field error, got %v", err)
+	}
+	if err := runAdd(io.Discard, addOptions{TemplateName: "defect", Title: "Crash on save", Fields: map[string]string{"customer": "Acme", "risk": "high"}}); err != nil {
+		t.Fatalf("runAdd failed: %v", err)
+	}
+
+	db := task.NewTaskDB(paths.TasksDir)
+	if err := db.LoadAll(); err != nil {
+		t.Fatalf("LoadAll: %v", err)
+	}
+	var created *task.Task
+	for _, tsk := range db.GetAll() {
+		created = tsk
+	}
+	if created.Field("customer") != "Acme" || created.Field("risk") != "high" {
+		t.Fatalf("expected fields to be stored, got %v", created.Meta.Fields)
+	}
+
+	if err := editTaskFields(created, paths.BaseDir, []string{"risk=extreme"}); err == nil {
+		t.Fatal("expected invalid enum value to be rejected")
+	}
+	if err := editTaskFields(created, paths.BaseDir, []string{"risk="}); err != nil {
+		t.Fatalf("editTaskFields: %v", err)
+	}
+	if _, ok := created.Meta.Fields["risk"]; ok {
+		t.Fatal("expected empty value to remove the field")
+	}
+
+	// A hand edit that breaks the schema is reported by repair.
+	data, err := os.ReadFile(created.FilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	broken := strings.Replace(string(data), "risk: high\n", "risk: extreme\n", 1)
+	if err := os.WriteFile(created.FilePath, []byte(broken), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	err = runRepair(&out, paths.TasksDir, paths.RootTasksFile, paths.FreeTasksFile, "text")
+	if err == nil || !strings.Contains(out.String(), "invalid field risk") {
+		t.Fatalf("expected repair to report invalid field, got %v:\n%s", err, out.String())
+	}
+}
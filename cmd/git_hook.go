@@ -0,0 +1,241 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ricochet1k/strandyard/pkg/activity"
+	"github.com/ricochet1k/strandyard/pkg/task"
+	"github.com/spf13/cobra"
+)
+
+// gitHookMarker identifies hook scripts written by strand.
+const gitHookMarker = "# strand: link commits to tasks"
+
+// gitHookGuard lets git operations proceed on machines without strand.
+const gitHookGuard = "command -v strand >/dev/null 2>&1 || exit 0"
+
+// gitHooks maps each installed hook to the hook runner it invokes.
+var gitHooks = map[string]string{
+	"commit-msg":  `exec strand git hook run commit-msg "$1"`,
+	"post-commit": `exec strand git hook run post-commit`,
+	"post-merge":  `exec strand git hook run post-merge`,
+}
+
+var gitCmd = &cobra.Command{
+	Use:   "git",
+	Short: "Git integration",
+}
+
+var gitHookCmd = &cobra.Command{
+	Use:   "hook",
+	Short: "Link commits to tasks with commit trailers",
+	Long: `Git hooks that read Strand-Task and Strand-Completes trailers from commit messages.
+
+  Strand-Task: T3k7x        append the commit to the task's Progress section
+  Strand-Completes: T3k7x   also mark the task completed
+
+The commit-msg hook rejects commits whose trailers name unknown tasks; the post-commit hook updates the tasks and logs commit_linked activity events. The post-merge hook regenerates master lists the merge driver marked stale.`,
+}
+
+var gitHookInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install the commit-msg, post-commit and post-merge hooks",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runGitHookInstall(cmd.OutOrStdout(), gitHookForce)
+	},
+}
+
+var gitHookRunCmd = &cobra.Command{
+	Use:    "run <hook> [args]",
+	Short:  "Run a strand git hook (called by git)",
+	Hidden: true,
+	Args:   cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		switch args[0] {
+		case "commit-msg":
+			if len(args) < 2 {
+				return fmt.Errorf("commit-msg hook needs the message file")
+			}
+			return runCommitMsgHook(projectName, args[1])
+		case "post-commit":
+			return runPostCommitHook(cmd.OutOrStdout(), projectName)
+		case "post-merge":
+			return runPostMergeHook(cmd.OutOrStdout(), projectName)
+		default:
+			return fmt.Errorf("unknown hook %q", args[0])
+		}
+	},
+}
+
+var gitHookForce bool
+
+func init() {
+	rootCmd.AddCommand(gitCmd)
+	gitCmd.AddCommand(gitHookCmd)
+	gitHookCmd.AddCommand(gitHookInstallCmd)
+	gitHookCmd.AddCommand(gitHookRunCmd)
+	gitHookInstallCmd.Flags().BoolVar(&gitHookForce, "force", false, "overwrite existing hooks not installed by strand")
+}
+
+func runGitHookInstall(w io.Writer, force bool) error {
+	gitRoot, err := gitRootDir()
+	if err != nil {
+		return err
+	}
+	out, err := gitOutput(gitRoot, "rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return err
+	}
+	hooksDir := strings.TrimSpace(out)
+	if !filepath.IsAbs(hooksDir) {
+		hooksDir = filepath.Join(gitRoot, hooksDir)
+	}
+	if err := os.MkdirAll(hooksDir, 0o755); err != nil {
+		return err
+	}
+
+	for _, name := range []string{"commit-msg", "post-commit", "post-merge"} {
+		path := filepath.Join(hooksDir, name)
+		if existing, err := os.ReadFile(path); err == nil && !strings.Contains(string(existing), gitHookMarker) && !force {
+			return fmt.Errorf("%s already exists and was not installed by strand (use --force to overwrite)", path)
+		}
+		script := "#!/bin/sh\n" + gitHookMarker + " (installed by strand git hook install)\n" +
+			gitHookGuard + "\n" + gitHooks[name] + "\n"
+		if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "✓ Installed %s\n", path)
+	}
+	return nil
+}
+
+// runCommitMsgHook rejects a commit whose trailers reference unknown tasks.
+func runCommitMsgHook(projectName, messageFile string) error {
+	data, err := os.ReadFile(messageFile)
+	if err != nil {
+		return err
+	}
+	links := task.ParseCommitTrailers(stripCommitComments(string(data)))
+	if len(links) == 0 {
+		return nil
+	}
+	paths, err := resolveProjectPaths(projectName)
+	if err != nil {
+		return err
+	}
+	db := task.NewTaskDB(paths.TasksDir)
+	for _, link := range links {
+		if _, err := db.ResolveID(link.TaskID); err != nil {
+			return fmt.Errorf("commit trailer references unknown task %s: %w", link.TaskID, err)
+		}
+	}
+	return nil
+}
+
+// runPostCommitHook links HEAD to the tasks named in its trailers and
+// completes those it marks with Strand-Completes.
+func runPostCommitHook(w io.Writer, projectName string) error {
+	gitRoot, err := gitRootDir()
+	if err != nil {
+		return err
+	}
+	out, err := gitOutput(gitRoot, "log", "-1", "--format=%H%x00%s%x00%B")
+	if err != nil {
+		return err
+	}
+	parts := strings.SplitN(out, "\x00", 3)
+	if len(parts) != 3 {
+		return fmt.Errorf("unexpected git log output")
+	}
+	hash, subject, message := parts[0], parts[1], parts[2]
+	links := task.ParseCommitTrailers(message)
+	if len(links) == 0 {
+		return nil
+	}
+
+	paths, err := resolveProjectPaths(projectName)
+	if err != nil {
+		return err
+	}
+	db := task.NewTaskDB(paths.TasksDir)
+	activityLog, err := activity.Open(paths.BaseDir)
+	if err != nil {
+		return fmt.Errorf("failed to open activity log: %w", err)
+	}
+	defer activityLog.Close()
+
+	var completes []string
+	for _, link := range links {
+		t, id, err := db.GetResolved(link.TaskID)
+		if err != nil {
+			fmt.Fprintf(w, "⚠️  Skipping %s: %v\n", link.TaskID, err)
+			continue
+		}
+		if !t.LinkCommit(hash, subject, time.Now()) {
+			continue
+		}
+		if err := activityLog.WriteCommitLinked(id, hash, subject, link.Completes); err != nil {
+			return fmt.Errorf("failed to write activity log: %w", err)
+		}
+		fmt.Fprintf(w, "✓ Linked commit %s to task %s\n", task.ShortCommit(hash), task.ShortID(id))
+		if link.Completes && !t.Meta.Completed {
+			completes = append(completes, id)
+		}
+	}
+	if _, err := db.SaveDirty(); err != nil {
+		return fmt.Errorf("failed to write task updates: %w", err)
+	}
+
+	for _, id := range completes {
+		report := fmt.Sprintf("Completed in commit %s: %s", task.ShortCommit(hash), subject)
+		if err := runComplete(w, projectName, id, 0, "", report); err != nil {
+			fmt.Fprintf(w, "⚠️  Could not complete %s: %v\n", task.ShortID(id), err)
+		}
+	}
+	return nil
+}
+
+// runPostMergeHook regenerates the master lists the merge driver marked stale.
+func runPostMergeHook(w io.Writer, projectName string) error {
+	paths, err := resolveProjectPaths(projectName)
+	if err != nil {
+		return err
+	}
+	regenerated, err := regenerateStaleLists(io.Discard, paths)
+	if err != nil {
+		return err
+	}
+	if regenerated {
+		fmt.Fprintln(w, "✓ Regenerated master lists after merge")
+	}
+	return nil
+}
+
+// stripCommitComments drops the comment lines git removes from a message.
+func stripCommitComments(message string) string {
+	var lines []string
+	for _, line := range strings.Split(message, "\n") {
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func gitOutput(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
+	}
+	return string(out), nil
+}
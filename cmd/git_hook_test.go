@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ricochet1k/strandyard/pkg/task"
+)
+
+func gitCommit(t *testing.T, dir, message string) {
+	t.Helper()
+	cmd := exec.Command("git", "-c", "user.name=Test", "-c", "user.email=test@example.com", "-c", "core.hooksPath=/dev/null",
+		"commit", "--allow-empty", "-q", "-m", message)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git commit: %v: %s", err, out)
+	}
+}
+
+func TestGitHookInstall(t *testing.T) {
+	paths := setupTestProject(t, initOptions{StorageMode: storageLocal})
+	hooksDir := filepath.Join(paths.GitRoot, ".git", "hooks")
+	existing := filepath.Join(hooksDir, "post-commit")
+	if err := os.MkdirAll(hooksDir, 0o755); err != nil {
+		t.Fatalf("mkdir hooks: %v", err)
+	}
+	if err := os.WriteFile(existing, []byte("#!/bin/sh\necho mine\n"), 0o755); err != nil {
+		t.Fatalf("write hook: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := runGitHookInstall(&out, false); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Fatalf("expected refusal to overwrite foreign hook, got %v", err)
+	}
+	if err := runGitHookInstall(&out, true); err != nil {
+		t.Fatalf("install --force: %v", err)
+	}
+	if err := runGitHookInstall(&out, false); err != nil {
+		t.Fatalf("reinstall over strand hooks: %v", err)
+	}
+	for _, name := range []string{"commit-msg", "post-commit", "post-merge"} {
+		info, err := os.Stat(filepath.Join(hooksDir, name))
+		if err != nil {
+			t.Fatalf("stat %s: %v", name, err)
+		}
+		if info.Mode()&0o111 == 0 {
+			t.Errorf("%s is not executable", name)
+		}
+		data, _ := os.ReadFile(filepath.Join(hooksDir, name))
+		if !strings.Contains(string(data), "strand git hook run "+name) {
+			t.Errorf("%s does not run strand:\n%s", name, data)
+		}
+		if !strings.Contains(string(data), gitHookGuard+"\n") {
+			t.Errorf("%s does not skip when strand is missing:\n%s", name, data)
+		}
+	}
+}
+
+func TestCommitMsgHookRejectsUnknownTasks(t *testing.T) {
+	paths := setupTestProject(t, initOptions{StorageMode: storageLocal})
+	writeClaimTaskFile(t, paths.TasksDir, "T1abc-linked", "developer")
+
+	msg := filepath.Join(t.TempDir(), "COMMIT_EDITMSG")
+	write := func(content string) {
+		if err := os.WriteFile(msg, []byte(content), 0o644); err != nil {
+			t.Fatalf("write message: %v", err)
+		}
+	}
+	write("Fix\n\nStrand-Task: T1abc\n# Strand-Task: T9zzz in a comment\n")
+	if err := runCommitMsgHook("", msg); err != nil {
+		t.Fatalf("expected known task to pass: %v", err)
+	}
+	write("Fix\n\nStrand-Completes: T9zzz\n")
+	if err := runCommitMsgHook("", msg); err == nil || !strings.Contains(err.Error(), "T9zzz") {
+		t.Fatalf("expected unknown task error, got %v", err)
+	}
+}
+
+func TestPostCommitHookLinksAndCompletesTasks(t *testing.T) {
+	paths := setupTestProject(t, initOptions{StorageMode: storageLocal})
+	roleName := testRoleName(t, "hook")
+	if err := os.WriteFile(filepath.Join(paths.RolesDir, roleName+".md"), []byte("# "+roleName+"\n"), 0o644); err != nil {
+		t.Fatalf("write role file: %v", err)
+	}
+	writeClaimTaskFile(t, paths.TasksDir, "T1abc-linked", roleName)
+	writeClaimTaskFile(t, paths.TasksDir, "T2abc-finished", roleName)
+
+	gitCommit(t, paths.GitRoot, "Wire the parser\n\nStrand-Task: T1abc\nStrand-Completes: T2abc")
+
+	var out bytes.Buffer
+	if err := runPostCommitHook(&out, ""); err != nil {
+		t.Fatalf("post-commit: %v\n%s", err, out.String())
+	}
+	if err := runPostCommitHook(&out, ""); err != nil {
+		t.Fatalf("post-commit again: %v", err)
+	}
+
+	db := task.NewTaskDB(paths.TasksDir)
+	linked, err := db.Get("T1abc-linked")
+	if err != nil {
+		t.Fatalf("get task: %v", err)
+	}
+	if strings.Count(linked.ProgressContent, "Wire the parser") != 1 {
+		t.Errorf("progress = %q, want one entry for the commit", linked.ProgressContent)
+	}
+	if linked.Meta.Completed {
+		t.Error("Strand-Task should not complete the task")
+	}
+	finished, err := db.Get("T2abc-finished")
+	if err != nil {
+		t.Fatalf("get task: %v", err)
+	}
+	if !finished.Meta.Completed {
+		t.Errorf("Strand-Completes should complete the task\n%s", out.String())
+	}
+
+	var shown bytes.Buffer
+	if err := runShow(&shown, "", "T2abc"); err != nil {
+		t.Fatalf("show: %v", err)
+	}
+	if !strings.Contains(shown.String(), "Linked commits:") || !strings.Contains(shown.String(), "Wire the parser (completes)") {
+		t.Errorf("show output missing linked commit:\n%s", shown.String())
+	}
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ricochet1k/strandyard/pkg/task"
+	"github.com/spf13/cobra"
+)
+
+var (
+	graphFormat string
+	graphRoot   string
+	graphStatus string
+)
+
+// graphCmd represents the graph command
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Render the task dependency graph",
+	Long: `Render the dependency graph built from task blockers and parent relationships.
+
+Edges point from a blocking task to the task it blocks; child tasks point to
+their parent. Free tasks, the critical path (the longest chain of active
+tasks), and dependency cycles are highlighted.
+
+Examples:
+  # Mermaid diagram of every task
+  strand graph
+
+  # Graphviz DOT for one epic's open work
+  strand graph --root E1a2b --status open --format dot | dot -Tsvg > graph.svg
+
+  # JSON for scripting
+  strand graph --format json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		paths, err := resolveProjectPaths(projectName)
+		if err != nil {
+			return err
+		}
+		opts := task.GraphOptions{
+			Root:   strings.TrimSpace(graphRoot),
+			Status: strings.ToLower(strings.TrimSpace(graphStatus)),
+			Remote: remoteTasks(),
+		}
+		return runGraph(cmd.OutOrStdout(), paths.TasksDir, opts, strings.ToLower(strings.TrimSpace(graphFormat)))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(graphCmd)
+
+	graphCmd.Flags().StringVar(&graphFormat, "format", "mermaid", "output format: mermaid|dot|json")
+	graphCmd.Flags().StringVar(&graphRoot, "root", "", "only include this task and its descendants")
+	graphCmd.Flags().StringVar(&graphStatus, "status", "", fmt.Sprintf("only include tasks with status: %s", task.FormatStatusListForUser()))
+}
+
+func runGraph(w io.Writer, tasksRoot string, opts task.GraphOptions, format string) error {
+	switch format {
+	case "mermaid", "dot", "json":
+	default:
+		return fmt.Errorf("invalid format %q (expected mermaid, dot, or json)", format)
+	}
+
+	db := task.NewTaskDB(tasksRoot)
+	if err := db.LoadAll(); err != nil {
+		return err
+	}
+
+	graph, err := task.BuildDependencyGraph(db.GetAll(), opts)
+	if err != nil {
+		return err
+	}
+
+	var output string
+	switch format {
+	case "mermaid":
+		output = graph.Mermaid()
+	case "dot":
+		output = graph.DOT()
+	case "json":
+		output, err = graph.JSON()
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintln(w, output)
+	return nil
+}
@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ricochet1k/strandyard/pkg/create"
+	"github.com/ricochet1k/strandyard/pkg/importer"
+	"github.com/spf13/cobra"
+)
+
+var (
+	importFrom   string
+	importType   string
+	importRole   string
+	importMap    []string
+	importDryRun bool
+	importFormat string
+)
+
+type importOptions struct {
+	From    string
+	Mapping map[string]string
+	Format  string
+	importer.Options
+}
+
+// importCmd represents the import command
+var importCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import tasks from other trackers' export files",
+	Long: `Import a backlog exported from another tracker. Use - to read stdin.
+
+Sources (--from):
+  github       JSON array of issues from the REST API or
+               gh issue list --json number,title,body,state,labels,url,createdAt
+  taskwarrior  JSON from task export
+  todotxt      a todo.txt file
+  csv          CSV with a header row; map columns with --map field=Column
+
+Every item becomes a task created from --type, with the original ID recorded
+in the imported_from frontmatter field. Items already imported are skipped, so
+re-running an import only adds what is new. Dependencies between imported
+items become parents and blockers.
+
+Examples:
+  gh issue list --state all --json number,title,body,state,labels,url,createdAt > issues.json
+  strand import --from github issues.json --dry-run
+  task export | strand import --from taskwarrior --type issue -
+  strand import --from csv --map title=Summary --map id=Key --map blockers="Blocked By" jira.csv`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mapping, err := parseKeyValueFlags("map", importMap)
+		if err != nil {
+			return err
+		}
+		opts := importOptions{
+			From:    strings.ToLower(strings.TrimSpace(importFrom)),
+			Mapping: mapping,
+			Format:  strings.ToLower(strings.TrimSpace(importFormat)),
+			Options: importer.Options{
+				TemplateName: strings.TrimSpace(importType),
+				Role:         strings.TrimSpace(importRole),
+				DryRun:       importDryRun,
+			},
+		}
+		if args[0] == "-" {
+			return runImport(cmd.OutOrStdout(), cmd.InOrStdin(), projectName, opts)
+		}
+		f, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return runImport(cmd.OutOrStdout(), f, projectName, opts)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+
+	importCmd.Flags().StringVar(&importFrom, "from", "", "source format: "+strings.Join(importer.Formats, "|")+" (required)")
+	importCmd.Flags().StringVar(&importType, "type", "task", "template to create the tasks from")
+	importCmd.Flags().StringVarP(&importRole, "role", "r", "", "role for every imported task (default: the item's role, then the template's)")
+	importCmd.Flags().StringArrayVar(&importMap, "map", nil, "csv column mapping as field=Column (repeatable); fields: "+strings.Join(importer.CSVFields, ", "))
+	importCmd.Flags().BoolVar(&importDryRun, "dry-run", false, "show what would be imported without writing tasks")
+	importCmd.Flags().StringVar(&importFormat, "format", "text", "output format: text|json")
+}
+
+func runImport(w io.Writer, r io.Reader, projectName string, opts importOptions) error {
+	if opts.From == "" {
+		return fmt.Errorf("--from is required (one of: %s)", strings.Join(importer.Formats, ", "))
+	}
+	if opts.Format != "text" && opts.Format != "json" {
+		return fmt.Errorf("invalid format %q (expected text or json)", opts.Format)
+	}
+	if len(opts.Mapping) > 0 && opts.From != importer.FormatCSV {
+		return fmt.Errorf("--map is only supported with --from csv")
+	}
+	paths, err := resolveProjectPaths(projectName)
+	if err != nil {
+		return err
+	}
+	cfg, err := loadConfig(paths.BaseDir)
+	if err != nil {
+		return err
+	}
+	items, err := importer.Parse(opts.From, r, opts.Mapping)
+	if err != nil {
+		return err
+	}
+
+	project := create.Project{
+		BaseDir:      paths.BaseDir,
+		TasksDir:     paths.TasksDir,
+		TemplatesDir: paths.TemplatesDir,
+		RolesDir:     paths.RolesDir,
+	}
+	report, importErr := importer.Import(project, cfg, items, opts.Options)
+	if report == nil {
+		return importErr
+	}
+	if !opts.DryRun && report.Created() > 0 {
+		if err := runRepair(io.Discard, paths.TasksDir, paths.RootTasksFile, paths.FreeTasksFile, "text"); err != nil && importErr == nil {
+			importErr = fmt.Errorf("imported tasks but repair failed: %w", err)
+		}
+	}
+
+	if opts.Format == "json" {
+		b, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(b))
+		return importErr
+	}
+	printImportReport(w, report, opts.DryRun)
+	return importErr
+}
+
+func printImportReport(w io.Writer, report *importer.Report, dryRun bool) {
+	existing := 0
+	for _, e := range report.Entries {
+		switch {
+		case e.Action == importer.ActionExists:
+			existing++
+		case dryRun:
+			fmt.Fprintf(w, "Would create %s: %s\n", e.Origin, e.Title)
+		default:
+			fmt.Fprintf(w, "✓ Created %s from %s\n", e.TaskID, e.Origin)
+		}
+	}
+	for _, warning := range report.Warnings {
+		fmt.Fprintln(w, "WARNING:", warning)
+	}
+	if dryRun {
+		fmt.Fprintf(w, "Dry run: %d to create, %d already imported\n", report.Created(), existing)
+		return
+	}
+	fmt.Fprintf(w, "Imported %d tasks (%d already imported, %d dependencies linked)\n", report.Created(), existing, report.Links)
+}
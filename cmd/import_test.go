@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ricochet1k/strandyard/pkg/importer"
+)
+
+func TestImportTodoTxtIsIdempotent(t *testing.T) {
+	paths := setupTestProject(t, initOptions{StorageMode: storageLocal})
+	if err := os.WriteFile(filepath.Join(paths.RolesDir, "developer.md"), []byte("# developer\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(paths.TemplatesDir, "task.md"), []byte("---\nrole: developer\npriority: medium\nid_prefix: T\n---\n\n# {{ .Title }}\n\n{{ .Body }}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	todo := "(A) Draft the schema id:schema\nMigrate the data dep:schema\n"
+	opts := importOptions{From: importer.FormatTodoTxt, Format: "text", Options: importer.Options{TemplateName: "task"}}
+
+	var out bytes.Buffer
+	opts.DryRun = true
+	if err := runImport(&out, strings.NewReader(todo), "", opts); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "Would create todotxt:schema: Draft the schema") || !strings.Contains(out.String(), "Dry run: 2 to create") {
+		t.Errorf("dry run output:\n%s", out.String())
+	}
+
+	out.Reset()
+	opts.DryRun = false
+	if err := runImport(&out, strings.NewReader(todo), "", opts); err != nil {
+		t.Fatalf("import: %v\n%s", err, out.String())
+	}
+	if !strings.Contains(out.String(), "Imported 2 tasks (0 already imported, 1 dependencies linked)") {
+		t.Errorf("import output:\n%s", out.String())
+	}
+	free, err := os.ReadFile(paths.FreeTasksFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(free), "Draft the schema") || strings.Contains(string(free), "Migrate the data") {
+		t.Errorf("free list should hold only the unblocked task:\n%s", free)
+	}
+
+	out.Reset()
+	if err := runImport(&out, strings.NewReader(todo), "", opts); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "Imported 0 tasks (2 already imported") {
+		t.Errorf("re-import output:\n%s", out.String())
+	}
+}
@@ -17,7 +17,7 @@ import (
 var initCmd = &cobra.Command{
 	Use:   "init [project_name]",
 	Short: "Initialize strand storage",
-	Long:  "Initialize the strand project storage.\n\nBy default this creates a global project under ~/.config/strand/projects/<project_name> and records a mapping from the current git root to the project name. Use --storage=local to place tasks, roles, and templates inside .strand/ at the git root instead.",
+	Long:  "Initialize the strand project storage.\n\nBy default this creates a global project under ~/.config/strand/projects/<project_name> and records a mapping from the current git root to the project name. Use --storage=local to place tasks, roles, and templates inside .strand/ at the git root instead; local storage also registers the strand merge driver for task files in .gitattributes.",
 	Args:  cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		project := ""
@@ -38,8 +38,8 @@ func init() {
 	// and all subcommands, e.g.:
 	// initCmd.PersistentFlags().String("foo", "", "A help for foo")
 
-	initCmd.Flags().StringVar(&initStorageMode, "storage", storageGlobal, "storage mode: global or local")
-	initCmd.Flags().StringVar(&initPreset, "preset", "", "preset directory or git repo to seed tasks/roles/templates")
+	initCmd.Flags().StringVar(&initStorageMode, "storage", "", "storage mode: global or local (default init.storage, global)")
+	initCmd.Flags().StringVar(&initPreset, "preset", "", "preset directory or git repo (url[@ref][#subdir]) to seed tasks/roles/templates")
 }
 
 var (
@@ -64,7 +64,11 @@ func initOptionsFromFlags(projectArg string) initOptions {
 func runInit(w io.Writer, opts initOptions) error {
 	storage := strings.ToLower(strings.TrimSpace(opts.StorageMode))
 	if storage == "" {
-		storage = storageGlobal
+		cfg, err := loadConfig("")
+		if err != nil {
+			return err
+		}
+		storage = cfg.Init.Storage
 	}
 	switch storage {
 	case storageGlobal, storageLocal:
@@ -127,5 +131,11 @@ func runInit(w io.Writer, opts initOptions) error {
 
 	fmt.Fprintf(w, "✓ Initialized strand at %s\n", baseDir)
 	fmt.Fprintf(w, "✓ Linked %s to project %s\n", gitRoot, projectName)
+	if storage == storageLocal {
+		if err := setupMergeDriver(gitRoot); err != nil {
+			return fmt.Errorf("failed to configure git merge driver: %w", err)
+		}
+		fmt.Fprintln(w, "✓ Configured git merge driver for task files in .gitattributes")
+	}
 	return nil
 }
@@ -24,6 +24,8 @@ var (
 	listBlocks         bool
 	listOwnerApproval  bool
 	listLabel          string
+	listVars           []string
+	listFields         []string
 	listSort           string
 	listOrder          string
 	listFormat         string
@@ -32,6 +34,8 @@ var (
 	listStatus         string
 	listMDTable        bool
 	listUseMasterLists bool
+	listWIP            bool
+	listAllProjects    bool
 )
 
 // listCmd represents the list command
@@ -39,14 +43,42 @@ var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List tasks with filtering and formatting options",
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if listAllProjects {
+			if listWIP {
+				return fmt.Errorf("invalid flag combination: --wip cannot be used with --all-projects")
+			}
+			if !cmd.Flags().Changed("format") {
+				cfg, err := workspaceConfig()
+				if err != nil {
+					return err
+				}
+				listFormat = cfg.List.Format
+			}
+			opts, err := listOptionsFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+			return runListAllProjects(cmd.OutOrStdout(), opts)
+		}
 		paths, err := resolveProjectPaths(projectName)
 		if err != nil {
 			return err
 		}
+		cfg, err := loadConfig(paths.BaseDir)
+		if err != nil {
+			return err
+		}
+		if !cmd.Flags().Changed("format") {
+			listFormat = cfg.List.Format
+		}
 		opts, err := listOptionsFromFlags(cmd)
 		if err != nil {
 			return err
 		}
+		opts.FieldSchema = cfg.Fields
+		if listWIP {
+			return runListWIP(cmd.OutOrStdout(), paths, opts.Format)
+		}
 		return runList(cmd.OutOrStdout(), paths.TasksDir, opts)
 	},
 }
@@ -54,23 +86,34 @@ var listCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(listCmd)
 
-	listCmd.Flags().StringVar(&listScope, "scope", "all", "scope of tasks to list: all|root|free")
-	listCmd.Flags().StringVar(&listChildren, "children", "", "list direct children of the given task ID")
-	listCmd.Flags().StringVar(&listRole, "role", "", "filter by role name")
-	listCmd.Flags().StringVar(&listPriority, "priority", "", "filter by priority: high|medium|low")
-	listCmd.Flags().StringVar(&listStatus, "status", "", fmt.Sprintf("filter by status: %s", task.FormatStatusListForUser()))
+	addListFilterFlags(listCmd)
 	listCmd.Flags().BoolVar(&listCompleted, "completed", false, "list only completed tasks (default: uncompleted)")
-	listCmd.Flags().BoolVar(&listBlocked, "blocked", false, "filter by blocked status (has blockers)")
-	listCmd.Flags().BoolVar(&listBlocks, "blocks", false, "filter by blocks status (has blocks)")
-	listCmd.Flags().BoolVar(&listOwnerApproval, "owner-approval", false, "filter by owner approval")
 	listCmd.Flags().StringVar(&listLabel, "label", "", "reserved for future labels support")
-	listCmd.Flags().StringVar(&listSort, "sort", "", "sort by: id|priority|created|edited|role")
-	listCmd.Flags().StringVar(&listOrder, "order", "asc", "sort order: asc|desc")
-	listCmd.Flags().StringVar(&listFormat, "format", "table", "output format: table|md|json")
+	listCmd.Flags().StringVar(&listFormat, "format", "", "output format: table|md|json (default list.format, table)")
 	listCmd.Flags().StringVar(&listColumns, "columns", "", "comma-separated list of columns to include")
-	listCmd.Flags().StringVar(&listGroup, "group", "none", "group by: none|priority|parent|role")
+	listCmd.Flags().StringVar(&listGroup, "group", "none", "group by: none|priority|parent|role or a custom field")
 	listCmd.Flags().BoolVar(&listMDTable, "md-table", false, "use markdown table output (with --format md)")
 	listCmd.Flags().BoolVar(&listUseMasterLists, "use-master-lists", false, "use master lists for root/free scopes when no filters")
+	listCmd.Flags().BoolVar(&listAllProjects, "all-projects", false, "list tasks from every registered project, with a project column")
+	listCmd.Flags().BoolVar(&listWIP, "wip", false, "list in-progress usage per role and agent against the WIP limits instead of tasks")
+}
+
+// addListFilterFlags registers the filter and sort flags that list shares
+// with commands built on it, such as export. listOptionsFromFlags reads them;
+// each command registers --completed itself, since their defaults differ.
+func addListFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&listScope, "scope", "all", "scope of tasks: all|root|free")
+	cmd.Flags().StringVar(&listChildren, "children", "", "only direct children of the given task ID")
+	cmd.Flags().StringVar(&listRole, "role", "", "filter by role name")
+	cmd.Flags().StringVar(&listPriority, "priority", "", "filter by priority: high|medium|low")
+	cmd.Flags().StringVar(&listStatus, "status", "", fmt.Sprintf("filter by status: %s", task.FormatStatusListForUser()))
+	cmd.Flags().BoolVar(&listBlocked, "blocked", false, "filter by blocked status (has blockers)")
+	cmd.Flags().BoolVar(&listBlocks, "blocks", false, "filter by blocks status (has blocks)")
+	cmd.Flags().BoolVar(&listOwnerApproval, "owner-approval", false, "filter by owner approval")
+	cmd.Flags().StringArrayVar(&listVars, "var", nil, "filter by template var as key=value (repeatable)")
+	cmd.Flags().StringArrayVar(&listFields, "field", nil, "filter by custom field as key=value (repeatable)")
+	cmd.Flags().StringVar(&listSort, "sort", "", "sort by: id|priority|created|edited|role|impact or a custom field")
+	cmd.Flags().StringVar(&listOrder, "order", "asc", "sort order: asc|desc")
 }
 
 func listOptionsFromFlags(cmd *cobra.Command) (task.ListOptions, error) {
@@ -89,6 +132,18 @@ func listOptionsFromFlags(cmd *cobra.Command) (task.ListOptions, error) {
 		UseMasterLists: listUseMasterLists,
 	}
 
+	vars, err := parseVarFlags(listVars)
+	if err != nil {
+		return opts, err
+	}
+	opts.Vars = vars
+
+	fields, err := parseKeyValueFlags("field", listFields)
+	if err != nil {
+		return opts, err
+	}
+	opts.Fields = fields
+
 	if !cmd.Flags().Changed("completed") {
 		opts.Completed = boolPtr(false)
 	}
@@ -124,54 +179,36 @@ func listOptionsFromFlags(cmd *cobra.Command) (task.ListOptions, error) {
 	return opts, nil
 }
 
-func runList(w io.Writer, tasksRoot string, opts task.ListOptions) error {
+// validateListOptions checks the list options that do not depend on a
+// project's field schema; task.ValidateListFilters checks the rest.
+func validateListOptions(opts task.ListOptions) error {
 	if opts.Label != "" {
-		return fmt.Errorf("label filter is not supported yet")
-	}
-	switch opts.Scope {
-	case "all", "root", "free":
-	default:
-		return fmt.Errorf("invalid scope %q (expected all, root, or free)", opts.Scope)
-	}
-	if opts.Priority != "" && !task.IsValidPriority(opts.Priority) {
-		return fmt.Errorf("invalid priority %q (expected high, medium, or low)", opts.Priority)
-	}
-	switch opts.Sort {
-	case "", "id", "priority", "created", "edited", "role":
-	default:
-		return fmt.Errorf("invalid sort %q (expected id, priority, created, edited, or role)", opts.Sort)
-	}
-	switch opts.Order {
-	case "asc", "desc":
-	default:
-		return fmt.Errorf("invalid order %q (expected asc or desc)", opts.Order)
+		return fmt.Errorf("--label is not supported: use --field to filter on a custom field")
 	}
 	switch opts.Format {
 	case "table", "md", "json":
 	default:
 		return fmt.Errorf("invalid format %q (expected table, md, or json)", opts.Format)
 	}
-	switch opts.Group {
-	case "none", "priority", "parent", "role":
-	default:
-		return fmt.Errorf("invalid group %q (expected none, priority, parent, or role)", opts.Group)
+	if opts.Scope == "free" && opts.Group == "parent" {
+		return fmt.Errorf("invalid flag combination: --scope free cannot be used with --group parent")
 	}
-	if opts.Scope == "free" {
-		if opts.Parent != "" {
-			return fmt.Errorf("invalid flag combination: --scope free cannot be used with --children")
-		}
-		if opts.Group == "parent" {
-			return fmt.Errorf("invalid flag combination: --scope free cannot be used with --group parent")
-		}
+	return nil
+}
+
+func runList(w io.Writer, tasksRoot string, opts task.ListOptions) error {
+	if err := validateListOptions(opts); err != nil {
+		return err
 	}
-	if opts.Parent != "" && opts.Scope != "all" {
-		return fmt.Errorf("invalid flag combination: --children cannot be used with --scope %s", opts.Scope)
+	if err := task.ValidateListFilters(opts); err != nil {
+		return err
 	}
 
-	tasks, err := task.ListTasks(tasksRoot, opts)
+	tasks, impact, err := task.ListTasks(tasksRoot, opts)
 	if err != nil {
 		return err
 	}
+	opts.Impact = impact
 	output, err := task.FormatList(tasks, opts)
 	if err != nil {
 		return err
@@ -187,6 +224,11 @@ func runListWithProject(w io.Writer, projectName string, opts task.ListOptions)
 	if err != nil {
 		return err
 	}
+	cfg, err := loadConfig(paths.BaseDir)
+	if err != nil {
+		return err
+	}
+	opts.FieldSchema = cfg.Fields
 	return runList(w, paths.TasksDir, opts)
 }
 
@@ -3,13 +3,17 @@ package cmd
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
 	"io"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/ricochet1k/strandyard/pkg/task"
+	"github.com/ricochet1k/strandyard/pkg/template"
 	"github.com/spf13/cobra"
 )
 
@@ -27,15 +31,17 @@ func init() {
 }
 
 type addArgs struct {
-	Project  string   `json:"project,omitempty" jsonschema_description:"Project name (equivalent to --project)"`
-	Type     string   `json:"type" jsonschema:"required" jsonschema_description:"Template type name"`
-	Title    string   `json:"title" jsonschema:"required" jsonschema_description:"Task title"`
-	Role     string   `json:"role,omitempty" jsonschema_description:"Role responsible for the task"`
-	Priority string   `json:"priority,omitempty" jsonschema:"enum=high,enum=medium,enum=low" jsonschema_description:"Task priority"`
-	Parent   string   `json:"parent,omitempty" jsonschema_description:"Parent task ID"`
-	Blockers []string `json:"blockers,omitempty" jsonschema_description:"Blocker task IDs"`
-	NoRepair bool     `json:"no_repair,omitempty" jsonschema_description:"Skip repair and master list updates"`
-	Body     string   `json:"body,omitempty" jsonschema_description:"Task body content"`
+	Project  string            `json:"project,omitempty" jsonschema_description:"Project name (equivalent to --project)"`
+	Type     string            `json:"type" jsonschema:"required" jsonschema_description:"Template type name"`
+	Title    string            `json:"title" jsonschema:"required" jsonschema_description:"Task title"`
+	Role     string            `json:"role,omitempty" jsonschema_description:"Role responsible for the task"`
+	Priority string            `json:"priority,omitempty" jsonschema:"enum=high,enum=medium,enum=low" jsonschema_description:"Task priority"`
+	Parent   string            `json:"parent,omitempty" jsonschema_description:"Parent task ID"`
+	Blockers []string          `json:"blockers,omitempty" jsonschema_description:"Blocker task IDs"`
+	Vars     map[string]string `json:"vars,omitempty" jsonschema_description:"Template variables declared by the template"`
+	Fields   map[string]string `json:"fields,omitempty" jsonschema_description:"Custom fields declared in the project config"`
+	NoRepair bool              `json:"no_repair,omitempty" jsonschema_description:"Skip repair and master list updates"`
+	Body     string            `json:"body,omitempty" jsonschema_description:"Task body content"`
 }
 
 type nextArgs struct {
@@ -43,6 +49,7 @@ type nextArgs struct {
 	Role         string `json:"role,omitempty" jsonschema_description:"Filter by role"`
 	Claim        bool   `json:"claim,omitempty" jsonschema_description:"Claim the selected task by marking it in_progress"`
 	ClaimTimeout string `json:"claim_timeout,omitempty" jsonschema_description:"Claim timeout duration (e.g. 1h, 30m)"`
+	Agent        string `json:"agent,omitempty" jsonschema_description:"Agent identity recorded on claim and used for WIP limits"`
 }
 
 type completeArgs struct {
@@ -66,23 +73,25 @@ type repairArgs struct {
 }
 
 type listArgs struct {
-	Project        string   `json:"project,omitempty" jsonschema_description:"Project name (equivalent to --project)"`
-	Scope          string   `json:"scope,omitempty" jsonschema:"enum=all,enum=root,enum=free" jsonschema_description:"Scope of tasks to list"`
-	Children       string   `json:"children,omitempty" jsonschema_description:"List direct children of the given task ID"`
-	Role           string   `json:"role,omitempty" jsonschema_description:"Filter by role"`
-	Priority       string   `json:"priority,omitempty" jsonschema:"enum=high,enum=medium,enum=low" jsonschema_description:"Filter by priority"`
-	Completed      *bool    `json:"completed,omitempty" jsonschema_description:"Filter by completed status"`
-	Blocked        *bool    `json:"blocked,omitempty" jsonschema_description:"Filter by blocked status"`
-	Blocks         *bool    `json:"blocks,omitempty" jsonschema_description:"Filter by blocks status"`
-	OwnerApproval  *bool    `json:"owner_approval,omitempty" jsonschema_description:"Filter by owner approval"`
-	Label          string   `json:"label,omitempty" jsonschema_description:"Reserved for future labels support"`
-	Sort           string   `json:"sort,omitempty" jsonschema:"enum=id,enum=priority,enum=created,enum=edited,enum=role" jsonschema_description:"Sort field"`
-	Order          string   `json:"order,omitempty" jsonschema:"enum=asc,enum=desc" jsonschema_description:"Sort order"`
-	Format         string   `json:"format,omitempty" jsonschema:"enum=table,enum=md,enum=json" jsonschema_description:"Output format"`
-	Columns        []string `json:"columns,omitempty" jsonschema_description:"Columns to include"`
-	Group          string   `json:"group,omitempty" jsonschema:"enum=none,enum=priority,enum=parent,enum=role" jsonschema_description:"Group by"`
-	MdTable        bool     `json:"md_table,omitempty" jsonschema_description:"Use markdown table output"`
-	UseMasterLists bool     `json:"use_master_lists,omitempty" jsonschema_description:"Use master lists for root/free"`
+	Project        string            `json:"project,omitempty" jsonschema_description:"Project name (equivalent to --project)"`
+	Scope          string            `json:"scope,omitempty" jsonschema:"enum=all,enum=root,enum=free" jsonschema_description:"Scope of tasks to list"`
+	Children       string            `json:"children,omitempty" jsonschema_description:"List direct children of the given task ID"`
+	Role           string            `json:"role,omitempty" jsonschema_description:"Filter by role"`
+	Priority       string            `json:"priority,omitempty" jsonschema:"enum=high,enum=medium,enum=low" jsonschema_description:"Filter by priority"`
+	Completed      *bool             `json:"completed,omitempty" jsonschema_description:"Filter by completed status"`
+	Blocked        *bool             `json:"blocked,omitempty" jsonschema_description:"Filter by blocked status"`
+	Blocks         *bool             `json:"blocks,omitempty" jsonschema_description:"Filter by blocks status"`
+	OwnerApproval  *bool             `json:"owner_approval,omitempty" jsonschema_description:"Filter by owner approval"`
+	Label          string            `json:"label,omitempty" jsonschema_description:"Reserved for future labels support"`
+	Vars           map[string]string `json:"vars,omitempty" jsonschema_description:"Filter by template vars (all must match)"`
+	Fields         map[string]string `json:"fields,omitempty" jsonschema_description:"Filter by custom fields (all must match)"`
+	Sort           string            `json:"sort,omitempty" jsonschema_description:"Sort field: id, priority, created, edited, role, impact, or a custom field"`
+	Order          string            `json:"order,omitempty" jsonschema:"enum=asc,enum=desc" jsonschema_description:"Sort order"`
+	Format         string            `json:"format,omitempty" jsonschema:"enum=table,enum=md,enum=json" jsonschema_description:"Output format"`
+	Columns        []string          `json:"columns,omitempty" jsonschema_description:"Columns to include"`
+	Group          string            `json:"group,omitempty" jsonschema_description:"Group by: none, priority, parent, role, or a custom field"`
+	MdTable        bool              `json:"md_table,omitempty" jsonschema_description:"Use markdown table output"`
+	UseMasterLists bool              `json:"use_master_lists,omitempty" jsonschema_description:"Use master lists for root/free"`
 }
 
 type searchArgs struct {
@@ -97,7 +106,10 @@ type searchArgs struct {
 }
 
 func runMCP() error {
-	s := server.NewMCPServer("strand", "dev", server.WithToolCapabilities(true))
+	s := server.NewMCPServer("strand", "dev",
+		server.WithToolCapabilities(true),
+		server.WithToolFilter(templateVarsToolFilter),
+	)
 	registerMCPTools(s)
 	return server.ServeStdio(s)
 }
@@ -185,6 +197,104 @@ func registerMCPTools(s *server.MCPServer) {
 
 }
 
+// templateVarsToolFilter rewrites the strand_add input schema on each
+// tools/list so the type enum and per-template vars follow the project's
+// current templates.
+func templateVarsToolFilter(ctx context.Context, tools []mcp.Tool) []mcp.Tool {
+	paths, err := resolveProjectPaths(projectName)
+	if err != nil {
+		return tools
+	}
+	templates, err := template.LoadTemplates(paths.TemplatesDir)
+	if err != nil {
+		return tools
+	}
+	out := make([]mcp.Tool, len(tools))
+	copy(out, tools)
+	for i, tool := range out {
+		if tool.Name != "strand_add" {
+			continue
+		}
+		schema, err := addToolSchema(tool.RawInputSchema, templates)
+		if err == nil {
+			out[i].RawInputSchema = schema
+		}
+	}
+	return out
+}
+
+// addToolSchema extends the generated strand_add schema with the template
+// names and, per template, the vars it declares.
+func addToolSchema(base json.RawMessage, templates map[string]*template.Template) (json.RawMessage, error) {
+	var schema map[string]any
+	if err := json.Unmarshal(base, &schema); err != nil {
+		return nil, err
+	}
+	props, _ := schema["properties"].(map[string]any)
+	if props == nil {
+		return nil, fmt.Errorf("strand_add schema has no properties")
+	}
+
+	names := make([]string, 0, len(templates))
+	for name := range templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if typeProp, ok := props["type"].(map[string]any); ok {
+		typeProp["enum"] = names
+	}
+
+	rules := make([]any, 0, len(names))
+	for _, name := range names {
+		varProps := map[string]any{}
+		var required []string
+		for _, v := range templates[name].Meta.Vars {
+			prop := map[string]any{"type": "string"}
+			switch v.Kind() {
+			case template.VarEnum:
+				prop["enum"] = v.Values
+			case template.VarBool:
+				prop["enum"] = []string{"true", "false"}
+			}
+			desc := v.Help
+			if v.Kind() == template.VarTaskID {
+				desc = strings.TrimSpace("Task ID. " + desc)
+			}
+			if desc != "" {
+				prop["description"] = desc
+			}
+			if v.Default != "" {
+				prop["default"] = v.Default
+			}
+			varProps[v.Name] = prop
+			if v.Required && v.Default == "" {
+				required = append(required, v.Name)
+			}
+		}
+		varsSchema := map[string]any{
+			"type":                 "object",
+			"properties":           varProps,
+			"additionalProperties": false,
+		}
+		then := map[string]any{"properties": map[string]any{"vars": varsSchema}}
+		if len(required) > 0 {
+			varsSchema["required"] = required
+			then["required"] = []string{"vars"}
+		}
+		rules = append(rules, map[string]any{
+			"if": map[string]any{
+				"properties": map[string]any{"type": map[string]any{"const": name}},
+				"required":   []string{"type"},
+			},
+			"then": then,
+		})
+	}
+	if len(rules) > 0 {
+		schema["allOf"] = rules
+	}
+	return json.Marshal(schema)
+}
+
 func handleMCPAdd(ctx context.Context, request mcp.CallToolRequest, args addArgs) (*mcp.CallToolResult, error) {
 	opts := addOptions{
 		ProjectName:       strings.TrimSpace(args.Project),
@@ -195,6 +305,8 @@ func handleMCPAdd(ctx context.Context, request mcp.CallToolRequest, args addArgs
 		Parent:            strings.TrimSpace(args.Parent),
 		Blockers:          args.Blockers,
 		Every:             []string{}, // Not supported in MCP context yet
+		Vars:              args.Vars,
+		Fields:            args.Fields,
 		RoleSpecified:     strings.TrimSpace(args.Role) != "",
 		PrioritySpecified: strings.TrimSpace(args.Priority) != "",
 		Body:              args.Body,
@@ -206,10 +318,7 @@ func handleMCPAdd(ctx context.Context, request mcp.CallToolRequest, args addArgs
 
 func handleMCPNext(ctx context.Context, request mcp.CallToolRequest, args nextArgs) (*mcp.CallToolResult, error) {
 	return runWithOutput(func(w io.Writer) error {
-		timeout := nextClaimTimeout
-		if timeout <= 0 {
-			timeout = time.Hour
-		}
+		var timeout time.Duration
 		if strings.TrimSpace(args.ClaimTimeout) != "" {
 			parsed, err := time.ParseDuration(strings.TrimSpace(args.ClaimTimeout))
 			if err != nil {
@@ -221,6 +330,7 @@ func handleMCPNext(ctx context.Context, request mcp.CallToolRequest, args nextAr
 		return runNextWithOptions(w, strings.TrimSpace(args.Project), strings.TrimSpace(args.Role), nextOptions{
 			Claim:        args.Claim,
 			ClaimTimeout: timeout,
+			Agent:        resolveAgent(args.Agent),
 		})
 	})
 }
@@ -275,6 +385,8 @@ func handleMCPList(ctx context.Context, request mcp.CallToolRequest, args listAr
 			Role:           strings.TrimSpace(args.Role),
 			Priority:       normalizeEnum(args.Priority, ""),
 			Label:          strings.TrimSpace(args.Label),
+			Vars:           args.Vars,
+			Fields:         args.Fields,
 			Sort:           normalizeEnum(args.Sort, ""),
 			Order:          normalizeEnum(args.Order, "asc"),
 			Format:         normalizeEnum(args.Format, "table"),
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/ricochet1k/strandyard/pkg/task"
+	"github.com/spf13/cobra"
+)
+
+// mergeDriverName is the merge driver name used in .gitattributes and git config.
+const mergeDriverName = "strand"
+
+// mergeDriverAttributes routes every file under the local tasks directory
+// through the strand merge driver.
+const mergeDriverAttributes = ".strand/tasks/**/*.md merge=" + mergeDriverName
+
+var mergeDriverCmd = &cobra.Command{
+	Use:   "merge-driver <base> <current> <other> [path]",
+	Short: "Git merge driver for task files",
+	Long: `Three-way merge a task file for git. git runs this as "strand merge-driver %O %A %B %P"; the merged file is written to <current>.
+
+Frontmatter is merged field by field (union for blockers/blocks, latest edit wins for status with done preferred), TODO and subtask lists are merged item by item, and root-tasks.md/free-tasks.md keep the current version marked stale instead of being merged; strand regenerates them after the merge (from the post-merge hook installed by "strand git hook install") or on the next command that reads them. The command exits non-zero when conflict markers were left in the body.
+
+strand init --storage local sets this up. For other clones, register the driver with:
+  git config merge.strand.driver "strand merge-driver %O %A %B %P"`,
+	Args: cobra.RangeArgs(3, 4),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := ""
+		if len(args) > 3 {
+			path = args[3]
+		}
+		return runMergeDriver(cmd.ErrOrStderr(), args[0], args[1], args[2], path)
+	},
+	SilenceUsage: true,
+}
+
+func init() {
+	rootCmd.AddCommand(mergeDriverCmd)
+}
+
+func runMergeDriver(w io.Writer, basePath, oursPath, theirsPath, path string) error {
+	if path == "" {
+		path = oursPath
+	}
+	switch filepath.Base(path) {
+	case "root-tasks.md", "free-tasks.md":
+		// The working tree still holds the pre-merge tasks, so the list
+		// cannot be generated correctly here.
+		if err := markListStale(oursPath); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "strand: %s: marked stale; it is regenerated after the merge\n", path)
+		return nil
+	}
+
+	read := func(p string) (string, error) {
+		data, err := os.ReadFile(p)
+		return string(data), err
+	}
+	base, err := read(basePath)
+	if err != nil {
+		return err
+	}
+	ours, err := read(oursPath)
+	if err != nil {
+		return err
+	}
+	theirs, err := read(theirsPath)
+	if err != nil {
+		return err
+	}
+
+	result, err := task.MergeTaskFiles(base, ours, theirs)
+	if err != nil {
+		fmt.Fprintf(w, "strand: %s: %v; falling back to a line merge\n", path, err)
+		return gitMergeFile(oursPath, basePath, theirsPath)
+	}
+	if err := os.WriteFile(oursPath, []byte(result.Content), 0o644); err != nil {
+		return err
+	}
+	for _, note := range result.Notes {
+		fmt.Fprintf(w, "strand: %s: %s\n", path, note)
+	}
+	if len(result.Conflicts) > 0 {
+		return fmt.Errorf("%s: %s", path, strings.Join(result.Conflicts, "; "))
+	}
+	return nil
+}
+
+// markListStale prefixes the master list at path with task.StaleListMarker.
+func markListStale(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if task.IsStaleList(string(data)) {
+		return nil
+	}
+	return os.WriteFile(path, append([]byte(task.StaleListMarker+"\n"), data...), 0o644)
+}
+
+// regenerateStaleLists runs repair when either master list was marked stale
+// by the merge driver. It reports whether the lists were regenerated.
+func regenerateStaleLists(w io.Writer, paths projectPaths) (bool, error) {
+	for _, path := range []string{paths.RootTasksFile, paths.FreeTasksFile} {
+		data, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return false, err
+		}
+		if task.IsStaleList(string(data)) {
+			return true, runRepair(w, paths.TasksDir, paths.RootTasksFile, paths.FreeTasksFile, "text")
+		}
+	}
+	return false, nil
+}
+
+// gitMergeFile runs git's own line merge on files that cannot be parsed.
+func gitMergeFile(oursPath, basePath, theirsPath string) error {
+	cmd := exec.Command("git", "merge-file", "-L", "current", "-L", "base", "-L", "other", oursPath, basePath, theirsPath)
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 {
+			return fmt.Errorf("%s: conflicts left in file", oursPath)
+		}
+		return err
+	}
+	return nil
+}
+
+// setupMergeDriver adds the task merge driver to .gitattributes and the
+// repository's git config.
+func setupMergeDriver(gitRoot string) error {
+	attrsPath := filepath.Join(gitRoot, ".gitattributes")
+	existing, err := os.ReadFile(attrsPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if !strings.Contains(string(existing), mergeDriverAttributes) {
+		content := string(existing)
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		content += mergeDriverAttributes + "\n"
+		if err := os.WriteFile(attrsPath, []byte(content), 0o644); err != nil {
+			return err
+		}
+	}
+
+	for _, kv := range [][2]string{
+		{"merge." + mergeDriverName + ".name", "strand task merge"},
+		{"merge." + mergeDriverName + ".driver", "strand merge-driver %O %A %B %P"},
+	} {
+		cmd := exec.Command("git", "config", kv[0], kv[1])
+		cmd.Dir = gitRoot
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("git config %s: %s", kv[0], strings.TrimSpace(string(output)))
+		}
+	}
+	return nil
+}
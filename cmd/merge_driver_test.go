@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/strandyard/pkg/task"
+)
+
+func TestInitLocalConfiguresMergeDriver(t *testing.T) {
+	paths := setupTestProject(t, initOptions{StorageMode: storageLocal})
+
+	attrs, err := os.ReadFile(filepath.Join(paths.GitRoot, ".gitattributes"))
+	if err != nil {
+		t.Fatalf("read .gitattributes: %v", err)
+	}
+	if !strings.Contains(string(attrs), mergeDriverAttributes) {
+		t.Fatalf(".gitattributes = %q, want %q", attrs, mergeDriverAttributes)
+	}
+
+	cmd := exec.Command("git", "config", "merge.strand.driver")
+	cmd.Dir = paths.GitRoot
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("git config: %v", err)
+	}
+	if got := strings.TrimSpace(string(out)); got != "strand merge-driver %O %A %B %P" {
+		t.Fatalf("merge.strand.driver = %q", got)
+	}
+
+	if err := setupMergeDriver(paths.GitRoot); err != nil {
+		t.Fatalf("setupMergeDriver again: %v", err)
+	}
+	again, _ := os.ReadFile(filepath.Join(paths.GitRoot, ".gitattributes"))
+	if strings.Count(string(again), mergeDriverAttributes) != 1 {
+		t.Fatalf(".gitattributes should list the driver once, got %q", again)
+	}
+}
+
+func TestMergeDriverMergesTaskFiles(t *testing.T) {
+	dir := t.TempDir()
+	base := `---
+role: developer
+priority: medium
+parent: ""
+blockers: []
+blocks: []
+date_created: 2026-03-01T00:00:00Z
+date_edited: 2026-03-01T00:00:00Z
+owner_approval: false
+completed: false
+status: open
+---
+
+# Driver task
+
+## TODOs
+- [ ] (role: developer) First
+- [ ] (role: developer) Second
+`
+	ours := strings.Replace(base, "- [ ] (role: developer) First", "- [x] (role: developer) First", 1)
+	theirs := strings.Replace(base, "blockers: []", "blockers:\n  - T2abc", 1)
+	theirs = strings.Replace(theirs, "- [ ] (role: developer) Second", "- [x] (role: developer) Second", 1)
+
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+		return path
+	}
+	basePath, oursPath, theirsPath := write("base", base), write("ours", ours), write("theirs", theirs)
+
+	var stderr bytes.Buffer
+	if err := runMergeDriver(&stderr, basePath, oursPath, theirsPath, ".strand/tasks/T1abc-driver/T1abc-driver.md"); err != nil {
+		t.Fatalf("runMergeDriver: %v (%s)", err, stderr.String())
+	}
+	merged, _ := os.ReadFile(oursPath)
+	for _, want := range []string{"- T2abc\n", "- [x] (role: developer) First", "- [x] (role: developer) Second"} {
+		if !strings.Contains(string(merged), want) {
+			t.Errorf("merged file missing %q:\n%s", want, merged)
+		}
+	}
+}
+
+func TestMergeDriverMarksMasterListsStale(t *testing.T) {
+	paths := setupTestProject(t, initOptions{StorageMode: storageLocal})
+	roleName := testRoleName(t, "merge")
+	if err := os.WriteFile(filepath.Join(paths.RolesDir, roleName+".md"), []byte("# "+roleName+"\n"), 0o644); err != nil {
+		t.Fatalf("write role file: %v", err)
+	}
+	writeClaimTaskFile(t, paths.TasksDir, "T1abc-listed", roleName)
+
+	current := filepath.Join(t.TempDir(), "current")
+	if err := os.WriteFile(current, []byte("# Free tasks\n\n- old-entry\n"), 0o644); err != nil {
+		t.Fatalf("write current: %v", err)
+	}
+	rel, err := filepath.Rel(paths.GitRoot, paths.FreeTasksFile)
+	if err != nil {
+		t.Fatalf("rel: %v", err)
+	}
+
+	var stderr bytes.Buffer
+	if err := runMergeDriver(&stderr, current, current, current, rel); err != nil {
+		t.Fatalf("runMergeDriver: %v", err)
+	}
+	if err := runMergeDriver(&stderr, current, current, current, rel); err != nil {
+		t.Fatalf("runMergeDriver again: %v", err)
+	}
+	merged, _ := os.ReadFile(current)
+	if want := task.StaleListMarker + "\n# Free tasks\n\n- old-entry\n"; string(merged) != want {
+		t.Fatalf("merged list = %q, want %q", merged, want)
+	}
+
+	// git writes the merged result to the list; the post-merge hook regenerates it.
+	if err := os.WriteFile(paths.FreeTasksFile, merged, 0o644); err != nil {
+		t.Fatalf("write free list: %v", err)
+	}
+	var out bytes.Buffer
+	if err := runPostMergeHook(&out, ""); err != nil {
+		t.Fatalf("post-merge: %v", err)
+	}
+	got, _ := os.ReadFile(paths.FreeTasksFile)
+	if task.IsStaleList(string(got)) || !strings.Contains(string(got), "T1abc-listed") {
+		t.Fatalf("free list was not regenerated:\n%s\n%s", got, out.String())
+	}
+
+	// Without the hook, next regenerates the stale list before reading it.
+	if err := os.WriteFile(paths.FreeTasksFile, merged, 0o644); err != nil {
+		t.Fatalf("write free list: %v", err)
+	}
+	out.Reset()
+	if err := runNextWithOptions(&out, "", "", nextOptions{ClaimTimeout: time.Hour}); err != nil {
+		t.Fatalf("next: %v", err)
+	}
+	if !strings.Contains(out.String(), "Your task is T1abc-listed") {
+		t.Fatalf("expected next to select the listed task, got: %s", out.String())
+	}
+	got, _ = os.ReadFile(paths.FreeTasksFile)
+	if task.IsStaleList(string(got)) {
+		t.Fatalf("next left the free list stale:\n%s", got)
+	}
+}
@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// migratedFile is the tombstone left in storage a project was migrated away
+// from. Storage containing it is never resolved as a project.
+const migratedFile = "MIGRATED"
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate --to local|global",
+	Short: "Move a project between global and local storage",
+	Long: `Move the current project between global storage (~/.config/strand/projects/<name>)
+and local storage (.strand/ at the git root).
+
+Everything in the project's storage is copied: tasks, roles, templates, the
+activity log, config, preset lock and preset base files. The copy is compared
+with the original and checked with repair before projects.json is updated; if
+either fails, the copy is removed and nothing changes.
+
+The old storage is removed only after confirmation (or --yes). Otherwise it is
+kept with a MIGRATED tombstone recording where the project went, and strand
+ignores it from then on.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runMigrate(cmd.OutOrStdout(), cmd.InOrStdin(), projectName, migrateTo, migrateOpts)
+	},
+}
+
+type migrateOptions struct {
+	// Yes removes the old storage without asking.
+	Yes bool
+	// Keep leaves a tombstone without asking.
+	Keep bool
+}
+
+var (
+	migrateTo   string
+	migrateOpts migrateOptions
+)
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+	migrateCmd.Flags().StringVar(&migrateTo, "to", "", "target storage mode: local or global")
+	migrateCmd.Flags().BoolVar(&migrateOpts.Yes, "yes", false, "remove the old storage without asking")
+	migrateCmd.Flags().BoolVar(&migrateOpts.Keep, "keep", false, "keep the old storage with a tombstone without asking")
+	_ = migrateCmd.MarkFlagRequired("to")
+}
+
+func runMigrate(w io.Writer, in io.Reader, projectName, to string, opts migrateOptions) error {
+	to = strings.ToLower(strings.TrimSpace(to))
+	if to != storageLocal && to != storageGlobal {
+		return fmt.Errorf("invalid storage mode %q (expected global or local)", to)
+	}
+	if opts.Yes && opts.Keep {
+		return fmt.Errorf("--yes and --keep cannot be used together")
+	}
+
+	paths, err := resolveProjectPaths(projectName)
+	if err != nil {
+		return err
+	}
+	if paths.Storage == to {
+		return fmt.Errorf("project already uses %s storage at %s", to, paths.BaseDir)
+	}
+
+	name, gitRoot := paths.ProjectName, paths.GitRoot
+	if paths.Storage == storageLocal {
+		name = localProjectName(gitRoot)
+	} else {
+		gitRoot = findGitRootForProject(name)
+		if gitRoot == "" {
+			if gitRoot, err = gitRootDir(); err != nil {
+				return fmt.Errorf("project %s is not linked to a git repository: %w", name, err)
+			}
+		}
+	}
+	if name == "" || gitRoot == "" {
+		return fmt.Errorf("cannot determine the project name and git root for %s", paths.BaseDir)
+	}
+
+	var dest string
+	if to == storageLocal {
+		dest = filepath.Join(gitRoot, ".strand")
+	} else {
+		dir, err := projectsDir()
+		if err != nil {
+			return err
+		}
+		dest = filepath.Join(dir, name)
+	}
+	if _, err := os.Stat(dest); err == nil {
+		return fmt.Errorf("destination %s already exists; move or remove it first", dest)
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	fmt.Fprintf(w, "Migrating project %s from %s storage to %s storage\n", name, paths.Storage, to)
+	fmt.Fprintf(w, "  from: %s\n  to:   %s\n", paths.BaseDir, dest)
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return err
+	}
+	if err := copyDir(nil, paths.BaseDir, dest, ""); err != nil {
+		_ = os.RemoveAll(dest)
+		return fmt.Errorf("failed to copy project: %w", err)
+	}
+	if err := compareDirs(paths.BaseDir, dest); err != nil {
+		_ = os.RemoveAll(dest)
+		return fmt.Errorf("copy verification failed, nothing was changed: %w", err)
+	}
+	fmt.Fprintln(w, "✓ Copied project storage")
+
+	newPaths, err := projectPathsFromBase(dest, name, gitRoot, to)
+	if err != nil {
+		return err
+	}
+	var repairOut bytes.Buffer
+	if err := runRepair(&repairOut, newPaths.TasksDir, newPaths.RootTasksFile, newPaths.FreeTasksFile, "text"); err != nil {
+		_ = os.RemoveAll(dest)
+		fmt.Fprint(w, repairOut.String())
+		return fmt.Errorf("repair failed on the migrated copy, nothing was changed: %w", err)
+	}
+	fmt.Fprintln(w, "✓ Verified migrated copy with repair")
+
+	cfg, err := loadProjectMap()
+	if err != nil {
+		_ = os.RemoveAll(dest)
+		return err
+	}
+	if to == storageLocal {
+		delete(cfg.Repos, gitRoot)
+		cfg.LocalPaths[name] = gitRoot
+	} else {
+		delete(cfg.LocalPaths, name)
+		cfg.Repos[gitRoot] = name
+	}
+	if err := saveProjectMap(cfg); err != nil {
+		_ = os.RemoveAll(dest)
+		return err
+	}
+	fmt.Fprintf(w, "✓ Linked %s to %s storage\n", gitRoot, to)
+
+	if to == storageLocal {
+		if err := setupMergeDriver(gitRoot); err != nil {
+			return fmt.Errorf("failed to configure git merge driver: %w", err)
+		}
+		fmt.Fprintln(w, "✓ Configured git merge driver for task files in .gitattributes")
+	}
+
+	remove := opts.Yes
+	if !opts.Yes && !opts.Keep {
+		fmt.Fprintf(w, "Remove old storage at %s? [y/N] ", paths.BaseDir)
+		answer, _ := bufio.NewReader(in).ReadString('\n')
+		fmt.Fprintln(w)
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		remove = answer == "y" || answer == "yes"
+	}
+	if remove {
+		if err := os.RemoveAll(paths.BaseDir); err != nil {
+			return fmt.Errorf("failed to remove old storage: %w", err)
+		}
+		fmt.Fprintf(w, "✓ Removed %s\n", paths.BaseDir)
+	} else {
+		tombstone := fmt.Sprintf("This strand project was migrated to %s storage at %s on %s.\nStrand ignores this directory; delete it once you no longer need the old copy.\n",
+			to, dest, time.Now().UTC().Format(time.RFC3339))
+		if err := os.WriteFile(filepath.Join(paths.BaseDir, migratedFile), []byte(tombstone), 0o644); err != nil {
+			return fmt.Errorf("failed to write tombstone: %w", err)
+		}
+		fmt.Fprintf(w, "✓ Kept old storage with a %s tombstone: %s\n", migratedFile, paths.BaseDir)
+	}
+
+	if to == storageLocal {
+		fmt.Fprintln(w, "Next: git add .strand .gitattributes")
+	} else if remove {
+		fmt.Fprintln(w, "Next: commit the removal of .strand if it was tracked in git")
+	}
+	return nil
+}
+
+// isMigrated reports whether baseDir holds a migration tombstone.
+func isMigrated(baseDir string) bool {
+	_, err := os.Stat(filepath.Join(baseDir, migratedFile))
+	return err == nil
+}
+
+// compareDirs checks that every file under src exists in dst with the same
+// content.
+func compareDirs(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		want, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		got, err := os.ReadFile(filepath.Join(dst, rel))
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(want, got) {
+			return fmt.Errorf("%s differs after copying", rel)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMigrateGlobalToLocal(t *testing.T) {
+	paths := setupTestProject(t, initOptions{StorageMode: storageGlobal})
+	writeClaimTaskFile(t, paths.TasksDir, "T1abc-moved", "developer")
+	if err := os.WriteFile(filepath.Join(paths.RolesDir, "developer.md"), []byte("# developer\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(paths.BaseDir, "activity.log"), []byte("{}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	gitRoot, err := gitRootDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := runMigrate(&out, strings.NewReader(""), "", storageLocal, migrateOptions{Yes: true}); err != nil {
+		t.Fatalf("migrate: %v\n%s", err, out.String())
+	}
+
+	local := filepath.Join(gitRoot, ".strand")
+	for _, rel := range []string{"tasks/T1abc-moved.md", "roles/developer.md", "activity.log"} {
+		if _, err := os.Stat(filepath.Join(local, rel)); err != nil {
+			t.Errorf("%s not migrated: %v", rel, err)
+		}
+	}
+	if _, err := os.Stat(paths.BaseDir); !os.IsNotExist(err) {
+		t.Errorf("old storage should be removed with --yes, stat err = %v", err)
+	}
+	cfg, err := loadProjectMap()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cfg.Repos[gitRoot]; ok {
+		t.Errorf("global mapping for %s should be removed: %v", gitRoot, cfg.Repos)
+	}
+	if cfg.LocalPaths[paths.ProjectName] != gitRoot {
+		t.Errorf("local_paths = %v, want %s -> %s", cfg.LocalPaths, paths.ProjectName, gitRoot)
+	}
+	resolved, err := resolveProjectPaths("")
+	if err != nil || resolved.Storage != storageLocal {
+		t.Errorf("resolved %+v, %v; want local storage", resolved, err)
+	}
+}
+
+func TestMigrateLocalToGlobalLeavesTombstone(t *testing.T) {
+	paths := setupTestProject(t, initOptions{StorageMode: storageLocal})
+	writeClaimTaskFile(t, paths.TasksDir, "T1abc-moved", "developer")
+	if err := os.WriteFile(filepath.Join(paths.RolesDir, "developer.md"), []byte("# developer\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := runMigrate(&out, strings.NewReader("n\n"), "", storageGlobal, migrateOptions{}); err != nil {
+		t.Fatalf("migrate: %v\n%s", err, out.String())
+	}
+	if !strings.Contains(out.String(), "Remove old storage") {
+		t.Errorf("expected a confirmation prompt:\n%s", out.String())
+	}
+	if !isMigrated(paths.BaseDir) {
+		t.Fatalf("declining removal should leave a %s tombstone", migratedFile)
+	}
+
+	resolved, err := resolveProjectPaths("")
+	if err != nil {
+		t.Fatalf("resolve after migrate: %v", err)
+	}
+	if resolved.Storage != storageGlobal {
+		t.Fatalf("resolved storage = %s, want global (tombstoned .strand must be ignored)", resolved.Storage)
+	}
+	if _, err := os.Stat(filepath.Join(resolved.TasksDir, "T1abc-moved.md")); err != nil {
+		t.Errorf("task not migrated: %v", err)
+	}
+
+	if err := runMigrate(io.Discard, strings.NewReader(""), "", storageGlobal, migrateOptions{}); err == nil || !strings.Contains(err.Error(), "already uses global") {
+		t.Errorf("expected already-global error, got %v", err)
+	}
+}
+
+func TestMigrateRollsBackWhenRepairFails(t *testing.T) {
+	paths := setupTestProject(t, initOptions{StorageMode: storageLocal})
+	writeClaimTaskFile(t, paths.TasksDir, "T1abc-orphan", "no-such-role")
+
+	err := runMigrate(io.Discard, strings.NewReader(""), "", storageGlobal, migrateOptions{Yes: true})
+	if err == nil || !strings.Contains(err.Error(), "nothing was changed") {
+		t.Fatalf("expected repair failure, got %v", err)
+	}
+	dir, err := projectsDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, filepath.Base(paths.GitRoot))); !os.IsNotExist(err) {
+		t.Errorf("failed migration should remove the copy, stat err = %v", err)
+	}
+	if isMigrated(paths.BaseDir) {
+		t.Error("failed migration must not tombstone the source")
+	}
+}
+
+func TestMigrateRefusesExistingDestination(t *testing.T) {
+	paths := setupTestProject(t, initOptions{StorageMode: storageGlobal})
+	gitRoot, err := gitRootDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(gitRoot, ".strand"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// An empty .strand is not a project, so the global one still resolves.
+	err = runMigrate(io.Discard, strings.NewReader(""), paths.ProjectName, storageLocal, migrateOptions{Yes: true})
+	if err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("expected existing destination error, got %v", err)
+	}
+	if _, err := os.Stat(paths.BaseDir); err != nil {
+		t.Errorf("source must be untouched: %v", err)
+	}
+}
@@ -4,14 +4,15 @@ Copyright © 2026 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
-	"sort"
 	"strings"
 	"time"
 
+	"github.com/ricochet1k/strandyard/pkg/config"
 	"github.com/ricochet1k/strandyard/pkg/task"
 	"github.com/spf13/cobra"
 )
@@ -19,10 +20,17 @@ import (
 var nextRole string
 var nextClaim bool
 var nextClaimTimeout time.Duration
+var nextPreferImpact bool
+var nextPolicy string
+var nextAgent string
+var nextAllProjects bool
 
 type nextOptions struct {
 	Claim        bool
 	ClaimTimeout time.Duration
+	PreferImpact bool
+	Policy       string
+	Agent        string
 	Now          func() time.Time
 }
 
@@ -35,10 +43,24 @@ Also prints the full role (from metadata or first TODO) so that the output
 contains all the information an agent needs to execute the task without
 looking anything else up.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return runNextWithOptions(cmd.OutOrStdout(), projectName, nextRole, nextOptions{
+		var claimTimeout time.Duration
+		if cmd.Flags().Changed("claim-timeout") {
+			if nextClaimTimeout <= 0 {
+				return fmt.Errorf("--claim-timeout must be greater than 0")
+			}
+			claimTimeout = nextClaimTimeout
+		}
+		opts := nextOptions{
 			Claim:        nextClaim,
-			ClaimTimeout: nextClaimTimeout,
-		})
+			ClaimTimeout: claimTimeout,
+			PreferImpact: nextPreferImpact,
+			Policy:       nextPolicy,
+			Agent:        resolveAgent(nextAgent),
+		}
+		if nextAllProjects {
+			return runNextAllProjects(cmd.OutOrStdout(), nextRole, opts)
+		}
+		return runNextWithOptions(cmd.OutOrStdout(), projectName, nextRole, opts)
 	},
 }
 
@@ -46,15 +68,19 @@ func init() {
 	rootCmd.AddCommand(nextCmd)
 	nextCmd.Flags().StringVar(&nextRole, "role", "", "optional: filter tasks by role")
 	nextCmd.Flags().BoolVar(&nextClaim, "claim", false, "claim the selected task by marking it in_progress")
-	nextCmd.Flags().DurationVar(&nextClaimTimeout, "claim-timeout", time.Hour, "timeout before an in-progress claim is treated as open again")
+	nextCmd.Flags().DurationVar(&nextClaimTimeout, "claim-timeout", 0, "timeout before an in-progress claim is treated as open again (default next.claim_timeout, 1h)")
+	nextCmd.Flags().BoolVar(&nextPreferImpact, "prefer-impact", false, "prefer high-impact tasks within a priority (shorthand for --policy impact)")
+	nextCmd.Flags().StringVar(&nextAgent, "agent", "", "agent identity recorded on --claim and used for WIP limits (default $STRAND_AGENT)")
+	nextCmd.Flags().BoolVar(&nextAllProjects, "all-projects", false, "pick the next free task across every registered project")
+	nextCmd.Flags().StringVar(&nextPolicy, "policy", "", "selection policy: "+strings.Join(task.SelectionPolicyNames(), ", ")+" (default next.policy, priority)")
 }
 
 func runNext(w io.Writer, projectName, roleFilter string) error {
-	return runNextWithOptions(w, projectName, roleFilter, nextOptions{ClaimTimeout: time.Hour})
+	return runNextWithOptions(w, projectName, roleFilter, nextOptions{})
 }
 
 func runNextWithOptions(w io.Writer, projectName, roleFilter string, opts nextOptions) error {
-	if opts.ClaimTimeout <= 0 {
+	if opts.ClaimTimeout < 0 {
 		return fmt.Errorf("--claim-timeout must be greater than 0")
 	}
 	if opts.Now == nil {
@@ -67,37 +93,198 @@ func runNextWithOptions(w io.Writer, projectName, roleFilter string, opts nextOp
 		return err
 	}
 
-	freePath := paths.FreeTasksFile
-	if _, err := os.Stat(freePath); os.IsNotExist(err) {
-		if err := runRepair(w, paths.TasksDir, paths.RootTasksFile, freePath, "text"); err != nil {
-			return fmt.Errorf("unable to generate master lists: %w", err)
+	cfg, err := loadConfig(paths.BaseDir)
+	if err != nil {
+		return err
+	}
+	policyName, err := resolveNextPolicy(opts, cfg)
+	if err != nil {
+		return err
+	}
+
+	pool, err := loadNextPool(w, paths, cfg, roleFilter, opts, now)
+	if err != nil {
+		return err
+	}
+
+	policy, err := task.NewSelectionPolicy(policyName, task.SelectionContext{
+		Now:           now,
+		Tasks:         pool.db.GetAll(),
+		AgingInterval: cfg.Next.AgingInterval,
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(pool.candidates) == 0 {
+		printNoFreeTasks(w, roleFilter, pool.free, pool.hasOwnerTasks)
+		return nil
+	}
+
+	candidates := pool.candidates
+	task.SortCandidates(candidates, policy)
+
+	selectedTask := candidates[0]
+	if opts.Claim {
+		selectedTask, err = firstClaimable(candidates, func(t *task.Task) error {
+			return task.CheckWIPClaim(pool.db.GetAll(), wipLimits(cfg), t, opts.Agent)
+		})
+		if err != nil {
+			return err
 		}
+		if err := pool.claim(selectedTask, opts.Agent); err != nil {
+			return err
+		}
+	}
+	if err := pool.save(); err != nil {
+		return err
 	}
 
-	data, err := os.ReadFile(freePath)
+	printNextTask(w, pool, selectedTask, "")
+	return nil
+}
+
+// runNextAllProjects picks the next free task across every registered
+// project, ordering the merged candidates by a single selection policy.
+func runNextAllProjects(w io.Writer, roleFilter string, opts nextOptions) error {
+	if opts.ClaimTimeout < 0 {
+		return fmt.Errorf("--claim-timeout must be greater than 0")
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	now := opts.Now().UTC()
+
+	projects, err := loadWorkspaceProjects()
+	if err != nil {
+		return err
+	}
+	cfg, err := workspaceConfig()
 	if err != nil {
-		return fmt.Errorf("unable to read %s: %w", freePath, err)
+		return err
+	}
+	policyName, err := resolveNextPolicy(opts, cfg)
+	if err != nil {
+		return err
 	}
 
-	db := task.NewTaskDB(paths.TasksDir)
-	if err := db.LoadAllIfEmpty(); err != nil {
-		return fmt.Errorf("failed to load tasks: %w", err)
+	pools := make(map[string]*nextPool, len(projects))
+	allTasks := make(map[string]*task.Task)
+	var candidates []*task.Task
+	free := 0
+	hasOwnerTasks := false
+	for _, p := range projects {
+		pool, err := loadNextPool(w, p.Paths, p.Config, roleFilter, opts, now)
+		if err != nil {
+			return fmt.Errorf("project %s: %w", p.Paths.ProjectName, err)
+		}
+		name := p.Paths.ProjectName
+		pools[name] = pool
+		for _, t := range pool.db.GetAll() {
+			t.Project = name
+			allTasks[t.Key()] = t
+		}
+		candidates = append(candidates, pool.candidates...)
+		free += pool.free
+		hasOwnerTasks = hasOwnerTasks || pool.hasOwnerTasks
 	}
 
-	parsed := task.ParseFreeList(string(data), db.GetAll())
-	if len(parsed.TaskIDs) == 0 {
-		fmt.Fprintln(w, "No free tasks found")
+	policy, err := task.NewSelectionPolicy(policyName, task.SelectionContext{
+		Now:           now,
+		Tasks:         allTasks,
+		AgingInterval: cfg.Next.AgingInterval,
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(candidates) == 0 {
+		printNoFreeTasks(w, roleFilter, free, hasOwnerTasks)
 		return nil
 	}
 
-	claimStateChanged := false
+	task.SortCandidates(candidates, policy)
 
-	type candidate struct {
-		task *task.Task
-		path string
+	selectedTask := candidates[0]
+	if opts.Claim {
+		selectedTask, err = firstClaimable(candidates, func(t *task.Task) error {
+			pool := pools[t.Project]
+			return task.CheckWorkspaceWIPClaim(pool.db.GetAll(), allTasks, wipLimits(pool.cfg), t, opts.Agent)
+		})
+		if err != nil {
+			return err
+		}
+		if err := pools[selectedTask.Project].claim(selectedTask, opts.Agent); err != nil {
+			return err
+		}
+	}
+	for _, p := range projects {
+		if err := pools[p.Paths.ProjectName].save(); err != nil {
+			return fmt.Errorf("project %s: %w", p.Paths.ProjectName, err)
+		}
+	}
+
+	printNextTask(w, pools[selectedTask.Project], selectedTask, selectedTask.Project)
+	return nil
+}
+
+// nextPool holds one project's free candidates for `strand next`.
+type nextPool struct {
+	paths projectPaths
+	cfg   config.Config
+	db    *task.TaskDB
+	// free counts the tasks in the free list, before role filtering.
+	free          int
+	candidates    []*task.Task
+	hasOwnerTasks bool
+	// changed records claim state that must be saved, such as reopened
+	// expired claims.
+	changed bool
+}
+
+// loadNextPool reads a project's free list, regenerating it first when it is
+// missing, stale after a merge or out of date with a cross-project blocker,
+// reopens expired claims and keeps the free tasks that match roleFilter.
+func loadNextPool(w io.Writer, paths projectPaths, cfg config.Config, roleFilter string, opts nextOptions, now time.Time) (*nextPool, error) {
+	claimTimeout := opts.ClaimTimeout
+	if claimTimeout == 0 {
+		claimTimeout = cfg.Next.ClaimTimeout
+	}
+
+	freePath := paths.FreeTasksFile
+	if _, err := os.Stat(freePath); os.IsNotExist(err) {
+		if err := runRepair(w, paths.TasksDir, paths.RootTasksFile, freePath, "text"); err != nil {
+			return nil, fmt.Errorf("unable to generate master lists: %w", err)
+		}
+	} else if _, err := regenerateStaleLists(w, paths); err != nil {
+		return nil, fmt.Errorf("unable to regenerate master lists: %w", err)
+	}
+
+	db := task.NewTaskDB(paths.TasksDir)
+	if err := db.LoadAllIfEmpty(); err != nil {
+		return nil, fmt.Errorf("failed to load tasks: %w", err)
 	}
-	var candidatesParsed []candidate
-	var hasOwnerTasks bool
+
+	// Cross-project blockers finish in other projects without touching this
+	// one's free list, so drop the done ones and regenerate the list first.
+	// Broken references are left for repair to report.
+	if updated, _ := task.ReconcileRemoteReferences(db.GetAll(), remoteTasks()); updated > 0 {
+		if _, err := db.SaveDirty(); err != nil {
+			return nil, fmt.Errorf("failed to write task updates: %w", err)
+		}
+		if err := task.GenerateMasterLists(db.GetAll(), paths.TasksDir, paths.RootTasksFile, freePath); err != nil {
+			return nil, fmt.Errorf("failed to update master lists: %w", err)
+		}
+	}
+
+	data, err := os.ReadFile(freePath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s: %w", freePath, err)
+	}
+
+	pool := &nextPool{paths: paths, cfg: cfg, db: db}
+	parsed := task.ParseFreeList(string(data), db.GetAll())
+	pool.free = len(parsed.TaskIDs)
 
 	for _, taskID := range parsed.TaskIDs {
 		t, err := db.Get(taskID)
@@ -106,11 +293,11 @@ func runNextWithOptions(w io.Writer, projectName, roleFilter string, opts nextOp
 		}
 
 		if t.Meta.IsInProgress() {
-			if now.Sub(t.Meta.DateEdited) >= opts.ClaimTimeout {
+			if now.Sub(t.Meta.DateEdited) >= claimTimeout {
 				if err := db.SetStatus(taskID, task.StatusOpen); err != nil {
-					return fmt.Errorf("failed to reopen expired claim for %s: %w", taskID, err)
+					return nil, fmt.Errorf("failed to reopen expired claim for %s: %w", taskID, err)
 				}
-				claimStateChanged = true
+				pool.changed = true
 			} else {
 				continue
 			}
@@ -118,7 +305,7 @@ func runNextWithOptions(w io.Writer, projectName, roleFilter string, opts nextOp
 
 		taskRole := t.GetEffectiveRole()
 		if taskRole == "owner" {
-			hasOwnerTasks = true
+			pool.hasOwnerTasks = true
 		}
 
 		if roleFilter != "" {
@@ -129,54 +316,60 @@ func runNextWithOptions(w io.Writer, projectName, roleFilter string, opts nextOp
 			continue
 		}
 
-		candidatesParsed = append(candidatesParsed, candidate{
-			task: t,
-			path: filepath.ToSlash(t.FilePath),
-		})
+		pool.candidates = append(pool.candidates, t)
 	}
+	return pool, nil
+}
 
-	if len(candidatesParsed) == 0 {
-		if roleFilter != "" {
-			fmt.Fprintf(w, "No free tasks found for role: %s\n", roleFilter)
-		} else if hasOwnerTasks {
-			fmt.Fprintln(w, "No free tasks found. There are owner tasks remaining; try `strand next --role owner`.")
-		} else {
-			fmt.Fprintln(w, "No free tasks found")
-		}
-		return nil
+func (p *nextPool) claim(t *task.Task, agent string) error {
+	if err := p.db.ClaimTaskAs(t.ID, agent); err != nil {
+		return fmt.Errorf("failed to claim task %s: %w", t.ID, err)
 	}
+	p.changed = true
+	return nil
+}
 
-	sort.Slice(candidatesParsed, func(i, j int) bool {
-		pi := task.PriorityRank(candidatesParsed[i].task.Meta.Priority)
-		pj := task.PriorityRank(candidatesParsed[j].task.Meta.Priority)
-		if pi != pj {
-			return pi < pj
-		}
-		return candidatesParsed[i].path < candidatesParsed[j].path
-	})
-
-	selectedTask := candidatesParsed[0].task
+// save persists changed claim state and regenerates the master lists.
+func (p *nextPool) save() error {
+	if !p.changed {
+		return nil
+	}
+	if _, err := p.db.SaveDirty(); err != nil {
+		return fmt.Errorf("failed to persist task claim state: %w", err)
+	}
+	if err := task.GenerateMasterLists(p.db.GetAll(), p.paths.TasksDir, p.paths.RootTasksFile, p.paths.FreeTasksFile); err != nil {
+		return fmt.Errorf("failed to update master lists: %w", err)
+	}
+	return nil
+}
 
-	if opts.Claim {
-		if err := db.MarkInProgress(selectedTask.ID); err != nil {
-			return fmt.Errorf("failed to claim task %s: %w", selectedTask.ID, err)
-		}
-		claimStateChanged = true
+func printNoFreeTasks(w io.Writer, roleFilter string, free int, hasOwnerTasks bool) {
+	switch {
+	case free == 0:
+		fmt.Fprintln(w, "No free tasks found")
+	case roleFilter != "":
+		fmt.Fprintf(w, "No free tasks found for role: %s\n", roleFilter)
+	case hasOwnerTasks:
+		fmt.Fprintln(w, "No free tasks found. There are owner tasks remaining; try `strand next --role owner`.")
+	default:
+		fmt.Fprintln(w, "No free tasks found")
 	}
+}
 
-	if claimStateChanged {
-		if _, err := db.SaveDirty(); err != nil {
-			return fmt.Errorf("failed to persist task claim state: %w", err)
-		}
-		if err := task.GenerateMasterLists(db.GetAll(), paths.TasksDir, paths.RootTasksFile, paths.FreeTasksFile); err != nil {
-			return fmt.Errorf("failed to update master lists: %w", err)
-		}
+// printNextTask prints the role, ancestors and description of the selected
+// task. project is set when the task was picked across projects, so the
+// printed commands name it.
+func printNextTask(w io.Writer, pool *nextPool, selectedTask *task.Task, project string) {
+	projectFlag := ""
+	if project != "" {
+		projectFlag = " --project " + project
+		fmt.Fprintf(w, "Your task is in project %s; pass `--project %s` to strand commands for it.\n\n", project, project)
 	}
 
 	role := selectedTask.GetEffectiveRole()
 
 	if role != "" {
-		rolePath := filepath.Join(paths.RolesDir, role+".md")
+		rolePath := filepath.Join(pool.paths.RolesDir, role+".md")
 		roleData, err := os.ReadFile(rolePath)
 		if err == nil {
 			roleDoc := string(roleData)
@@ -194,7 +387,7 @@ func runNextWithOptions(w io.Writer, projectName, roleFilter string, opts nextOp
 	}
 
 	// Print ancestors if this task has parents
-	ancestors := db.GetAncestors(selectedTask.ID)
+	ancestors := pool.db.GetAncestors(selectedTask.ID)
 	if len(ancestors) > 0 {
 		fmt.Fprint(w, "\nAncestors:\n")
 		for _, ancestor := range ancestors {
@@ -209,10 +402,46 @@ func runNextWithOptions(w io.Writer, projectName, roleFilter string, opts nextOp
 	for i, todo := range selectedTask.TodoItems {
 		if !todo.Checked {
 			fmt.Fprintf(w, "\n\nYou should focus on TODO #%v which is: %v\n", i+1, todo.Text)
-			fmt.Fprintf(w, "\nMark the TODO completed with `strand complete %v --role %v --todo %v \"report\"`\n", selectedTask.ID, role, i+1)
+			fmt.Fprintf(w, "\nMark the TODO completed with `strand complete%s %v --role %v --todo %v \"report\"`\n", projectFlag, selectedTask.ID, role, i+1)
 			break
 		}
 	}
+}
 
-	return nil
+// resolveNextPolicy picks the selection policy from --policy, --prefer-impact
+// and strand.yaml, in that order.
+func resolveNextPolicy(opts nextOptions, cfg config.Config) (string, error) {
+	policy := strings.TrimSpace(opts.Policy)
+	if opts.PreferImpact {
+		if policy != "" && policy != task.PolicyImpact {
+			return "", fmt.Errorf("--prefer-impact cannot be combined with --policy %s", policy)
+		}
+		policy = task.PolicyImpact
+	}
+	if policy == "" {
+		policy = cfg.Next.Policy
+	}
+	return policy, nil
+}
+
+// firstClaimable returns the first candidate that check allows to be claimed
+// without exceeding a WIP limit. Candidates whose role is full are skipped; a
+// full agent cannot claim anything.
+func firstClaimable(candidates []*task.Task, check func(*task.Task) error) (*task.Task, error) {
+	var roleErr error
+	for _, candidate := range candidates {
+		err := check(candidate)
+		if err == nil {
+			return candidate, nil
+		}
+		var limitErr *task.WIPLimitError
+		if errors.As(err, &limitErr) && limitErr.Usage.Scope == task.WIPScopeRole {
+			if roleErr == nil {
+				roleErr = err
+			}
+			continue
+		}
+		return nil, err
+	}
+	return nil, roleErr
 }
@@ -103,6 +103,135 @@ func TestNextReopensExpiredInProgressTask(t *testing.T) {
 	}
 }
 
+func TestNextPreferImpactPicksUnblockingTask(t *testing.T) {
+	paths := setupTestProject(t, initOptions{ProjectName: "", StorageMode: storageLocal})
+	roleName := testRoleName(t, "impact")
+	writeRoleFile(t, filepath.Join(paths.RolesDir, roleName+".md"), roleName)
+
+	now := time.Date(2026, 2, 1, 12, 0, 0, 0, time.UTC)
+	taskA := "T1a1a-alpha"
+	taskB := "T2a1a-beta"
+	taskC := "T3a1a-gamma"
+	writeNextTaskFile(t, paths.TasksDir, taskA, roleName, task.StatusOpen, now)
+	writeNextTaskFile(t, paths.TasksDir, taskB, roleName, task.StatusOpen, now)
+	writeNextTaskFile(t, paths.TasksDir, taskC, roleName, task.StatusOpen, now)
+
+	db := task.NewTaskDB(paths.TasksDir)
+	if err := db.LoadAll(); err != nil {
+		t.Fatalf("failed to load tasks: %v", err)
+	}
+	if err := db.AddBlocker(taskC, taskB); err != nil {
+		t.Fatalf("failed to add blocker: %v", err)
+	}
+	if _, err := db.SaveDirty(); err != nil {
+		t.Fatalf("failed to save tasks: %v", err)
+	}
+	if err := runRepair(io.Discard, paths.TasksDir, paths.RootTasksFile, paths.FreeTasksFile, "text"); err != nil {
+		t.Fatalf("runRepair failed: %v", err)
+	}
+
+	var defaultOutput bytes.Buffer
+	if err := runNextWithOptions(&defaultOutput, "", "", nextOptions{ClaimTimeout: time.Hour}); err != nil {
+		t.Fatalf("runNextWithOptions failed: %v", err)
+	}
+	if !strings.Contains(defaultOutput.String(), "Your task is "+taskA) {
+		t.Fatalf("expected default selection %s, got: %s", taskA, defaultOutput.String())
+	}
+
+	var impactOutput bytes.Buffer
+	if err := runNextWithOptions(&impactOutput, "", "", nextOptions{ClaimTimeout: time.Hour, PreferImpact: true}); err != nil {
+		t.Fatalf("runNextWithOptions with impact failed: %v", err)
+	}
+	if !strings.Contains(impactOutput.String(), "Your task is "+taskB) {
+		t.Fatalf("expected impact selection %s, got: %s", taskB, impactOutput.String())
+	}
+}
+
+func TestNextPolicyFromProjectConfig(t *testing.T) {
+	paths := setupTestProject(t, initOptions{ProjectName: "", StorageMode: storageLocal})
+	roleName := testRoleName(t, "policy")
+	writeRoleFile(t, filepath.Join(paths.RolesDir, roleName+".md"), roleName)
+
+	now := time.Date(2026, 2, 1, 12, 0, 0, 0, time.UTC)
+	taskA := "T1a1a-alpha"
+	taskB := "T2a1a-beta"
+	writeNextTaskFile(t, paths.TasksDir, taskA, roleName, task.StatusOpen, now)
+	writeNextTaskFile(t, paths.TasksDir, taskB, roleName, task.StatusOpen, now.Add(-48*time.Hour))
+	if err := runRepair(io.Discard, paths.TasksDir, paths.RootTasksFile, paths.FreeTasksFile, "text"); err != nil {
+		t.Fatalf("runRepair failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(paths.BaseDir, "strand.yaml"), []byte("next:\n  policy: oldest\n"), 0o644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	opts := nextOptions{ClaimTimeout: time.Hour, Now: func() time.Time { return now }}
+
+	var configOutput bytes.Buffer
+	if err := runNextWithOptions(&configOutput, "", "", opts); err != nil {
+		t.Fatalf("runNextWithOptions failed: %v", err)
+	}
+	if !strings.Contains(configOutput.String(), "Your task is "+taskB) {
+		t.Fatalf("expected configured oldest policy to select %s, got: %s", taskB, configOutput.String())
+	}
+
+	opts.Policy = task.PolicyPriority
+	var flagOutput bytes.Buffer
+	if err := runNextWithOptions(&flagOutput, "", "", opts); err != nil {
+		t.Fatalf("runNextWithOptions with --policy failed: %v", err)
+	}
+	if !strings.Contains(flagOutput.String(), "Your task is "+taskA) {
+		t.Fatalf("expected --policy to override config and select %s, got: %s", taskA, flagOutput.String())
+	}
+
+	opts.Policy = task.PolicyOldest
+	opts.PreferImpact = true
+	if err := runNextWithOptions(io.Discard, "", "", opts); err == nil {
+		t.Fatal("expected error combining --prefer-impact with another policy")
+	}
+
+	opts = nextOptions{ClaimTimeout: time.Hour, Policy: "random"}
+	if err := runNextWithOptions(io.Discard, "", "", opts); err == nil || !strings.Contains(err.Error(), "invalid policy") {
+		t.Fatalf("expected invalid policy error, got %v", err)
+	}
+}
+
+func TestNextClaimSkipsRoleAtWIPLimit(t *testing.T) {
+	paths := setupTestProject(t, initOptions{ProjectName: "", StorageMode: storageLocal})
+	devRole := testRoleName(t, "wip-dev")
+	reviewRole := testRoleName(t, "wip-review")
+	writeRoleFile(t, filepath.Join(paths.RolesDir, devRole+".md"), devRole)
+	writeRoleFile(t, filepath.Join(paths.RolesDir, reviewRole+".md"), reviewRole)
+	config := "wip:\n  roles:\n    " + devRole + ": 1\n"
+	if err := os.WriteFile(filepath.Join(paths.BaseDir, "strand.yaml"), []byte(config), 0o644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	now := time.Date(2026, 2, 1, 12, 0, 0, 0, time.UTC)
+	taskA := "T1a1a-alpha"
+	taskB := "T2a1a-beta"
+	taskC := "T3a1a-gamma"
+	writeNextTaskFile(t, paths.TasksDir, taskA, devRole, task.StatusInProgress, now)
+	writeNextTaskFile(t, paths.TasksDir, taskB, devRole, task.StatusOpen, now)
+	writeNextTaskFile(t, paths.TasksDir, taskC, reviewRole, task.StatusOpen, now)
+	if err := runRepair(io.Discard, paths.TasksDir, paths.RootTasksFile, paths.FreeTasksFile, "text"); err != nil {
+		t.Fatalf("runRepair failed: %v", err)
+	}
+
+	opts := nextOptions{Claim: true, ClaimTimeout: time.Hour, Agent: "alice", Now: func() time.Time { return now }}
+	var output bytes.Buffer
+	if err := runNextWithOptions(&output, "", "", opts); err != nil {
+		t.Fatalf("runNextWithOptions failed: %v", err)
+	}
+	if !strings.Contains(output.String(), "Your task is "+taskC) {
+		t.Fatalf("expected next to skip full role and claim %s, got: %s", taskC, output.String())
+	}
+
+	err := runNextWithOptions(io.Discard, "", devRole, opts)
+	if err == nil || !strings.Contains(err.Error(), "WIP limit reached for role "+devRole) {
+		t.Fatalf("expected role limit error with --role, got %v", err)
+	}
+}
+
 func writeRoleFile(t *testing.T, path, roleName string) {
 	t.Helper()
 	content := "# " + roleName + "\n\nrole description\n"
@@ -6,7 +6,12 @@ package cmd
 import (
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
+	"strings"
 
+	"github.com/ricochet1k/strandyard/pkg/preset"
+	"github.com/ricochet1k/strandyard/pkg/task"
 	"github.com/spf13/cobra"
 )
 
@@ -19,7 +24,7 @@ var presetCmd = &cobra.Command{
 
 // refreshCmd represents the refresh command
 var refreshCmd = &cobra.Command{
-	Use:   "refresh <preset>",
+	Use:   "refresh [preset]",
 	Short: "Refresh roles and templates from a preset",
 	Long: `Refresh roles and templates from a preset source (local directory or git URL).
 
@@ -27,16 +32,36 @@ A preset is a directory containing:
   - roles/       (role documents)
   - templates/   (task templates)
 
-This command will:
-  ✓ Overwrite existing role and template files
+The preset source, git commit and a hash of every role and template file are
+recorded in preset.lock in the project, and the applied files are kept in
+preset.base/. Refresh merges three ways, using the applied file as the base:
+  ✓ Files you have not edited are updated to the new preset version
+  ✓ Files you edited keep your edits when the preset did not change them
+  ✓ Files changed on both sides are merged; overlapping edits are left with
+    conflict markers and reported
   ✓ Preserve your tasks/ directory (tasks are never touched)
   ✓ Run repair automatically after refreshing
 
+Several presets can be layered, lowest precedence first, by passing several
+sources or listing them in the preset.sources config key. Later layers win per
+file, or per frontmatter key when both versions have frontmatter (the body is
+kept unless the later layer has one). Layers above the first may provide only
+roles/ or only templates/; project files no layer provides stay local. The
+merged workflow is validated after refreshing.
+
+Without arguments, preset.sources is used, else the layers in preset.lock.
+
 The preset source can be:
   - Local directory path: /path/to/my-preset
   - Git HTTPS URL:       https://github.com/user/strand-preset.git
   - Git SSH URL:         git@github.com:user/strand-preset.git
 
+Git sources accept url[@ref][#subdir]: @ref pins a branch, tag or commit and
+#subdir selects the preset directory inside the repository. Clones are cached
+under the strand config dir, so a previously fetched ref refreshes offline.
+With --verify, refresh fails unless the fetched files match the tree hash
+recorded in preset.lock.
+
 Examples:
   # Refresh from local directory
   strand preset refresh /path/to/my-preset
@@ -45,33 +70,243 @@ Examples:
   strand preset refresh https://github.com/user/strand-preset.git
 
   # Refresh using SSH (requires configured keys)
-  strand preset refresh git@github.com:user/strand-preset.git`,
-	Args: cobra.ExactArgs(1),
+  strand preset refresh git@github.com:user/strand-preset.git
+
+  # Pin a tag and a subdirectory, and check it still matches preset.lock
+  strand preset refresh https://github.com/user/presets.git@v1.2#web --verify
+
+  # Layer a team preset over a company-wide one
+  strand preset refresh https://example.com/company.git@v3 https://example.com/team.git
+
+  # Show what would change without writing anything
+  strand preset refresh --dry-run`,
+	Args: cobra.ArbitraryArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runPresetRefresh(cmd.OutOrStdout(), cmd.ErrOrStderr(), args, presetRefreshOpts)
+	},
+}
+
+var presetListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Show which preset layer each role and template came from",
+	Args:  cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		preset := args[0]
-		return runPresetRefresh(cmd.OutOrStdout(), preset)
+		return runPresetList(cmd.OutOrStdout())
 	},
 }
 
+type presetRefreshOptions struct {
+	DryRun bool
+	Verify bool
+}
+
+var presetRefreshOpts presetRefreshOptions
+
 func init() {
 	rootCmd.AddCommand(presetCmd)
 	presetCmd.AddCommand(refreshCmd)
+	presetCmd.AddCommand(presetListCmd)
+	refreshCmd.Flags().BoolVar(&presetRefreshOpts.DryRun, "dry-run", false, "show a diff of the changes without writing them")
+	refreshCmd.Flags().BoolVar(&presetRefreshOpts.Verify, "verify", false, "fail unless the fetched presets match the tree hashes in preset.lock")
+}
+
+// presetSources picks the layers to refresh from: the arguments, else
+// preset.sources from config, else the layers recorded in preset.lock.
+func presetSources(baseDir string, args []string, lock *preset.Lock) ([]string, error) {
+	if len(args) > 0 {
+		return args, nil
+	}
+	cfg, err := loadConfig(baseDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(cfg.Preset.Sources) > 0 {
+		return cfg.Preset.Sources, nil
+	}
+	if lock != nil && len(lock.Layers) > 0 {
+		return lock.Sources(), nil
+	}
+	return nil, fmt.Errorf("preset path or URL cannot be empty: set preset.sources or refresh from a preset once to record it in %s", preset.LockFile)
 }
 
-func runPresetRefresh(w io.Writer, preset string) error {
+func runPresetRefresh(w, errW io.Writer, args []string, opts presetRefreshOptions) error {
 	paths, err := resolveProjectPaths(projectName)
 	if err != nil {
 		return fmt.Errorf("project not initialized: %w", err)
 	}
 
-	fmt.Fprintf(w, "Refreshing roles and templates from preset %q...\n", preset)
+	lock, err := preset.ReadLock(paths.BaseDir)
+	if err != nil {
+		return err
+	}
+	presetSources, err := presetSources(paths.BaseDir, args, lock)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "Refreshing roles and templates from preset %q...\n", strings.Join(presetSources, `", "`))
 	fmt.Fprintf(w, "Target project: %s (base: %s, storage: %s)\n", paths.ProjectName, paths.BaseDir, paths.Storage)
 
-	if err := applyPreset(w, paths.BaseDir, preset, []string{"roles", "templates"}); err != nil {
+	cacheDir, err := presetCacheDir()
+	if err != nil {
+		return err
+	}
+	var sources []*preset.Source
+	defer func() {
+		for _, src := range sources {
+			src.Close()
+		}
+	}()
+	for i, presetSource := range presetSources {
+		src, err := preset.Fetch(w, presetSource, cacheDir)
+		if err != nil {
+			return err
+		}
+		sources = append(sources, src)
+		// The lowest layer is a complete preset; layers above it may
+		// override only roles or only templates.
+		if i == 0 {
+			err = src.Validate(w, preset.LockedDirs)
+		} else {
+			err = src.ValidateOverlay(w, preset.LockedDirs)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	plan, err := preset.PlanRefresh(paths.BaseDir, sources, lock, preset.LockedDirs)
+	if err != nil {
+		return err
+	}
+	if opts.Verify {
+		if lock == nil {
+			return fmt.Errorf("cannot verify: project has no %s", preset.LockFile)
+		}
+		if err := lock.Verify(plan.Lock); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "✓ Verified preset tree hash %s\n", plan.Lock.TreeHash)
+	}
+	for _, name := range preset.LockedDirs {
+		fmt.Fprintf(w, "Refreshing %s/...\n", name)
+		for _, c := range plan.Changes {
+			if !strings.HasPrefix(c.Path, name+"/") || c.Action == preset.ActionUnchanged {
+				continue
+			}
+			line := fmt.Sprintf("  %-9s %s", c.Action, c.Path)
+			if c.Note != "" {
+				line += " (" + c.Note + ")"
+			}
+			fmt.Fprintln(w, line)
+			if opts.DryRun {
+				diff, err := c.Diff()
+				if err != nil {
+					return err
+				}
+				fmt.Fprint(w, diff)
+			}
+		}
+	}
+
+	conflicts := plan.Conflicts()
+	if opts.DryRun {
+		previewDir, err := os.MkdirTemp("", "strand-preset-preview-")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(previewDir)
+		if err := plan.Preview(paths.BaseDir, previewDir, preset.LockedDirs); err != nil {
+			return err
+		}
+		reportMergedWorkflow(w, errW, filepath.Join(previewDir, "roles"), filepath.Join(previewDir, "templates"))
+		fmt.Fprintf(w, "\nDry run: no files written (%d conflict(s) would need resolving).\n", len(conflicts))
+		return nil
+	}
+	if err := plan.Apply(paths.BaseDir); err != nil {
 		return err
 	}
+	reportMergedWorkflow(w, errW, paths.RolesDir, paths.TemplatesDir)
 
 	fmt.Fprintln(w, "✓ Refresh complete. Running repair...")
 
-	return runRepair(w, paths.TasksDir, paths.RootTasksFile, paths.FreeTasksFile, "text")
+	if err := runRepair(w, paths.TasksDir, paths.RootTasksFile, paths.FreeTasksFile, "text"); err != nil {
+		return err
+	}
+	if len(conflicts) > 0 {
+		names := make([]string, 0, len(conflicts))
+		for _, c := range conflicts {
+			names = append(names, c.Path)
+		}
+		return fmt.Errorf("%d file(s) have conflict markers to resolve: %s", len(conflicts), strings.Join(names, ", "))
+	}
+	return nil
+}
+
+// reportMergedWorkflow validates the workflow of the merged roles and
+// templates. Problems are written to errW but do not fail the refresh.
+func reportMergedWorkflow(w, errW io.Writer, rolesDir, templatesDir string) {
+	fmt.Fprintln(w, "Validating merged workflow...")
+	graph, err := loadWorkflowGraph(rolesDir, templatesDir)
+	if err != nil {
+		fmt.Fprintf(errW, "⚠️  Could not load merged workflow: %v\n", err)
+		return
+	}
+	if err := runWorkflowValidation(graph, w, errW); err != nil {
+		fmt.Fprintln(errW, "⚠️  Merged workflow has errors; fix them and run strand workflow --validate")
+	}
+}
+
+func runPresetList(w io.Writer) error {
+	paths, err := resolveProjectPaths(projectName)
+	if err != nil {
+		return err
+	}
+	lock, err := preset.ReadLock(paths.BaseDir)
+	if err != nil {
+		return err
+	}
+	origins, err := preset.Origins(paths.BaseDir, lock, preset.LockedDirs)
+	if err != nil {
+		return err
+	}
+
+	layerNumber := make(map[string]int)
+	if lock == nil || len(lock.Layers) == 0 {
+		fmt.Fprintln(w, "No presets applied.")
+	} else {
+		fmt.Fprintln(w, "Preset layers (lowest precedence first):")
+		for i, layer := range lock.Layers {
+			layerNumber[layer.Source] = i + 1
+			line := fmt.Sprintf("  %d. %s", i+1, layer.Source)
+			if layer.Commit != "" {
+				line += " (" + task.ShortCommit(layer.Commit) + ")"
+			}
+			fmt.Fprintln(w, line)
+		}
+	}
+	fmt.Fprintln(w)
+
+	width := 0
+	for _, origin := range origins {
+		width = max(width, len(origin.Path))
+	}
+	for _, origin := range origins {
+		from := "local"
+		if len(origin.Layers) > 0 {
+			labels := make([]string, 0, len(origin.Layers))
+			for _, source := range origin.Layers {
+				labels = append(labels, fmt.Sprintf("%d", layerNumber[source]))
+			}
+			from = "layer " + strings.Join(labels, "+")
+			if len(labels) > 1 {
+				from = "layers " + strings.Join(labels, "+") + " (frontmatter merged)"
+			}
+			if origin.Edited {
+				from += ", edited locally"
+			}
+		}
+		fmt.Fprintf(w, "%-*s  %s\n", width, origin.Path, from)
+	}
+	return nil
 }
@@ -50,10 +50,14 @@ func TestPresetRefresh(t *testing.T) {
 	os.WriteFile(filepath.Join(presetDir, "tasks", "new-task.md"), []byte("new task"), 0o644)
 
 	// Refresh from preset
-	var buf bytes.Buffer
-	if err := runPresetRefresh(&buf, presetDir); err != nil {
+	var buf, errBuf bytes.Buffer
+	if err := runPresetRefresh(&buf, &errBuf, []string{presetDir}, presetRefreshOptions{}); err != nil {
 		t.Fatalf("runPresetRefresh failed: %v", err)
 	}
+	// The templates have no frontmatter, so the merged workflow cannot load.
+	if !strings.Contains(errBuf.String(), "Could not load merged workflow") || strings.Contains(buf.String(), "Could not load merged workflow") {
+		t.Errorf("workflow problems should go to stderr\nstdout:\n%s\nstderr:\n%s", buf.String(), errBuf.String())
+	}
 
 	// Verify roles and templates updated
 	content, _ = os.ReadFile(filepath.Join(paths.RolesDir, "dev.md"))
@@ -131,7 +135,7 @@ func TestPresetRefreshGit(t *testing.T) {
 	// Actually os.Stat will say it's a directory. But if we pass it as a file:// URL or just the path, applyPreset handles it.)
 
 	var buf bytes.Buffer
-	if err := runPresetRefresh(&buf, presetDir); err != nil {
+	if err := runPresetRefresh(&buf, &buf, []string{presetDir}, presetRefreshOptions{}); err != nil {
 		t.Fatalf("runPresetRefresh failed: %v", err)
 	}
 
@@ -146,7 +150,7 @@ func TestPresetRefreshNotInitialized(t *testing.T) {
 	_, _ = setupTestEnv(t)
 	// Do NOT runInit
 
-	if err := runPresetRefresh(io.Discard, "some-preset"); err == nil {
+	if err := runPresetRefresh(io.Discard, io.Discard, []string{"some-preset"}, presetRefreshOptions{}); err == nil {
 		t.Error("expected error when refreshing in uninitialized project, got nil")
 	}
 }
@@ -171,7 +175,7 @@ func TestPresetRefreshMissingDirectory(t *testing.T) {
 
 	// Try to refresh from incomplete preset
 	var buf bytes.Buffer
-	err := runPresetRefresh(&buf, presetDir)
+	err := runPresetRefresh(&buf, &buf, []string{presetDir}, presetRefreshOptions{})
 	if err == nil {
 		t.Fatal("expected error when preset is missing templates directory, got nil")
 	}
@@ -196,7 +200,7 @@ func TestPresetRefreshInvalidGitURL(t *testing.T) {
 
 	// Try to refresh from invalid git URL
 	var buf bytes.Buffer
-	err := runPresetRefresh(&buf, "https://github.com/nonexistent/repo-that-does-not-exist-12345.git")
+	err := runPresetRefresh(&buf, &buf, []string{"https://github.com/nonexistent/repo-that-does-not-exist-12345.git"}, presetRefreshOptions{})
 	if err == nil {
 		t.Fatal("expected error when cloning invalid git URL, got nil")
 	}
@@ -218,7 +222,7 @@ func TestPresetRefreshEmptyPath(t *testing.T) {
 
 	// Try to refresh with empty preset path
 	var buf bytes.Buffer
-	err := runPresetRefresh(&buf, "")
+	err := runPresetRefresh(&buf, &buf, []string{""}, presetRefreshOptions{})
 	if err == nil {
 		t.Fatal("expected error when preset path is empty, got nil")
 	}
@@ -251,7 +255,7 @@ func TestPresetRefreshVerboseOutput(t *testing.T) {
 
 	// Refresh and capture output
 	var buf bytes.Buffer
-	if err := runPresetRefresh(&buf, presetDir); err != nil {
+	if err := runPresetRefresh(&buf, &buf, []string{presetDir}, presetRefreshOptions{}); err != nil {
 		t.Fatalf("runPresetRefresh failed: %v", err)
 	}
 
@@ -272,3 +276,178 @@ func TestPresetRefreshVerboseOutput(t *testing.T) {
 		}
 	}
 }
+
+func TestPresetRefreshKeepsLocalEditsAndDryRun(t *testing.T) {
+	_, _ = setupTestEnv(t)
+
+	presetDir := t.TempDir()
+	for _, d := range []string{"tasks", "roles", "templates"} {
+		if err := os.Mkdir(filepath.Join(presetDir, d), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	os.WriteFile(filepath.Join(presetDir, "roles", "dev.md"), []byte("dev role\n"), 0o644)
+	os.WriteFile(filepath.Join(presetDir, "templates", "task.md"), []byte("task template\n"), 0o644)
+
+	if err := runInit(io.Discard, initOptions{StorageMode: storageLocal, Preset: presetDir}); err != nil {
+		t.Fatalf("runInit failed: %v", err)
+	}
+	paths, err := resolveProjectPaths("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(paths.BaseDir, "preset.lock")); err != nil {
+		t.Fatalf("init should write preset.lock: %v", err)
+	}
+
+	// Customize the role locally; update only the template in the preset.
+	os.WriteFile(filepath.Join(paths.RolesDir, "dev.md"), []byte("my dev role\n"), 0o644)
+	os.WriteFile(filepath.Join(presetDir, "templates", "task.md"), []byte("updated task template\n"), 0o644)
+
+	var buf bytes.Buffer
+	if err := runPresetRefresh(&buf, &buf, nil, presetRefreshOptions{DryRun: true}); err != nil {
+		t.Fatalf("dry run failed: %v", err)
+	}
+	for _, want := range []string{"keep      roles/dev.md (local edits kept)", "update    templates/task.md", "+updated task template", "Dry run"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("dry run output missing %q:\n%s", want, buf.String())
+		}
+	}
+	content, _ := os.ReadFile(filepath.Join(paths.TemplatesDir, "task.md"))
+	if string(content) != "task template\n" {
+		t.Errorf("dry run wrote template: %q", content)
+	}
+
+	if err := runPresetRefresh(io.Discard, io.Discard, nil, presetRefreshOptions{}); err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+	content, _ = os.ReadFile(filepath.Join(paths.RolesDir, "dev.md"))
+	if string(content) != "my dev role\n" {
+		t.Errorf("local role edit lost: %q", content)
+	}
+	content, _ = os.ReadFile(filepath.Join(paths.TemplatesDir, "task.md"))
+	if string(content) != "updated task template\n" {
+		t.Errorf("template not updated: %q", content)
+	}
+}
+
+func TestPresetPinnedRefVerify(t *testing.T) {
+	_, _ = setupTestEnv(t)
+
+	run := func(dir string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=Test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	work := t.TempDir()
+	run(work, "init", "-q")
+	for _, d := range []string{"preset/tasks", "preset/roles", "preset/templates"} {
+		if err := os.MkdirAll(filepath.Join(work, d), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	os.WriteFile(filepath.Join(work, "preset", "tasks", ".keep"), nil, 0o644)
+	os.WriteFile(filepath.Join(work, "preset", "roles", "dev.md"), []byte("dev v1\n"), 0o644)
+	os.WriteFile(filepath.Join(work, "preset", "templates", "task.md"), []byte("task v1\n"), 0o644)
+	run(work, "add", "-A")
+	run(work, "commit", "-q", "-m", "v1")
+	run(work, "tag", "v1")
+	bare := filepath.Join(t.TempDir(), "preset.git")
+	run(work, "clone", "-q", "--bare", work, bare)
+
+	spec := bare + "@v1#preset"
+	if err := runInit(io.Discard, initOptions{StorageMode: storageLocal, Preset: spec}); err != nil {
+		t.Fatalf("runInit failed: %v", err)
+	}
+	paths, err := resolveProjectPaths("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lockData, _ := os.ReadFile(filepath.Join(paths.BaseDir, "preset.lock"))
+	if !strings.Contains(string(lockData), "source: "+spec) || !strings.Contains(string(lockData), "tree_hash: sha256:") {
+		t.Fatalf("preset.lock does not pin the preset:\n%s", lockData)
+	}
+
+	if err := runPresetRefresh(io.Discard, io.Discard, nil, presetRefreshOptions{Verify: true}); err != nil {
+		t.Fatalf("verify against unchanged tag: %v", err)
+	}
+
+	// Move the tag to different content.
+	os.WriteFile(filepath.Join(work, "preset", "roles", "dev.md"), []byte("dev tampered\n"), 0o644)
+	run(work, "commit", "-q", "-am", "tampered")
+	run(work, "tag", "-f", "v1")
+	run(work, "push", "-q", "--force", bare, "refs/tags/v1")
+
+	err = runPresetRefresh(io.Discard, io.Discard, nil, presetRefreshOptions{Verify: true})
+	if err == nil || !strings.Contains(err.Error(), "integrity check failed") {
+		t.Fatalf("expected integrity failure, got %v", err)
+	}
+	content, _ := os.ReadFile(filepath.Join(paths.RolesDir, "dev.md"))
+	if string(content) != "dev v1\n" {
+		t.Errorf("failed verification should not write files, got %q", content)
+	}
+}
+
+func TestPresetLayersFromConfig(t *testing.T) {
+	_, _ = setupTestEnv(t)
+
+	company, team := t.TempDir(), t.TempDir()
+	for _, d := range []string{"tasks", "roles", "templates"} {
+		if err := os.Mkdir(filepath.Join(company, d), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	os.WriteFile(filepath.Join(company, "roles", "developer.md"), []byte("---\ndescription: Company developer\n---\n# Developer\n"), 0o644)
+	os.WriteFile(filepath.Join(company, "roles", "reviewer.md"), []byte("# Reviewer\n"), 0o644)
+	os.WriteFile(filepath.Join(company, "templates", "task.md"), []byte("---\nrole: developer\n---\n# {{ .Title }}\n"), 0o644)
+	if err := os.Mkdir(filepath.Join(team, "roles"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(team, "roles", "developer.md"), []byte("---\ndescription: Web developer\n---\n"), 0o644)
+
+	if err := runInit(io.Discard, initOptions{StorageMode: storageLocal}); err != nil {
+		t.Fatalf("runInit failed: %v", err)
+	}
+	paths, err := resolveProjectPaths("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(paths.RolesDir, "mine.md"), []byte("# Mine\n"), 0o644)
+	cfg := "preset:\n  sources:\n    - " + company + "\n    - " + team + "\n"
+	if err := os.WriteFile(filepath.Join(paths.BaseDir, "strand.yaml"), []byte(cfg), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := runPresetRefresh(&buf, &buf, nil, presetRefreshOptions{}); err != nil {
+		t.Fatalf("layered refresh failed: %v\n%s", err, buf.String())
+	}
+	if !strings.Contains(buf.String(), "Validating merged workflow") {
+		t.Errorf("refresh should validate the merged workflow:\n%s", buf.String())
+	}
+	content, _ := os.ReadFile(filepath.Join(paths.RolesDir, "developer.md"))
+	if string(content) != "---\ndescription: Web developer\n---\n# Developer\n" {
+		t.Errorf("developer.md = %q", content)
+	}
+
+	os.WriteFile(filepath.Join(paths.RolesDir, "reviewer.md"), []byte("# Our reviewer\n"), 0o644)
+	var list bytes.Buffer
+	if err := runPresetList(&list); err != nil {
+		t.Fatalf("preset list: %v", err)
+	}
+	for _, want := range []string{
+		"1. " + company,
+		"2. " + team,
+		"roles/developer.md  layers 1+2 (frontmatter merged)",
+		"roles/mine.md       local",
+		"roles/reviewer.md   layer 1, edited locally",
+		"templates/task.md   layer 1",
+	} {
+		if !strings.Contains(list.String(), want) {
+			t.Errorf("preset list missing %q:\n%s", want, list.String())
+		}
+	}
+}
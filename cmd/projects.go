@@ -8,6 +8,9 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+
+	"github.com/ricochet1k/strandyard/pkg/config"
+	"github.com/ricochet1k/strandyard/pkg/task"
 )
 
 const (
@@ -43,7 +46,7 @@ func resolveProjectPaths(projectName string) (projectPaths, error) {
 	}
 
 	localDir := filepath.Join(gitRoot, ".strand")
-	if info, err := os.Stat(localDir); err == nil && info.IsDir() {
+	if info, err := os.Stat(localDir); err == nil && info.IsDir() && !isMigrated(localDir) {
 		return projectPathsFromBase(localDir, "", gitRoot, storageLocal)
 	}
 
@@ -58,6 +61,14 @@ func resolveProjectPaths(projectName string) (projectPaths, error) {
 	return projectPathsForName(project)
 }
 
+// remoteTasks resolves project:TaskID references through the project registry.
+func remoteTasks() *task.RemoteTasks {
+	return task.NewRemoteTasks(func(name string) (string, error) {
+		paths, err := projectPathsForName(name)
+		return paths.TasksDir, err
+	})
+}
+
 func projectPathsForName(projectName string) (projectPaths, error) {
 	// Check if this is a local project registered in projects.json
 	cfg, err := loadProjectMap()
@@ -66,7 +77,7 @@ func projectPathsForName(projectName string) (projectPaths, error) {
 	}
 	if gitRoot, ok := cfg.LocalPaths[projectName]; ok {
 		localDir := filepath.Join(gitRoot, ".strand")
-		if info, err := os.Stat(localDir); err == nil && info.IsDir() {
+		if info, err := os.Stat(localDir); err == nil && info.IsDir() && !isMigrated(localDir) {
 			return projectPathsFromBase(localDir, projectName, gitRoot, storageLocal)
 		}
 	}
@@ -91,6 +102,9 @@ func projectPathsForName(projectName string) (projectPaths, error) {
 	if !info.IsDir() {
 		return projectPaths{}, fmt.Errorf("project path %s is not a directory", base)
 	}
+	if isMigrated(base) {
+		return projectPaths{}, fmt.Errorf("project %q was migrated away from %s (see %s there)", projectName, base, migratedFile)
+	}
 	return projectPathsFromBase(base, projectName, "", storageGlobal)
 }
 
@@ -188,6 +202,9 @@ func projectPathsForLocalCandidate(candidate, projectName string) (projectPaths,
 }
 
 func hasStrandLayout(baseDir string) bool {
+	if isMigrated(baseDir) {
+		return false
+	}
 	for _, name := range []string{"tasks", "roles", "templates"} {
 		info, err := os.Stat(filepath.Join(baseDir, name))
 		if err != nil || !info.IsDir() {
@@ -231,14 +248,7 @@ func gitRootDir() (string, error) {
 }
 
 func configDir() (string, error) {
-	if dir := os.Getenv("STRAND_CONFIG_DIR"); dir != "" {
-		return dir, nil
-	}
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("unable to resolve home directory: %w", err)
-	}
-	return filepath.Join(home, ".config", "strand"), nil
+	return config.UserDir()
 }
 
 func projectsDir() (string, error) {
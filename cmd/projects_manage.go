@@ -0,0 +1,471 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var projectsCmd = &cobra.Command{
+	Use:   "projects",
+	Short: "List and manage registered projects",
+	Long: `List and manage the projects recorded in projects.json.
+
+Global projects live in ~/.config/strand/projects/<name> and are linked from
+one or more git roots; local projects live in .strand/ at their git root. An
+entry is stale when its git root or storage no longer exists.`,
+}
+
+var projectsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List projects and flag stale entries",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runProjectsList(cmd.OutOrStdout(), projectsFormat)
+	},
+}
+
+var projectsShowCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Show a project's storage, links and contents",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runProjectsShow(cmd.OutOrStdout(), args[0], projectsFormat)
+	},
+}
+
+var projectsRenameCmd = &cobra.Command{
+	Use:   "rename <name> <new-name>",
+	Short: "Rename a project",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runProjectsRename(cmd.OutOrStdout(), args[0], args[1])
+	},
+}
+
+var projectsRelinkCmd = &cobra.Command{
+	Use:   "relink <name> <git-root>",
+	Short: "Point a project at a repository that moved",
+	Long: `Point a project at a repository that moved.
+
+A local project is relinked to the new git root, which must contain its
+.strand/ directory. A global project linked from one repository has that link
+replaced; one linked from several needs --from to say which link to replace.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runProjectsRelink(cmd.OutOrStdout(), args[0], args[1], projectsRelinkFrom)
+	},
+}
+
+var projectsRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Unregister a project, optionally deleting its storage",
+	Long: `Remove a project's entries from projects.json.
+
+With --delete-storage the project's tasks, roles and templates are deleted
+too, after confirmation: type the project name, or pass --yes. Local storage
+that git still tracks is never deleted; remove it with git rm instead.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runProjectsRemove(cmd.OutOrStdout(), cmd.InOrStdin(), args[0], projectsRemoveOpts)
+	},
+}
+
+type projectsRemoveOptions struct {
+	DeleteStorage bool
+	Yes           bool
+}
+
+var (
+	projectsFormat     string
+	projectsRelinkFrom string
+	projectsRemoveOpts projectsRemoveOptions
+)
+
+func init() {
+	rootCmd.AddCommand(projectsCmd)
+	projectsCmd.AddCommand(projectsListCmd, projectsShowCmd, projectsRenameCmd, projectsRelinkCmd, projectsRemoveCmd)
+	for _, c := range []*cobra.Command{projectsListCmd, projectsShowCmd} {
+		c.Flags().StringVar(&projectsFormat, "format", "table", "output format: table|json")
+	}
+	projectsRelinkCmd.Flags().StringVar(&projectsRelinkFrom, "from", "", "git root of the link to replace, for projects linked from several repositories")
+	projectsRemoveCmd.Flags().BoolVar(&projectsRemoveOpts.DeleteStorage, "delete-storage", false, "also delete the project's storage directory")
+	projectsRemoveCmd.Flags().BoolVar(&projectsRemoveOpts.Yes, "yes", false, "delete storage without asking")
+}
+
+// projectEntry is a project as recorded in projects.json or found in the
+// global projects directory.
+type projectEntry struct {
+	Name        string   `json:"name"`
+	Storage     string   `json:"storage"`
+	StorageRoot string   `json:"storage_root"`
+	GitRoots    []string `json:"git_roots"`
+	Stale       bool     `json:"stale"`
+	Problems    []string `json:"problems,omitempty"`
+	Tasks       int      `json:"tasks"`
+	Roles       int      `json:"roles"`
+	Templates   int      `json:"templates"`
+}
+
+// loadProjectEntries lists every registered project, plus global project
+// directories no repository links to, sorted by name.
+func loadProjectEntries() ([]projectEntry, error) {
+	cfg, err := loadProjectMap()
+	if err != nil {
+		return nil, err
+	}
+	globalDir, err := projectsDir()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make(map[string]*projectEntry)
+	global := func(name string) *projectEntry {
+		if e, ok := entries[name]; ok {
+			return e
+		}
+		e := &projectEntry{Name: name, Storage: storageGlobal, StorageRoot: filepath.Join(globalDir, name), GitRoots: []string{}}
+		entries[name] = e
+		return e
+	}
+	for gitRoot, name := range cfg.Repos {
+		e := global(name)
+		e.GitRoots = append(e.GitRoots, gitRoot)
+	}
+	for name, gitRoot := range cfg.LocalPaths {
+		if _, ok := entries[name]; ok {
+			// A name registered both ways resolves to the local project.
+			delete(entries, name)
+		}
+		entries[name] = &projectEntry{Name: name, Storage: storageLocal, StorageRoot: filepath.Join(gitRoot, ".strand"), GitRoots: []string{gitRoot}}
+	}
+	if dirs, err := os.ReadDir(globalDir); err == nil {
+		for _, d := range dirs {
+			if d.IsDir() && hasStrandLayout(filepath.Join(globalDir, d.Name())) {
+				if _, ok := entries[d.Name()]; !ok {
+					global(d.Name())
+				}
+			}
+		}
+	}
+
+	list := make([]projectEntry, 0, len(entries))
+	for _, e := range entries {
+		sort.Strings(e.GitRoots)
+		e.check()
+		list = append(list, *e)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
+
+// check records stale links and counts the project's files.
+func (e *projectEntry) check() {
+	if len(e.GitRoots) == 0 {
+		e.Problems = append(e.Problems, "not linked to any git repository")
+	}
+	for _, root := range e.GitRoots {
+		if info, err := os.Stat(root); err != nil || !info.IsDir() {
+			e.Stale = true
+			e.Problems = append(e.Problems, fmt.Sprintf("git root %s no longer exists", root))
+		}
+	}
+	if isMigrated(e.StorageRoot) {
+		e.Stale = true
+		e.Problems = append(e.Problems, fmt.Sprintf("storage %s was migrated away (see %s)", e.StorageRoot, migratedFile))
+	} else if !hasStrandLayout(e.StorageRoot) {
+		e.Stale = true
+		e.Problems = append(e.Problems, fmt.Sprintf("storage %s is missing", e.StorageRoot))
+		return
+	}
+	e.Tasks = countMarkdown(filepath.Join(e.StorageRoot, "tasks"), "root-tasks.md", "free-tasks.md")
+	e.Roles = countMarkdown(filepath.Join(e.StorageRoot, "roles"))
+	e.Templates = countMarkdown(filepath.Join(e.StorageRoot, "templates"))
+}
+
+func (e projectEntry) status() string {
+	switch {
+	case e.Stale:
+		return "stale: " + strings.Join(e.Problems, "; ")
+	case len(e.Problems) > 0:
+		return strings.Join(e.Problems, "; ")
+	default:
+		return "ok"
+	}
+}
+
+func countMarkdown(dir string, skip ...string) int {
+	count := 0
+	_ = filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(d.Name(), ".md") {
+			return nil
+		}
+		for _, name := range skip {
+			if d.Name() == name {
+				return nil
+			}
+		}
+		count++
+		return nil
+	})
+	return count
+}
+
+func findProjectEntry(name string) (projectEntry, error) {
+	entries, err := loadProjectEntries()
+	if err != nil {
+		return projectEntry{}, err
+	}
+	for _, e := range entries {
+		if e.Name == name {
+			return e, nil
+		}
+	}
+	return projectEntry{}, fmt.Errorf("project %q not found", name)
+}
+
+func runProjectsList(w io.Writer, format string) error {
+	entries, err := loadProjectEntries()
+	if err != nil {
+		return err
+	}
+	switch format {
+	case "json":
+		b, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(b))
+	case "table":
+		if len(entries) == 0 {
+			fmt.Fprintln(w, "No projects")
+			return nil
+		}
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "NAME\tSTORAGE\tGIT ROOT\tTASKS\tSTATUS")
+		for _, e := range entries {
+			roots := strings.Join(e.GitRoots, ", ")
+			if roots == "" {
+				roots = "-"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\n", e.Name, e.Storage, roots, e.Tasks, e.status())
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("invalid format %q (expected table or json)", format)
+	}
+	return nil
+}
+
+func runProjectsShow(w io.Writer, name, format string) error {
+	e, err := findProjectEntry(name)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case "json":
+		b, err := json.MarshalIndent(e, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(b))
+	case "table":
+		fmt.Fprintf(w, "Project:   %s\n", e.Name)
+		fmt.Fprintf(w, "Storage:   %s (%s)\n", e.Storage, e.StorageRoot)
+		if len(e.GitRoots) == 0 {
+			fmt.Fprintln(w, "Git roots: none")
+		} else {
+			fmt.Fprintf(w, "Git roots: %s\n", strings.Join(e.GitRoots, "\n           "))
+		}
+		fmt.Fprintf(w, "Contents:  %d tasks, %d roles, %d templates\n", e.Tasks, e.Roles, e.Templates)
+		fmt.Fprintf(w, "Status:    %s\n", e.status())
+	default:
+		return fmt.Errorf("invalid format %q (expected table or json)", format)
+	}
+	return nil
+}
+
+func validateProjectName(name string) error {
+	if strings.TrimSpace(name) == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid project name %q", name)
+	}
+	return nil
+}
+
+func runProjectsRename(w io.Writer, oldName, newName string) error {
+	if err := validateProjectName(newName); err != nil {
+		return err
+	}
+	e, err := findProjectEntry(oldName)
+	if err != nil {
+		return err
+	}
+	if _, err := findProjectEntry(newName); err == nil {
+		return fmt.Errorf("project %q already exists", newName)
+	}
+	cfg, err := loadProjectMap()
+	if err != nil {
+		return err
+	}
+
+	if e.Storage == storageGlobal {
+		newRoot := filepath.Join(filepath.Dir(e.StorageRoot), newName)
+		if _, err := os.Stat(newRoot); err == nil {
+			return fmt.Errorf("%s already exists", newRoot)
+		}
+		if _, err := os.Stat(e.StorageRoot); err == nil {
+			if err := os.Rename(e.StorageRoot, newRoot); err != nil {
+				return fmt.Errorf("failed to move storage: %w", err)
+			}
+			fmt.Fprintf(w, "✓ Moved %s to %s\n", e.StorageRoot, newRoot)
+		}
+		for root, name := range cfg.Repos {
+			if name == oldName {
+				cfg.Repos[root] = newName
+			}
+		}
+	} else {
+		cfg.LocalPaths[newName] = cfg.LocalPaths[oldName]
+		delete(cfg.LocalPaths, oldName)
+	}
+	if err := saveProjectMap(cfg); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "✓ Renamed project %s to %s\n", oldName, newName)
+	return nil
+}
+
+func runProjectsRelink(w io.Writer, name, gitRoot, from string) error {
+	e, err := findProjectEntry(name)
+	if err != nil {
+		return err
+	}
+	abs, err := filepath.Abs(gitRoot)
+	if err != nil {
+		return err
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		abs = resolved
+	}
+	if info, err := os.Stat(abs); err != nil || !info.IsDir() {
+		return fmt.Errorf("git root %s does not exist", abs)
+	}
+	cfg, err := loadProjectMap()
+	if err != nil {
+		return err
+	}
+
+	if e.Storage == storageLocal {
+		if !hasStrandLayout(filepath.Join(abs, ".strand")) {
+			return fmt.Errorf("%s has no .strand project to link to", abs)
+		}
+		cfg.LocalPaths[name] = abs
+	} else {
+		if other, ok := cfg.Repos[abs]; ok && other != name {
+			return fmt.Errorf("%s is already linked to project %s", abs, other)
+		}
+		switch {
+		case from != "":
+			if cfg.Repos[from] != name {
+				return fmt.Errorf("project %s is not linked from %s", name, from)
+			}
+			delete(cfg.Repos, from)
+		case len(e.GitRoots) > 1:
+			return fmt.Errorf("project %s is linked from %d repositories; pass --from with the one to replace", name, len(e.GitRoots))
+		case len(e.GitRoots) == 1:
+			delete(cfg.Repos, e.GitRoots[0])
+		}
+		cfg.Repos[abs] = name
+	}
+	if err := saveProjectMap(cfg); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "✓ Linked project %s to %s\n", name, abs)
+	return nil
+}
+
+func runProjectsRemove(w io.Writer, in io.Reader, name string, opts projectsRemoveOptions) error {
+	e, err := findProjectEntry(name)
+	if err != nil {
+		return err
+	}
+
+	if opts.DeleteStorage {
+		if err := checkStorageDeletable(e); err != nil {
+			return err
+		}
+		if !opts.Yes {
+			fmt.Fprintf(w, "This deletes %s (%d tasks, %d roles, %d templates).\nType the project name to confirm: ", e.StorageRoot, e.Tasks, e.Roles, e.Templates)
+			answer, _ := bufio.NewReader(in).ReadString('\n')
+			fmt.Fprintln(w)
+			if strings.TrimSpace(answer) != name {
+				return fmt.Errorf("confirmation did not match; nothing was removed")
+			}
+		}
+	}
+
+	cfg, err := loadProjectMap()
+	if err != nil {
+		return err
+	}
+	for root, project := range cfg.Repos {
+		if project == name {
+			delete(cfg.Repos, root)
+		}
+	}
+	delete(cfg.LocalPaths, name)
+	if err := saveProjectMap(cfg); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "✓ Removed project %s from projects.json\n", name)
+
+	if opts.DeleteStorage {
+		if _, err := os.Stat(e.StorageRoot); err == nil {
+			if err := os.RemoveAll(e.StorageRoot); err != nil {
+				return fmt.Errorf("failed to delete storage: %w", err)
+			}
+			fmt.Fprintf(w, "✓ Deleted %s\n", e.StorageRoot)
+		}
+	} else if e.Storage == storageGlobal && hasStrandLayout(e.StorageRoot) {
+		fmt.Fprintf(w, "Storage kept at %s (use --delete-storage to delete it)\n", e.StorageRoot)
+	}
+	return nil
+}
+
+// checkStorageDeletable refuses to delete anything that is not plainly a
+// strand project directory, and local storage that git still tracks.
+func checkStorageDeletable(e projectEntry) error {
+	if _, err := os.Stat(e.StorageRoot); os.IsNotExist(err) {
+		return nil
+	}
+	switch e.Storage {
+	case storageGlobal:
+		globalDir, err := projectsDir()
+		if err != nil {
+			return err
+		}
+		if filepath.Dir(e.StorageRoot) != filepath.Clean(globalDir) {
+			return fmt.Errorf("refusing to delete %s: it is not inside %s", e.StorageRoot, globalDir)
+		}
+	case storageLocal:
+		if filepath.Base(e.StorageRoot) != ".strand" {
+			return fmt.Errorf("refusing to delete %s: it is not a .strand directory", e.StorageRoot)
+		}
+		gitRoot := filepath.Dir(e.StorageRoot)
+		if out, err := gitOutput(gitRoot, "ls-files", "--", ".strand"); err == nil && strings.TrimSpace(out) != "" {
+			return fmt.Errorf("refusing to delete %s: git tracks files in it (run git rm -r .strand instead)", e.StorageRoot)
+		}
+	}
+	if !hasStrandLayout(e.StorageRoot) && !isMigrated(e.StorageRoot) {
+		return fmt.Errorf("refusing to delete %s: it does not look like strand storage", e.StorageRoot)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProjectsListFlagsStaleEntries(t *testing.T) {
+	paths := setupTestProject(t, initOptions{StorageMode: storageGlobal})
+	writeClaimTaskFile(t, paths.TasksDir, "T1abc-listed", "developer")
+
+	cfg, err := loadProjectMap()
+	if err != nil {
+		t.Fatal(err)
+	}
+	gone := filepath.Join(t.TempDir(), "moved-away")
+	cfg.Repos[gone] = "orphan"
+	if err := saveProjectMap(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := runProjectsList(&out, "json"); err != nil {
+		t.Fatal(err)
+	}
+	var entries []projectEntry
+	if err := json.Unmarshal(out.Bytes(), &entries); err != nil {
+		t.Fatalf("invalid json: %v\n%s", err, out.String())
+	}
+	byName := map[string]projectEntry{}
+	for _, e := range entries {
+		byName[e.Name] = e
+	}
+	if e := byName[paths.ProjectName]; e.Stale || e.Tasks != 1 || e.Storage != storageGlobal {
+		t.Errorf("project entry = %+v, want healthy global project with 1 task", e)
+	}
+	orphan := byName["orphan"]
+	if !orphan.Stale || !strings.Contains(strings.Join(orphan.Problems, "\n"), "no longer exists") {
+		t.Errorf("orphan entry = %+v, want stale", orphan)
+	}
+
+	out.Reset()
+	if err := runProjectsList(&out, "table"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "stale:") {
+		t.Errorf("table should flag stale entries:\n%s", out.String())
+	}
+}
+
+func TestProjectsRenameAndRelinkGlobal(t *testing.T) {
+	paths := setupTestProject(t, initOptions{StorageMode: storageGlobal})
+	writeClaimTaskFile(t, paths.TasksDir, "T1abc-kept", "developer")
+
+	if err := runProjectsRename(io.Discard, paths.ProjectName, "renamed"); err != nil {
+		t.Fatal(err)
+	}
+	resolved, err := resolveProjectPaths("")
+	if err != nil || resolved.ProjectName != "renamed" {
+		t.Fatalf("resolved %+v, %v; want renamed project", resolved, err)
+	}
+	if _, err := os.Stat(filepath.Join(resolved.TasksDir, "T1abc-kept.md")); err != nil {
+		t.Errorf("task not moved with the project: %v", err)
+	}
+	if err := runProjectsRename(io.Discard, "renamed", "a/b"); err == nil {
+		t.Error("rename to a name with a slash should fail")
+	}
+
+	moved := t.TempDir()
+	if err := runProjectsRelink(io.Discard, "renamed", moved, ""); err != nil {
+		t.Fatal(err)
+	}
+	e, err := findProjectEntry("renamed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(e.GitRoots) != 1 || !samePath(e.GitRoots[0], moved) {
+		t.Errorf("git roots = %v, want [%s]", e.GitRoots, moved)
+	}
+	if err := runProjectsRelink(io.Discard, "renamed", filepath.Join(moved, "missing"), ""); err == nil {
+		t.Error("relink to a missing directory should fail")
+	}
+}
+
+func TestProjectsRemoveDeletesStorageAfterConfirmation(t *testing.T) {
+	paths := setupTestProject(t, initOptions{StorageMode: storageGlobal})
+	opts := projectsRemoveOptions{DeleteStorage: true}
+
+	var out bytes.Buffer
+	err := runProjectsRemove(&out, strings.NewReader("wrong\n"), paths.ProjectName, opts)
+	if err == nil || !strings.Contains(err.Error(), "did not match") {
+		t.Fatalf("expected confirmation error, got %v", err)
+	}
+	if _, err := os.Stat(paths.BaseDir); err != nil {
+		t.Fatalf("storage must survive a failed confirmation: %v", err)
+	}
+
+	if err := runProjectsRemove(&out, strings.NewReader(paths.ProjectName+"\n"), paths.ProjectName, opts); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(paths.BaseDir); !os.IsNotExist(err) {
+		t.Errorf("storage should be deleted, stat err = %v", err)
+	}
+	if _, err := findProjectEntry(paths.ProjectName); err == nil {
+		t.Error("project should no longer be listed")
+	}
+}
+
+func TestProjectsRemoveRefusesTrackedLocalStorage(t *testing.T) {
+	paths := setupTestProject(t, initOptions{StorageMode: storageLocal})
+	name := localProjectName(paths.GitRoot)
+	writeClaimTaskFile(t, paths.TasksDir, "T1abc-tracked", "developer")
+	if _, err := gitOutput(paths.GitRoot, "add", ".strand"); err != nil {
+		t.Fatal(err)
+	}
+
+	err := runProjectsRemove(io.Discard, strings.NewReader(""), name, projectsRemoveOptions{DeleteStorage: true, Yes: true})
+	if err == nil || !strings.Contains(err.Error(), "git rm") {
+		t.Fatalf("expected tracked-storage refusal, got %v", err)
+	}
+	if _, err := os.Stat(paths.BaseDir); err != nil {
+		t.Errorf("tracked storage must not be deleted: %v", err)
+	}
+
+	if err := runProjectsRemove(io.Discard, strings.NewReader(""), name, projectsRemoveOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := loadProjectMap()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cfg.LocalPaths[name]; ok {
+		t.Errorf("local entry should be removed: %v", cfg.LocalPaths)
+	}
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/strandyard/pkg/task"
+)
+
+func TestRepairResolvesCrossProjectBlockers(t *testing.T) {
+	frontend := setupTestProject(t, initOptions{StorageMode: storageGlobal, ProjectName: "frontend"})
+	frontendRepo, err := gitRootDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	backendRepo := initGitRepo(t)
+	chdir(t, backendRepo)
+	if err := runInit(io.Discard, initOptions{ProjectName: "backend"}); err != nil {
+		t.Fatal(err)
+	}
+	backend, err := resolveProjectPaths("backend")
+	if err != nil {
+		t.Fatal(err)
+	}
+	chdir(t, frontendRepo)
+
+	for _, paths := range []projectPaths{frontend, backend} {
+		if err := os.WriteFile(filepath.Join(paths.RolesDir, "developer.md"), []byte("# developer\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeClaimTaskFile(t, backend.TasksDir, "T1abc-auth-api", "developer")
+	writeRemoteBlockedTask(t, frontend.TasksDir, "T2xyz-login-ui", "backend:T1abc")
+	writeRemoteBlockedTask(t, frontend.TasksDir, "T3xyz-typo", "backend:T9zzz")
+
+	var out bytes.Buffer
+	if err := runRepair(&out, frontend.TasksDir, frontend.RootTasksFile, frontend.FreeTasksFile, "text"); err != nil {
+		t.Fatalf("repair: %v\n%s", err, out.String())
+	}
+	if !strings.Contains(out.String(), "WARNING: Task T3xyz-typo: blocker backend:T9zzz") {
+		t.Errorf("expected a broken reference warning:\n%s", out.String())
+	}
+	ui := loadTestTask(t, frontend.TasksDir, "T2xyz-login-ui")
+	if len(ui.Meta.Blockers) != 1 || ui.Meta.Blockers[0] != "backend:T1abc-auth-api" {
+		t.Errorf("blockers = %v, want canonical open remote blocker", ui.Meta.Blockers)
+	}
+	if free, _ := os.ReadFile(frontend.FreeTasksFile); strings.Contains(string(free), "T2xyz-login-ui") {
+		t.Errorf("task blocked by an open remote task should not be free:\n%s", free)
+	}
+
+	db := task.NewTaskDB(backend.TasksDir)
+	if err := db.LoadAll(); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SetCompleted("T1abc-auth-api", true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.SaveDirty(); err != nil {
+		t.Fatal(err)
+	}
+
+	// next sees the finished remote blocker without a repair in between.
+	out.Reset()
+	if err := runNextWithOptions(&out, "frontend", "", nextOptions{ClaimTimeout: time.Hour}); err != nil {
+		t.Fatalf("next: %v\n%s", err, out.String())
+	}
+	if !strings.Contains(out.String(), "Your task is T2xyz-login-ui") {
+		t.Errorf("next should pick the task whose remote blocker is done:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := runRepair(&out, frontend.TasksDir, frontend.RootTasksFile, frontend.FreeTasksFile, "text"); err != nil {
+		t.Fatalf("repair: %v\n%s", err, out.String())
+	}
+	if ui := loadTestTask(t, frontend.TasksDir, "T2xyz-login-ui"); len(ui.Meta.Blockers) != 0 {
+		t.Errorf("done remote blocker should be dropped, got %v", ui.Meta.Blockers)
+	}
+	free, _ := os.ReadFile(frontend.FreeTasksFile)
+	if !strings.Contains(string(free), "T2xyz-login-ui") {
+		t.Errorf("task should be free once the remote blocker is done:\n%s", free)
+	}
+	if strings.Contains(string(free), "T3xyz-typo") {
+		t.Errorf("broken remote blocker must keep the task blocked:\n%s", free)
+	}
+}
+
+func writeRemoteBlockedTask(t *testing.T, tasksDir, id, blocker string) {
+	t.Helper()
+	writeClaimTaskFile(t, tasksDir, id, "developer")
+	path := filepath.Join(tasksDir, id+".md")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = bytes.Replace(data, []byte("blockers: []"), []byte("blockers: [\""+blocker+"\"]"), 1)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func loadTestTask(t *testing.T, tasksDir, id string) *task.Task {
+	t.Helper()
+	db := task.NewTaskDB(tasksDir)
+	got, err := db.Get(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return got
+}
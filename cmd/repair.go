@@ -43,6 +43,11 @@ func runRepair(w io.Writer, tasksRoot, rootsFile, freeFile, outFormat string) er
 		return fmt.Errorf("failed to load tasks: %w", err)
 	}
 
+	// Drop cross-project blockers that are done before local reconciliation,
+	// and keep broken ones as warnings: the other project may simply not be
+	// checked out here.
+	_, remoteProblems := task.ReconcileRemoteReferences(db.GetAll(), remoteTasks())
+
 	if _, err := db.ReconcileBlockerRelationships(); err != nil {
 		return fmt.Errorf("failed to reconcile blocker relationships: %w", err)
 	}
@@ -51,13 +56,17 @@ func runRepair(w io.Writer, tasksRoot, rootsFile, freeFile, outFormat string) er
 		return fmt.Errorf("failed to update parent TODO entries: %w", err)
 	}
 
+	cfg, err := loadConfig(filepath.Dir(tasksRoot))
+	if err != nil {
+		return err
+	}
+
 	rolesDir := filepath.Join(filepath.Dir(tasksRoot), "roles")
-	validator := task.NewValidatorWithRoles(db.GetAll(), rolesDir)
+	validator := task.NewValidatorWithRoles(db.GetAll(), rolesDir).WithFields(cfg.Fields)
 	fixed := validator.FixMissingReferences()
 	validationErrors := validator.ValidateAndRepair()
 
 	var repairedCount int
-	var err error
 	if repairAll {
 		fmt.Printf("Writing all tasks...")
 		repairedCount, err = db.SaveAll()
@@ -78,6 +87,19 @@ func runRepair(w io.Writer, tasksRoot, rootsFile, freeFile, outFormat string) er
 		}
 	}
 
+	if len(remoteProblems) > 0 && outFormat != "json" {
+		for _, e := range remoteProblems {
+			fmt.Fprintln(w, "WARNING:", e.Error())
+		}
+	}
+
+	wipViolations := task.WIPViolations(db.GetAll(), wipLimits(cfg))
+	if len(wipViolations) > 0 && outFormat != "json" {
+		for _, u := range wipViolations {
+			fmt.Fprintf(w, "WARNING: WIP limit exceeded for %s %s (%d/%d in progress)\n", u.Scope, u.Name, u.InProgress, u.Limit)
+		}
+	}
+
 	if len(validationErrors) > 0 {
 		if outFormat == "json" {
 			errMsgs := make([]string, len(validationErrors))
@@ -85,6 +107,12 @@ func runRepair(w io.Writer, tasksRoot, rootsFile, freeFile, outFormat string) er
 				errMsgs[i] = e.Error()
 			}
 			payload := map[string]interface{}{"errors": errMsgs}
+			if len(wipViolations) > 0 {
+				payload["wip_violations"] = wipViolations
+			}
+			if len(remoteProblems) > 0 {
+				payload["remote_problems"] = validationMessages(remoteProblems)
+			}
 			if len(fixed) > 0 {
 				fixedMsgs := make([]string, len(fixed))
 				for i, e := range fixed {
@@ -113,6 +141,12 @@ func runRepair(w io.Writer, tasksRoot, rootsFile, freeFile, outFormat string) er
 			}
 		}
 		payload := map[string]interface{}{"roots": roots, "free": free}
+		if len(wipViolations) > 0 {
+			payload["wip_violations"] = wipViolations
+		}
+		if len(remoteProblems) > 0 {
+			payload["remote_problems"] = validationMessages(remoteProblems)
+		}
 		if len(fixed) > 0 {
 			fixedMsgs := make([]string, len(fixed))
 			for i, e := range fixed {
@@ -129,3 +163,11 @@ func runRepair(w io.Writer, tasksRoot, rootsFile, freeFile, outFormat string) er
 
 	return nil
 }
+
+func validationMessages(errs []task.ValidationError) []string {
+	msgs := make([]string, len(errs))
+	for i, e := range errs {
+		msgs[i] = e.Error()
+	}
+	return msgs
+}
@@ -21,6 +21,7 @@ var (
 	searchColumns string
 	searchGroup   string
 	searchMDTable bool
+	searchAll     bool
 )
 
 // searchCmd represents the search command
@@ -34,20 +35,24 @@ var searchCmd = &cobra.Command{
 			return fmt.Errorf("search query cannot be empty")
 		}
 
-		paths, err := resolveProjectPaths(projectName)
+		opts, err := searchOptionsFromFlags(query)
 		if err != nil {
 			return err
 		}
+		if searchAll {
+			return runSearchAllProjects(cmd.OutOrStdout(), opts)
+		}
 
-		opts, err := searchOptionsFromFlags(query)
+		paths, err := resolveProjectPaths(projectName)
 		if err != nil {
 			return err
 		}
 
-		tasks, err := task.SearchTasks(paths.TasksDir, opts)
+		tasks, impact, err := task.SearchTasks(paths.TasksDir, opts)
 		if err != nil {
 			return err
 		}
+		opts.Impact = impact
 		output, err := task.FormatList(tasks, opts.ListOptions)
 		if err != nil {
 			return err
@@ -62,12 +67,13 @@ var searchCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(searchCmd)
 
-	searchCmd.Flags().StringVar(&searchSort, "sort", "", "sort by: id|priority|created|edited|role")
+	searchCmd.Flags().StringVar(&searchSort, "sort", "", "sort by: id|priority|created|edited|role|impact")
 	searchCmd.Flags().StringVar(&searchOrder, "order", "asc", "sort order: asc|desc")
 	searchCmd.Flags().StringVar(&searchFormat, "format", "table", "output format: table|md|json")
 	searchCmd.Flags().StringVar(&searchColumns, "columns", "", "comma-separated list of columns to include")
 	searchCmd.Flags().StringVar(&searchGroup, "group", "none", "group by: none|priority|parent|role")
 	searchCmd.Flags().BoolVar(&searchMDTable, "md-table", false, "use markdown table output (with --format md)")
+	searchCmd.Flags().BoolVar(&searchAll, "all-projects", false, "search every registered project, with a project column")
 }
 
 func searchOptionsFromFlags(query string) (task.SearchOptions, error) {
@@ -98,9 +104,9 @@ func searchOptionsFromFlags(query string) (task.SearchOptions, error) {
 	}
 
 	switch opts.Sort {
-	case "", "id", "priority", "created", "edited", "role":
+	case "", "id", "priority", "created", "edited", "role", "impact":
 	default:
-		return task.SearchOptions{}, fmt.Errorf("invalid sort %q (expected id, priority, created, edited, or role)", opts.Sort)
+		return task.SearchOptions{}, fmt.Errorf("invalid sort %q (expected id, priority, created, edited, role, or impact)", opts.Sort)
 	}
 	switch opts.Order {
 	case "asc", "desc":
@@ -126,10 +132,11 @@ func runSearchWithProject(w io.Writer, projectName string, opts task.SearchOptio
 	if err != nil {
 		return err
 	}
-	tasks, err := task.SearchTasks(paths.TasksDir, opts)
+	tasks, impact, err := task.SearchTasks(paths.TasksDir, opts)
 	if err != nil {
 		return err
 	}
+	opts.Impact = impact
 	output, err := task.FormatList(tasks, opts.ListOptions)
 	if err != nil {
 		return err
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 
+	"github.com/ricochet1k/strandyard/pkg/activity"
 	"github.com/ricochet1k/strandyard/pkg/task"
 	"github.com/spf13/cobra"
 )
@@ -13,7 +14,8 @@ var showCmd = &cobra.Command{
 	Use:   "show <task-id>",
 	Short: "Print the full contents of a task",
 	Long: `Print the full contents of a task by ID, short ID, or any valid prefix.
-The output includes the complete markdown file content including frontmatter.`,
+The output includes the complete markdown file content including frontmatter,
+followed by the commits linked to the task through commit trailers.`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		taskID := args[0]
@@ -43,5 +45,31 @@ func runShow(w io.Writer, projectName, taskID string) error {
 	}
 
 	fmt.Fprint(w, string(content))
+	return showLinkedCommits(w, paths.BaseDir, id)
+}
+
+// showLinkedCommits lists the commits whose trailers referenced the task.
+func showLinkedCommits(w io.Writer, baseDir, id string) error {
+	activityLog, err := activity.Open(baseDir)
+	if err != nil {
+		return fmt.Errorf("failed to open activity log: %w", err)
+	}
+	defer activityLog.Close()
+
+	linked, err := activityLog.LinkedCommits(id)
+	if err != nil {
+		return err
+	}
+	if len(linked) == 0 {
+		return nil
+	}
+	fmt.Fprintln(w, "\nLinked commits:")
+	for _, entry := range linked {
+		line := fmt.Sprintf("  %s  %s  %s", task.ShortCommit(entry.Metadata["commit"]), entry.Timestamp.Format("2006-01-02"), entry.Metadata["subject"])
+		if entry.Metadata["completes"] == "true" {
+			line += " (completes)"
+		}
+		fmt.Fprintln(w, line)
+	}
 	return nil
 }
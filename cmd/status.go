@@ -33,12 +33,14 @@ var markDuplicateCmd = &cobra.Command{
 	},
 }
 
+var claimAgent string
+
 var markInProgressCmd = &cobra.Command{
 	Use:   "mark-in-progress <task-id>",
 	Short: "Mark a task as in progress",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return runClaim(cmd.OutOrStdout(), args[0])
+		return runClaim(cmd.OutOrStdout(), args[0], resolveAgent(claimAgent))
 	},
 }
 
@@ -47,7 +49,7 @@ var claimCmd = &cobra.Command{
 	Short: "Claim a task by marking it in progress",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return runClaim(cmd.OutOrStdout(), args[0])
+		return runClaim(cmd.OutOrStdout(), args[0], resolveAgent(claimAgent))
 	},
 }
 
@@ -56,26 +58,42 @@ func init() {
 	rootCmd.AddCommand(markDuplicateCmd)
 	rootCmd.AddCommand(markInProgressCmd)
 	rootCmd.AddCommand(claimCmd)
+	for _, c := range []*cobra.Command{markInProgressCmd, claimCmd} {
+		c.Flags().StringVar(&claimAgent, "agent", "", "agent identity recorded on the claim and used for WIP limits (default $STRAND_AGENT)")
+	}
 }
 
-func runClaim(w io.Writer, inputID string) error {
-	return runSetStatus(w, inputID, task.StatusInProgress, "")
+func runClaim(w io.Writer, inputID, agent string) error {
+	return runSetStatusAs(w, inputID, task.StatusInProgress, "", agent)
 }
 
 func runSetStatus(w io.Writer, inputID, status, report string) error {
+	return runSetStatusAs(w, inputID, status, report, "")
+}
+
+func runSetStatusAs(w io.Writer, inputID, status, report, agent string) error {
 	paths, err := resolveProjectPaths(projectName)
 	if err != nil {
 		return err
 	}
 
 	db := task.NewTaskDB(paths.TasksDir)
-	_, taskID, err := db.GetResolved(inputID)
+	t, taskID, err := db.GetResolved(inputID)
 	if err != nil {
 		return err
 	}
 
+	var limits task.WIPLimits
 	if status == task.StatusInProgress {
-		if err := db.ClaimTask(taskID); err != nil {
+		cfg, err := loadConfig(paths.BaseDir)
+		if err != nil {
+			return err
+		}
+		limits = wipLimits(cfg)
+		if err := task.CheckWIPClaim(db.GetAll(), limits, t, agent); err != nil {
+			return err
+		}
+		if err := db.ClaimTaskAs(taskID, agent); err != nil {
 			return err
 		}
 	} else {
@@ -94,5 +112,8 @@ func runSetStatus(w io.Writer, inputID, status, report string) error {
 	}
 
 	fmt.Fprintf(w, "✓ Task %s status set to %s\n", task.ShortID(taskID), status)
+	if status == task.StatusInProgress {
+		printWIPUsage(w, task.ComputeWIPUsage(db.GetAll(), limits))
+	}
 	return nil
 }
@@ -0,0 +1,296 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/ricochet1k/strandyard/pkg/config"
+	tasksync "github.com/ricochet1k/strandyard/pkg/sync"
+	"github.com/ricochet1k/strandyard/pkg/task"
+	"github.com/spf13/cobra"
+)
+
+type syncOptions struct {
+	Prefer string
+	DryRun bool
+	Format string
+}
+
+var syncOpts syncOptions
+
+// syncCmd represents the sync command
+var syncCmd = &cobra.Command{
+	Use:   "sync [task-id...]",
+	Short: "Sync linked tasks with issues in an issue tracker",
+	Long: `Sync tasks linked with ` + "`strand sync link`" + ` with their remote issues.
+
+The tracker is set by the sync.provider, sync.url and sync.repo config keys;
+the API token is read from the environment variable named by sync.token_env
+(GITHUB_TOKEN by default).
+
+Title, description and open/closed state are mirrored. A side edited since the
+last sync is copied to the other. A task edited on both sides is reported as a
+conflict and left alone; rerun with --prefer local or --prefer remote to pick
+the side to keep. New issue comments are appended to the task's Progress
+section. Without task IDs, every linked task is synced.
+
+Examples:
+  strand config set sync.repo acme/app
+  strand sync link T3k7x          # create an issue from the task
+  strand sync link T3k7x 42       # link the task to issue #42
+  strand sync --dry-run
+  strand sync --prefer remote T3k7x`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSync(cmd.Context(), cmd.OutOrStdout(), projectName, args, syncOpts)
+	},
+}
+
+var syncLinkCmd = &cobra.Command{
+	Use:   "link <task-id> [issue]",
+	Short: "Link a task to a remote issue, creating one if none is given",
+	Long: `Link a task to a remote issue, given by number or URL. Without an issue, a new
+issue is created from the task. When the task and an existing issue differ,
+pass --prefer local to overwrite the issue or --prefer remote to overwrite the
+task.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		issue := ""
+		if len(args) == 2 {
+			issue = args[1]
+		}
+		return runSyncLink(cmd.Context(), cmd.OutOrStdout(), projectName, args[0], issue, syncOpts)
+	},
+}
+
+var syncUnlinkCmd = &cobra.Command{
+	Use:   "unlink <task-id>",
+	Short: "Stop syncing a task; the remote issue is left as is",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSyncUnlink(cmd.OutOrStdout(), projectName, args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(syncCmd)
+	syncCmd.AddCommand(syncLinkCmd, syncUnlinkCmd)
+	for _, c := range []*cobra.Command{syncCmd, syncLinkCmd} {
+		c.Flags().StringVar(&syncOpts.Prefer, "prefer", "", "side to keep when both changed: local|remote")
+		c.Flags().BoolVar(&syncOpts.DryRun, "dry-run", false, "show what would change without writing tasks or issues")
+		c.Flags().StringVar(&syncOpts.Format, "format", "text", "output format: text|json")
+	}
+}
+
+// newSyncProvider returns the provider configured by the sync config keys.
+func newSyncProvider(cfg config.Config) (tasksync.Provider, error) {
+	if strings.TrimSpace(cfg.Sync.Repo) == "" {
+		return nil, fmt.Errorf("sync.repo is not set; run `strand config set sync.repo owner/name`")
+	}
+	token := ""
+	if cfg.Sync.TokenEnv != "" {
+		token = os.Getenv(cfg.Sync.TokenEnv)
+	}
+	switch cfg.Sync.Provider {
+	case "", tasksync.ProviderGitHub:
+		return tasksync.NewGitHub(cfg.Sync.URL, cfg.Sync.Repo, token)
+	}
+	return nil, fmt.Errorf("unknown sync provider %q", cfg.Sync.Provider)
+}
+
+// openSync loads the project's tasks and configured provider.
+func openSync(projectName string, opts syncOptions) (projectPaths, *task.TaskDB, tasksync.Provider, error) {
+	if opts.Format != "text" && opts.Format != "json" {
+		return projectPaths{}, nil, nil, fmt.Errorf("invalid format %q (expected text or json)", opts.Format)
+	}
+	paths, err := resolveProjectPaths(projectName)
+	if err != nil {
+		return projectPaths{}, nil, nil, err
+	}
+	cfg, err := loadConfig(paths.BaseDir)
+	if err != nil {
+		return projectPaths{}, nil, nil, err
+	}
+	provider, err := newSyncProvider(cfg)
+	if err != nil {
+		return projectPaths{}, nil, nil, err
+	}
+	db := task.NewTaskDB(paths.TasksDir)
+	if err := db.LoadAll(); err != nil {
+		return projectPaths{}, nil, nil, fmt.Errorf("failed to load tasks: %w", err)
+	}
+	return paths, db, provider, nil
+}
+
+// saveSync writes tasks changed by a sync, repairing the free list when a
+// pulled state change may have freed or blocked tasks.
+func saveSync(paths projectPaths, db *task.TaskDB, results []tasksync.Result) error {
+	if _, err := db.SaveDirty(); err != nil {
+		return err
+	}
+	for _, r := range results {
+		if (r.Action == tasksync.ActionPulled || r.Action == tasksync.ActionLinked) && slices.Contains(r.Fields, "state") {
+			return runRepair(io.Discard, paths.TasksDir, paths.RootTasksFile, paths.FreeTasksFile, "text")
+		}
+	}
+	return nil
+}
+
+// issueRef names a remote issue by URL, falling back to its ID.
+func issueRef(url, provider, issue string) string {
+	if url != "" {
+		return url
+	}
+	return strings.TrimSpace(provider + " issue " + issue)
+}
+
+func runSync(ctx context.Context, w io.Writer, projectName string, taskIDs []string, opts syncOptions) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	paths, db, provider, err := openSync(projectName, opts)
+	if err != nil {
+		return err
+	}
+	ids, err := db.ResolveIDs(taskIDs)
+	if err != nil {
+		return err
+	}
+
+	results, syncErr := tasksync.Sync(ctx, provider, db, tasksync.Options{TaskIDs: ids, Prefer: opts.Prefer, DryRun: opts.DryRun})
+	if !opts.DryRun {
+		if err := saveSync(paths, db, results); err != nil && syncErr == nil {
+			syncErr = err
+		}
+	}
+
+	if opts.Format == "json" {
+		if results == nil {
+			results = []tasksync.Result{}
+		}
+		b, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(b))
+	} else {
+		printSyncResults(w, results, opts.DryRun)
+	}
+	if syncErr != nil {
+		return syncErr
+	}
+	if n := tasksync.Conflicts(results); n > 0 {
+		return fmt.Errorf("%d tasks changed on both sides; rerun with --prefer local or --prefer remote", n)
+	}
+	return nil
+}
+
+func printSyncResults(w io.Writer, results []tasksync.Result, dryRun bool) {
+	if len(results) == 0 {
+		fmt.Fprintln(w, "No linked tasks; link one with `strand sync link <task-id> [issue]`")
+		return
+	}
+	counts := map[string]int{}
+	comments := 0
+	for _, r := range results {
+		counts[r.Action]++
+		comments += r.Comments
+		where := issueRef(r.URL, "", r.Issue)
+		fields := strings.Join(r.Fields, ", ")
+		switch {
+		case r.Action == tasksync.ActionConflict:
+			fmt.Fprintf(w, "CONFLICT: %s and %s both changed: %s\n", r.TaskID, where, fields)
+		case r.Action == tasksync.ActionMissing:
+			fmt.Fprintf(w, "WARNING: %s: cannot fetch %s: %s\n", r.TaskID, where, r.Error)
+		case dryRun && r.Action == tasksync.ActionPushed:
+			fmt.Fprintf(w, "Would push %s of %s to %s\n", fields, r.TaskID, where)
+		case dryRun && r.Action == tasksync.ActionPulled:
+			fmt.Fprintf(w, "Would pull %s of %s into %s\n", fields, where, r.TaskID)
+		case r.Action == tasksync.ActionPushed:
+			fmt.Fprintf(w, "✓ Pushed %s of %s to %s\n", fields, r.TaskID, where)
+		case r.Action == tasksync.ActionPulled:
+			fmt.Fprintf(w, "✓ Pulled %s of %s into %s\n", fields, where, r.TaskID)
+		}
+		if r.Comments > 0 {
+			if dryRun {
+				fmt.Fprintf(w, "Would add %d comments from %s to %s\n", r.Comments, where, r.TaskID)
+			} else {
+				fmt.Fprintf(w, "✓ Added %d comments from %s to %s\n", r.Comments, where, r.TaskID)
+			}
+		}
+	}
+	prefix := "Synced"
+	if dryRun {
+		prefix = "Dry run:"
+	}
+	fmt.Fprintf(w, "%s %d tasks (%d pushed, %d pulled, %d unchanged, %d conflicts, %d comments)\n",
+		prefix, len(results), counts[tasksync.ActionPushed], counts[tasksync.ActionPulled], counts[tasksync.ActionUnchanged], counts[tasksync.ActionConflict], comments)
+}
+
+func runSyncLink(ctx context.Context, w io.Writer, projectName, taskID, issue string, opts syncOptions) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	paths, db, provider, err := openSync(projectName, opts)
+	if err != nil {
+		return err
+	}
+	resolved, err := db.ResolveID(taskID)
+	if err != nil {
+		return err
+	}
+	result, err := tasksync.LinkIssue(ctx, provider, db, resolved, issue, tasksync.Options{Prefer: opts.Prefer, DryRun: opts.DryRun})
+	if err != nil {
+		return err
+	}
+	if !opts.DryRun {
+		if err := saveSync(paths, db, []tasksync.Result{result}); err != nil {
+			return err
+		}
+	}
+
+	if opts.Format == "json" {
+		b, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(b))
+		return nil
+	}
+	switch {
+	case opts.DryRun && result.Action == tasksync.ActionCreated:
+		fmt.Fprintf(w, "Would create an issue from %s\n", result.TaskID)
+	case opts.DryRun:
+		fmt.Fprintf(w, "Would link %s to %s\n", result.TaskID, issueRef(result.URL, "", result.Issue))
+	case result.Action == tasksync.ActionCreated:
+		fmt.Fprintf(w, "✓ Created %s from %s\n", issueRef(result.URL, "", result.Issue), result.TaskID)
+	default:
+		fmt.Fprintf(w, "✓ Linked %s to %s\n", result.TaskID, issueRef(result.URL, "", result.Issue))
+	}
+	return nil
+}
+
+func runSyncUnlink(w io.Writer, projectName, taskID string) error {
+	paths, err := resolveProjectPaths(projectName)
+	if err != nil {
+		return err
+	}
+	db := task.NewTaskDB(paths.TasksDir)
+	t, _, err := db.GetResolved(taskID)
+	if err != nil {
+		return err
+	}
+	link, _, _ := tasksync.LinkOf(t)
+	if !tasksync.RemoveLink(t) {
+		return fmt.Errorf("task %s is not linked to an issue", t.ID)
+	}
+	if _, err := db.SaveDirty(); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "✓ Unlinked %s from %s\n", t.ID, issueRef(link.URL, link.Provider, link.Issue))
+	return nil
+}
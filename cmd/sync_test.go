@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ricochet1k/strandyard/pkg/sync/synctest"
+)
+
+func TestSyncLinkPullsStateAndUnlinks(t *testing.T) {
+	paths := setupTestProject(t, initOptions{StorageMode: storageLocal})
+	if err := os.WriteFile(filepath.Join(paths.RolesDir, "developer.md"), []byte("# developer\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	writeClaimTaskFile(t, paths.TasksDir, "T1abc-auth-api", "developer")
+	writeClaimTaskFile(t, paths.TasksDir, "T2def-login", "developer")
+	if err := runRepair(&bytes.Buffer{}, paths.TasksDir, paths.RootTasksFile, paths.FreeTasksFile, "text"); err != nil {
+		t.Fatal(err)
+	}
+
+	server := synctest.NewGitHub("acme/app")
+	server.Token = "secret"
+	defer server.Close()
+	var out bytes.Buffer
+	opts := syncOptions{Format: "text"}
+	if err := runSync(context.Background(), &out, "", nil, opts); err == nil || !strings.Contains(err.Error(), "sync.repo is not set") {
+		t.Fatalf("sync without a repository should fail, got %v", err)
+	}
+	t.Setenv("STRAND_SYNC_URL", server.URL)
+	t.Setenv("STRAND_SYNC_REPO", "acme/app")
+	t.Setenv("GITHUB_TOKEN", "secret")
+
+	if err := runSyncLink(context.Background(), &out, "", "T1abc", "", opts); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "✓ Created "+server.URL+"/acme/app/issues/1 from T1abc-auth-api") {
+		t.Errorf("link output:\n%s", out.String())
+	}
+	raw, err := os.ReadFile(filepath.Join(paths.TasksDir, "T1abc-auth-api.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(raw), "sync:\n") || !strings.Contains(string(raw), "provider: github") {
+		t.Errorf("link metadata not written:\n%s", raw)
+	}
+
+	server.EditIssue(1, func(i *synctest.Issue) { i.Closed = true })
+	out.Reset()
+	if err := runSync(context.Background(), &out, "", nil, opts); err != nil {
+		t.Fatalf("sync: %v\n%s", err, out.String())
+	}
+	if !strings.Contains(out.String(), "✓ Pulled state of "+server.URL+"/acme/app/issues/1 into T1abc-auth-api") ||
+		!strings.Contains(out.String(), "Synced 1 tasks (0 pushed, 1 pulled") {
+		t.Errorf("sync output:\n%s", out.String())
+	}
+	if task := loadTestTask(t, paths.TasksDir, "T1abc-auth-api"); !task.Meta.Completed {
+		t.Error("closing the issue should complete the task")
+	}
+	free, err := os.ReadFile(paths.FreeTasksFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(free), "T1abc") {
+		t.Errorf("completed task should leave the free list:\n%s", free)
+	}
+
+	out.Reset()
+	if err := runSyncUnlink(&out, "", "T1abc"); err != nil {
+		t.Fatal(err)
+	}
+	if err := runSyncUnlink(&out, "", "T1abc"); err == nil {
+		t.Error("unlinking twice should fail")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"sort"
+	"strings"
 
 	"github.com/ricochet1k/strandyard/pkg/template"
 	"github.com/spf13/cobra"
@@ -52,6 +53,16 @@ func runTemplates(w io.Writer) error {
 			desc = "(no description found)"
 		}
 		fmt.Fprintf(w, "%-20s %s\n", name, desc)
+		for _, v := range t.Meta.Vars {
+			help := v.Help
+			if v.Required {
+				help = strings.TrimSpace("(required) " + help)
+			}
+			if v.Default != "" {
+				help = strings.TrimSpace(help + " [default: " + v.Default + "]")
+			}
+			fmt.Fprintf(w, "  %-30s %s\n", v.Usage(), help)
+		}
 	}
 	return nil
 }
@@ -186,6 +186,9 @@ func samePath(left, right string) bool {
 }
 
 func hasProjectStructure(dir string) bool {
+	if isMigrated(dir) {
+		return false
+	}
 	for _, sub := range []string{"tasks", "roles", "templates"} {
 		path := filepath.Join(dir, sub)
 		if info, err := os.Stat(path); err != nil || !info.IsDir() {
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ricochet1k/strandyard/pkg/config"
+	"github.com/ricochet1k/strandyard/pkg/task"
+)
+
+// agentEnvVar names the environment variable used when --agent is not given.
+const agentEnvVar = "STRAND_AGENT"
+
+// resolveAgent returns the agent identity from the flag value or STRAND_AGENT.
+func resolveAgent(flagValue string) string {
+	if agent := strings.TrimSpace(flagValue); agent != "" {
+		return agent
+	}
+	return strings.TrimSpace(os.Getenv(agentEnvVar))
+}
+
+func wipLimits(cfg config.Config) task.WIPLimits {
+	return task.WIPLimits{
+		Roles:  cfg.WIP.Roles,
+		Agent:  cfg.WIP.Agent,
+		Agents: cfg.WIP.Agents,
+	}
+}
+
+// printWIPUsage prints one line per limited role or agent, skipping entries
+// without a limit.
+func printWIPUsage(w io.Writer, usage []task.WIPUsage) {
+	for _, u := range usage {
+		if u.Limit > 0 {
+			fmt.Fprintf(w, "WIP %s\n", u)
+		}
+	}
+}
+
+// runListWIP prints in-progress usage for every role and agent, marking
+// entries that exceed their limit.
+func runListWIP(w io.Writer, paths projectPaths, format string) error {
+	cfg, err := loadConfig(paths.BaseDir)
+	if err != nil {
+		return err
+	}
+	db := task.NewTaskDB(paths.TasksDir)
+	if err := db.LoadAllIfEmpty(); err != nil {
+		return fmt.Errorf("failed to load tasks: %w", err)
+	}
+	usage := task.ComputeWIPUsage(db.GetAll(), wipLimits(cfg))
+
+	switch format {
+	case "json":
+		b, err := json.MarshalIndent(usage, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(b))
+	case "table", "md":
+		if len(usage) == 0 {
+			fmt.Fprintln(w, "No tasks in progress")
+			return nil
+		}
+		for _, u := range usage {
+			line := u.String()
+			if u.Exceeded() {
+				line += " (over limit)"
+			} else if u.Full() {
+				line += " (full)"
+			}
+			fmt.Fprintln(w, line)
+		}
+	default:
+		return fmt.Errorf("invalid format %q (expected table, md, or json)", format)
+	}
+	return nil
+}
@@ -63,22 +63,11 @@ func runWorkflow(w io.Writer, errW io.Writer) error {
 		return err
 	}
 
-	// Load roles and templates
-	parser := workflow.NewParser()
-
-	roles, err := parser.LoadRoles(paths.RolesDir)
-	if err != nil {
-		return fmt.Errorf("failed to load roles: %w", err)
-	}
-
-	templates, err := parser.LoadTemplates(paths.TemplatesDir)
+	graph, err := loadWorkflowGraph(paths.RolesDir, paths.TemplatesDir)
 	if err != nil {
-		return fmt.Errorf("failed to load templates: %w", err)
+		return err
 	}
 
-	// Build workflow graph
-	graph := workflow.BuildGraph(roles, templates)
-
 	// Handle --validate flag
 	if workflowValidate {
 		return runWorkflowValidation(graph, w, errW)
@@ -114,6 +103,23 @@ func runWorkflow(w io.Writer, errW io.Writer) error {
 	}
 }
 
+// loadWorkflowGraph loads roles and templates and builds their workflow graph.
+func loadWorkflowGraph(rolesDir, templatesDir string) (*workflow.WorkflowGraph, error) {
+	parser := workflow.NewParser()
+
+	roles, err := parser.LoadRoles(rolesDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load roles: %w", err)
+	}
+
+	templates, err := parser.LoadTemplates(templatesDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load templates: %w", err)
+	}
+
+	return workflow.BuildGraph(roles, templates), nil
+}
+
 func runWorkflowValidation(graph *workflow.WorkflowGraph, w io.Writer, errW io.Writer) error {
 	result := graph.Validate()
 
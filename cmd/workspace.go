@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/ricochet1k/strandyard/pkg/config"
+	"github.com/ricochet1k/strandyard/pkg/task"
+)
+
+// workspaceProject is one project included by --all-projects.
+type workspaceProject struct {
+	Paths  projectPaths
+	Config config.Config
+}
+
+// loadWorkspaceProjects returns every registered project whose storage can be
+// read, in name order. Stale projects are skipped; `strand projects list`
+// reports them.
+func loadWorkspaceProjects() ([]workspaceProject, error) {
+	entries, err := loadProjectEntries()
+	if err != nil {
+		return nil, err
+	}
+	projects := make([]workspaceProject, 0, len(entries))
+	for _, e := range entries {
+		if e.Stale {
+			continue
+		}
+		gitRoot := ""
+		if len(e.GitRoots) > 0 {
+			gitRoot = e.GitRoots[0]
+		}
+		paths, err := projectPathsFromBase(e.StorageRoot, e.Name, gitRoot, e.Storage)
+		if err != nil {
+			return nil, err
+		}
+		cfg, err := loadConfig(paths.BaseDir)
+		if err != nil {
+			return nil, fmt.Errorf("project %s: %w", e.Name, err)
+		}
+		projects = append(projects, workspaceProject{Paths: paths, Config: cfg})
+	}
+	if len(projects) == 0 {
+		return nil, fmt.Errorf("no projects found (run `strand init` in a repository first)")
+	}
+	return projects, nil
+}
+
+// workspaceConfig loads the settings that apply to a workspace-wide command
+// as a whole: those of the project it runs in, or the user config when it
+// runs outside any project.
+func workspaceConfig() (config.Config, error) {
+	dir, err := configProjectDir(projectName)
+	if err != nil {
+		return config.Config{}, err
+	}
+	return loadConfig(dir)
+}
+
+// collectWorkspaceTasks runs load against every project, tags the results
+// with their project and sorts the merged list by opts. The impact load
+// returns for each project is merged into opts.Impact, keyed by Task.Key.
+func collectWorkspaceTasks(projects []workspaceProject, opts *task.ListOptions, load func(tasksRoot string, opts task.ListOptions) ([]*task.Task, map[string]task.TaskImpact, error)) ([]*task.Task, error) {
+	var all []*task.Task
+	schema := task.FieldSchema{}
+	var impact map[string]task.TaskImpact
+	if opts.NeedsImpact() {
+		impact = make(map[string]task.TaskImpact)
+	}
+	for _, p := range projects {
+		projectOpts := *opts
+		projectOpts.FieldSchema = p.Config.Fields
+		if err := task.ValidateListFilters(projectOpts); err != nil {
+			return nil, fmt.Errorf("project %s: %w", p.Paths.ProjectName, err)
+		}
+		tasks, projectImpact, err := load(p.Paths.TasksDir, projectOpts)
+		if err != nil {
+			return nil, fmt.Errorf("project %s: %w", p.Paths.ProjectName, err)
+		}
+		for id, ti := range projectImpact {
+			impact[task.RemoteRef{Project: p.Paths.ProjectName, ID: id}.String()] = ti
+		}
+		for _, t := range tasks {
+			t.Project = p.Paths.ProjectName
+		}
+		all = append(all, tasks...)
+		for name, spec := range p.Config.Fields {
+			schema[name] = spec
+		}
+	}
+	opts.FieldSchema = schema
+	opts.Impact = impact
+	task.SortTasks(all, *opts)
+	return all, nil
+}
+
+func runListAllProjects(w io.Writer, opts task.ListOptions) error {
+	if err := validateListOptions(opts); err != nil {
+		return err
+	}
+	projects, err := loadWorkspaceProjects()
+	if err != nil {
+		return err
+	}
+	tasks, err := collectWorkspaceTasks(projects, &opts, task.ListTasks)
+	if err != nil {
+		return err
+	}
+	return printTaskList(w, tasks, opts)
+}
+
+func runSearchAllProjects(w io.Writer, opts task.SearchOptions) error {
+	projects, err := loadWorkspaceProjects()
+	if err != nil {
+		return err
+	}
+	tasks, err := collectWorkspaceTasks(projects, &opts.ListOptions, func(tasksRoot string, listOpts task.ListOptions) ([]*task.Task, map[string]task.TaskImpact, error) {
+		return task.SearchTasks(tasksRoot, task.SearchOptions{Query: opts.Query, ListOptions: listOpts})
+	})
+	if err != nil {
+		return err
+	}
+	return printTaskList(w, tasks, opts.ListOptions)
+}
+
+func printTaskList(w io.Writer, tasks []*task.Task, opts task.ListOptions) error {
+	output, err := task.FormatList(tasks, opts)
+	if err != nil {
+		return err
+	}
+	if output != "" {
+		fmt.Fprintln(w, output)
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ricochet1k/strandyard/pkg/task"
+)
+
+// setupWorkspace creates a frontend and a backend project, each with one
+// developer task; the backend task has high priority.
+func setupWorkspace(t *testing.T) (frontend, backend projectPaths) {
+	t.Helper()
+	frontend = setupTestProject(t, initOptions{StorageMode: storageGlobal, ProjectName: "frontend"})
+	frontendRepo, err := gitRootDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	backendRepo := initGitRepo(t)
+	chdir(t, backendRepo)
+	if err := runInit(io.Discard, initOptions{ProjectName: "backend"}); err != nil {
+		t.Fatal(err)
+	}
+	if backend, err = resolveProjectPaths("backend"); err != nil {
+		t.Fatal(err)
+	}
+	chdir(t, frontendRepo)
+
+	writeClaimTaskFile(t, frontend.TasksDir, "T1aaa-login-ui", "developer")
+	writeClaimTaskFile(t, backend.TasksDir, "T2bbb-auth-api", "developer")
+	apiPath := filepath.Join(backend.TasksDir, "T2bbb-auth-api.md")
+	data, err := os.ReadFile(apiPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(apiPath, bytes.Replace(data, []byte("priority: medium"), []byte("priority: high"), 1), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, paths := range []projectPaths{frontend, backend} {
+		if err := os.WriteFile(filepath.Join(paths.RolesDir, "developer.md"), []byte("# developer\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := runRepair(io.Discard, paths.TasksDir, paths.RootTasksFile, paths.FreeTasksFile, "text"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return frontend, backend
+}
+
+func TestListAllProjectsMergesProjects(t *testing.T) {
+	setupWorkspace(t)
+
+	var out bytes.Buffer
+	opts := task.ListOptions{Scope: "free", Format: "json", Completed: boolPtr(false)}
+	if err := runListAllProjects(&out, opts); err != nil {
+		t.Fatal(err)
+	}
+	var rows []struct {
+		Project string `json:"project"`
+		ID      string `json:"id"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &rows); err != nil {
+		t.Fatalf("decode: %v\n%s", err, out.String())
+	}
+	if len(rows) != 2 || rows[0].Project != "backend" || rows[1].Project != "frontend" {
+		t.Errorf("rows = %+v, want the high priority backend task first", rows)
+	}
+
+	// Impact is keyed by project:ID, so every row finds its own.
+	out.Reset()
+	impactOpts := opts
+	impactOpts.Sort = "impact"
+	if err := runListAllProjects(&out, impactOpts); err != nil {
+		t.Fatal(err)
+	}
+	var impactRows []struct {
+		ID     string           `json:"id"`
+		Impact *task.TaskImpact `json:"impact"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &impactRows); err != nil {
+		t.Fatalf("decode: %v\n%s", err, out.String())
+	}
+	for _, row := range impactRows {
+		if row.Impact == nil || row.Impact.Chain != 1 {
+			t.Errorf("%s: impact = %v, want chain 1", row.ID, row.Impact)
+		}
+	}
+
+	out.Reset()
+	opts.Format = "table"
+	if err := runListAllProjects(&out, opts); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out.String(), "PROJECT") {
+		t.Errorf("table should lead with a project column:\n%s", out.String())
+	}
+}
+
+func TestNextAllProjectsClaimsAcrossProjects(t *testing.T) {
+	frontend, backend := setupWorkspace(t)
+
+	var out bytes.Buffer
+	if err := runNextAllProjects(&out, "developer", nextOptions{Claim: true, Agent: "agent-1"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "Your task is in project backend") || !strings.Contains(out.String(), "Your task is T2bbb-auth-api") {
+		t.Errorf("expected the backend task:\n%s", out.String())
+	}
+	if api := loadTestTask(t, backend.TasksDir, "T2bbb-auth-api"); !api.Meta.IsInProgress() || api.Meta.ClaimedBy != "agent-1" {
+		t.Errorf("backend task should be claimed, got %+v", api.Meta)
+	}
+	if ui := loadTestTask(t, frontend.TasksDir, "T1aaa-login-ui"); ui.Meta.IsInProgress() {
+		t.Errorf("frontend task should stay open")
+	}
+
+	out.Reset()
+	if err := runNextAllProjects(&out, "developer", nextOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "Your task is T1aaa-login-ui") {
+		t.Errorf("claimed task should no longer be offered:\n%s", out.String())
+	}
+}
+
+func TestNextAllProjectsCountsAgentWIPAcrossProjects(t *testing.T) {
+	frontend, _ := setupWorkspace(t)
+	if err := os.WriteFile(filepath.Join(frontend.BaseDir, "strand.yaml"), []byte("wip:\n  agent: 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runNextAllProjects(io.Discard, "developer", nextOptions{Claim: true, Agent: "agent-1"}); err != nil {
+		t.Fatal(err)
+	}
+	err := runNextAllProjects(io.Discard, "developer", nextOptions{Claim: true, Agent: "agent-1"})
+	if err == nil || !strings.Contains(err.Error(), "WIP limit reached for agent agent-1 (1/1 in progress)") {
+		t.Fatalf("expected the backend claim to count against the frontend agent limit, got %v", err)
+	}
+	if ui := loadTestTask(t, frontend.TasksDir, "T1aaa-login-ui"); ui.Meta.IsInProgress() {
+		t.Errorf("frontend task should stay open")
+	}
+	if err := runNextAllProjects(io.Discard, "developer", nextOptions{Claim: true, Agent: "agent-2"}); err != nil {
+		t.Fatalf("another agent should still claim: %v", err)
+	}
+}
@@ -22,6 +22,7 @@ type EventType string
 const (
 	EventTaskCompleted            EventType = "task_completed"
 	EventRecurrenceAnchorResolved EventType = "recurrence_anchor_resolved"
+	EventCommitLinked             EventType = "commit_linked"
 )
 
 // Entry represents a single activity log entry
@@ -123,6 +124,38 @@ func (l *Log) WriteRecurrenceAnchorResolution(taskID, original, resolved string)
 	})
 }
 
+// WriteCommitLinked writes a commit linked event to the activity log
+func (l *Log) WriteCommitLinked(taskID, commit, subject string, completes bool) error {
+	metadata := map[string]string{
+		"commit":  commit,
+		"subject": subject,
+	}
+	if completes {
+		metadata["completes"] = "true"
+	}
+	return l.WriteEntry(Entry{
+		TaskID:   taskID,
+		Type:     EventCommitLinked,
+		Metadata: metadata,
+	})
+}
+
+// LinkedCommits returns the commit linked events of the given task, oldest first.
+func (l *Log) LinkedCommits(taskID string) ([]Entry, error) {
+	entries, err := l.ReadEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	var linked []Entry
+	for _, entry := range entries {
+		if entry.Type == EventCommitLinked && (entry.TaskID == taskID || strings.HasPrefix(entry.TaskID, taskID+"-")) {
+			linked = append(linked, entry)
+		}
+	}
+	return linked, nil
+}
+
 // ReadEntries reads all entries from the activity log
 func (l *Log) ReadEntries() ([]Entry, error) {
 	l.mu.RLock()
@@ -1073,3 +1073,37 @@ func TestErrorRecovery_RapidConcurrentCorruption(t *testing.T) {
 		taskIDs[entry.TaskID] = true
 	}
 }
+
+func TestLinkedCommits(t *testing.T) {
+	log, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to open log: %v", err)
+	}
+	defer log.Close()
+
+	if err := log.WriteCommitLinked("T3k7x-example", "0123456789abcdef", "Fix parser", false); err != nil {
+		t.Fatalf("WriteCommitLinked: %v", err)
+	}
+	if err := log.WriteTaskCompletion("T3k7x-example", "done"); err != nil {
+		t.Fatalf("WriteTaskCompletion: %v", err)
+	}
+	if err := log.WriteCommitLinked("T9z9z-other", "fedcba9876543210", "Other", true); err != nil {
+		t.Fatalf("WriteCommitLinked: %v", err)
+	}
+	if err := log.WriteCommitLinked("T3k7x-example", "aaaaaaaaaaaaaaaa", "Finish parser", true); err != nil {
+		t.Fatalf("WriteCommitLinked: %v", err)
+	}
+
+	linked, err := log.LinkedCommits("T3k7x")
+	if err != nil {
+		t.Fatalf("LinkedCommits: %v", err)
+	}
+	var got []string
+	for _, entry := range linked {
+		got = append(got, entry.Metadata["commit"]+" "+entry.Metadata["completes"])
+	}
+	want := []string{"0123456789abcdef ", "aaaaaaaaaaaaaaaa true"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("LinkedCommits mismatch (-want +got):\n%s", diff)
+	}
+}
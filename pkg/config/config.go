@@ -0,0 +1,216 @@
+// Package config loads strand settings from layered strand.yaml files and
+// STRAND_* environment variables.
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ricochet1k/strandyard/pkg/task"
+)
+
+// FileName is the name of the config file in the user config dir and in the
+// project storage root.
+const FileName = "strand.yaml"
+
+// Config is the typed schema of strand.yaml. Default returns the values used
+// for keys no layer sets.
+type Config struct {
+	Init InitConfig `yaml:"init"`
+	Add  AddConfig  `yaml:"add"`
+	List ListConfig `yaml:"list"`
+	Next NextConfig `yaml:"next"`
+	WIP  WIPConfig  `yaml:"wip"`
+	// Preset lists the presets layered into the project.
+	Preset PresetConfig `yaml:"preset"`
+	Sync   SyncConfig   `yaml:"sync"`
+	// Fields declares custom task frontmatter fields by name.
+	Fields task.FieldSchema `yaml:"fields"`
+}
+
+// InitConfig controls `strand init`.
+type InitConfig struct {
+	// Storage is the default storage mode: global or local.
+	Storage string `yaml:"storage"`
+}
+
+// AddConfig controls task creation.
+type AddConfig struct {
+	// DefaultIDPrefix is the ID prefix for templates not matched by IDPrefixes.
+	DefaultIDPrefix string `yaml:"default_id_prefix"`
+	// IDPrefixes maps a template-name substring to an ID prefix.
+	IDPrefixes map[string]string `yaml:"id_prefixes"`
+	// PlaceholderTitles lists titles rejected as unfilled template placeholders.
+	PlaceholderTitles []string `yaml:"placeholder_titles"`
+}
+
+// ListConfig controls `strand list`.
+type ListConfig struct {
+	// Format is the default output format: table, md or json.
+	Format string `yaml:"format"`
+}
+
+// NextConfig controls how `strand next` picks a task.
+type NextConfig struct {
+	// Policy names the selection policy; empty means the default policy.
+	Policy string `yaml:"policy"`
+	// AgingInterval is how long a task waits before the aging policy raises
+	// it by one priority level; zero means the policy default.
+	AgingInterval time.Duration `yaml:"aging_interval"`
+	// ClaimTimeout is how long an in-progress claim lasts before next reopens it.
+	ClaimTimeout time.Duration `yaml:"claim_timeout"`
+}
+
+// PresetConfig controls `strand preset refresh`.
+type PresetConfig struct {
+	// Sources are preset references overlaid in order: later layers win per
+	// file, or per frontmatter key when both versions have frontmatter.
+	Sources []string `yaml:"sources"`
+}
+
+// SyncConfig controls `strand sync`.
+type SyncConfig struct {
+	// Provider names the issue tracker API: github.
+	Provider string `yaml:"provider"`
+	// URL is the API root, for GitHub Enterprise or compatible servers.
+	URL string `yaml:"url"`
+	// Repo is the repository to mirror tasks to, as owner/name.
+	Repo string `yaml:"repo"`
+	// TokenEnv names the environment variable holding the API token.
+	TokenEnv string `yaml:"token_env"`
+}
+
+// WIPConfig caps the number of in_progress tasks. Zero or missing means unlimited.
+type WIPConfig struct {
+	// Roles maps a role name to its limit.
+	Roles map[string]int `yaml:"roles"`
+	// Agent is the default limit for every agent.
+	Agent int `yaml:"agent"`
+	// Agents overrides Agent for specific agents.
+	Agents map[string]int `yaml:"agents"`
+}
+
+// Default returns the built-in configuration.
+func Default() Config {
+	return Config{
+		Init: InitConfig{Storage: "global"},
+		Add: AddConfig{
+			DefaultIDPrefix:   "T",
+			IDPrefixes:        map[string]string{"epic": "E"},
+			PlaceholderTitles: []string{"description", "task title", "new task", "title", "summary", "todo"},
+		},
+		List: ListConfig{Format: "table"},
+		Next: NextConfig{Policy: task.PolicyPriority, AgingInterval: task.DefaultAgingInterval, ClaimTimeout: time.Hour},
+		Sync: SyncConfig{Provider: "github", URL: "https://api.github.com", TokenEnv: "GITHUB_TOKEN"},
+	}
+}
+
+// Validate checks values that the YAML types alone cannot. Empty values are
+// allowed so that a single layer can be validated on its own.
+func (c Config) Validate() error {
+	switch c.Init.Storage {
+	case "", "global", "local":
+	default:
+		return fmt.Errorf("init.storage: invalid storage mode %q (expected global or local)", c.Init.Storage)
+	}
+	if c.Add.DefaultIDPrefix != "" && !task.IsValidIDPrefix(c.Add.DefaultIDPrefix) {
+		return fmt.Errorf("add.default_id_prefix: invalid prefix %q (expected 1-5 uppercase letters)", c.Add.DefaultIDPrefix)
+	}
+	for name, prefix := range c.Add.IDPrefixes {
+		if !task.IsValidIDPrefix(prefix) {
+			return fmt.Errorf("add.id_prefixes.%s: invalid prefix %q (expected 1-5 uppercase letters)", name, prefix)
+		}
+	}
+	switch c.List.Format {
+	case "", "table", "md", "json":
+	default:
+		return fmt.Errorf("list.format: invalid format %q (expected table, md, or json)", c.List.Format)
+	}
+	if c.Next.Policy != "" {
+		if _, err := task.NewSelectionPolicy(c.Next.Policy, task.SelectionContext{}); err != nil {
+			return fmt.Errorf("next.policy: %w", err)
+		}
+	}
+	if c.Next.AgingInterval < 0 {
+		return fmt.Errorf("next.aging_interval must not be negative")
+	}
+	if c.Next.ClaimTimeout < 0 {
+		return fmt.Errorf("next.claim_timeout must not be negative")
+	}
+	if c.WIP.Agent < 0 {
+		return fmt.Errorf("wip.agent must not be negative")
+	}
+	for role, limit := range c.WIP.Roles {
+		if limit < 0 {
+			return fmt.Errorf("wip.roles.%s must not be negative", role)
+		}
+	}
+	for agent, limit := range c.WIP.Agents {
+		if limit < 0 {
+			return fmt.Errorf("wip.agents.%s must not be negative", agent)
+		}
+	}
+	for i, source := range c.Preset.Sources {
+		if strings.TrimSpace(source) == "" {
+			return fmt.Errorf("preset.sources[%d] must not be empty", i)
+		}
+	}
+	switch c.Sync.Provider {
+	case "", "github":
+	default:
+		return fmt.Errorf("sync.provider: unknown provider %q (expected github)", c.Sync.Provider)
+	}
+	if repo := c.Sync.Repo; repo != "" {
+		if owner, name, ok := strings.Cut(repo, "/"); !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+			return fmt.Errorf("sync.repo: invalid repository %q (expected owner/name)", repo)
+		}
+	}
+	return c.Fields.Validate()
+}
+
+// IDPrefixFor returns the ID prefix for a template. When several IDPrefixes
+// keys occur in the name, the longest wins, then the alphabetically first.
+func (c AddConfig) IDPrefixFor(templateName string) string {
+	name := strings.ToLower(templateName)
+	keys := make([]string, 0, len(c.IDPrefixes))
+	for key := range c.IDPrefixes {
+		if key != "" && strings.Contains(name, strings.ToLower(key)) {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return c.DefaultIDPrefix
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+	return c.IDPrefixes[keys[0]]
+}
+
+// ResolveIDPrefix returns the ID prefix for new tasks from a template. The
+// template's id_prefix wins; otherwise IDPrefixFor decides.
+func (c AddConfig) ResolveIDPrefix(templateName, templatePrefix string) (string, error) {
+	prefix := strings.TrimSpace(templatePrefix)
+	if prefix == "" {
+		return c.IDPrefixFor(templateName), nil
+	}
+	if !task.IsValidIDPrefix(prefix) {
+		return "", fmt.Errorf("template %s has invalid id_prefix %q (expected 1-5 uppercase letters)", templateName, prefix)
+	}
+	return prefix, nil
+}
+
+// IsPlaceholderTitle reports whether title matches a placeholder title, ignoring case.
+func (c AddConfig) IsPlaceholderTitle(title string) bool {
+	for _, placeholder := range c.PlaceholderTitles {
+		if strings.EqualFold(strings.TrimSpace(title), placeholder) {
+			return true
+		}
+	}
+	return false
+}
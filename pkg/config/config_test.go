@@ -0,0 +1,139 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfigFile(t *testing.T, dir, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, FileName), []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+}
+
+func TestLoadLayerPrecedence(t *testing.T) {
+	userDir := t.TempDir()
+	projectDir := t.TempDir()
+	writeConfigFile(t, userDir, "list:\n  format: md\nnext:\n  policy: oldest\n  claim_timeout: 2h\n")
+	writeConfigFile(t, projectDir, "next:\n  policy: aging\nwip:\n  roles:\n    developer: 2\n")
+	t.Setenv("STRAND_NEXT_CLAIM_TIMEOUT", "15m")
+	t.Setenv("STRAND_ADD_PLACEHOLDER_TITLES", "tbd, fixme")
+
+	cfg, err := Load(userDir, projectDir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.List.Format != "md" {
+		t.Errorf("list.format = %q, want user value md", cfg.List.Format)
+	}
+	if cfg.Next.Policy != "aging" {
+		t.Errorf("next.policy = %q, want project value aging", cfg.Next.Policy)
+	}
+	if cfg.Next.ClaimTimeout != 15*time.Minute {
+		t.Errorf("next.claim_timeout = %v, want env value 15m", cfg.Next.ClaimTimeout)
+	}
+	if strings.Join(cfg.Add.PlaceholderTitles, ",") != "tbd,fixme" {
+		t.Errorf("add.placeholder_titles = %v", cfg.Add.PlaceholderTitles)
+	}
+	if cfg.Init.Storage != "global" || cfg.Add.IDPrefixFor("epic") != "E" {
+		t.Errorf("expected defaults to survive layering: %+v", cfg)
+	}
+	if cfg.WIP.Roles["developer"] != 2 {
+		t.Errorf("wip.roles.developer = %d, want 2", cfg.WIP.Roles["developer"])
+	}
+}
+
+func TestLoadRejectsInvalidConfig(t *testing.T) {
+	cases := map[string]string{
+		"unknown key":    "next:\n  polcy: oldest\n",
+		"bad policy":     "next:\n  policy: random\n",
+		"bad type":       "wip:\n  agent: lots\n",
+		"bad prefix":     "add:\n  default_id_prefix: t\n",
+		"emptied prefix": "add:\n  default_id_prefix: \"\"\n",
+	}
+	for name, content := range cases {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeConfigFile(t, dir, content)
+			if _, err := Load("", dir); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestSetGetAndValidate(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigFile(t, dir, "# project settings\nlist:\n  format: md\n")
+
+	if err := Set(dir, "wip.roles.reviewer", "2"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := Set(dir, "add.placeholder_titles", "tbd,wip"); err != nil {
+		t.Fatalf("Set list failed: %v", err)
+	}
+	if err := Set(dir, "list.format", "xml"); err == nil {
+		t.Fatal("expected invalid format to be rejected")
+	}
+	if err := Set(dir, "next.nope", "1"); err == nil || !strings.Contains(err.Error(), "unknown config key") {
+		t.Fatalf("expected unknown key error, got %v", err)
+	}
+	if err := Set(dir, "wip", "1"); err == nil {
+		t.Fatal("expected section key to be rejected")
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, FileName))
+	if err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+	if !strings.Contains(string(data), "# project settings") || !strings.Contains(string(data), "format: md") {
+		t.Fatalf("expected existing content to be preserved:\n%s", data)
+	}
+
+	layers, err := LoadLayers("", dir)
+	if err != nil {
+		t.Fatalf("LoadLayers failed: %v", err)
+	}
+	if errs := ValidateLayers(layers); len(errs) != 0 {
+		t.Fatalf("unexpected validation errors: %v", errs)
+	}
+	cfg, err := Merge(layers)
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	for key, want := range map[string]string{
+		"wip.roles.reviewer":     "2",
+		"add.placeholder_titles": "tbd,wip",
+		"list.format":            "md",
+		"next.claim_timeout":     "1h0m0s",
+	} {
+		got, err := Get(cfg, key)
+		if err != nil {
+			t.Fatalf("Get(%s) failed: %v", key, err)
+		}
+		if got != want {
+			t.Errorf("Get(%s) = %q, want %q", key, got, want)
+		}
+	}
+	if !layers[1].Has("wip.roles.reviewer") || layers[1].Has("next.policy") {
+		t.Errorf("unexpected Has results for project layer")
+	}
+}
+
+func TestIDPrefixFor(t *testing.T) {
+	add := AddConfig{DefaultIDPrefix: "T", IDPrefixes: map[string]string{"epic": "E", "bug": "B", "epic-bug": "X"}}
+	for name, want := range map[string]string{
+		"task":     "T",
+		"Epic":     "E",
+		"bug":      "B",
+		"epic-bug": "X",
+	} {
+		if got := add.IDPrefixFor(name); got != want {
+			t.Errorf("IDPrefixFor(%s) = %s, want %s", name, got, want)
+		}
+	}
+}
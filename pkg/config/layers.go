@@ -0,0 +1,448 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix prefixes the environment variable for every scalar and list key:
+// next.claim_timeout is read from STRAND_NEXT_CLAIM_TIMEOUT. List values are
+// comma-separated. Map keys cannot be set from the environment.
+const EnvPrefix = "STRAND_"
+
+// Names of the configuration layers, lowest precedence first.
+const (
+	LayerDefault = "default"
+	LayerUser    = "user"
+	LayerProject = "project"
+	LayerEnv     = "env"
+)
+
+// Layer is one source of configuration values.
+type Layer struct {
+	// Name is one of the Layer* constants.
+	Name string
+	// Path is the file backing the layer; empty for the default and env layers.
+	Path string
+	// root is the layer's top-level mapping node, or nil if the layer is empty.
+	root *yaml.Node
+}
+
+// Has reports whether the layer sets key.
+func (l Layer) Has(key string) bool {
+	return lookupNode(l.root, strings.Split(key, ".")) != nil
+}
+
+// UserDir returns the strand config dir: $STRAND_CONFIG_DIR, or
+// ~/.config/strand when it is unset.
+func UserDir() (string, error) {
+	if dir := os.Getenv("STRAND_CONFIG_DIR"); dir != "" {
+		return dir, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("unable to resolve home directory: %w", err)
+	}
+	return filepath.Join(home, ".config", "strand"), nil
+}
+
+// Load merges the default config, <userDir>/strand.yaml, <projectDir>/strand.yaml
+// and STRAND_* environment variables, later layers overriding earlier ones.
+// Empty dirs and missing files are skipped.
+func Load(userDir, projectDir string) (Config, error) {
+	layers, err := LoadLayers(userDir, projectDir)
+	if err != nil {
+		return Config{}, err
+	}
+	return Merge(layers)
+}
+
+// LoadLayers reads every configuration layer without merging them.
+func LoadLayers(userDir, projectDir string) ([]Layer, error) {
+	defaults, err := encodeNode(Default())
+	if err != nil {
+		return nil, err
+	}
+	layers := []Layer{{Name: LayerDefault, root: defaults}}
+
+	for _, file := range []struct{ name, dir string }{{LayerUser, userDir}, {LayerProject, projectDir}} {
+		if file.dir == "" {
+			continue
+		}
+		layer, err := readFileLayer(file.name, filepath.Join(file.dir, FileName))
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, layer)
+	}
+
+	env, err := envLayer(os.LookupEnv)
+	if err != nil {
+		return nil, err
+	}
+	return append(layers, env), nil
+}
+
+// Merge applies layers in order and validates the result.
+func Merge(layers []Layer) (Config, error) {
+	var cfg Config
+	for _, layer := range layers {
+		if layer.root == nil {
+			continue
+		}
+		if err := decodeStrict(layer.root, &cfg); err != nil {
+			return Config{}, fmt.Errorf("%s: %w", layer.describe(), err)
+		}
+	}
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+	switch {
+	case cfg.Init.Storage == "":
+		return Config{}, fmt.Errorf("init.storage must not be empty")
+	case cfg.Add.DefaultIDPrefix == "":
+		return Config{}, fmt.Errorf("add.default_id_prefix must not be empty")
+	case cfg.List.Format == "":
+		return Config{}, fmt.Errorf("list.format must not be empty")
+	case cfg.Next.ClaimTimeout == 0:
+		return Config{}, fmt.Errorf("next.claim_timeout must be greater than 0")
+	}
+	return cfg, nil
+}
+
+// ValidateLayers checks each layer on its own and returns every problem found.
+func ValidateLayers(layers []Layer) []error {
+	var errs []error
+	for _, layer := range layers {
+		if layer.root == nil {
+			continue
+		}
+		var cfg Config
+		if err := decodeStrict(layer.root, &cfg); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", layer.describe(), err))
+			continue
+		}
+		if err := cfg.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", layer.describe(), err))
+		}
+	}
+	if len(errs) == 0 {
+		if _, err := Merge(layers); err != nil {
+			errs = append(errs, fmt.Errorf("merged config: %w", err))
+		}
+	}
+	return errs
+}
+
+// Flatten returns the config as sorted dotted keys mapped to display values.
+// Lists are comma-separated.
+func Flatten(cfg Config) (map[string]string, []string, error) {
+	root, err := encodeNode(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	values := make(map[string]string)
+	flattenNode(root, "", values)
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return values, keys, nil
+}
+
+// Get returns the value of key in cfg. Section keys such as "wip" return
+// the section as YAML.
+func Get(cfg Config, key string) (string, error) {
+	if _, err := fieldType(key); err != nil {
+		return "", err
+	}
+	root, err := encodeNode(cfg)
+	if err != nil {
+		return "", err
+	}
+	node := lookupNode(root, strings.Split(key, "."))
+	if node == nil {
+		return "", nil
+	}
+	if node.Kind == yaml.ScalarNode {
+		return node.Value, nil
+	}
+	if node.Kind == yaml.SequenceNode {
+		return joinSequence(node), nil
+	}
+	out, err := yaml.Marshal(node)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(out), "\n"), nil
+}
+
+// Set writes key=value into the strand.yaml in dir, creating the file if
+// needed. The file is checked against the schema before it is written.
+// List values are comma-separated.
+func Set(dir, key, value string) error {
+	typ, err := fieldType(key)
+	if err != nil {
+		return err
+	}
+	if typ.Kind() == reflect.Struct || typ.Kind() == reflect.Map {
+		return fmt.Errorf("%s is a section; set one of its keys instead", key)
+	}
+
+	path := filepath.Join(dir, FileName)
+	layer, err := readFileLayer(LayerProject, path)
+	if err != nil {
+		return err
+	}
+	root := layer.root
+	if root == nil {
+		root = &yaml.Node{Kind: yaml.MappingNode}
+	}
+	setNode(root, strings.Split(key, "."), valueNode(typ, value))
+
+	var cfg Config
+	if err := decodeStrict(root, &cfg); err != nil {
+		return fmt.Errorf("invalid value for %s: %w", key, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(root); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", path, err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// EnvVar returns the environment variable that overrides key.
+func EnvVar(key string) string {
+	return EnvPrefix + strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(key))
+}
+
+func (l Layer) describe() string {
+	if l.Path != "" {
+		return l.Path
+	}
+	return l.Name + " config"
+}
+
+func readFileLayer(name, path string) (Layer, error) {
+	layer := Layer{Name: name, Path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return layer, nil
+	}
+	if err != nil {
+		return Layer{}, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return Layer{}, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if len(doc.Content) == 0 {
+		return layer, nil
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return Layer{}, fmt.Errorf("failed to parse %s: top level must be a mapping", path)
+	}
+	layer.root = doc.Content[0]
+	return layer, nil
+}
+
+// envLayer builds a layer from every set STRAND_* variable that names a
+// scalar or list key.
+func envLayer(lookup func(string) (string, bool)) (Layer, error) {
+	layer := Layer{Name: LayerEnv}
+	for _, key := range scalarKeys(reflect.TypeOf(Config{}), "") {
+		value, ok := lookup(EnvVar(key))
+		if !ok {
+			continue
+		}
+		if layer.root == nil {
+			layer.root = &yaml.Node{Kind: yaml.MappingNode}
+		}
+		typ, err := fieldType(key)
+		if err != nil {
+			return Layer{}, err
+		}
+		setNode(layer.root, strings.Split(key, "."), valueNode(typ, value))
+	}
+	return layer, nil
+}
+
+// scalarKeys lists the dotted keys of every non-struct, non-map field of typ.
+func scalarKeys(typ reflect.Type, prefix string) []string {
+	var keys []string
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		key := prefix + yamlName(field)
+		switch field.Type.Kind() {
+		case reflect.Struct:
+			keys = append(keys, scalarKeys(field.Type, key+".")...)
+		case reflect.Map:
+		default:
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// fieldType resolves a dotted key against the Config schema. Map fields
+// accept any key below them.
+func fieldType(key string) (reflect.Type, error) {
+	typ := reflect.TypeOf(Config{})
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		switch typ.Kind() {
+		case reflect.Struct:
+			field, ok := structField(typ, part)
+			if !ok {
+				return nil, fmt.Errorf("unknown config key: %s", key)
+			}
+			typ = field.Type
+		case reflect.Map:
+			if part == "" {
+				return nil, fmt.Errorf("unknown config key: %s", key)
+			}
+			typ = typ.Elem()
+		default:
+			return nil, fmt.Errorf("unknown config key: %s (%s is not a section)", key, strings.Join(parts[:i], "."))
+		}
+	}
+	return typ, nil
+}
+
+func structField(typ reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < typ.NumField(); i++ {
+		if yamlName(typ.Field(i)) == name {
+			return typ.Field(i), true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func yamlName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	if name == "" {
+		return strings.ToLower(field.Name)
+	}
+	return name
+}
+
+// valueNode builds the YAML node for a string value of the given type.
+func valueNode(typ reflect.Type, value string) *yaml.Node {
+	if typ.Kind() == reflect.Slice {
+		seq := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				seq.Content = append(seq.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: item})
+			}
+		}
+		return seq
+	}
+	node := &yaml.Node{Kind: yaml.ScalarNode, Value: value}
+	if typ.Kind() == reflect.String {
+		node.Tag = "!!str"
+	}
+	return node
+}
+
+func lookupNode(node *yaml.Node, path []string) *yaml.Node {
+	for _, part := range path {
+		if node == nil || node.Kind != yaml.MappingNode {
+			return nil
+		}
+		var next *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == part {
+				next = node.Content[i+1]
+			}
+		}
+		node = next
+	}
+	return node
+}
+
+func setNode(node *yaml.Node, path []string, value *yaml.Node) {
+	for i, part := range path {
+		var next *yaml.Node
+		for j := 0; j+1 < len(node.Content); j += 2 {
+			if node.Content[j].Value == part {
+				next = node.Content[j+1]
+			}
+		}
+		last := i == len(path)-1
+		if next == nil {
+			next = value
+			if !last {
+				next = &yaml.Node{Kind: yaml.MappingNode}
+			}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: part}, next)
+		} else if last {
+			*next = *value
+		} else if next.Kind != yaml.MappingNode {
+			*next = yaml.Node{Kind: yaml.MappingNode}
+		}
+		node = next
+	}
+}
+
+func flattenNode(node *yaml.Node, prefix string, out map[string]string) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			flattenNode(node.Content[i+1], prefix+node.Content[i].Value+".", out)
+		}
+	case yaml.SequenceNode:
+		out[strings.TrimSuffix(prefix, ".")] = joinSequence(node)
+	default:
+		if node.Tag != "!!null" {
+			out[strings.TrimSuffix(prefix, ".")] = node.Value
+		}
+	}
+}
+
+func joinSequence(node *yaml.Node) string {
+	items := make([]string, 0, len(node.Content))
+	for _, item := range node.Content {
+		items = append(items, item.Value)
+	}
+	return strings.Join(items, ",")
+}
+
+func encodeNode(cfg Config) (*yaml.Node, error) {
+	var node yaml.Node
+	if err := node.Encode(cfg); err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	return &node, nil
+}
+
+// decodeStrict decodes node into out, rejecting keys the schema does not know.
+func decodeStrict(node *yaml.Node, out *Config) error {
+	data, err := yaml.Marshal(node)
+	if err != nil {
+		return err
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	return dec.Decode(out)
+}
@@ -0,0 +1,379 @@
+// Package create implements task creation shared by the CLI, the MCP server
+// and the web API, so every front end validates, names and links new tasks
+// the same way.
+package create
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ricochet1k/strandyard/pkg/activity"
+	"github.com/ricochet1k/strandyard/pkg/config"
+	"github.com/ricochet1k/strandyard/pkg/idgen"
+	rPkg "github.com/ricochet1k/strandyard/pkg/role"
+	"github.com/ricochet1k/strandyard/pkg/task"
+	"github.com/ricochet1k/strandyard/pkg/template"
+	"gopkg.in/yaml.v3"
+)
+
+// Project locates the directories of the project a task is created in.
+type Project struct {
+	BaseDir      string
+	TasksDir     string
+	TemplatesDir string
+	RolesDir     string
+	// Remote resolves project:TaskID parents and blockers. When nil, such
+	// references are rejected.
+	Remote *task.RemoteTasks
+}
+
+// Request describes a task to create. Empty Role and Priority fall back to
+// the template's frontmatter, then to medium priority.
+type Request struct {
+	TemplateName string
+	Title        string
+	Role         string
+	Priority     string
+	Parent       string
+	Blockers     []string
+	Blocks       []string
+	Every        []string
+	Vars         map[string]string
+	// Fields sets custom fields declared in the config's fields section.
+	Fields map[string]string
+	// Extra sets frontmatter fields as given, without checking them against
+	// the config.
+	Extra map[string]interface{}
+	// Created overrides the creation date; the zero value means now.
+	Created time.Time
+	Body    string
+}
+
+// Result describes a created task.
+type Result struct {
+	ID       string
+	Path     string
+	Parent   string
+	Blockers []string
+	Blocks   []string
+	Every    []string
+}
+
+// UnknownTemplateError is returned when the requested template does not exist.
+type UnknownTemplateError struct {
+	Name      string
+	Templates map[string]*template.Template
+}
+
+func (e *UnknownTemplateError) Error() string {
+	return fmt.Sprintf("unknown type %q", e.Name)
+}
+
+// UnknownRoleError is returned when the resolved role does not exist.
+type UnknownRoleError struct {
+	Name  string
+	Roles map[string]*task.Task
+}
+
+func (e *UnknownRoleError) Error() string {
+	return fmt.Sprintf("invalid role %q", e.Name)
+}
+
+// Task validates req, writes the new task file, links blockers and the
+// parent's TODO list, and records recurrence anchors in the activity log.
+func Task(project Project, cfg config.Config, req Request) (*Result, error) {
+	db := task.NewTaskDB(project.TasksDir)
+	if err := db.LoadAllIfEmpty(); err != nil {
+		return nil, err
+	}
+	db.SetRemote(project.Remote)
+
+	tmplName := strings.TrimSpace(req.TemplateName)
+	if tmplName == "" {
+		return nil, fmt.Errorf("type is required")
+	}
+
+	templates, err := template.LoadTemplates(project.TemplatesDir)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, ok := templates[tmplName]
+	if !ok {
+		return nil, &UnknownTemplateError{Name: tmplName, Templates: templates}
+	}
+
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		return nil, fmt.Errorf("title is required")
+	}
+	// Reject placeholder titles that indicate the template wasn't properly filled in
+	if cfg.Add.IsPlaceholderTitle(title) {
+		return nil, fmt.Errorf("title %q looks like a placeholder; please provide a descriptive title", title)
+	}
+
+	every, err := task.ValidateEvery(req.Every, project.BaseDir, db.GetAll())
+	if err != nil {
+		return nil, err
+	}
+	vars, err := tmpl.ResolveVars(req.Vars, db.ResolveID)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := cfg.Fields.ParseValues(tmplName, req.Fields)
+	if err != nil {
+		return nil, err
+	}
+
+	roleName := strings.TrimSpace(req.Role)
+	if roleName == "" {
+		roleName = strings.TrimSpace(tmpl.Meta.Role)
+	}
+	if roleName == "" {
+		return nil, fmt.Errorf("role is required (set role in the request or in template frontmatter)")
+	}
+	roles, err := rPkg.LoadRoles(project.RolesDir)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := roles[roleName]; !ok {
+		return nil, &UnknownRoleError{Name: roleName, Roles: roles}
+	}
+
+	priority := strings.TrimSpace(req.Priority)
+	if priority == "" {
+		if pStr, ok := tmpl.Meta.Priority.(string); ok {
+			priority = pStr
+		}
+	}
+	priority = task.NormalizePriority(priority)
+	if !task.IsValidPriority(priority) {
+		return nil, fmt.Errorf("invalid priority: %s", priority)
+	}
+
+	parent := strings.TrimSpace(req.Parent)
+	if parent != "" {
+		resolvedParent, err := db.ResolveID(parent)
+		if err != nil {
+			return nil, fmt.Errorf("parent task %s does not exist: %w", parent, err)
+		}
+		parent = resolvedParent
+	}
+
+	blockers, err := db.ResolveIDs(NormalizeTaskIDs(req.Blockers))
+	if err != nil {
+		return nil, err
+	}
+	blocks, err := db.ResolveIDs(NormalizeTaskIDs(req.Blocks))
+	if err != nil {
+		return nil, err
+	}
+
+	prefix, err := cfg.Add.ResolveIDPrefix(tmplName, tmpl.Meta.IDPrefix)
+	if err != nil {
+		return nil, err
+	}
+	id, err := idgen.GenerateID(prefix, title)
+	if err != nil {
+		return nil, err
+	}
+	taskFile := filepath.Join(project.TasksDir, id+".md")
+	if _, err := os.Stat(taskFile); err == nil {
+		return nil, fmt.Errorf("task file already exists: %s", taskFile)
+	}
+
+	for name, value := range req.Extra {
+		if fields == nil {
+			fields = map[string]interface{}{}
+		}
+		fields[name] = value
+	}
+
+	now := time.Now().UTC()
+	created := now
+	if !req.Created.IsZero() {
+		created = req.Created.UTC()
+	}
+	meta := task.Metadata{
+		Type:          tmplName,
+		Role:          roleName,
+		Priority:      priority,
+		Parent:        parent,
+		Blockers:      []string{},
+		Blocks:        []string{},
+		DateCreated:   created,
+		DateEdited:    now,
+		OwnerApproval: false,
+		Completed:     false,
+		Every:         every,
+		Vars:          nonEmptyVars(vars),
+		Fields:        fields,
+	}
+	body, err := tmpl.Render(template.RenderData{
+		ID:                  id,
+		Title:               title,
+		Body:                req.Body,
+		SuggestedSubtaskDir: fmt.Sprintf("%s-subtask", id),
+		Role:                roleName,
+		Priority:            priority,
+		Parent:              parent,
+		Vars:                vars,
+		Now:                 now,
+	}, template.Helpers{
+		ParentTitle: func() string {
+			if p, err := db.Get(parent); err == nil {
+				return p.Title()
+			}
+			return ""
+		},
+		RoleDoc: func(name string) (string, error) {
+			r, ok := roles[name]
+			if !ok {
+				return "", fmt.Errorf("unknown role %q", name)
+			}
+			return strings.TrimSpace(r.BodyContent), nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if req.Body != "" && !tmpl.UsesField("Body") {
+		if strings.TrimSpace(body) != "" {
+			body += "\n\n"
+		}
+		body += req.Body
+	}
+	if err := WriteTaskFile(taskFile, meta, body); err != nil {
+		return nil, err
+	}
+
+	if len(blockers) > 0 || len(blocks) > 0 || parent != "" {
+		if _, err := db.Load(id); err != nil {
+			return nil, fmt.Errorf("failed to load new task: %w", err)
+		}
+	}
+	for _, blockerID := range blockers {
+		if err := db.AddBlocker(id, blockerID); err != nil {
+			return nil, fmt.Errorf("failed to add blocker %s: %w", blockerID, err)
+		}
+	}
+	for _, blockedID := range blocks {
+		if err := db.AddBlocked(id, blockedID); err != nil {
+			return nil, fmt.Errorf("failed to add blocked %s: %w", blockedID, err)
+		}
+	}
+	if parent != "" && !task.IsRemoteRef(parent) {
+		if _, err := db.UpdateParentTodos(parent); err != nil {
+			return nil, fmt.Errorf("failed to update parent task TODO entries: %w", err)
+		}
+	}
+	if _, err := db.SaveDirty(); err != nil {
+		return nil, fmt.Errorf("failed to write task updates: %w", err)
+	}
+
+	logRecurrenceAnchors(project.BaseDir, id, every, now)
+
+	return &Result{
+		ID:       id,
+		Path:     taskFile,
+		Parent:   parent,
+		Blockers: blockers,
+		Blocks:   blocks,
+		Every:    every,
+	}, nil
+}
+
+// nonEmptyVars returns the vars worth recording in task metadata.
+func nonEmptyVars(vars map[string]string) map[string]string {
+	out := make(map[string]string, len(vars))
+	for name, value := range vars {
+		if value != "" {
+			out[name] = value
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// logRecurrenceAnchors records how implicit "now" and HEAD anchors resolved
+// at creation time. Logging is best effort and never fails creation.
+func logRecurrenceAnchors(baseDir, id string, every []string, now time.Time) {
+	if len(every) == 0 {
+		return
+	}
+	activeLog, err := activity.Open(baseDir)
+	if err != nil {
+		return
+	}
+	defer activeLog.Close()
+	for _, rule := range every {
+		parts := strings.Fields(rule)
+		if len(parts) < 2 {
+			continue
+		}
+		metric := parts[1]
+		anchor := ""
+		if len(parts) >= 4 && parts[2] == "from" {
+			anchor = strings.Join(parts[3:], " ")
+		}
+
+		if metric == "commits" || metric == "lines_changed" {
+			if anchor == "HEAD" || anchor == "" {
+				if resolved, err := task.ResolveGitHash(baseDir, "HEAD"); err == nil {
+					_ = activeLog.WriteRecurrenceAnchorResolution(id, "HEAD", resolved)
+				}
+			}
+		} else if anchor == "now" || anchor == "" {
+			_ = activeLog.WriteRecurrenceAnchorResolution(id, "now", now.Format("Jan 2 2006 15:04 MST"))
+		}
+	}
+}
+
+// NormalizeTaskIDs splits comma-separated IDs, trims and de-duplicates them,
+// and returns them sorted.
+func NormalizeTaskIDs(items []string) []string {
+	seen := map[string]struct{}{}
+	var out []string
+	for _, item := range items {
+		parts := strings.Split(item, ",")
+		for _, part := range parts {
+			trimmed := strings.TrimSpace(part)
+			if trimmed == "" {
+				continue
+			}
+			if _, ok := seen[trimmed]; ok {
+				continue
+			}
+			seen[trimmed] = struct{}{}
+			out = append(out, trimmed)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// WriteTaskFile writes a task file with YAML frontmatter followed by body.
+func WriteTaskFile(path string, meta task.Metadata, body string) error {
+	frontmatterBytes, err := yaml.Marshal(&meta)
+	if err != nil {
+		return fmt.Errorf("failed to marshal frontmatter: %w", err)
+	}
+	frontmatterBytes = bytes.TrimSpace(frontmatterBytes)
+
+	var sb strings.Builder
+	sb.WriteString("---\n")
+	sb.Write(frontmatterBytes)
+	sb.WriteString("\n---\n\n")
+	sb.WriteString(body)
+	if !strings.HasSuffix(body, "\n") {
+		sb.WriteString("\n")
+	}
+
+	return os.WriteFile(path, []byte(sb.String()), 0o644)
+}
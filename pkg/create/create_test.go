@@ -0,0 +1,125 @@
+package create
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ricochet1k/strandyard/pkg/config"
+	"github.com/ricochet1k/strandyard/pkg/task"
+)
+
+func setupProject(t *testing.T) Project {
+	t.Helper()
+	base := t.TempDir()
+	project := Project{
+		BaseDir:      base,
+		TasksDir:     filepath.Join(base, "tasks"),
+		TemplatesDir: filepath.Join(base, "templates"),
+		RolesDir:     filepath.Join(base, "roles"),
+	}
+	for _, dir := range []string{project.TasksDir, project.TemplatesDir, project.RolesDir} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("mkdir %s: %v", dir, err)
+		}
+	}
+	writeFile(t, filepath.Join(project.RolesDir, "developer.md"), "# developer\n\nwrites code\n")
+	writeFile(t, filepath.Join(project.TemplatesDir, "task.md"), "---\nrole: developer\npriority: low\n---\n\n# {{ .Title }}\n\n{{ .Body }}\n")
+	return project
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func TestTaskCreatesLinkedTask(t *testing.T) {
+	project := setupProject(t)
+	cfg := config.Default()
+
+	parent, err := Task(project, cfg, Request{TemplateName: "task", Title: "Parent work"})
+	if err != nil {
+		t.Fatalf("create parent: %v", err)
+	}
+	blocker, err := Task(project, cfg, Request{TemplateName: "task", Title: "Blocker work", Priority: "high"})
+	if err != nil {
+		t.Fatalf("create blocker: %v", err)
+	}
+	child, err := Task(project, cfg, Request{
+		TemplateName: "task",
+		Title:        "Child work",
+		Parent:       task.ShortID(parent.ID),
+		Blockers:     []string{task.ShortID(blocker.ID)},
+		Body:         "details here",
+	})
+	if err != nil {
+		t.Fatalf("create child: %v", err)
+	}
+	if child.Parent != parent.ID {
+		t.Fatalf("expected parent %s to be resolved, got %s", parent.ID, child.Parent)
+	}
+
+	db := task.NewTaskDB(project.TasksDir)
+	if err := db.LoadAll(); err != nil {
+		t.Fatalf("LoadAll: %v", err)
+	}
+	created, err := db.Get(child.ID)
+	if err != nil {
+		t.Fatalf("get child: %v", err)
+	}
+	if created.Meta.Priority != task.PriorityLow || created.Meta.Role != "developer" {
+		t.Errorf("expected template defaults, got role %q priority %q", created.Meta.Role, created.Meta.Priority)
+	}
+	if len(created.Meta.Blockers) != 1 || created.Meta.Blockers[0] != blocker.ID {
+		t.Errorf("expected blocker %s, got %v", blocker.ID, created.Meta.Blockers)
+	}
+	if !strings.Contains(created.BodyContent, "details here") {
+		t.Errorf("expected body to be rendered, got %q", created.BodyContent)
+	}
+	parentData, err := os.ReadFile(parent.Path)
+	if err != nil {
+		t.Fatalf("read parent: %v", err)
+	}
+	if !strings.Contains(string(parentData), task.ShortID(child.ID)) {
+		t.Errorf("expected parent TODOs to list %s:\n%s", child.ID, parentData)
+	}
+}
+
+func TestTaskRejectsInvalidRequests(t *testing.T) {
+	project := setupProject(t)
+	cfg := config.Default()
+
+	_, err := Task(project, cfg, Request{TemplateName: "missing", Title: "Something"})
+	var tmplErr *UnknownTemplateError
+	if !errors.As(err, &tmplErr) || tmplErr.Templates["task"] == nil {
+		t.Fatalf("expected UnknownTemplateError listing templates, got %v", err)
+	}
+
+	_, err = Task(project, cfg, Request{TemplateName: "task", Title: "Something", Role: "nobody"})
+	var roleErr *UnknownRoleError
+	if !errors.As(err, &roleErr) || roleErr.Roles["developer"] == nil {
+		t.Fatalf("expected UnknownRoleError listing roles, got %v", err)
+	}
+
+	if _, err := Task(project, cfg, Request{TemplateName: "task", Title: "Todo"}); err == nil || !strings.Contains(err.Error(), "placeholder") {
+		t.Fatalf("expected placeholder error, got %v", err)
+	}
+
+	_, err = Task(project, cfg, Request{TemplateName: "task", Title: "Recurring", Every: []string{"10 hours"}})
+	var ruleErr *task.RecurrenceRuleError
+	if !errors.As(err, &ruleErr) {
+		t.Fatalf("expected RecurrenceRuleError, got %v", err)
+	}
+
+	entries, err := os.ReadDir(project.TasksDir)
+	if err != nil {
+		t.Fatalf("read tasks dir: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("expected rejected requests to write nothing, found %d files", len(entries))
+	}
+}
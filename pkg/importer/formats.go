@@ -0,0 +1,425 @@
+package importer
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Names of the supported export formats.
+const (
+	FormatGitHub      = "github"
+	FormatTaskwarrior = "taskwarrior"
+	FormatTodoTxt     = "todotxt"
+	FormatCSV         = "csv"
+)
+
+// Formats lists the supported export formats.
+var Formats = []string{FormatGitHub, FormatTaskwarrior, FormatTodoTxt, FormatCSV}
+
+// CSVFields are the item fields a CSV column can be mapped to. Blockers and
+// labels are split on commas, semicolons and whitespace.
+var CSVFields = []string{"id", "title", "body", "priority", "role", "status", "completed", "parent", "blockers", "labels", "created"}
+
+// Parse reads an export in the named format. mapping is only used for CSV;
+// see ParseCSV.
+func Parse(format string, r io.Reader, mapping map[string]string) ([]Item, error) {
+	switch format {
+	case FormatGitHub:
+		return ParseGitHub(r)
+	case FormatTaskwarrior:
+		return ParseTaskwarrior(r)
+	case FormatTodoTxt:
+		return ParseTodoTxt(r)
+	case FormatCSV:
+		return ParseCSV(r, mapping)
+	default:
+		return nil, fmt.Errorf("invalid format %q (expected %s)", format, strings.Join(Formats, ", "))
+	}
+}
+
+// githubLabel accepts both label objects and plain label names.
+type githubLabel string
+
+func (l *githubLabel) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*l = githubLabel(name)
+		return nil
+	}
+	var obj struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+	*l = githubLabel(obj.Name)
+	return nil
+}
+
+type githubIssue struct {
+	Number      int             `json:"number"`
+	Title       string          `json:"title"`
+	Body        string          `json:"body"`
+	State       string          `json:"state"`
+	Labels      []githubLabel   `json:"labels"`
+	HTMLURL     string          `json:"html_url"`
+	URL         string          `json:"url"`
+	CreatedAt   string          `json:"created_at"`
+	CreatedAtGH string          `json:"createdAt"`
+	PullRequest json.RawMessage `json:"pull_request"`
+}
+
+var (
+	githubBlockedBy = regexp.MustCompile(`(?i)\b(?:blocked by|depends on)\b:?((?:[\s,]*(?:and\s+)?#\d+)+)`)
+	githubParent    = regexp.MustCompile(`(?i)\b(?:part of|parent:?)\s+#(\d+)`)
+	githubIssueRef  = regexp.MustCompile(`#(\d+)`)
+)
+
+// ParseGitHub reads a JSON array of issues as written by the REST API or by
+// `gh issue list --json number,title,body,state,labels,url,createdAt`. Pull
+// requests are skipped. "Blocked by #N" or "Depends on #N" in the body become
+// blockers and "Part of #N" becomes the parent; labels such as
+// "priority: high" or "P1" set the priority.
+func ParseGitHub(r io.Reader) ([]Item, error) {
+	var issues []githubIssue
+	if err := json.NewDecoder(r).Decode(&issues); err != nil {
+		return nil, fmt.Errorf("invalid GitHub issues JSON: %w", err)
+	}
+	items := make([]Item, 0, len(issues))
+	for _, issue := range issues {
+		if len(issue.PullRequest) > 0 && string(issue.PullRequest) != "null" {
+			continue
+		}
+		it := Item{
+			Source:    FormatGitHub,
+			SourceID:  strconv.Itoa(issue.Number),
+			URL:       firstNonEmpty(issue.HTMLURL, githubWebURL(issue.URL)),
+			Title:     strings.TrimSpace(issue.Title),
+			Body:      issue.Body,
+			Completed: strings.EqualFold(issue.State, "closed"),
+			Created:   parseTime(firstNonEmpty(issue.CreatedAt, issue.CreatedAtGH)),
+		}
+		for _, label := range issue.Labels {
+			if p := labelPriority(string(label)); p != "" {
+				it.Priority = p
+				continue
+			}
+			it.Labels = append(it.Labels, string(label))
+		}
+		for _, m := range githubBlockedBy.FindAllStringSubmatch(issue.Body, -1) {
+			for _, ref := range githubIssueRef.FindAllStringSubmatch(m[1], -1) {
+				it.Blockers = append(it.Blockers, ref[1])
+			}
+		}
+		if m := githubParent.FindStringSubmatch(issue.Body); m != nil {
+			it.Parent = m[1]
+		}
+		items = append(items, it)
+	}
+	return items, nil
+}
+
+// githubWebURL turns an API issue URL into its web URL; web URLs, as written
+// by gh, are returned unchanged.
+func githubWebURL(u string) string {
+	if rest, ok := strings.CutPrefix(u, "https://api.github.com/repos/"); ok {
+		return "https://github.com/" + rest
+	}
+	return u
+}
+
+type taskwarriorTask struct {
+	UUID        string          `json:"uuid"`
+	Description string          `json:"description"`
+	Status      string          `json:"status"`
+	Priority    string          `json:"priority"`
+	Project     string          `json:"project"`
+	Tags        []string        `json:"tags"`
+	Depends     json.RawMessage `json:"depends"`
+	Entry       string          `json:"entry"`
+	Annotations []struct {
+		Entry       string `json:"entry"`
+		Description string `json:"description"`
+	} `json:"annotations"`
+}
+
+// ParseTaskwarrior reads the JSON array written by `task export`. Deleted
+// tasks and recurring templates are skipped; depends becomes blockers, and
+// the project and tags become labels.
+func ParseTaskwarrior(r io.Reader) ([]Item, error) {
+	var tasks []taskwarriorTask
+	if err := json.NewDecoder(r).Decode(&tasks); err != nil {
+		return nil, fmt.Errorf("invalid Taskwarrior JSON: %w", err)
+	}
+	items := make([]Item, 0, len(tasks))
+	for _, tw := range tasks {
+		if tw.Status == "deleted" || tw.Status == "recurring" {
+			continue
+		}
+		it := Item{
+			Source:    FormatTaskwarrior,
+			SourceID:  tw.UUID,
+			Title:     strings.TrimSpace(tw.Description),
+			Priority:  normalizePriority(tw.Priority),
+			Completed: tw.Status == "completed",
+			Created:   parseTime(tw.Entry),
+		}
+		if tw.Project != "" {
+			it.Labels = append(it.Labels, "project:"+tw.Project)
+		}
+		it.Labels = append(it.Labels, tw.Tags...)
+		depends, err := taskwarriorDepends(tw.Depends)
+		if err != nil {
+			return nil, fmt.Errorf("task %s: %w", tw.UUID, err)
+		}
+		it.Blockers = depends
+		var notes []string
+		for _, a := range tw.Annotations {
+			notes = append(notes, "- "+strings.TrimSpace(a.Description))
+		}
+		it.Body = strings.Join(notes, "\n")
+		items = append(items, it)
+	}
+	return items, nil
+}
+
+// taskwarriorDepends accepts the array form of depends and the comma
+// separated string written by Taskwarrior before 2.6.
+func taskwarriorDepends(raw json.RawMessage) ([]string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var list []string
+	if err := json.Unmarshal(raw, &list); err == nil {
+		return list, nil
+	}
+	var joined string
+	if err := json.Unmarshal(raw, &joined); err != nil {
+		return nil, fmt.Errorf("invalid depends: %s", raw)
+	}
+	return splitList(joined), nil
+}
+
+var todoTxtDate = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+
+// ParseTodoTxt reads a todo.txt file. Completion marks, (A)-(C) priorities
+// and creation dates are honored; +project and @context tags become labels.
+// The id: tag identifies a line and dep: lists the ids it depends on; lines
+// without id: are identified by a hash of their text, so editing the text of
+// such a line makes a re-import treat it as new.
+func ParseTodoTxt(r io.Reader) ([]Item, error) {
+	var items []Item
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		fields := strings.Fields(line)
+		it := Item{Source: FormatTodoTxt}
+		if fields[0] == "x" {
+			it.Completed = true
+			fields = fields[1:]
+			// A completed task may carry a completion date before the
+			// creation date.
+			if len(fields) > 1 && todoTxtDate.MatchString(fields[0]) && todoTxtDate.MatchString(fields[1]) {
+				fields = fields[1:]
+			}
+		}
+		if len(fields) > 0 && len(fields[0]) == 3 && fields[0][0] == '(' && fields[0][2] == ')' {
+			it.Priority = normalizePriority(fields[0][1:2])
+			fields = fields[1:]
+		}
+		if len(fields) > 0 && todoTxtDate.MatchString(fields[0]) {
+			it.Created = parseTime(fields[0])
+			fields = fields[1:]
+		}
+
+		var words []string
+		for _, f := range fields {
+			switch {
+			case strings.HasPrefix(f, "+") && len(f) > 1, strings.HasPrefix(f, "@") && len(f) > 1:
+				it.Labels = append(it.Labels, f)
+			case strings.HasPrefix(f, "id:") && len(f) > 3:
+				it.SourceID = f[3:]
+			case strings.HasPrefix(f, "dep:") && len(f) > 4:
+				it.Blockers = append(it.Blockers, splitList(f[4:])...)
+			case strings.HasPrefix(f, "pri:") && len(f) > 4:
+				it.Priority = normalizePriority(f[4:])
+			default:
+				words = append(words, f)
+			}
+		}
+		it.Title = strings.Join(words, " ")
+		if it.SourceID == "" {
+			sum := sha1.Sum([]byte(it.Title))
+			it.SourceID = hex.EncodeToString(sum[:])[:12]
+		}
+		items = append(items, it)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// ParseCSV reads a CSV file with a header row. mapping maps a field in
+// CSVFields to a column header; unmapped fields use the column whose header
+// matches the field name, ignoring case. A title column is required. Rows
+// without an id are identified by a hash of their title.
+func ParseCSV(r io.Reader, mapping map[string]string) ([]Item, error) {
+	for field := range mapping {
+		if !isCSVField(field) {
+			return nil, fmt.Errorf("unknown field %q in column mapping (expected one of: %s)", field, strings.Join(CSVFields, ", "))
+		}
+	}
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	index := make(map[string]int)
+	for _, field := range CSVFields {
+		name, mapped := mapping[field]
+		if !mapped {
+			name = field
+		}
+		i, ok := columns[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			if mapped {
+				return nil, fmt.Errorf("column %q mapped to %s not found in CSV header", name, field)
+			}
+			continue
+		}
+		index[field] = i
+	}
+	if _, ok := index["title"]; !ok {
+		return nil, fmt.Errorf("CSV has no title column (map one with --map title=<column>)")
+	}
+
+	var items []Item
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		value := func(field string) string {
+			i, ok := index[field]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+		it := Item{
+			Source:    FormatCSV,
+			SourceID:  value("id"),
+			Title:     value("title"),
+			Body:      value("body"),
+			Priority:  normalizePriority(value("priority")),
+			Role:      value("role"),
+			Completed: isTruthy(value("completed")) || isDoneStatus(value("status")),
+			Created:   parseTime(value("created")),
+			Labels:    splitList(value("labels")),
+			Parent:    value("parent"),
+			Blockers:  splitList(value("blockers")),
+		}
+		if it.SourceID == "" {
+			sum := sha1.Sum([]byte(it.Title))
+			it.SourceID = hex.EncodeToString(sum[:])[:12]
+		}
+		items = append(items, it)
+	}
+	return items, nil
+}
+
+func isCSVField(field string) bool {
+	for _, f := range CSVFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizePriority maps the priority spellings of common trackers to high,
+// medium or low; anything else yields "" so the template default applies.
+func normalizePriority(value string) string {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "high", "h", "a", "p0", "p1", "urgent", "critical":
+		return "high"
+	case "medium", "m", "b", "p2", "normal":
+		return "medium"
+	case "low", "l", "c", "d", "e", "p3", "p4", "minor":
+		return "low"
+	default:
+		return ""
+	}
+}
+
+// labelPriority returns the priority named by a label such as
+// "priority: high", "priority/low" or "P1", or "".
+func labelPriority(label string) string {
+	lower := strings.ToLower(strings.TrimSpace(label))
+	if rest, ok := strings.CutPrefix(lower, "priority"); ok {
+		return normalizePriority(strings.TrimLeft(rest, ":/- "))
+	}
+	if len(lower) == 2 && lower[0] == 'p' {
+		return normalizePriority(lower)
+	}
+	return ""
+}
+
+func isTruthy(value string) bool {
+	switch strings.ToLower(value) {
+	case "true", "yes", "y", "1", "x", "done":
+		return true
+	}
+	return false
+}
+
+func isDoneStatus(status string) bool {
+	switch strings.ToLower(status) {
+	case "done", "closed", "completed", "resolved":
+		return true
+	}
+	return false
+}
+
+// parseTime accepts RFC 3339, Taskwarrior's compact form and plain dates. It
+// returns the zero time for anything else.
+func parseTime(value string) time.Time {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{time.RFC3339, "20060102T150405Z", "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func splitList(value string) []string {
+	parts := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ';' || r == ' ' || r == '\t'
+	})
+	if len(parts) == 0 {
+		return nil
+	}
+	return parts
+}
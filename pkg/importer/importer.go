@@ -0,0 +1,358 @@
+// Package importer migrates backlogs from other trackers' export files into
+// strand tasks. Parsers turn each format into Items; Import creates them
+// through the normal creation path and links their dependencies.
+package importer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ricochet1k/strandyard/pkg/config"
+	"github.com/ricochet1k/strandyard/pkg/create"
+	rPkg "github.com/ricochet1k/strandyard/pkg/role"
+	"github.com/ricochet1k/strandyard/pkg/task"
+	"github.com/ricochet1k/strandyard/pkg/template"
+)
+
+// OriginField is the frontmatter key that records where an imported task
+// came from, as "<source>:<id>" or the item's URL. Re-imports skip items whose
+// origin is already present.
+const OriginField = "imported_from"
+
+// Item is one task read from an export file.
+type Item struct {
+	// Source names the format, such as "github" or "todotxt".
+	Source string
+	// SourceID identifies the item within the export; Parent and Blockers
+	// refer to other items by it.
+	SourceID string
+	// URL, when known, is recorded as the origin instead of Source:SourceID.
+	URL       string
+	Title     string
+	Body      string
+	Priority  string
+	Role      string
+	Completed bool
+	Created   time.Time
+	Labels    []string
+	Parent    string
+	Blockers  []string
+}
+
+// Origin returns the value recorded in OriginField.
+func (it Item) Origin() string {
+	if it.URL != "" {
+		return it.URL
+	}
+	return it.Source + ":" + it.SourceID
+}
+
+// Options controls how items become tasks.
+type Options struct {
+	// TemplateName is the template every task is created from.
+	TemplateName string
+	// Role overrides both the items' roles and the template's role.
+	Role   string
+	DryRun bool
+}
+
+// Action describes what Import did with an item.
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionExists Action = "exists"
+)
+
+// Entry reports the outcome for one item.
+type Entry struct {
+	Action Action `json:"action"`
+	Origin string `json:"origin"`
+	TaskID string `json:"task_id,omitempty"`
+	Title  string `json:"title"`
+}
+
+// Report summarizes an import. Entries are in creation order.
+type Report struct {
+	Entries  []Entry  `json:"entries"`
+	Links    int      `json:"links"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// Created counts the entries that were (or, in a dry run, would be) created.
+func (r *Report) Created() int {
+	n := 0
+	for _, e := range r.Entries {
+		if e.Action == ActionCreate {
+			n++
+		}
+	}
+	return n
+}
+
+// Import creates a task for every item whose origin is not already in the
+// project. Items are created after the items they depend on so parents and
+// blockers go through create.Task; dependency cycles are linked afterwards.
+// On error the report covers the tasks created so far, and re-running the
+// import picks up where it stopped.
+func Import(project create.Project, cfg config.Config, items []Item, opts Options) (*Report, error) {
+	report := &Report{}
+	if strings.TrimSpace(opts.TemplateName) == "" {
+		return nil, fmt.Errorf("type is required")
+	}
+
+	db := task.NewTaskDB(project.TasksDir)
+	if err := db.LoadAll(); err != nil {
+		return nil, err
+	}
+	taskIDs := existingOrigins(db.GetAll())
+
+	bySourceID := make(map[string]Item, len(items))
+	for _, it := range items {
+		if strings.TrimSpace(it.Title) == "" {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("%s: skipped item without a title", it.Origin()))
+			continue
+		}
+		if _, dup := bySourceID[it.SourceID]; dup {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("%s: skipped duplicate item", it.Origin()))
+			continue
+		}
+		bySourceID[it.SourceID] = it
+	}
+	resolve := func(ref string) (string, bool) {
+		target, ok := bySourceID[ref]
+		if !ok {
+			return "", false
+		}
+		id, ok := taskIDs[target.Origin()]
+		return id, ok
+	}
+
+	if opts.DryRun {
+		if err := checkCreatable(project, opts, bySourceID); err != nil {
+			return nil, err
+		}
+	}
+
+	var created []Item
+	var createErr error
+	for _, it := range orderItems(bySourceID) {
+		if id, ok := taskIDs[it.Origin()]; ok {
+			report.Entries = append(report.Entries, Entry{Action: ActionExists, Origin: it.Origin(), TaskID: id, Title: it.Title})
+			continue
+		}
+		for _, ref := range it.references() {
+			if _, ok := bySourceID[ref]; !ok {
+				report.Warnings = append(report.Warnings, fmt.Sprintf("%s: dependency %s is not in the import", it.Origin(), ref))
+			}
+		}
+		if opts.DryRun {
+			report.Entries = append(report.Entries, Entry{Action: ActionCreate, Origin: it.Origin(), Title: it.Title})
+			continue
+		}
+
+		req := create.Request{
+			TemplateName: opts.TemplateName,
+			Title:        it.Title,
+			Role:         firstNonEmpty(opts.Role, it.Role),
+			Priority:     it.Priority,
+			Extra:        map[string]interface{}{OriginField: it.Origin()},
+			Created:      it.Created,
+			Body:         it.body(),
+		}
+		if id, ok := resolve(it.Parent); ok {
+			req.Parent = id
+		}
+		for _, ref := range it.Blockers {
+			if id, ok := resolve(ref); ok {
+				req.Blockers = append(req.Blockers, id)
+			}
+		}
+		result, err := create.Task(project, cfg, req)
+		if err != nil {
+			createErr = fmt.Errorf("%s: %w", it.Origin(), err)
+			break
+		}
+		taskIDs[it.Origin()] = result.ID
+		created = append(created, it)
+		report.Entries = append(report.Entries, Entry{Action: ActionCreate, Origin: it.Origin(), TaskID: result.ID, Title: it.Title})
+	}
+	if opts.DryRun || len(created) == 0 {
+		return report, createErr
+	}
+
+	// Link and complete what was created even after a failure.
+	links, err := finishCreated(project.TasksDir, created, taskIDs, resolve)
+	report.Links = links
+	if createErr != nil {
+		return report, createErr
+	}
+	return report, err
+}
+
+// finishCreated records completion on the new tasks and links the
+// dependencies that pointed forward in a cycle.
+func finishCreated(tasksDir string, created []Item, taskIDs map[string]string, resolve func(string) (string, bool)) (int, error) {
+	db := task.NewTaskDB(tasksDir)
+	if err := db.LoadAll(); err != nil {
+		return 0, err
+	}
+	links := 0
+	parents := map[string]bool{}
+	for _, it := range created {
+		id := taskIDs[it.Origin()]
+		t, err := db.Get(id)
+		if err != nil {
+			return links, err
+		}
+		if parentID, ok := resolve(it.Parent); ok {
+			if t.Meta.Parent != parentID {
+				if err := db.SetParent(id, parentID); err != nil {
+					return links, fmt.Errorf("%s: %w", it.Origin(), err)
+				}
+				parents[parentID] = true
+			}
+			links++
+		}
+		for _, ref := range it.Blockers {
+			blockerID, ok := resolve(ref)
+			if !ok {
+				continue
+			}
+			if !containsID(t.Meta.Blockers, blockerID) {
+				if err := db.AddBlocker(id, blockerID); err != nil {
+					return links, fmt.Errorf("%s: %w", it.Origin(), err)
+				}
+			}
+			links++
+		}
+	}
+	for _, it := range created {
+		if it.Completed {
+			if err := db.SetCompleted(taskIDs[it.Origin()], true); err != nil {
+				return links, fmt.Errorf("%s: %w", it.Origin(), err)
+			}
+		}
+	}
+	for parentID := range parents {
+		if _, err := db.UpdateParentTodos(parentID); err != nil {
+			return links, fmt.Errorf("failed to update parent task TODO entries: %w", err)
+		}
+	}
+	if _, err := db.SaveDirty(); err != nil {
+		return links, fmt.Errorf("failed to write task updates: %w", err)
+	}
+	return links, nil
+}
+
+// checkCreatable validates the template and roles a dry run would use.
+func checkCreatable(project create.Project, opts Options, items map[string]Item) error {
+	templates, err := template.LoadTemplates(project.TemplatesDir)
+	if err != nil {
+		return err
+	}
+	tmpl, ok := templates[opts.TemplateName]
+	if !ok {
+		return &create.UnknownTemplateError{Name: opts.TemplateName, Templates: templates}
+	}
+	roles, err := rPkg.LoadRoles(project.RolesDir)
+	if err != nil {
+		return err
+	}
+	for _, it := range items {
+		role := firstNonEmpty(opts.Role, it.Role, tmpl.Meta.Role)
+		if role == "" {
+			return fmt.Errorf("%s: role is required (use --role or set role in template frontmatter)", it.Origin())
+		}
+		if _, ok := roles[role]; !ok {
+			return fmt.Errorf("%s: %w", it.Origin(), &create.UnknownRoleError{Name: role, Roles: roles})
+		}
+	}
+	return nil
+}
+
+// existingOrigins maps the origin of every previously imported task to its ID.
+func existingOrigins(tasks map[string]*task.Task) map[string]string {
+	out := make(map[string]string)
+	for id, t := range tasks {
+		if origin, ok := t.Meta.Fields[OriginField].(string); ok && origin != "" {
+			out[origin] = id
+		}
+	}
+	return out
+}
+
+// orderItems returns items sorted by source ID, then moved after the items
+// they depend on. Dependencies inside a cycle are left for finishCreated.
+func orderItems(items map[string]Item) []Item {
+	ids := make([]string, 0, len(items))
+	for id := range items {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int, len(items))
+	ordered := make([]Item, 0, len(items))
+	var visit func(id string)
+	visit = func(id string) {
+		it, ok := items[id]
+		if !ok || state[id] != 0 {
+			return
+		}
+		state[id] = visiting
+		for _, ref := range it.references() {
+			visit(ref)
+		}
+		state[id] = done
+		ordered = append(ordered, it)
+	}
+	for _, id := range ids {
+		visit(id)
+	}
+	return ordered
+}
+
+func (it Item) references() []string {
+	refs := make([]string, 0, len(it.Blockers)+1)
+	if it.Parent != "" {
+		refs = append(refs, it.Parent)
+	}
+	return append(refs, it.Blockers...)
+}
+
+// body returns the task body with the item's labels appended.
+func (it Item) body() string {
+	body := strings.TrimSpace(it.Body)
+	if len(it.Labels) == 0 {
+		return body
+	}
+	labels := "Labels: " + strings.Join(it.Labels, ", ")
+	if body == "" {
+		return labels
+	}
+	return body + "\n\n" + labels
+}
+
+func containsID(ids []string, id string) bool {
+	for _, existing := range ids {
+		if existing == id {
+			return true
+		}
+	}
+	return false
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,196 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ricochet1k/strandyard/pkg/config"
+	"github.com/ricochet1k/strandyard/pkg/create"
+	"github.com/ricochet1k/strandyard/pkg/task"
+)
+
+func setupProject(t *testing.T) create.Project {
+	t.Helper()
+	base := t.TempDir()
+	project := create.Project{
+		BaseDir:      base,
+		TasksDir:     filepath.Join(base, "tasks"),
+		TemplatesDir: filepath.Join(base, "templates"),
+		RolesDir:     filepath.Join(base, "roles"),
+	}
+	for _, dir := range []string{project.TasksDir, project.TemplatesDir, project.RolesDir} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile(t, filepath.Join(project.RolesDir, "developer.md"), "# developer\n")
+	writeFile(t, filepath.Join(project.TemplatesDir, "task.md"), "---\nrole: developer\npriority: low\nid_prefix: T\n---\n\n# {{ .Title }}\n\n{{ .Body }}\n")
+	return project
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseGitHub(t *testing.T) {
+	input := `[
+  {"number": 1, "title": "Auth epic", "body": "", "state": "OPEN", "labels": [{"name": "P1"}], "url": "https://github.com/acme/app/issues/1", "createdAt": "2025-03-01T10:00:00Z"},
+  {"number": 2, "title": "Login form", "body": "Part of #1\n\nBlocked by #3 and #4", "state": "closed", "labels": ["ui"], "html_url": "https://github.com/acme/app/issues/2"},
+  {"number": 5, "title": "A pull request", "pull_request": {"url": "x"}}
+]`
+	items, err := ParseGitHub(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("items = %+v, want pull requests skipped", items)
+	}
+	epic, login := items[0], items[1]
+	if epic.Priority != "high" || epic.Origin() != "https://github.com/acme/app/issues/1" || epic.Created.Year() != 2025 {
+		t.Errorf("epic = %+v", epic)
+	}
+	if login.Parent != "1" || !slices.Equal(login.Blockers, []string{"3", "4"}) || !login.Completed || !slices.Equal(login.Labels, []string{"ui"}) {
+		t.Errorf("login = %+v", login)
+	}
+}
+
+func TestParseTaskwarrior(t *testing.T) {
+	input := `[
+  {"uuid": "a-1", "description": "Write API", "status": "pending", "priority": "H", "project": "backend", "tags": ["api"], "entry": "20250301T100000Z",
+   "annotations": [{"entry": "20250302T100000Z", "description": "see RFC"}]},
+  {"uuid": "b-2", "description": "Ship it", "status": "completed", "depends": "a-1"},
+  {"uuid": "c-3", "description": "Gone", "status": "deleted"}
+]`
+	items, err := ParseTaskwarrior(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("items = %+v, want deleted task skipped", items)
+	}
+	if api := items[0]; api.Priority != "high" || !slices.Equal(api.Labels, []string{"project:backend", "api"}) || api.Body != "- see RFC" || api.Created.IsZero() {
+		t.Errorf("api = %+v", api)
+	}
+	if ship := items[1]; !ship.Completed || !slices.Equal(ship.Blockers, []string{"a-1"}) {
+		t.Errorf("ship = %+v", ship)
+	}
+}
+
+func TestParseTodoTxt(t *testing.T) {
+	input := "(A) 2025-03-01 Call the bank +finance @phone id:bank\nx 2025-03-04 2025-03-02 Pay invoice dep:bank\n\nWater plants\n"
+	items, err := ParseTodoTxt(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 3 {
+		t.Fatalf("items = %+v", items)
+	}
+	bank, invoice, plants := items[0], items[1], items[2]
+	if bank.SourceID != "bank" || bank.Title != "Call the bank" || bank.Priority != "high" || !slices.Equal(bank.Labels, []string{"+finance", "@phone"}) {
+		t.Errorf("bank = %+v", bank)
+	}
+	if !invoice.Completed || invoice.Created.Day() != 2 || !slices.Equal(invoice.Blockers, []string{"bank"}) {
+		t.Errorf("invoice = %+v", invoice)
+	}
+	if plants.SourceID == "" || plants.Title != "Water plants" {
+		t.Errorf("plants = %+v", plants)
+	}
+}
+
+func TestParseCSVMapping(t *testing.T) {
+	input := "Key,Summary,Priority,Status,Blocked By\nPRJ-1,Design schema,Major,Open,\nPRJ-2,Build it,,Done,PRJ-1\n"
+	if _, err := ParseCSV(strings.NewReader(input), nil); err == nil {
+		t.Error("CSV without a title column should fail")
+	}
+	items, err := ParseCSV(strings.NewReader(input), map[string]string{"id": "Key", "title": "Summary", "blockers": "Blocked By"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[0].SourceID != "PRJ-1" || items[0].Title != "Design schema" || items[0].Priority != "" {
+		t.Errorf("items = %+v", items)
+	}
+	if !items[1].Completed || !slices.Equal(items[1].Blockers, []string{"PRJ-1"}) {
+		t.Errorf("second item = %+v", items[1])
+	}
+	if _, err := ParseCSV(strings.NewReader(input), map[string]string{"bogus": "Key"}); err == nil {
+		t.Error("unknown mapped field should fail")
+	}
+}
+
+func TestImportLinksDependenciesAndIsIdempotent(t *testing.T) {
+	project := setupProject(t)
+	cfg := config.Default()
+	items := []Item{
+		{Source: "csv", SourceID: "3", Title: "Child task", Parent: "1", Blockers: []string{"2"}},
+		{Source: "csv", SourceID: "1", Title: "Parent epic", Priority: "high"},
+		{Source: "csv", SourceID: "2", Title: "Blocking task", Completed: true, Blockers: []string{"4"}},
+		{Source: "csv", SourceID: "4", Title: "Cycle task", Blockers: []string{"2"}},
+		{Source: "csv", SourceID: "5", Title: "Orphan", Blockers: []string{"99"}},
+	}
+
+	dry, err := Import(project, cfg, items, Options{TemplateName: "task", DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dry.Created() != 5 {
+		t.Errorf("dry run would create %d, want 5", dry.Created())
+	}
+	if entries, _ := os.ReadDir(project.TasksDir); len(entries) != 0 {
+		t.Fatalf("dry run wrote %d files", len(entries))
+	}
+
+	report, err := Import(project, cfg, items, Options{TemplateName: "task"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Created() != 5 || report.Links != 4 || len(report.Warnings) != 1 {
+		t.Errorf("report = %+v", report)
+	}
+	ids := map[string]string{}
+	for _, e := range report.Entries {
+		ids[e.Origin] = e.TaskID
+	}
+
+	db := task.NewTaskDB(project.TasksDir)
+	if err := db.LoadAll(); err != nil {
+		t.Fatal(err)
+	}
+	child, _ := db.Get(ids["csv:3"])
+	if child.Meta.Parent != ids["csv:1"] || !slices.Contains(child.Meta.Blockers, ids["csv:2"]) {
+		t.Errorf("child meta = %+v", child.Meta)
+	}
+	if child.Meta.Fields[OriginField] != "csv:3" {
+		t.Errorf("origin not recorded: %+v", child.Meta.Fields)
+	}
+	cycle, _ := db.Get(ids["csv:4"])
+	if !slices.Contains(cycle.Meta.Blockers, ids["csv:2"]) {
+		t.Errorf("cycle edge should be linked after creation: %+v", cycle.Meta)
+	}
+	if blocking, _ := db.Get(ids["csv:2"]); !blocking.Meta.Completed {
+		t.Error("completed item should be imported as completed")
+	}
+	if parent, _ := db.Get(ids["csv:1"]); parent.Meta.Priority != "high" || !strings.Contains(parent.Content(), task.ShortID(ids["csv:3"])) {
+		t.Errorf("parent should be high priority with a TODO for its child:\n%s", parent.Content())
+	}
+
+	again, err := Import(project, cfg, append(items, Item{Source: "csv", SourceID: "6", Title: "New since last time", Blockers: []string{"1"}}), Options{TemplateName: "task"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Created() != 1 || len(again.Entries) != 6 {
+		t.Errorf("re-import report = %+v, want only the new item created", again)
+	}
+	newTask, err := task.NewTaskDB(project.TasksDir).Get(again.Entries[len(again.Entries)-1].TaskID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(newTask.Meta.Blockers, []string{ids["csv:1"]}) {
+		t.Errorf("new item should be blocked by the previously imported task: %v", newTask.Meta.Blockers)
+	}
+}
@@ -0,0 +1,232 @@
+package preset
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LockFile is the name of the lockfile written to a project's base directory.
+const LockFile = "preset.lock"
+
+// BaseDir is the directory next to LockFile that holds each locked file as
+// last applied, the base of the next refresh's three-way merge.
+const BaseDir = "preset.base"
+
+// Lock records which presets a project's roles and templates came from.
+type Lock struct {
+	// Layers are the presets applied, lowest precedence first.
+	Layers  []LockLayer `yaml:"layers"`
+	Applied time.Time   `yaml:"applied"`
+	// TreeHash covers every file in Files; see TreeHash.
+	TreeHash string `yaml:"tree_hash"`
+	// Files maps each composed preset file, relative to the project base
+	// directory, to the hash of its content as last applied.
+	Files map[string]string `yaml:"files"`
+	// Origins maps each file to the sources of the layers it was composed
+	// from, lowest first.
+	Origins map[string][]string `yaml:"origins"`
+
+	// bases holds the content of each file in Files, written to BaseDir.
+	bases map[string][]byte
+}
+
+// LockLayer records one applied preset.
+type LockLayer struct {
+	Source string `yaml:"source"`
+	Commit string `yaml:"commit,omitempty"`
+	// TreeHash covers the layer's own files before overlaying.
+	TreeHash string `yaml:"tree_hash"`
+}
+
+// Sources returns the preset source of each layer, lowest first.
+func (l *Lock) Sources() []string {
+	sources := make([]string, 0, len(l.Layers))
+	for _, layer := range l.Layers {
+		sources = append(sources, layer.Source)
+	}
+	return sources
+}
+
+// Layer returns the layer applied from source.
+func (l *Lock) Layer(source string) (LockLayer, bool) {
+	for _, layer := range l.Layers {
+		if layer.Source == source {
+			return layer, true
+		}
+	}
+	return LockLayer{}, false
+}
+
+// ReadLock loads the project's lockfile. It returns nil without error when
+// the project has none.
+func ReadLock(baseDir string) (*Lock, error) {
+	data, err := os.ReadFile(filepath.Join(baseDir, LockFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var lock Lock
+	if err := yaml.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", LockFile, err)
+	}
+	if lock.Files == nil {
+		lock.Files = map[string]string{}
+	}
+	if lock.Origins == nil {
+		lock.Origins = map[string][]string{}
+	}
+	return &lock, nil
+}
+
+// Write saves the lockfile to the project's base directory, along with the
+// applied content of each file under BaseDir when the lock was built by
+// NewLock.
+func (l *Lock) Write(baseDir string) error {
+	data, err := yaml.Marshal(l)
+	if err != nil {
+		return err
+	}
+	if l.bases != nil {
+		if err := writeBases(filepath.Join(baseDir, BaseDir), l.bases); err != nil {
+			return fmt.Errorf("failed to write %s: %w", BaseDir, err)
+		}
+	}
+	header := "# Generated by strand preset; records the preset roles and templates were last applied from.\n"
+	return os.WriteFile(filepath.Join(baseDir, LockFile), append([]byte(header), data...), 0o644)
+}
+
+func writeBases(dir string, bases map[string][]byte) error {
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	for rel, data := range bases {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Base returns a file's content as last applied, read from BaseDir. It
+// returns nil when the snapshot is missing or does not match the lock.
+func (l *Lock) Base(baseDir, rel string) []byte {
+	hash, ok := l.Files[rel]
+	if !ok {
+		return nil
+	}
+	data, err := os.ReadFile(filepath.Join(baseDir, BaseDir, filepath.FromSlash(rel)))
+	if err != nil || Hash(data) != hash {
+		return nil
+	}
+	return data
+}
+
+// Hash returns the lockfile hash of a file's content.
+func Hash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// TreeHash hashes a set of file hashes keyed by path: the sha256 of one
+// "<path> <hash>" line per file, sorted by path.
+func TreeHash(files map[string]string) string {
+	paths := make([]string, 0, len(files))
+	for rel := range files {
+		paths = append(paths, rel)
+	}
+	sort.Strings(paths)
+	var b strings.Builder
+	for _, rel := range paths {
+		fmt.Fprintf(&b, "%s %s\n", rel, files[rel])
+	}
+	return Hash([]byte(b.String()))
+}
+
+// Verify checks that each freshly fetched layer matches the tree hash this
+// lock records for the same source.
+func (l *Lock) Verify(fetched *Lock) error {
+	if l.TreeHash == "" {
+		return fmt.Errorf("%s records no tree hash to verify against", LockFile)
+	}
+	if TreeHash(l.Files) != l.TreeHash {
+		return fmt.Errorf("%s is inconsistent: its file hashes do not match tree_hash %s", LockFile, l.TreeHash)
+	}
+	for _, layer := range fetched.Layers {
+		locked, ok := l.Layer(layer.Source)
+		if !ok {
+			return fmt.Errorf("preset integrity check failed: %s is not recorded in %s", layer.Source, LockFile)
+		}
+		if layer.TreeHash != locked.TreeHash {
+			return fmt.Errorf("preset integrity check failed: %s at %s has tree hash %s, but %s records %s",
+				layer.Source, layer.Commit, layer.TreeHash, LockFile, locked.TreeHash)
+		}
+	}
+	return nil
+}
+
+// FileOrigin describes where a project role or template came from.
+type FileOrigin struct {
+	// Path is relative to the project base directory, slash separated.
+	Path string
+	// Layers are the sources the file was composed from, lowest first; empty
+	// for project-local files.
+	Layers []string
+	// Edited reports a preset file changed in the project since it was applied.
+	Edited bool
+}
+
+// Origins lists the project files in dirs with the layers each came from.
+// A nil lock makes every file project-local.
+func Origins(baseDir string, lock *Lock, dirs []string) ([]FileOrigin, error) {
+	var origins []FileOrigin
+	for _, name := range dirs {
+		root := filepath.Join(baseDir, name)
+		err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) && path == root {
+					return filepath.SkipDir
+				}
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			rel, err := filepath.Rel(baseDir, path)
+			if err != nil {
+				return err
+			}
+			origin := FileOrigin{Path: filepath.ToSlash(rel)}
+			if lock != nil {
+				if hash, ok := lock.Files[origin.Path]; ok {
+					data, err := os.ReadFile(path)
+					if err != nil {
+						return err
+					}
+					origin.Layers = lock.Origins[origin.Path]
+					origin.Edited = Hash(data) != hash
+				}
+			}
+			origins = append(origins, origin)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(origins, func(i, j int) bool { return origins[i].Path < origins[j].Path })
+	return origins, nil
+}
@@ -0,0 +1,129 @@
+package preset
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// layeredFile is a preset file composed from one or more layers.
+type layeredFile struct {
+	presetFile
+	// origins are the sources of the layers that contributed, lowest first.
+	origins []string
+}
+
+// overlay composes the files of each source in order. A later layer replaces
+// a file, except that when both versions are markdown with frontmatter the
+// frontmatter is merged key by key and the earlier body is kept unless the
+// later layer has one of its own.
+func overlay(sources []*Source, dirs []string) (map[string]layeredFile, error) {
+	composed := make(map[string]layeredFile)
+	for _, src := range sources {
+		files, err := presetFiles(src, dirs)
+		if err != nil {
+			return nil, err
+		}
+		for rel, file := range files {
+			prev, ok := composed[rel]
+			if !ok {
+				composed[rel] = layeredFile{presetFile: file, origins: []string{src.Preset}}
+				continue
+			}
+			merged, both, err := overlayContent(prev.data, file.data)
+			if err != nil {
+				return nil, fmt.Errorf("%s from %s: %w", rel, src.Preset, err)
+			}
+			origins := []string{src.Preset}
+			if both {
+				origins = append(append([]string{}, prev.origins...), src.Preset)
+			}
+			composed[rel] = layeredFile{presetFile: presetFile{data: merged, mode: file.mode}, origins: origins}
+		}
+	}
+	return composed, nil
+}
+
+// overlayContent lays upper over lower, reporting whether the result keeps
+// anything from lower.
+func overlayContent(lower, upper []byte) ([]byte, bool, error) {
+	lowerMeta, lowerBody, ok := splitFrontmatter(lower)
+	if !ok {
+		return upper, false, nil
+	}
+	upperMeta, upperBody, ok := splitFrontmatter(upper)
+	if !ok {
+		return upper, false, nil
+	}
+
+	var lowerNode, upperNode yaml.Node
+	if err := yaml.Unmarshal([]byte(lowerMeta), &lowerNode); err != nil {
+		return nil, false, fmt.Errorf("failed to parse frontmatter: %w", err)
+	}
+	if err := yaml.Unmarshal([]byte(upperMeta), &upperNode); err != nil {
+		return nil, false, fmt.Errorf("failed to parse frontmatter: %w", err)
+	}
+	lowerMap, upperMap := mappingOf(&lowerNode), mappingOf(&upperNode)
+	if lowerMap == nil || upperMap == nil {
+		return upper, false, nil
+	}
+
+	for i := 0; i+1 < len(upperMap.Content); i += 2 {
+		key, value := upperMap.Content[i], upperMap.Content[i+1]
+		replaced := false
+		for j := 0; j+1 < len(lowerMap.Content); j += 2 {
+			if lowerMap.Content[j].Value == key.Value {
+				lowerMap.Content[j+1] = value
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			lowerMap.Content = append(lowerMap.Content, key, value)
+		}
+	}
+	meta, err := yaml.Marshal(lowerMap)
+	if err != nil {
+		return nil, false, err
+	}
+
+	body := lowerBody
+	if strings.TrimSpace(upperBody) != "" {
+		body = upperBody
+	}
+	var out bytes.Buffer
+	out.WriteString("---\n")
+	out.Write(meta)
+	out.WriteString("---\n")
+	out.WriteString(body)
+	return out.Bytes(), true, nil
+}
+
+// splitFrontmatter splits a markdown file into its frontmatter and body.
+func splitFrontmatter(data []byte) (string, string, bool) {
+	content := strings.ReplaceAll(string(data), "\r\n", "\n")
+	if !strings.HasPrefix(content, "---\n") {
+		return "", "", false
+	}
+	rest := content[len("---\n"):]
+	end := strings.Index(rest, "\n---\n")
+	if end < 0 {
+		if strings.HasSuffix(rest, "\n---") {
+			return rest[:len(rest)-len("\n---")], "", true
+		}
+		return "", "", false
+	}
+	return rest[:end+1], rest[end+len("\n---\n"):], true
+}
+
+func mappingOf(doc *yaml.Node) *yaml.Node {
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) == 1 {
+		doc = doc.Content[0]
+	}
+	if doc.Kind != yaml.MappingNode {
+		return nil
+	}
+	return doc
+}
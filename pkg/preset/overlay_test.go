@@ -0,0 +1,84 @@
+package preset
+
+import (
+	"io"
+	"testing"
+)
+
+func TestOverlayContent(t *testing.T) {
+	lower := "---\ndescription: Company developer\nmodel: small\n---\n# Developer\n\nCompany body.\n"
+
+	got, both, err := overlayContent([]byte(lower), []byte("---\nmodel: large\nteam: web\n---\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "---\ndescription: Company developer\nmodel: large\nteam: web\n---\n# Developer\n\nCompany body.\n"
+	if !both || string(got) != want {
+		t.Errorf("frontmatter overlay = %q (both=%v), want %q", got, both, want)
+	}
+
+	got, both, err = overlayContent([]byte(lower), []byte("---\nmodel: large\n---\n# Team developer\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "---\ndescription: Company developer\nmodel: large\n---\n# Team developer\n"; !both || string(got) != want {
+		t.Errorf("body override = %q, want %q", got, want)
+	}
+
+	got, both, _ = overlayContent([]byte(lower), []byte("plain replacement\n"))
+	if both || string(got) != "plain replacement\n" {
+		t.Errorf("file without frontmatter should replace, got %q", got)
+	}
+}
+
+func TestOverlayOrigins(t *testing.T) {
+	company, team := t.TempDir(), t.TempDir()
+	writeFiles(t, company, map[string]string{
+		"roles/dev.md":      "---\nmodel: small\n---\n# Dev\n",
+		"roles/ops.md":      "# Ops\n",
+		"templates/task.md": "task\n",
+	})
+	writeFiles(t, team, map[string]string{
+		"roles/dev.md": "---\nmodel: large\n---\n",
+		"roles/ops.md": "# Team ops\n",
+		"roles/web.md": "# Web\n",
+	})
+	var sources []*Source
+	for _, dir := range []string{company, team} {
+		src, err := Fetch(io.Discard, dir, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer src.Close()
+		sources = append(sources, src)
+	}
+
+	lock, err := NewLock(sources, LockedDirs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lock.Layers) != 2 {
+		t.Fatalf("layers = %+v", lock.Layers)
+	}
+	want := map[string][]string{
+		"roles/dev.md":      {sources[0].Preset, sources[1].Preset},
+		"roles/ops.md":      {sources[1].Preset},
+		"roles/web.md":      {sources[1].Preset},
+		"templates/task.md": {sources[0].Preset},
+	}
+	for rel, origins := range want {
+		got := lock.Origins[rel]
+		if len(got) != len(origins) {
+			t.Errorf("%s origins = %v, want %v", rel, got, origins)
+			continue
+		}
+		for i := range got {
+			if got[i] != origins[i] {
+				t.Errorf("%s origins = %v, want %v", rel, got, origins)
+			}
+		}
+	}
+	if lock.Files["roles/dev.md"] != Hash([]byte("---\nmodel: large\n---\n# Dev\n")) {
+		t.Error("roles/dev.md hash does not match the merged file")
+	}
+}
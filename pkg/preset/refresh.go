@@ -0,0 +1,419 @@
+package preset
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// LockedDirs are the preset directories tracked by the lockfile. Tasks are
+// only seeded on init and never refreshed.
+var LockedDirs = []string{"roles", "templates"}
+
+// Action is what a refresh does to a single file.
+type Action string
+
+const (
+	// ActionUnchanged: the project already matches the preset.
+	ActionUnchanged Action = "unchanged"
+	// ActionAdd: the file is new in the preset.
+	ActionAdd Action = "add"
+	// ActionUpdate: the preset changed a file that has no local edits.
+	ActionUpdate Action = "update"
+	// ActionKeep: the file has local edits and the preset did not change it.
+	ActionKeep Action = "keep"
+	// ActionMerge: local and preset changes were merged cleanly.
+	ActionMerge Action = "merge"
+	// ActionConflict: local and preset changes overlap; the file gets
+	// conflict markers.
+	ActionConflict Action = "conflict"
+	// ActionRemove: the preset dropped a file that has no local edits.
+	ActionRemove Action = "remove"
+)
+
+// Change is the planned refresh of one file.
+type Change struct {
+	// Path is relative to the project base directory, slash separated.
+	Path   string
+	Action Action
+	// Local is the project's current content, nil when the file is missing.
+	Local []byte
+	// Result is the content to write; nil when the file is left alone or
+	// removed.
+	Result []byte
+	// Note explains keep and conflict decisions.
+	Note string
+
+	mode fs.FileMode
+}
+
+// Plan is a computed refresh that has not been written yet.
+type Plan struct {
+	Changes []Change
+	// Lock is the lockfile to write once the plan is applied.
+	Lock *Lock
+}
+
+// NewLock records the current files of the layered sources in dirs without
+// touching the project.
+func NewLock(sources []*Source, dirs []string) (*Lock, error) {
+	lock := &Lock{Applied: time.Now().UTC(), Files: map[string]string{}, Origins: map[string][]string{}, bases: map[string][]byte{}}
+	for _, src := range sources {
+		files, err := presetFiles(src, dirs)
+		if err != nil {
+			return nil, err
+		}
+		hashes := make(map[string]string, len(files))
+		for rel, file := range files {
+			hashes[rel] = Hash(file.data)
+		}
+		lock.Layers = append(lock.Layers, LockLayer{Source: src.Preset, Commit: src.Commit, TreeHash: TreeHash(hashes)})
+	}
+	files, err := overlay(sources, dirs)
+	if err != nil {
+		return nil, err
+	}
+	for rel, file := range files {
+		lock.Files[rel] = Hash(file.data)
+		lock.Origins[rel] = file.origins
+		lock.bases[rel] = file.data
+	}
+	lock.TreeHash = TreeHash(lock.Files)
+	return lock, nil
+}
+
+// PlanRefresh compares the layered sources with the project files in dirs.
+// With a lock, each file is merged three ways against its content as last
+// applied so local edits survive; without one, any difference is a conflict.
+func PlanRefresh(baseDir string, sources []*Source, lock *Lock, dirs []string) (*Plan, error) {
+	files, err := overlay(sources, dirs)
+	if err != nil {
+		return nil, err
+	}
+	if lock == nil {
+		lock = &Lock{Files: map[string]string{}}
+	}
+	next, err := NewLock(sources, dirs)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make(map[string]bool)
+	for rel := range files {
+		paths[rel] = true
+	}
+	for rel := range lock.Files {
+		if inDirs(rel, dirs) {
+			paths[rel] = true
+		}
+	}
+	sorted := make([]string, 0, len(paths))
+	for rel := range paths {
+		sorted = append(sorted, rel)
+	}
+	sort.Strings(sorted)
+
+	plan := &Plan{Lock: next}
+	for _, rel := range sorted {
+		local, err := os.ReadFile(filepath.Join(baseDir, filepath.FromSlash(rel)))
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		if err != nil {
+			local = nil
+		}
+		file, inPreset := files[rel]
+		change := Change{Path: rel, Local: local, mode: 0o644}
+		if inPreset {
+			change.mode = file.mode
+		}
+		baseHash, locked := lock.Files[rel]
+		localEdited := local != nil && (!locked || Hash(local) != baseHash)
+
+		switch {
+		case !inPreset:
+			// Dropped from the preset.
+			if local == nil {
+				continue
+			}
+			if localEdited {
+				change.Action = ActionKeep
+				change.Note = "removed from preset but edited locally"
+			} else {
+				change.Action = ActionRemove
+			}
+		case local != nil && bytes.Equal(local, file.data):
+			change.Action = ActionUnchanged
+		case local == nil && locked:
+			change.Action = ActionKeep
+			change.Note = "deleted locally"
+		case local == nil:
+			change.Action = ActionAdd
+			change.Result = file.data
+		case !localEdited:
+			change.Action = ActionUpdate
+			change.Result = file.data
+		case locked && Hash(file.data) == baseHash:
+			change.Action = ActionKeep
+			change.Note = "local edits kept"
+		default:
+			var base []byte
+			if locked {
+				base = lock.Base(baseDir, rel)
+			}
+			if base == nil && locked {
+				// The snapshot in BaseDir is missing or altered, as in a
+				// clone where it was not committed: rebuild the base from
+				// the preset's git history.
+				base = lockedBase(lock, sources, rel)
+				if base != nil && Hash(base) != baseHash {
+					base = nil
+				}
+			}
+			merged, conflicts, err := mergeFile(local, base, file.data)
+			if err != nil {
+				return nil, fmt.Errorf("failed to merge %s: %w", rel, err)
+			}
+			change.Result = merged
+			change.Action = ActionMerge
+			if conflicts {
+				change.Action = ActionConflict
+				change.Note = "local and preset changes overlap"
+				if base == nil {
+					change.Note = "no locked base to merge against"
+				}
+			}
+		}
+		plan.Changes = append(plan.Changes, change)
+	}
+	return plan, nil
+}
+
+// lockedBase rebuilds a file as it was last applied by overlaying each
+// origin layer's version at its locked commit. It returns nil when a layer is
+// no longer among sources or its history is unavailable.
+func lockedBase(lock *Lock, sources []*Source, rel string) []byte {
+	origins := lock.Origins[rel]
+	if len(origins) == 0 {
+		return nil
+	}
+	var base []byte
+	for _, origin := range origins {
+		layer, ok := lock.Layer(origin)
+		if !ok {
+			return nil
+		}
+		var data []byte
+		for _, src := range sources {
+			if src.Preset == origin {
+				data = src.ReadAt(layer.Commit, rel)
+				break
+			}
+		}
+		if data == nil {
+			return nil
+		}
+		if base == nil {
+			base = data
+			continue
+		}
+		merged, _, err := overlayContent(base, data)
+		if err != nil {
+			return nil
+		}
+		base = merged
+	}
+	return base
+}
+
+// Conflicts returns the changes that leave conflict markers.
+func (p *Plan) Conflicts() []Change {
+	var conflicts []Change
+	for _, c := range p.Changes {
+		if c.Action == ActionConflict {
+			conflicts = append(conflicts, c)
+		}
+	}
+	return conflicts
+}
+
+// Apply writes the planned changes and the new lockfile.
+func (p *Plan) Apply(baseDir string) error {
+	if err := p.write(baseDir); err != nil {
+		return err
+	}
+	return p.Lock.Write(baseDir)
+}
+
+// Preview writes the project's dirs as they would be after Apply into
+// another directory, leaving the project untouched.
+func (p *Plan) Preview(baseDir, dir string, dirs []string) error {
+	for _, name := range dirs {
+		src := filepath.Join(baseDir, name)
+		err := filepath.WalkDir(src, func(path string, d os.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) && path == src {
+					return filepath.SkipDir
+				}
+				return err
+			}
+			rel, err := filepath.Rel(baseDir, path)
+			if err != nil {
+				return err
+			}
+			target := filepath.Join(dir, rel)
+			if d.IsDir() {
+				return os.MkdirAll(target, 0o755)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			return os.WriteFile(target, data, 0o644)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return p.write(dir)
+}
+
+func (p *Plan) write(baseDir string) error {
+	for _, c := range p.Changes {
+		target := filepath.Join(baseDir, filepath.FromSlash(c.Path))
+		switch {
+		case c.Action == ActionRemove:
+			if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		case c.Result != nil:
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			if err := os.WriteFile(target, c.Result, c.mode); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Diff renders the change as a unified diff of the project file.
+func (c Change) Diff() (string, error) {
+	if c.Result == nil && c.Action != ActionRemove {
+		return "", nil
+	}
+	dir, err := os.MkdirTemp("", "strand-preset-diff-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(dir)
+	for prefix, data := range map[string][]byte{"a": c.Local, "b": c.Result} {
+		path := filepath.Join(dir, prefix, filepath.FromSlash(c.Path))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return "", err
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			return "", err
+		}
+	}
+	cmd := exec.Command("git", "diff", "--no-index", "--no-color", "--no-prefix", "--", "a/"+c.Path, "b/"+c.Path)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	var exitErr *exec.ExitError
+	if err != nil && !(errors.As(err, &exitErr) && exitErr.ExitCode() == 1) {
+		return "", fmt.Errorf("git diff: %w", err)
+	}
+	// Drop the "diff --git" and "index" header lines.
+	diff := string(out)
+	if i := strings.Index(diff, "\n--- "); i >= 0 {
+		diff = diff[i+1:]
+	}
+	return diff, nil
+}
+
+type presetFile struct {
+	data []byte
+	mode fs.FileMode
+}
+
+// presetFiles reads the preset files in dirs keyed by slash-separated path.
+func presetFiles(src *Source, dirs []string) (map[string]presetFile, error) {
+	files := make(map[string]presetFile)
+	for _, name := range dirs {
+		root := filepath.Join(src.Dir, name)
+		err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+			if err != nil {
+				// Overlay layers may omit a directory.
+				if os.IsNotExist(err) && path == root {
+					return filepath.SkipDir
+				}
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(src.Dir, path)
+			if err != nil {
+				return err
+			}
+			files[filepath.ToSlash(rel)] = presetFile{data: data, mode: info.Mode().Perm()}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+func inDirs(rel string, dirs []string) bool {
+	for _, name := range dirs {
+		if strings.HasPrefix(rel, name+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeFile runs a three-way merge of local and preset changes against
+// base, leaving conflict markers where they overlap.
+func mergeFile(local, base, preset []byte) ([]byte, bool, error) {
+	dir, err := os.MkdirTemp("", "strand-preset-merge-")
+	if err != nil {
+		return nil, false, err
+	}
+	defer os.RemoveAll(dir)
+	names := []string{"local", "base", "preset"}
+	for i, data := range [][]byte{local, base, preset} {
+		if err := os.WriteFile(filepath.Join(dir, names[i]), data, 0o644); err != nil {
+			return nil, false, err
+		}
+	}
+	cmd := exec.Command("git", "merge-file", "-p", "-L", "local", "-L", "base", "-L", "preset", "local", "base", "preset")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 && exitErr.ExitCode() < 128 {
+		return out, true, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("git merge-file: %w", err)
+	}
+	return out, false, nil
+}
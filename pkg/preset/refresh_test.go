@@ -0,0 +1,229 @@
+package preset
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for rel, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func git(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-c", "user.name=Test", "-c", "user.email=test@example.com"}, args...)...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v: %s", args, err, out)
+	}
+}
+
+func commitAll(t *testing.T, dir, message string) {
+	t.Helper()
+	git(t, dir, "add", "-A")
+	git(t, dir, "commit", "-q", "-m", message)
+}
+
+// applyFresh copies the preset into a project directory and writes its lock.
+func applyFresh(t *testing.T, presetDir, baseDir string) {
+	t.Helper()
+	src, err := Fetch(io.Discard, presetDir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+	plan, err := PlanRefresh(baseDir, []*Source{src}, nil, LockedDirs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := plan.Apply(baseDir); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func planFrom(t *testing.T, presetDir, baseDir string) *Plan {
+	t.Helper()
+	lock, err := ReadLock(baseDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := Fetch(io.Discard, presetDir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+	plan, err := PlanRefresh(baseDir, []*Source{src}, lock, LockedDirs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return plan
+}
+
+func actions(plan *Plan) map[string]Action {
+	got := make(map[string]Action)
+	for _, c := range plan.Changes {
+		got[c.Path] = c.Action
+	}
+	return got
+}
+
+func TestPlanRefreshThreeWay(t *testing.T) {
+	presetDir := t.TempDir()
+	baseDir := t.TempDir()
+	git(t, presetDir, "init", "-q")
+	writeFiles(t, presetDir, map[string]string{
+		"roles/dev.md":        "# Dev\n\none\ntwo\nthree\nfour\nfive\n",
+		"roles/ops.md":        "# Ops\n",
+		"roles/gone.md":       "# Gone\n",
+		"roles/clash.md":      "# Clash\nline\n",
+		"templates/task.md":   "task template\n",
+		"templates/unused.md": "unused\n",
+	})
+	commitAll(t, presetDir, "v1")
+	applyFresh(t, presetDir, baseDir)
+
+	lock, err := ReadLock(baseDir)
+	if err != nil || lock == nil {
+		t.Fatalf("read lock: %v %v", lock, err)
+	}
+	if len(lock.Layers) != 1 || lock.Layers[0].Commit == "" || len(lock.Files) != 6 || lock.Files["roles/ops.md"] != Hash([]byte("# Ops\n")) {
+		t.Fatalf("unexpected lock: %+v", lock)
+	}
+
+	// Local edits.
+	writeFiles(t, baseDir, map[string]string{
+		"roles/dev.md":        "# Dev\n\nONE\ntwo\nthree\nfour\nfive\n",
+		"roles/clash.md":      "# Clash\nlocal line\n",
+		"templates/unused.md": "unused, but mine\n",
+	})
+	// Preset changes.
+	writeFiles(t, presetDir, map[string]string{
+		"roles/dev.md":      "# Dev\n\none\ntwo\nthree\nfour\nFIVE\n",
+		"roles/ops.md":      "# Ops v2\n",
+		"roles/clash.md":    "# Clash\npreset line\n",
+		"roles/new.md":      "# New\n",
+		"templates/task.md": "task template\n",
+	})
+	if err := os.Remove(filepath.Join(presetDir, "roles", "gone.md")); err != nil {
+		t.Fatal(err)
+	}
+	commitAll(t, presetDir, "v2")
+
+	plan := planFrom(t, presetDir, baseDir)
+	want := map[string]Action{
+		"roles/dev.md":        ActionMerge,
+		"roles/ops.md":        ActionUpdate,
+		"roles/gone.md":       ActionRemove,
+		"roles/clash.md":      ActionConflict,
+		"roles/new.md":        ActionAdd,
+		"templates/task.md":   ActionUnchanged,
+		"templates/unused.md": ActionKeep,
+	}
+	got := actions(plan)
+	for path, action := range want {
+		if got[path] != action {
+			t.Errorf("%s: action = %q, want %q", path, got[path], action)
+		}
+	}
+
+	if err := plan.Apply(baseDir); err != nil {
+		t.Fatal(err)
+	}
+	read := func(rel string) string {
+		data, _ := os.ReadFile(filepath.Join(baseDir, rel))
+		return string(data)
+	}
+	if got := read("roles/dev.md"); got != "# Dev\n\nONE\ntwo\nthree\nfour\nFIVE\n" {
+		t.Errorf("merged dev.md = %q", got)
+	}
+	if got := read("roles/clash.md"); !strings.Contains(got, "<<<<<<< local") || !strings.Contains(got, ">>>>>>> preset") {
+		t.Errorf("clash.md lacks conflict markers: %q", got)
+	}
+	if got := read("templates/unused.md"); got != "unused, but mine\n" {
+		t.Errorf("local edit lost: %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(baseDir, "roles", "gone.md")); !os.IsNotExist(err) {
+		t.Errorf("gone.md should be removed, stat err = %v", err)
+	}
+
+	// The new lock tracks the v2 preset, so a second refresh changes nothing
+	// but keeps the local edits.
+	for path, action := range actions(planFrom(t, presetDir, baseDir)) {
+		if action != ActionUnchanged && action != ActionKeep {
+			t.Errorf("second refresh: %s action = %q", path, action)
+		}
+	}
+}
+
+func TestPlanRefreshMergesAgainstBaseSnapshot(t *testing.T) {
+	// A plain directory has no git history to read the base from.
+	presetDir := t.TempDir()
+	baseDir := t.TempDir()
+	writeFiles(t, presetDir, map[string]string{
+		"roles/dev.md":      "# Dev\n\none\ntwo\nthree\nfour\nfive\n",
+		"templates/task.md": "task\n",
+	})
+	applyFresh(t, presetDir, baseDir)
+	if data, err := os.ReadFile(filepath.Join(baseDir, BaseDir, "roles", "dev.md")); err != nil || string(data) != "# Dev\n\none\ntwo\nthree\nfour\nfive\n" {
+		t.Fatalf("base snapshot = %q, %v", data, err)
+	}
+
+	writeFiles(t, baseDir, map[string]string{"roles/dev.md": "# Dev\n\nONE\ntwo\nthree\nfour\nfive\n"})
+	writeFiles(t, presetDir, map[string]string{"roles/dev.md": "# Dev\n\none\ntwo\nthree\nfour\nFIVE\n"})
+
+	plan := planFrom(t, presetDir, baseDir)
+	if got := actions(plan)["roles/dev.md"]; got != ActionMerge {
+		t.Fatalf("dev.md action = %q, want %q", got, ActionMerge)
+	}
+	if err := plan.Apply(baseDir); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(baseDir, "roles", "dev.md")); string(data) != "# Dev\n\nONE\ntwo\nthree\nfour\nFIVE\n" {
+		t.Errorf("merged dev.md = %q", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(baseDir, BaseDir, "roles", "dev.md")); string(data) != "# Dev\n\none\ntwo\nthree\nfour\nFIVE\n" {
+		t.Errorf("base snapshot not updated: %q", data)
+	}
+}
+
+func TestPlanRefreshWithoutLockConflicts(t *testing.T) {
+	presetDir := t.TempDir()
+	baseDir := t.TempDir()
+	writeFiles(t, presetDir, map[string]string{"roles/dev.md": "preset\n", "templates/task.md": "task\n"})
+	writeFiles(t, baseDir, map[string]string{"roles/dev.md": "hand written\n"})
+
+	plan := planFrom(t, presetDir, baseDir)
+	got := actions(plan)
+	if got["roles/dev.md"] != ActionConflict || got["templates/task.md"] != ActionAdd {
+		t.Fatalf("actions = %v", got)
+	}
+	if conflicts := plan.Conflicts(); len(conflicts) != 1 || !strings.Contains(conflicts[0].Note, "no locked base") {
+		t.Errorf("conflicts = %+v", conflicts)
+	}
+}
+
+func TestChangeDiff(t *testing.T) {
+	c := Change{Path: "roles/dev.md", Action: ActionUpdate, Local: []byte("old\n"), Result: []byte("new\n")}
+	diff, err := c.Diff()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"--- a/roles/dev.md", "+++ b/roles/dev.md", "-old", "+new"} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("diff missing %q:\n%s", want, diff)
+		}
+	}
+}
@@ -0,0 +1,334 @@
+// Package preset fetches role and template presets and refreshes projects
+// from them without losing local edits.
+package preset
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Spec is a parsed preset reference of the form url[@ref][#subdir], where ref
+// is a branch, tag or commit and subdir is the preset's directory inside the
+// repository.
+type Spec struct {
+	URL    string
+	Ref    string
+	Subdir string
+}
+
+// ParseSpec splits a preset reference into its URL, ref and subdir. An @ only
+// starts a ref after the last / or :, so scp-style URLs such as
+// git@github.com:user/preset.git keep their user name.
+func ParseSpec(s string) (Spec, error) {
+	var spec Spec
+	if i := strings.LastIndex(s, "#"); i >= 0 {
+		s, spec.Subdir = s[:i], s[i+1:]
+		if spec.Subdir == "" {
+			return Spec{}, fmt.Errorf("preset subdir after # cannot be empty")
+		}
+		clean := path.Clean(filepath.ToSlash(spec.Subdir))
+		if path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+			return Spec{}, fmt.Errorf("preset subdir %q must stay inside the repository", spec.Subdir)
+		}
+		spec.Subdir = clean
+	}
+	if at := strings.LastIndex(s, "@"); at >= 0 && at > strings.LastIndexAny(s, "/:") {
+		s, spec.Ref = s[:at], s[at+1:]
+		if spec.Ref == "" {
+			return Spec{}, fmt.Errorf("preset ref after @ cannot be empty")
+		}
+	}
+	spec.URL = s
+	return spec, nil
+}
+
+// String formats the spec back into url[@ref][#subdir] form.
+func (s Spec) String() string {
+	out := s.URL
+	if s.Ref != "" {
+		out += "@" + s.Ref
+	}
+	if s.Subdir != "" {
+		out += "#" + s.Subdir
+	}
+	return out
+}
+
+// Source is a fetched preset: a local directory or a checkout of a git repo.
+type Source struct {
+	// Preset is the preset reference the source was fetched from.
+	Preset string
+	Spec   Spec
+	// Dir holds the preset's roles/, templates/ and tasks/ directories.
+	Dir string
+	// Commit is the git commit Dir was read at, or "" when the preset is not
+	// a clean git checkout.
+	Commit string
+
+	cleanup func()
+}
+
+// Fetch resolves a preset reference. A local directory is used in place;
+// anything else is fetched with git into a bare clone under cacheDir, which
+// is reused offline when the remote cannot be reached, and the resolved
+// commit is checked out to a temporary directory. With an empty cacheDir the
+// clone is temporary. Close the source when done.
+func Fetch(w io.Writer, preset, cacheDir string) (*Source, error) {
+	if preset == "" {
+		return nil, fmt.Errorf("preset path or URL cannot be empty")
+	}
+	if strings.TrimSpace(preset) == "" {
+		return nil, fmt.Errorf("preset cannot be empty or whitespace")
+	}
+
+	spec := Spec{URL: preset}
+	if info, err := os.Stat(preset); err != nil || !info.IsDir() {
+		if spec, err = ParseSpec(preset); err != nil {
+			return nil, err
+		}
+	}
+	src := &Source{Preset: preset, Spec: spec, cleanup: func() {}}
+
+	// A local directory without a ref is used as is, unless it is a bare
+	// repository with no files to read.
+	if info, err := os.Stat(spec.URL); err == nil && info.IsDir() && spec.Ref == "" && !isBareRepo(spec.URL) {
+		fmt.Fprintf(w, "Using local preset directory: %s\n", spec.URL)
+		if abs, err := filepath.Abs(spec.URL); err == nil {
+			spec.URL = abs
+		}
+		src.Spec = spec
+		src.Preset = spec.String()
+		src.Dir = filepath.Join(spec.URL, filepath.FromSlash(spec.Subdir))
+		src.Commit = cleanCheckoutCommit(spec.URL)
+		return src, nil
+	}
+	if info, err := os.Stat(spec.URL); err == nil && info.IsDir() {
+		if abs, err := filepath.Abs(spec.URL); err == nil {
+			spec.URL = abs
+			src.Spec = spec
+			src.Preset = spec.String()
+		}
+	}
+
+	var tempDirs []string
+	src.cleanup = func() {
+		for _, dir := range tempDirs {
+			_ = os.RemoveAll(dir)
+		}
+	}
+	if cacheDir == "" {
+		dir, err := os.MkdirTemp("", "strand-preset-cache-")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create temp dir: %w", err)
+		}
+		tempDirs = append(tempDirs, dir)
+		cacheDir = dir
+	}
+	repo, err := cachedRepo(w, spec.URL, cacheDir)
+	if err != nil {
+		src.Close()
+		return nil, err
+	}
+
+	commit, err := resolveRef(repo, spec.Ref)
+	if err != nil {
+		src.Close()
+		return nil, err
+	}
+	if spec.Ref != "" {
+		fmt.Fprintf(w, "✓ Resolved %s to %s\n", spec.Ref, commit)
+	}
+
+	checkout, err := os.MkdirTemp("", "strand-preset-")
+	if err != nil {
+		src.Close()
+		return nil, fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	tempDirs = append(tempDirs, checkout)
+	if out, err := exec.Command("git", "clone", "--quiet", "--shared", "--no-checkout", "--", repo, checkout).CombinedOutput(); err != nil {
+		src.Close()
+		return nil, fmt.Errorf("failed to check out preset: %s", strings.TrimSpace(string(out)))
+	}
+	if out, err := exec.Command("git", "-C", checkout, "checkout", "--quiet", "--detach", commit).CombinedOutput(); err != nil {
+		src.Close()
+		return nil, fmt.Errorf("failed to check out preset commit %s: %s", commit, strings.TrimSpace(string(out)))
+	}
+	src.Dir = filepath.Join(checkout, filepath.FromSlash(spec.Subdir))
+	src.Commit = commit
+	fmt.Fprintf(w, "✓ Cloned preset to temporary directory\n")
+	return src, nil
+}
+
+// cachedRepo returns a bare clone of url under cacheDir, cloning it on first
+// use and fetching updates afterwards.
+func cachedRepo(w io.Writer, url, cacheDir string) (string, error) {
+	sum := sha256.Sum256([]byte(url))
+	repo := filepath.Join(cacheDir, hex.EncodeToString(sum[:8])+".git")
+
+	if _, err := os.Stat(repo); err == nil {
+		fmt.Fprintf(w, "Fetching preset from %s...\n", url)
+		cmd := exec.Command("git", "-C", repo, "fetch", "--quiet", "--prune", "--force", "origin",
+			"+refs/heads/*:refs/heads/*", "+refs/tags/*:refs/tags/*")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			fmt.Fprintf(w, "⚠️  Could not fetch preset (%s); using cached copy\n", strings.TrimSpace(string(out)))
+		}
+		return repo, nil
+	}
+
+	fmt.Fprintf(w, "Cloning preset from %s...\n", url)
+	if err := os.MkdirAll(cacheDir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create preset cache: %w", err)
+	}
+	tmp, err := os.MkdirTemp(cacheDir, ".clone-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+	cmd := exec.Command("git", "clone", "--quiet", "--bare", "--", url, tmp)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		outputStr := strings.TrimSpace(string(output))
+		if strings.Contains(outputStr, "not found") || strings.Contains(outputStr, "could not read") {
+			return "", fmt.Errorf("failed to clone preset: repository not found or inaccessible\n  URL: %s\n  Hint: check the URL is correct and accessible", url)
+		}
+		if strings.Contains(outputStr, "Authentication failed") || strings.Contains(outputStr, "authentication required") {
+			return "", fmt.Errorf("failed to clone preset: authentication required\n  URL: %s\n  Hint: use HTTPS URLs for public repos or configure SSH keys for private repos", url)
+		}
+		return "", fmt.Errorf("failed to clone preset from %s:\n  %s", url, outputStr)
+	}
+	if err := os.Rename(tmp, repo); err != nil {
+		return "", fmt.Errorf("failed to cache preset: %w", err)
+	}
+	return repo, nil
+}
+
+// resolveRef returns the commit ref names in repo, defaulting to HEAD. A
+// commit that no branch or tag reaches is fetched by hash.
+func resolveRef(repo, ref string) (string, error) {
+	name := ref
+	if name == "" {
+		name = "HEAD"
+	}
+	if commit, err := gitOutput(repo, "rev-parse", "--verify", "--quiet", name+"^{commit}"); err == nil {
+		return commit, nil
+	}
+	if ref != "" && !strings.HasPrefix(ref, "-") {
+		if err := exec.Command("git", "-C", repo, "fetch", "--quiet", "origin", ref).Run(); err == nil {
+			if commit, err := gitOutput(repo, "rev-parse", "--verify", "--quiet", "FETCH_HEAD^{commit}"); err == nil {
+				return commit, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("preset ref %q not found", name)
+}
+
+// Close removes the temporary checkout and clone, if any.
+func (s *Source) Close() {
+	s.cleanup()
+}
+
+// Validate checks that the preset has each of dirs.
+func (s *Source) Validate(w io.Writer, dirs []string) error {
+	fmt.Fprintf(w, "Validating preset structure...\n")
+	missingDirs := []string{}
+	for _, name := range dirs {
+		src := filepath.Join(s.Dir, name)
+		info, err := os.Stat(src)
+		if err != nil {
+			if os.IsNotExist(err) {
+				missingDirs = append(missingDirs, name)
+				continue
+			}
+			return fmt.Errorf("failed to check %s directory: %w", name, err)
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("preset %s exists but is not a directory", name)
+		}
+	}
+
+	if len(missingDirs) > 0 {
+		return fmt.Errorf("preset is missing required directories: %s\n  Expected: %s\n  Location: %s\n  Hint: a valid preset must contain %s subdirectories",
+			strings.Join(missingDirs, ", "),
+			strings.Join(dirs, ", "),
+			s.Dir,
+			strings.Join(dirs, " and "))
+	}
+	fmt.Fprintf(w, "✓ Preset structure validated\n\n")
+	return nil
+}
+
+// ValidateOverlay checks that a preset layered over another has at least one
+// of dirs.
+func (s *Source) ValidateOverlay(w io.Writer, dirs []string) error {
+	for _, name := range dirs {
+		if info, err := os.Stat(filepath.Join(s.Dir, name)); err == nil && info.IsDir() {
+			return nil
+		}
+	}
+	return fmt.Errorf("preset layer %s has none of the directories: %s\n  Location: %s", s.Preset, strings.Join(dirs, ", "), s.Dir)
+}
+
+// ReadAt returns a preset file as of commit, or nil when it is unavailable.
+func (s *Source) ReadAt(commit, rel string) []byte {
+	if commit == "" || s.Commit == "" {
+		return nil
+	}
+	// "./" makes the path relative to the preset dir rather than the
+	// repository root.
+	out, err := exec.Command("git", "-C", s.Dir, "show", commit+":./"+filepath.ToSlash(rel)).Output()
+	if err != nil {
+		return nil
+	}
+	return out
+}
+
+func isBareRepo(dir string) bool {
+	out, err := gitOutput(dir, "rev-parse", "--is-bare-repository")
+	return err == nil && out == "true"
+}
+
+// cleanCheckoutCommit returns HEAD of dir when dir is the root of a git
+// checkout without uncommitted changes.
+func cleanCheckoutCommit(dir string) string {
+	top, err := gitOutput(dir, "rev-parse", "--show-toplevel")
+	if err != nil || !sameDir(top, dir) {
+		return ""
+	}
+	if status, err := gitOutput(dir, "status", "--porcelain"); err != nil || status != "" {
+		return ""
+	}
+	head, err := gitOutput(dir, "rev-parse", "HEAD")
+	if err != nil {
+		return ""
+	}
+	return head
+}
+
+func sameDir(a, b string) bool {
+	resolve := func(p string) string {
+		if abs, err := filepath.Abs(p); err == nil {
+			p = abs
+		}
+		if real, err := filepath.EvalSymlinks(p); err == nil {
+			p = real
+		}
+		return p
+	}
+	return resolve(a) == resolve(b)
+}
+
+func gitOutput(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
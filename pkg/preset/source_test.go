@@ -0,0 +1,148 @@
+package preset
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseSpec(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Spec
+	}{
+		{"https://example.com/preset.git", Spec{URL: "https://example.com/preset.git"}},
+		{"https://example.com/preset.git@v1.2", Spec{URL: "https://example.com/preset.git", Ref: "v1.2"}},
+		{"https://example.com/preset.git#presets/web", Spec{URL: "https://example.com/preset.git", Subdir: "presets/web"}},
+		{"https://example.com/preset.git@4f2c9e1#web", Spec{URL: "https://example.com/preset.git", Ref: "4f2c9e1", Subdir: "web"}},
+		{"git@github.com:user/preset.git", Spec{URL: "git@github.com:user/preset.git"}},
+		{"git@github.com:user/preset.git@main", Spec{URL: "git@github.com:user/preset.git", Ref: "main"}},
+		{"https://token@example.com/preset.git", Spec{URL: "https://token@example.com/preset.git"}},
+	}
+	for _, tt := range tests {
+		got, err := ParseSpec(tt.in)
+		if err != nil {
+			t.Errorf("ParseSpec(%q): %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseSpec(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+		if got.String() != tt.in {
+			t.Errorf("ParseSpec(%q).String() = %q", tt.in, got.String())
+		}
+	}
+
+	for _, bad := range []string{"repo.git@", "repo.git#", "repo.git#../escape", "repo.git#/abs"} {
+		if _, err := ParseSpec(bad); err == nil {
+			t.Errorf("ParseSpec(%q) should fail", bad)
+		}
+	}
+}
+
+// bareRepo creates a bare repository with two tagged versions of a preset
+// kept in the presets/web subdir, returning its path.
+func bareRepo(t *testing.T) string {
+	t.Helper()
+	work := t.TempDir()
+	git(t, work, "init", "-q")
+	writeFiles(t, work, map[string]string{
+		"presets/web/roles/dev.md":      "dev v1\n",
+		"presets/web/templates/task.md": "task v1\n",
+	})
+	commitAll(t, work, "v1")
+	git(t, work, "tag", "v1")
+	writeFiles(t, work, map[string]string{"presets/web/roles/dev.md": "dev v2\n"})
+	commitAll(t, work, "v2")
+	git(t, work, "tag", "v2")
+
+	bare := filepath.Join(t.TempDir(), "preset.git")
+	git(t, work, "clone", "-q", "--bare", work, bare)
+	return bare
+}
+
+func TestFetchPinnedSubdir(t *testing.T) {
+	bare := bareRepo(t)
+	cacheDir := t.TempDir()
+
+	src, err := Fetch(io.Discard, bare+"@v1#presets/web", cacheDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+	data, err := os.ReadFile(filepath.Join(src.Dir, "roles", "dev.md"))
+	if err != nil || string(data) != "dev v1\n" {
+		t.Fatalf("dev.md at v1 = %q, %v", data, err)
+	}
+	v1, _ := gitOutput(bare, "rev-parse", "v1^{commit}")
+	if src.Commit != v1 {
+		t.Errorf("commit = %s, want %s", src.Commit, v1)
+	}
+	if got := src.ReadAt(v1, "roles/dev.md"); string(got) != "dev v1\n" {
+		t.Errorf("ReadAt through subdir = %q", got)
+	}
+
+	head, err := Fetch(io.Discard, bare+"#presets/web", cacheDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer head.Close()
+	data, _ = os.ReadFile(filepath.Join(head.Dir, "roles", "dev.md"))
+	if string(data) != "dev v2\n" {
+		t.Errorf("dev.md at HEAD = %q", data)
+	}
+
+	if _, err := Fetch(io.Discard, bare+"@nope", cacheDir); err == nil || !strings.Contains(err.Error(), `"nope" not found`) {
+		t.Errorf("unknown ref error = %v", err)
+	}
+}
+
+func TestFetchUsesCacheOffline(t *testing.T) {
+	bare := bareRepo(t)
+	cacheDir := t.TempDir()
+
+	src, err := Fetch(io.Discard, bare+"@v1#presets/web", cacheDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src.Close()
+
+	if err := os.RemoveAll(bare); err != nil {
+		t.Fatal(err)
+	}
+	var out strings.Builder
+	src, err = Fetch(&out, bare+"@v1#presets/web", cacheDir)
+	if err != nil {
+		t.Fatalf("offline fetch: %v", err)
+	}
+	defer src.Close()
+	if !strings.Contains(out.String(), "using cached copy") {
+		t.Errorf("expected cache warning, got:\n%s", out.String())
+	}
+	if data, _ := os.ReadFile(filepath.Join(src.Dir, "roles", "dev.md")); string(data) != "dev v1\n" {
+		t.Errorf("dev.md from cache = %q", data)
+	}
+}
+
+func TestLockVerify(t *testing.T) {
+	files := map[string]string{"roles/dev.md": Hash([]byte("dev"))}
+	layer := func(files map[string]string) LockLayer {
+		return LockLayer{Source: "preset@v1", TreeHash: TreeHash(files)}
+	}
+	lock := &Lock{Layers: []LockLayer{layer(files)}, Files: files, TreeHash: TreeHash(files)}
+	if err := lock.Verify(&Lock{Layers: []LockLayer{layer(files)}}); err != nil {
+		t.Errorf("matching tree: %v", err)
+	}
+	moved := map[string]string{"roles/dev.md": Hash([]byte("moved"))}
+	if err := lock.Verify(&Lock{Layers: []LockLayer{layer(moved)}}); err == nil || !strings.Contains(err.Error(), "integrity check failed") {
+		t.Errorf("changed tree error = %v", err)
+	}
+	if err := lock.Verify(&Lock{Layers: []LockLayer{{Source: "other", TreeHash: TreeHash(files)}}}); err == nil || !strings.Contains(err.Error(), "not recorded") {
+		t.Errorf("unknown layer error = %v", err)
+	}
+	if err := (&Lock{Files: files}).Verify(lock); err == nil {
+		t.Error("lock without tree hash should not verify")
+	}
+}
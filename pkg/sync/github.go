@@ -0,0 +1,257 @@
+package sync
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ricochet1k/strandyard/pkg/task"
+)
+
+// ProviderGitHub names the GitHub REST provider.
+const ProviderGitHub = "github"
+
+// DefaultGitHubURL is the GitHub REST API endpoint.
+const DefaultGitHubURL = "https://api.github.com"
+
+// GitHub is a Provider for the GitHub REST API and servers that implement the
+// same issue endpoints, such as GitHub Enterprise, Gitea and Forgejo.
+type GitHub struct {
+	// BaseURL is the API root, such as https://api.github.com.
+	BaseURL string
+	// Repo is the repository as owner/name.
+	Repo  string
+	Token string
+	// Client defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// NewGitHub returns a provider for repo at baseURL, which defaults to
+// DefaultGitHubURL.
+func NewGitHub(baseURL, repo, token string) (*GitHub, error) {
+	owner, name, ok := strings.Cut(strings.TrimSpace(repo), "/")
+	if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+		return nil, fmt.Errorf("invalid repository %q (expected owner/name)", repo)
+	}
+	if strings.TrimSpace(baseURL) == "" {
+		baseURL = DefaultGitHubURL
+	}
+	return &GitHub{
+		BaseURL: strings.TrimRight(strings.TrimSpace(baseURL), "/"),
+		Repo:    owner + "/" + name,
+		Token:   token,
+	}, nil
+}
+
+// Name implements Provider.
+func (g *GitHub) Name() string { return ProviderGitHub }
+
+type githubIssue struct {
+	Number      int             `json:"number"`
+	HTMLURL     string          `json:"html_url"`
+	Title       string          `json:"title"`
+	Body        *string         `json:"body"`
+	State       string          `json:"state"`
+	PullRequest json.RawMessage `json:"pull_request,omitempty"`
+}
+
+func (i githubIssue) issue() Issue {
+	issue := Issue{
+		ID:     strconv.Itoa(i.Number),
+		URL:    i.HTMLURL,
+		Title:  i.Title,
+		Closed: strings.EqualFold(i.State, "closed"),
+	}
+	if i.Body != nil {
+		issue.Body = *i.Body
+	}
+	return issue
+}
+
+type githubComment struct {
+	ID   int64  `json:"id"`
+	Body string `json:"body"`
+	User struct {
+		Login string `json:"login"`
+	} `json:"user"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ListIssues implements Provider. Pull requests, which the issues endpoint
+// also returns, are skipped.
+func (g *GitHub) ListIssues(ctx context.Context) ([]Issue, error) {
+	var issues []Issue
+	err := g.paginate(ctx, g.repoPath("issues")+"?state=all", func(data []byte) (int, error) {
+		var page []githubIssue
+		if err := json.Unmarshal(data, &page); err != nil {
+			return 0, err
+		}
+		for _, item := range page {
+			if len(item.PullRequest) == 0 {
+				issues = append(issues, item.issue())
+			}
+		}
+		return len(page), nil
+	})
+	return issues, err
+}
+
+// GetIssue implements Provider.
+func (g *GitHub) GetIssue(ctx context.Context, id string) (Issue, error) {
+	var item githubIssue
+	if err := g.do(ctx, http.MethodGet, g.issuePath(id), nil, &item); err != nil {
+		return Issue{}, err
+	}
+	return item.issue(), nil
+}
+
+// CreateIssue implements Provider. GitHub always creates issues open, so a
+// closed issue is closed with a second request.
+func (g *GitHub) CreateIssue(ctx context.Context, fields Issue) (Issue, error) {
+	var item githubIssue
+	payload := map[string]string{"title": fields.Title, "body": fields.Body}
+	if err := g.do(ctx, http.MethodPost, g.repoPath("issues"), payload, &item); err != nil {
+		return Issue{}, err
+	}
+	created := item.issue()
+	if fields.Closed {
+		return g.UpdateIssue(ctx, created.ID, fields)
+	}
+	return created, nil
+}
+
+// UpdateIssue implements Provider.
+func (g *GitHub) UpdateIssue(ctx context.Context, id string, fields Issue) (Issue, error) {
+	state := "open"
+	if fields.Closed {
+		state = "closed"
+	}
+	var item githubIssue
+	payload := map[string]string{"title": fields.Title, "body": fields.Body, "state": state}
+	if err := g.do(ctx, http.MethodPatch, g.issuePath(id), payload, &item); err != nil {
+		return Issue{}, err
+	}
+	return item.issue(), nil
+}
+
+// ListComments implements Provider.
+func (g *GitHub) ListComments(ctx context.Context, id string) ([]Comment, error) {
+	var comments []Comment
+	err := g.paginate(ctx, g.issuePath(id)+"/comments", func(data []byte) (int, error) {
+		var page []githubComment
+		if err := json.Unmarshal(data, &page); err != nil {
+			return 0, err
+		}
+		for _, c := range page {
+			comments = append(comments, Comment{
+				ID:      strconv.FormatInt(c.ID, 10),
+				Author:  c.User.Login,
+				Body:    c.Body,
+				Created: c.CreatedAt,
+			})
+		}
+		return len(page), nil
+	})
+	return comments, err
+}
+
+// IssueFields implements Provider. The issue body is the task description;
+// TODOs and progress stay local, and any non-active status closes the issue.
+func (g *GitHub) IssueFields(t *task.Task) Issue {
+	return Issue{
+		Title:  t.Title(),
+		Body:   t.BodyContent,
+		Closed: t.Meta.Completed || !t.IsActive(),
+	}
+}
+
+func (g *GitHub) repoPath(suffix string) string {
+	return "/repos/" + g.Repo + "/" + suffix
+}
+
+func (g *GitHub) issuePath(id string) string {
+	return g.repoPath("issues/" + url.PathEscape(strings.TrimPrefix(id, "#")))
+}
+
+const githubPageSize = 100
+
+// paginate fetches path page by page until a page comes back short.
+func (g *GitHub) paginate(ctx context.Context, path string, handle func([]byte) (int, error)) error {
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	for page := 1; ; page++ {
+		var data json.RawMessage
+		if err := g.do(ctx, http.MethodGet, fmt.Sprintf("%s%sper_page=%d&page=%d", path, sep, githubPageSize, page), nil, &data); err != nil {
+			return err
+		}
+		n, err := handle(data)
+		if err != nil {
+			return fmt.Errorf("github: GET %s: %w", path, err)
+		}
+		if n < githubPageSize {
+			return nil
+		}
+	}
+}
+
+func (g *GitHub) do(ctx context.Context, method, path string, payload, out interface{}) error {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, g.BaseURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if g.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+g.Token)
+	}
+
+	client := g.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Message != "" {
+			return fmt.Errorf("github: %s %s: %s: %s", method, path, resp.Status, apiErr.Message)
+		}
+		return fmt.Errorf("github: %s %s: %s", method, path, resp.Status)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("github: %s %s: %w", method, path, err)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package sync
+
+import (
+	"fmt"
+
+	"github.com/ricochet1k/strandyard/pkg/task"
+	"gopkg.in/yaml.v3"
+)
+
+// LinkField is the frontmatter key that links a task to a remote issue.
+const LinkField = "sync"
+
+// Link records which remote issue a task mirrors and the state of both sides
+// at the last sync.
+type Link struct {
+	Provider string `yaml:"provider"`
+	Issue    string `yaml:"issue"`
+	URL      string `yaml:"url,omitempty"`
+	// Local and Remote fingerprint the mirrored fields of the task and the
+	// issue as of the last sync, so an edit on one side can be told apart
+	// from edits on both.
+	Local  string `yaml:"local"`
+	Remote string `yaml:"remote"`
+}
+
+// LinkOf returns the task's link, if it has one.
+func LinkOf(t *task.Task) (Link, bool, error) {
+	raw, ok := t.Meta.Fields[LinkField]
+	if !ok || raw == nil {
+		return Link{}, false, nil
+	}
+	data, err := yaml.Marshal(raw)
+	if err != nil {
+		return Link{}, false, err
+	}
+	var link Link
+	if err := yaml.Unmarshal(data, &link); err != nil {
+		return Link{}, false, fmt.Errorf("task %s: invalid %s metadata: %w", t.ID, LinkField, err)
+	}
+	if link.Provider == "" || link.Issue == "" {
+		return Link{}, false, fmt.Errorf("task %s: %s metadata needs provider and issue", t.ID, LinkField)
+	}
+	return link, true, nil
+}
+
+// SetLink stores link in the task's frontmatter.
+func SetLink(t *task.Task, link Link) {
+	if current, ok, _ := LinkOf(t); ok && current == link {
+		return
+	}
+	if t.Meta.Fields == nil {
+		t.Meta.Fields = map[string]interface{}{}
+	}
+	value := map[string]interface{}{
+		"provider": link.Provider,
+		"issue":    link.Issue,
+		"local":    link.Local,
+		"remote":   link.Remote,
+	}
+	if link.URL != "" {
+		value["url"] = link.URL
+	}
+	t.Meta.Fields[LinkField] = value
+	t.MarkDirty()
+}
+
+// RemoveLink drops the task's link and reports whether it had one.
+func RemoveLink(t *task.Task) bool {
+	if _, ok := t.Meta.Fields[LinkField]; !ok {
+		return false
+	}
+	delete(t.Meta.Fields, LinkField)
+	t.MarkDirty()
+	return true
+}
@@ -0,0 +1,80 @@
+// Package sync mirrors tasks to an issue tracker. A Provider talks to the
+// tracker; LinkIssue and Sync connect tasks to remote issues through task
+// metadata, push local edits, pull remote edits and comments, and report
+// tasks edited on both sides since the last sync.
+package sync
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ricochet1k/strandyard/pkg/task"
+)
+
+// Issue holds the fields of a remote issue that are mirrored to a task.
+type Issue struct {
+	// ID identifies the issue within the provider, such as an issue number.
+	ID     string `json:"id"`
+	URL    string `json:"url,omitempty"`
+	Title  string `json:"title"`
+	Body   string `json:"body"`
+	Closed bool   `json:"closed"`
+}
+
+// Comment is a comment on a remote issue.
+type Comment struct {
+	ID      string    `json:"id"`
+	Author  string    `json:"author"`
+	Body    string    `json:"body"`
+	Created time.Time `json:"created"`
+}
+
+// Provider is an issue tracker that tasks can be mirrored to.
+type Provider interface {
+	// Name identifies the provider in task metadata, such as "github".
+	Name() string
+	// ListIssues returns every issue, open or closed.
+	ListIssues(ctx context.Context) ([]Issue, error)
+	GetIssue(ctx context.Context, id string) (Issue, error)
+	// CreateIssue creates an issue from fields; fields.ID is ignored.
+	CreateIssue(ctx context.Context, fields Issue) (Issue, error)
+	UpdateIssue(ctx context.Context, id string, fields Issue) (Issue, error)
+	// ListComments returns the comments on an issue, oldest first.
+	ListComments(ctx context.Context, id string) ([]Comment, error)
+	// IssueFields maps a task to the issue fields the provider stores.
+	IssueFields(t *task.Task) Issue
+}
+
+// fieldsHash fingerprints the mirrored fields of an issue, ignoring its ID,
+// URL, line endings and surrounding whitespace.
+func fieldsHash(issue Issue) string {
+	h := sha256.New()
+	for _, part := range []string{normalizeText(issue.Title), normalizeText(issue.Body), strconv.FormatBool(issue.Closed)} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+func normalizeText(s string) string {
+	return strings.TrimSpace(strings.ReplaceAll(s, "\r\n", "\n"))
+}
+
+// changedFields names the mirrored fields that differ between a and b.
+func changedFields(a, b Issue) []string {
+	var fields []string
+	if normalizeText(a.Title) != normalizeText(b.Title) {
+		fields = append(fields, "title")
+	}
+	if normalizeText(a.Body) != normalizeText(b.Body) {
+		fields = append(fields, "body")
+	}
+	if a.Closed != b.Closed {
+		fields = append(fields, "state")
+	}
+	return fields
+}
@@ -0,0 +1,373 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ricochet1k/strandyard/pkg/task"
+)
+
+// Sides a conflict can be resolved in favor of.
+const (
+	PreferLocal  = "local"
+	PreferRemote = "remote"
+)
+
+// Actions reported for each synced task.
+const (
+	ActionUnchanged = "unchanged"
+	ActionPushed    = "pushed"
+	ActionPulled    = "pulled"
+	ActionConflict  = "conflict"
+	ActionCreated   = "created"
+	ActionLinked    = "linked"
+	ActionMissing   = "missing"
+)
+
+// Options controls Sync and LinkIssue.
+type Options struct {
+	// TaskIDs limits Sync to these tasks, which must be linked. Empty syncs
+	// every task linked to the provider.
+	TaskIDs []string
+	// Prefer resolves tasks edited on both sides: PreferLocal pushes the
+	// task, PreferRemote pulls the issue. Empty reports a conflict.
+	Prefer string
+	// DryRun reports what would happen without changing tasks or issues.
+	DryRun bool
+}
+
+// Result describes what happened to one task.
+type Result struct {
+	TaskID string `json:"task_id"`
+	Issue  string `json:"issue"`
+	URL    string `json:"url,omitempty"`
+	Action string `json:"action"`
+	// Fields lists the mirrored fields that were pushed, pulled or are in
+	// conflict.
+	Fields []string `json:"fields,omitempty"`
+	// Comments counts remote comments added to the task's progress.
+	Comments int    `json:"comments,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Conflicts counts results that are in conflict.
+func Conflicts(results []Result) int {
+	n := 0
+	for _, r := range results {
+		if r.Action == ActionConflict {
+			n++
+		}
+	}
+	return n
+}
+
+func validatePrefer(prefer string) error {
+	switch prefer {
+	case "", PreferLocal, PreferRemote:
+		return nil
+	}
+	return fmt.Errorf("invalid preference %q (expected %s or %s)", prefer, PreferLocal, PreferRemote)
+}
+
+// Sync reconciles linked tasks with their issues. A side changed since the
+// last sync is copied to the other; tasks changed on both sides are reported
+// as conflicts unless opts.Prefer picks a side. New remote comments are
+// appended to each task's progress. Changed tasks are left dirty in db for the
+// caller to save, including when Sync returns an error part-way.
+func Sync(ctx context.Context, p Provider, db *task.TaskDB, opts Options) ([]Result, error) {
+	if err := validatePrefer(opts.Prefer); err != nil {
+		return nil, err
+	}
+	tasks, err := linkedTasks(p, db, opts.TaskIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(tasks) == 0 {
+		return nil, nil
+	}
+
+	var remote map[string]Issue
+	if len(opts.TaskIDs) == 0 {
+		issues, err := p.ListIssues(ctx)
+		if err != nil {
+			return nil, err
+		}
+		remote = make(map[string]Issue, len(issues))
+		for _, issue := range issues {
+			remote[issue.ID] = issue
+		}
+	}
+
+	results := make([]Result, 0, len(tasks))
+	for _, t := range tasks {
+		link, _, _ := LinkOf(t)
+		issue, ok := remote[link.Issue]
+		if !ok {
+			issue, err = p.GetIssue(ctx, link.Issue)
+			if err != nil {
+				results = append(results, Result{TaskID: t.ID, Issue: link.Issue, URL: link.URL, Action: ActionMissing, Error: err.Error()})
+				continue
+			}
+		}
+		result, err := syncTask(ctx, p, db, t, link, issue, opts)
+		results = append(results, result)
+		if err != nil {
+			return results, err
+		}
+	}
+	return results, nil
+}
+
+// linkedTasks returns the requested tasks, or every task linked to p, in ID
+// order.
+func linkedTasks(p Provider, db *task.TaskDB, ids []string) ([]*task.Task, error) {
+	var tasks []*task.Task
+	if len(ids) > 0 {
+		for _, id := range ids {
+			t, err := db.Get(id)
+			if err != nil {
+				return nil, err
+			}
+			link, ok, err := LinkOf(t)
+			if err != nil {
+				return nil, err
+			}
+			if !ok || link.Provider != p.Name() {
+				return nil, fmt.Errorf("task %s is not linked to a %s issue", id, p.Name())
+			}
+			tasks = append(tasks, t)
+		}
+		return tasks, nil
+	}
+	for _, t := range db.GetAll() {
+		link, ok, err := LinkOf(t)
+		if err != nil {
+			return nil, err
+		}
+		if ok && link.Provider == p.Name() {
+			tasks = append(tasks, t)
+		}
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].ID < tasks[j].ID })
+	return tasks, nil
+}
+
+func syncTask(ctx context.Context, p Provider, db *task.TaskDB, t *task.Task, link Link, issue Issue, opts Options) (Result, error) {
+	result := Result{TaskID: t.ID, Issue: issue.ID, URL: firstNonEmpty(issue.URL, link.URL), Action: ActionUnchanged}
+	local := p.IssueFields(t)
+	localChanged := fieldsHash(local) != link.Local
+	remoteChanged := fieldsHash(issue) != link.Remote
+
+	push, pull := localChanged && !remoteChanged, remoteChanged && !localChanged
+	if localChanged && remoteChanged {
+		if fields := changedFields(local, issue); len(fields) > 0 {
+			switch opts.Prefer {
+			case PreferLocal:
+				push = true
+			case PreferRemote:
+				pull = true
+			default:
+				result.Action = ActionConflict
+				result.Fields = fields
+			}
+		}
+	}
+
+	switch {
+	case push:
+		result.Action = ActionPushed
+		result.Fields = changedFields(issue, local)
+		if !opts.DryRun {
+			updated, err := p.UpdateIssue(ctx, issue.ID, local)
+			if err != nil {
+				return result, err
+			}
+			issue = updated
+			result.URL = firstNonEmpty(issue.URL, result.URL)
+		}
+	case pull:
+		result.Action = ActionPulled
+		result.Fields = changedFields(local, issue)
+		if !opts.DryRun {
+			if err := applyIssue(db, t, issue); err != nil {
+				return result, err
+			}
+		}
+	}
+
+	comments, err := p.ListComments(ctx, issue.ID)
+	if err != nil {
+		return result, err
+	}
+	for _, c := range comments {
+		if hasComment(t, c) {
+			continue
+		}
+		result.Comments++
+		if !opts.DryRun {
+			appendComment(t, c)
+		}
+	}
+
+	if !opts.DryRun && result.Action != ActionConflict {
+		SetLink(t, Link{
+			Provider: p.Name(),
+			Issue:    issue.ID,
+			URL:      result.URL,
+			Local:    fieldsHash(p.IssueFields(t)),
+			Remote:   fieldsHash(issue),
+		})
+	}
+	return result, nil
+}
+
+// LinkIssue links a task to a remote issue, given by ID or URL. With an empty
+// issueID it creates an issue from the task. Linking an existing issue whose fields differ from the
+// task needs opts.Prefer to say which side to keep. Changed tasks are left
+// dirty in db for the caller to save.
+func LinkIssue(ctx context.Context, p Provider, db *task.TaskDB, taskID, issueID string, opts Options) (Result, error) {
+	if err := validatePrefer(opts.Prefer); err != nil {
+		return Result{}, err
+	}
+	t, err := db.Get(taskID)
+	if err != nil {
+		return Result{}, err
+	}
+	issueID = strings.TrimPrefix(strings.TrimSpace(issueID), "#")
+	if i := strings.LastIndex(issueID, "/issues/"); i >= 0 {
+		issueID = issueID[i+len("/issues/"):]
+	}
+	if link, ok, err := LinkOf(t); err != nil {
+		return Result{}, err
+	} else if ok && (link.Provider != p.Name() || issueID == "" || link.Issue != issueID) {
+		return Result{}, fmt.Errorf("task %s is already linked to %s; unlink it first", t.ID, firstNonEmpty(link.URL, link.Provider+" issue "+link.Issue))
+	}
+
+	local := p.IssueFields(t)
+	result := Result{TaskID: t.ID, Issue: issueID}
+	var issue Issue
+	if issueID == "" {
+		result.Action = ActionCreated
+		if opts.DryRun {
+			return result, nil
+		}
+		if issue, err = p.CreateIssue(ctx, local); err != nil {
+			return result, err
+		}
+	} else {
+		if issue, err = p.GetIssue(ctx, issueID); err != nil {
+			return result, err
+		}
+		result.Action = ActionLinked
+		if fields := changedFields(local, issue); len(fields) > 0 {
+			result.Fields = fields
+			switch opts.Prefer {
+			case PreferLocal:
+				if !opts.DryRun {
+					if issue, err = p.UpdateIssue(ctx, issue.ID, local); err != nil {
+						return result, err
+					}
+				}
+			case PreferRemote:
+				if !opts.DryRun {
+					if err := applyIssue(db, t, issue); err != nil {
+						return result, err
+					}
+				}
+			default:
+				result.Action = ActionConflict
+				return result, fmt.Errorf("task %s and issue %s differ in %s; pass a preference to keep the local or remote side", t.ID, issueID, strings.Join(fields, ", "))
+			}
+		}
+	}
+
+	result.Issue = issue.ID
+	result.URL = issue.URL
+	if opts.DryRun {
+		return result, nil
+	}
+	SetLink(t, Link{
+		Provider: p.Name(),
+		Issue:    issue.ID,
+		URL:      issue.URL,
+		Local:    fieldsHash(p.IssueFields(t)),
+		Remote:   fieldsHash(issue),
+	})
+	return result, nil
+}
+
+// applyIssue copies an issue's title, body and state onto a task.
+func applyIssue(db *task.TaskDB, t *task.Task, issue Issue) error {
+	if title := strings.TrimSpace(issue.Title); title != "" {
+		t.SetTitle(title)
+	}
+	t.SetBody(strings.ReplaceAll(issue.Body, "\r\n", "\n"))
+
+	closed := t.Meta.Completed || !t.IsActive()
+	if issue.Closed == closed {
+		return nil
+	}
+	if issue.Closed {
+		if err := db.SetCompleted(t.ID, true); err != nil {
+			return err
+		}
+		if err := db.UpdateBlockersAfterCompletion(t.ID); err != nil {
+			return err
+		}
+	} else {
+		if err := db.SetStatus(t.ID, task.StatusOpen); err != nil {
+			return err
+		}
+		if _, err := db.ReconcileBlockerRelationships(); err != nil {
+			return err
+		}
+	}
+	_, err := db.UpdateParentTodosForChild(t.ID)
+	return err
+}
+
+func commentMarker(c Comment) string {
+	return "comment " + c.ID
+}
+
+func hasComment(t *task.Task, c Comment) bool {
+	marker := commentMarker(c)
+	return strings.Contains(t.ProgressContent, marker+" ") || strings.Contains(t.ProgressContent, marker+":")
+}
+
+// appendComment adds a progress entry for a remote comment. Continuation lines
+// are indented so the entry stays a single list item.
+func appendComment(t *task.Task, c Comment) {
+	entry := "- " + c.Created.UTC().Format(task.FieldDateLayout) + " " + commentMarker(c)
+	if c.Author != "" {
+		entry += " by @" + c.Author
+	}
+	entry += ":"
+	for i, line := range strings.Split(normalizeText(c.Body), "\n") {
+		line = strings.TrimRight(line, " \t")
+		switch {
+		case line == "":
+			continue
+		case i == 0:
+			entry += " " + line
+		default:
+			entry += "\n  " + line
+		}
+	}
+	if t.ProgressContent != "" {
+		t.ProgressContent += "\n"
+	}
+	t.ProgressContent += entry
+	t.MarkDirty()
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,225 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/strandyard/pkg/sync/synctest"
+	"github.com/ricochet1k/strandyard/pkg/task"
+)
+
+func writeTask(t *testing.T, tasksRoot, id, title, body string) {
+	t.Helper()
+	dir := filepath.Join(tasksRoot, id)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	content := "---\nrole: dev\npriority: medium\nstatus: open\ndate_created: 2026-01-30T00:00:00Z\ndate_edited: 2026-01-30T00:00:00Z\ncompleted: false\n---\n\n# " + title + "\n\n" + body + "\n"
+	if err := os.WriteFile(filepath.Join(dir, id+".md"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func setup(t *testing.T) (*synctest.GitHub, *GitHub, string) {
+	t.Helper()
+	server := synctest.NewGitHub("acme/app")
+	server.Token = "secret"
+	t.Cleanup(server.Close)
+	provider, err := NewGitHub(server.URL, "acme/app", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return server, provider, t.TempDir()
+}
+
+// reload saves pending changes and reads the tasks back from disk.
+func reload(t *testing.T, db *task.TaskDB, root string) *task.TaskDB {
+	t.Helper()
+	if _, err := db.SaveDirty(); err != nil {
+		t.Fatal(err)
+	}
+	fresh := task.NewTaskDB(root)
+	if err := fresh.LoadAll(); err != nil {
+		t.Fatal(err)
+	}
+	return fresh
+}
+
+func TestLinkIssueCreatesAndLinksExisting(t *testing.T) {
+	server, provider, root := setup(t)
+	ctx := context.Background()
+	writeTask(t, root, "T1aaa-login", "Login form", "Build the login form.")
+	writeTask(t, root, "T2bbb-logout", "Logout button", "Add a logout button.")
+	existing := server.AddIssue("Logout", "Old text", false)
+	db := task.NewTaskDB(root)
+	if err := db.LoadAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	created, err := LinkIssue(ctx, provider, db, "T1aaa-login", "", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.Action != ActionCreated || created.Issue != "2" {
+		t.Errorf("created = %+v", created)
+	}
+	if issue, _ := server.Issue(2); issue.Title != "Login form" || issue.Body != "Build the login form." {
+		t.Errorf("issue = %+v", issue)
+	}
+
+	if _, err := LinkIssue(ctx, provider, db, "T2bbb-logout", strconv.Itoa(existing), Options{}); err == nil || !strings.Contains(err.Error(), "title, body") {
+		t.Fatalf("linking a differing issue without a preference should fail, got %v", err)
+	}
+	linked, err := LinkIssue(ctx, provider, db, "T2bbb-logout", fmt.Sprintf("%s/acme/app/issues/%d", server.URL, existing), Options{Prefer: PreferRemote})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if linked.Action != ActionLinked || linked.Issue != "1" {
+		t.Errorf("linked = %+v", linked)
+	}
+
+	db = reload(t, db, root)
+	logout, _ := db.Get("T2bbb-logout")
+	if logout.Title() != "Logout" || logout.BodyContent != "Old text" {
+		t.Errorf("remote fields should win: %q %q", logout.Title(), logout.BodyContent)
+	}
+	link, ok, err := LinkOf(logout)
+	if err != nil || !ok || link.Issue != "1" || link.URL == "" {
+		t.Errorf("link = %+v, %v, %v", link, ok, err)
+	}
+	if _, err := LinkIssue(ctx, provider, db, "T2bbb-logout", "", Options{}); err == nil {
+		t.Error("linking an already linked task to a new issue should fail")
+	}
+}
+
+func TestSyncPushesPullsAndDetectsConflicts(t *testing.T) {
+	server, provider, root := setup(t)
+	ctx := context.Background()
+	for _, id := range []string{"T1aaa-push", "T2bbb-pull", "T3ccc-both"} {
+		writeTask(t, root, id, "Task "+id, "Body of "+id)
+	}
+	db := task.NewTaskDB(root)
+	if err := db.LoadAll(); err != nil {
+		t.Fatal(err)
+	}
+	issues := map[string]int{}
+	for _, id := range []string{"T1aaa-push", "T2bbb-pull", "T3ccc-both"} {
+		result, err := LinkIssue(ctx, provider, db, id, "", Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		n, err := strconv.Atoi(result.Issue)
+		if err != nil {
+			t.Fatal(err)
+		}
+		issues[id] = n
+	}
+	db = reload(t, db, root)
+
+	results, err := Sync(ctx, provider, db, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results {
+		if r.Action != ActionUnchanged {
+			t.Errorf("fresh links should be unchanged: %+v", r)
+		}
+	}
+
+	push, _ := db.Get("T1aaa-push")
+	push.SetTitle("Pushed title")
+	server.EditIssue(issues["T2bbb-pull"], func(i *synctest.Issue) { i.Body = "Edited remotely"; i.Closed = true })
+	both, _ := db.Get("T3ccc-both")
+	both.SetBody("Local edit")
+	server.EditIssue(issues["T3ccc-both"], func(i *synctest.Issue) { i.Body = "Remote edit" })
+	server.AddComment(issues["T2bbb-pull"], "alice", "Looks good.\n\nShipping it.", time.Date(2026, 3, 4, 5, 0, 0, 0, time.UTC))
+	db = reload(t, db, root)
+
+	dry, err := Sync(ctx, provider, db, Options{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := actions(dry); got != "pushed pulled conflict" || dry[1].Comments != 1 {
+		t.Fatalf("dry run actions = %q, results = %+v", got, dry)
+	}
+	if issue, _ := server.Issue(issues["T1aaa-push"]); issue.Title != "Task T1aaa-push" {
+		t.Error("dry run should not push")
+	}
+
+	results, err = Sync(ctx, provider, db, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := actions(results); got != "pushed pulled conflict" || Conflicts(results) != 1 {
+		t.Fatalf("actions = %q", got)
+	}
+	if results[2].Fields[0] != "body" {
+		t.Errorf("conflict fields = %v", results[2].Fields)
+	}
+	if issue, _ := server.Issue(issues["T1aaa-push"]); issue.Title != "Pushed title" {
+		t.Errorf("title not pushed: %+v", issue)
+	}
+	db = reload(t, db, root)
+	pulled, _ := db.Get("T2bbb-pull")
+	if pulled.BodyContent != "Edited remotely" || !pulled.Meta.Completed {
+		t.Errorf("pull not applied: body %q, completed %v", pulled.BodyContent, pulled.Meta.Completed)
+	}
+	if !strings.Contains(pulled.ProgressContent, "- 2026-03-04 comment 1001 by @alice: Looks good.\n  Shipping it.") {
+		t.Errorf("comment not in progress:\n%s", pulled.ProgressContent)
+	}
+
+	again, err := Sync(ctx, provider, db, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := actions(again); got != "unchanged unchanged conflict" || again[1].Comments != 0 {
+		t.Errorf("second sync actions = %q, results = %+v", got, again)
+	}
+
+	resolved, err := Sync(ctx, provider, db, Options{TaskIDs: []string{"T3ccc-both"}, Prefer: PreferLocal})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved[0].Action != ActionPushed {
+		t.Errorf("resolved = %+v", resolved)
+	}
+	if issue, _ := server.Issue(issues["T3ccc-both"]); issue.Body != "Local edit" {
+		t.Errorf("local side not kept: %+v", issue)
+	}
+}
+
+func TestGitHubPaginatesAndReportsErrors(t *testing.T) {
+	server, provider, _ := setup(t)
+	for i := 0; i < githubPageSize+5; i++ {
+		server.AddIssue("Issue", "", i%2 == 0)
+	}
+	issues, err := provider.ListIssues(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != githubPageSize+5 || !issues[0].Closed || issues[1].Closed {
+		t.Errorf("got %d issues, want %d alternating closed and open", len(issues), githubPageSize+5)
+	}
+
+	bad, _ := NewGitHub(server.URL, "acme/app", "wrong")
+	if _, err := bad.ListIssues(context.Background()); err == nil || !strings.Contains(err.Error(), "Bad credentials") {
+		t.Errorf("want credentials error, got %v", err)
+	}
+	if _, err := NewGitHub("", "no-slash", ""); err == nil {
+		t.Error("repository without owner should be rejected")
+	}
+}
+
+func actions(results []Result) string {
+	parts := make([]string, len(results))
+	for i, r := range results {
+		parts[i] = r.Action
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,228 @@
+// Package synctest provides an in-memory stand-in for the GitHub issues REST
+// API, for testing sync without network access.
+package synctest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Issue is an issue held by the stand-in server.
+type Issue struct {
+	Number int
+	Title  string
+	Body   string
+	Closed bool
+}
+
+// Comment is a comment held by the stand-in server.
+type Comment struct {
+	ID      int64
+	Author  string
+	Body    string
+	Created time.Time
+}
+
+// GitHub serves the issue endpoints of the GitHub REST API for one
+// repository from memory.
+type GitHub struct {
+	*httptest.Server
+	// Repo is the repository the server answers for, as owner/name.
+	Repo string
+	// Token, when set, must be sent as a bearer token.
+	Token string
+
+	mu       sync.Mutex
+	issues   map[int]*Issue
+	comments map[int][]Comment
+	next     int
+	nextID   int64
+	requests int
+}
+
+// NewGitHub starts a stand-in server for repo. Callers must Close it.
+func NewGitHub(repo string) *GitHub {
+	g := &GitHub{
+		Repo:     repo,
+		issues:   map[int]*Issue{},
+		comments: map[int][]Comment{},
+		next:     1,
+		nextID:   1000,
+	}
+	g.Server = httptest.NewServer(http.HandlerFunc(g.serve))
+	return g
+}
+
+// AddIssue stores an issue and returns its number.
+func (g *GitHub) AddIssue(title, body string, closed bool) int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	n := g.next
+	g.next++
+	g.issues[n] = &Issue{Number: n, Title: title, Body: body, Closed: closed}
+	return n
+}
+
+// EditIssue changes a stored issue, as if edited on the website.
+func (g *GitHub) EditIssue(number int, edit func(*Issue)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if issue, ok := g.issues[number]; ok {
+		edit(issue)
+	}
+}
+
+// Issue returns a copy of a stored issue.
+func (g *GitHub) Issue(number int) (Issue, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	issue, ok := g.issues[number]
+	if !ok {
+		return Issue{}, false
+	}
+	return *issue, true
+}
+
+// AddComment stores a comment on an issue.
+func (g *GitHub) AddComment(number int, author, body string, created time.Time) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.nextID++
+	g.comments[number] = append(g.comments[number], Comment{ID: g.nextID, Author: author, Body: body, Created: created})
+}
+
+// Requests counts the API requests served so far.
+func (g *GitHub) Requests() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.requests
+}
+
+func (g *GitHub) serve(w http.ResponseWriter, r *http.Request) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.requests++
+
+	if g.Token != "" && r.Header.Get("Authorization") != "Bearer "+g.Token {
+		writeError(w, http.StatusUnauthorized, "Bad credentials")
+		return
+	}
+	rest, ok := strings.CutPrefix(r.URL.Path, "/repos/"+g.Repo+"/issues")
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	parts := strings.Split(strings.Trim(rest, "/"), "/")
+
+	switch {
+	case parts[0] == "" && r.Method == http.MethodGet:
+		var list []map[string]interface{}
+		for n := 1; n < g.next; n++ {
+			if issue, ok := g.issues[n]; ok {
+				list = append(list, g.issueJSON(issue))
+			}
+		}
+		writeJSON(w, http.StatusOK, page(r, list))
+	case parts[0] == "" && r.Method == http.MethodPost:
+		var payload struct{ Title, Body string }
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || payload.Title == "" {
+			writeError(w, http.StatusUnprocessableEntity, "Validation Failed")
+			return
+		}
+		issue := &Issue{Number: g.next, Title: payload.Title, Body: payload.Body}
+		g.issues[issue.Number] = issue
+		g.next++
+		writeJSON(w, http.StatusCreated, g.issueJSON(issue))
+	default:
+		n, err := strconv.Atoi(parts[0])
+		issue, found := g.issues[n]
+		if err != nil || !found || len(parts) > 2 {
+			writeError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		switch {
+		case len(parts) == 2 && parts[1] == "comments" && r.Method == http.MethodGet:
+			var list []map[string]interface{}
+			for _, c := range g.comments[n] {
+				list = append(list, map[string]interface{}{
+					"id":         c.ID,
+					"body":       c.Body,
+					"user":       map[string]string{"login": c.Author},
+					"created_at": c.Created.UTC().Format(time.RFC3339),
+				})
+			}
+			writeJSON(w, http.StatusOK, page(r, list))
+		case len(parts) == 1 && r.Method == http.MethodGet:
+			writeJSON(w, http.StatusOK, g.issueJSON(issue))
+		case len(parts) == 1 && r.Method == http.MethodPatch:
+			var payload struct {
+				Title *string
+				Body  *string
+				State *string
+			}
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+				writeError(w, http.StatusBadRequest, "Problems parsing JSON")
+				return
+			}
+			if payload.Title != nil {
+				issue.Title = *payload.Title
+			}
+			if payload.Body != nil {
+				issue.Body = *payload.Body
+			}
+			if payload.State != nil {
+				issue.Closed = *payload.State == "closed"
+			}
+			writeJSON(w, http.StatusOK, g.issueJSON(issue))
+		default:
+			writeError(w, http.StatusNotFound, "Not Found")
+		}
+	}
+}
+
+func (g *GitHub) issueJSON(issue *Issue) map[string]interface{} {
+	state := "open"
+	if issue.Closed {
+		state = "closed"
+	}
+	return map[string]interface{}{
+		"number":   issue.Number,
+		"html_url": fmt.Sprintf("%s/%s/issues/%d", g.URL, g.Repo, issue.Number),
+		"title":    issue.Title,
+		"body":     issue.Body,
+		"state":    state,
+	}
+}
+
+// page applies the per_page and page query parameters.
+func page(r *http.Request, list []map[string]interface{}) []map[string]interface{} {
+	perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+	if perPage <= 0 {
+		perPage = 30
+	}
+	p, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if p <= 0 {
+		p = 1
+	}
+	start := (p - 1) * perPage
+	if start >= len(list) {
+		return []map[string]interface{}{}
+	}
+	return list[start:min(start+perPage, len(list))]
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"message": message})
+}
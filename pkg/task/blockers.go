@@ -12,6 +12,7 @@ import (
 // - Incomplete tasks listed in Blockers or Blocks are treated as blocker edges.
 // - Completed tasks do not block other tasks.
 // - Blockers and Blocks are always rewritten as bidirectional, sorted, unique sets.
+// - Cross-project blockers (project:TaskID) are kept on incomplete tasks as is.
 //
 // Returns the number of tasks marked dirty.
 func ReconcileBlockerRelationships(tasks map[string]*Task) (int, error) {
@@ -46,6 +47,13 @@ func ReconcileBlockerRelationships(tasks map[string]*Task) (int, error) {
 		}
 
 		for _, blockerID := range current.Meta.Blockers {
+			if IsRemoteRef(blockerID) {
+				if desiredBlockers[current.ID] == nil {
+					desiredBlockers[current.ID] = make(map[string]struct{})
+				}
+				desiredBlockers[current.ID][blockerID] = struct{}{}
+				continue
+			}
 			addEdge(current.ID, blockerID)
 		}
 	}
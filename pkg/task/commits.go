@@ -0,0 +1,105 @@
+package task
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var trailerPattern = regexp.MustCompile(`^([A-Za-z0-9-]+)\s*:\s*(.*)$`)
+
+// commitEntryPattern matches a progress entry written by LinkCommit.
+var commitEntryPattern = regexp.MustCompile(`^- \d{4}-\d{2}-\d{2} commit ([0-9a-fA-F]+): `)
+
+// Commit trailers that link a commit to tasks.
+const (
+	TrailerTask      = "Strand-Task"
+	TrailerCompletes = "Strand-Completes"
+)
+
+// CommitLink is a task referenced by a commit trailer.
+type CommitLink struct {
+	TaskID    string
+	Completes bool
+}
+
+// ParseCommitTrailers returns the tasks referenced by Strand-Task and
+// Strand-Completes trailers. Like git, it only reads the last paragraph of
+// the message, and only when every line there is a trailer. A trailer may
+// list several comma-separated IDs; a task both referenced and completed is
+// returned once, as completed.
+func ParseCommitTrailers(message string) []CommitLink {
+	paragraphs := strings.Split(strings.TrimSpace(strings.ReplaceAll(message, "\r\n", "\n")), "\n\n")
+	last := strings.Split(paragraphs[len(paragraphs)-1], "\n")
+	for _, line := range last {
+		if !trailerPattern.MatchString(line) {
+			return nil
+		}
+	}
+
+	var links []CommitLink
+	index := make(map[string]int)
+	for _, line := range last {
+		match := trailerPattern.FindStringSubmatch(line)
+		completes := false
+		switch {
+		case strings.EqualFold(match[1], TrailerTask):
+		case strings.EqualFold(match[1], TrailerCompletes):
+			completes = true
+		default:
+			continue
+		}
+		for _, id := range strings.Split(match[2], ",") {
+			id = strings.TrimSpace(id)
+			if id == "" || strings.ContainsAny(id, " \t") {
+				continue
+			}
+			if i, seen := index[id]; seen {
+				links[i].Completes = links[i].Completes || completes
+				continue
+			}
+			index[id] = len(links)
+			links = append(links, CommitLink{TaskID: id, Completes: completes})
+		}
+	}
+	return links
+}
+
+// LinkCommit appends a progress entry for a commit to the task. It returns
+// false when the task already lists the commit.
+func (t *Task) LinkCommit(hash, subject string, when time.Time) bool {
+	short := ShortCommit(hash)
+	for _, linked := range t.LinkedCommits() {
+		if linked == short {
+			return false
+		}
+	}
+	entry := fmt.Sprintf("- %s commit %s: %s", when.UTC().Format(FieldDateLayout), short, strings.TrimSpace(subject))
+	if t.ProgressContent != "" {
+		t.ProgressContent += "\n"
+	}
+	t.ProgressContent += entry
+	t.MarkDirty()
+	return true
+}
+
+// LinkedCommits returns the short hashes of the commits LinkCommit recorded
+// in the task's progress, in order.
+func (t *Task) LinkedCommits() []string {
+	var hashes []string
+	for _, line := range strings.Split(t.ProgressContent, "\n") {
+		if match := commitEntryPattern.FindStringSubmatch(strings.TrimSpace(line)); match != nil {
+			hashes = append(hashes, match[1])
+		}
+	}
+	return hashes
+}
+
+// ShortCommit abbreviates a commit hash for display.
+func ShortCommit(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
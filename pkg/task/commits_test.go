@@ -0,0 +1,76 @@
+package task
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCommitTrailers(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    []CommitLink
+	}{
+		{
+			name:    "no trailers",
+			message: "Fix parser\n\nStrand-Task: T1abc is mentioned in the body only\nmore text",
+			want:    nil,
+		},
+		{
+			name:    "task and completes",
+			message: "Fix parser\n\nLonger description.\n\nStrand-Task: T1abc, T2def\nSigned-off-by: A <a@example.com>\nstrand-completes: T3ghi\n",
+			want: []CommitLink{
+				{TaskID: "T1abc"},
+				{TaskID: "T2def"},
+				{TaskID: "T3ghi", Completes: true},
+			},
+		},
+		{
+			name:    "completes upgrades task",
+			message: "Subject\n\nStrand-Task: T1abc\nStrand-Completes: T1abc\n",
+			want:    []CommitLink{{TaskID: "T1abc", Completes: true}},
+		},
+		{
+			name:    "subject only",
+			message: "Strand-Completes: T1abc",
+			want:    []CommitLink{{TaskID: "T1abc", Completes: true}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseCommitTrailers(tt.message); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseCommitTrailers() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLinkCommit(t *testing.T) {
+	task := &Task{ProgressContent: "- started"}
+	when := time.Date(2026, 4, 1, 23, 0, 0, 0, time.FixedZone("x", -2*3600))
+	if !task.LinkCommit("0123456789abcdef", "Fix parser", when) {
+		t.Fatal("expected first link to be added")
+	}
+	if task.LinkCommit("0123456789abcdef", "Fix parser", when) {
+		t.Fatal("expected repeated link to be skipped")
+	}
+	want := "- started\n- 2026-04-02 commit 0123456: Fix parser"
+	if task.ProgressContent != want {
+		t.Errorf("progress = %q, want %q", task.ProgressContent, want)
+	}
+	if !task.Dirty || !strings.Contains(task.Content(), "## Progress\n"+want) {
+		t.Errorf("expected dirty task with progress section, got:\n%s", task.Content())
+	}
+}
+
+func TestLinkCommitIgnoresMentionsOutsideEntries(t *testing.T) {
+	task := &Task{ProgressContent: "- reverted commit 0123456 by hand\n- 2026-04-01 commit fedcba9: Revert \"commit 0123456: Fix parser\""}
+	if !task.LinkCommit("0123456789abcdef", "Fix parser again", time.Date(2026, 4, 3, 0, 0, 0, 0, time.UTC)) {
+		t.Fatal("expected a commit only mentioned in other text to be linked")
+	}
+	if got, want := task.LinkedCommits(), []string{"fedcba9", "0123456"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LinkedCommits() = %v, want %v", got, want)
+	}
+}
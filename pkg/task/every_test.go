@@ -1,11 +1,11 @@
-package cmd
+package task
 
 import (
 	"testing"
 )
 
 func TestValidateEvery(t *testing.T) {
-	// Test that validateEvery handles various inputs correctly
+	// Test that ValidateEvery handles various inputs correctly
 	tests := []struct {
 		name    string
 		every   []string
@@ -26,15 +26,15 @@ func TestValidateEvery(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// We use "." as repoPath, which is the current directory (should be a git repo)
-			_, err := validateEvery(tt.every, ".", nil)
+			_, err := ValidateEvery(tt.every, ".", nil)
 
 			if tt.isValid {
 				if err != nil {
-					t.Errorf("validateEvery(%v) expected no error but got %v", tt.every, err)
+					t.Errorf("ValidateEvery(%v) expected no error but got %v", tt.every, err)
 				}
 			} else {
 				if err == nil {
-					t.Errorf("validateEvery(%v) expected error but got nil", tt.every)
+					t.Errorf("ValidateEvery(%v) expected error but got nil", tt.every)
 				}
 			}
 		})
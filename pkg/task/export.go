@@ -0,0 +1,207 @@
+package task
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Export formats accepted by ExportTasks.
+const (
+	ExportJSONL    = "jsonl"
+	ExportCSV      = "csv"
+	ExportMarkdown = "md"
+)
+
+// DefaultExportColumns are the CSV columns used when ListOptions.Columns is
+// empty. Any list column, including custom fields, can be requested.
+var DefaultExportColumns = []string{"id", "title", "status", "priority", "role", "parent", "blockers", "blocks", "completed", "date_created", "date_edited"}
+
+// ExportRecord is one line of a JSON Lines export: the full task snapshot
+// plus its Markdown body without frontmatter.
+type ExportRecord struct {
+	TaskSnapshot
+	Body string `json:"body"`
+}
+
+// ExportTasks writes the tasks under tasksRoot that match opts in the format
+// named by opts.Format, sorted like ListTasks.
+func ExportTasks(w io.Writer, tasksRoot string, opts ListOptions) error {
+	switch opts.Format {
+	case ExportJSONL, ExportCSV, ExportMarkdown:
+	default:
+		return fmt.Errorf("invalid format %q (expected jsonl, csv, or md)", opts.Format)
+	}
+	if err := ValidateListFilters(opts); err != nil {
+		return err
+	}
+
+	db := NewTaskDB(tasksRoot)
+	if err := db.LoadAll(); err != nil {
+		return err
+	}
+	all := db.GetAll()
+	items, err := filterTasks(tasksRoot, all, opts)
+	if err != nil {
+		return err
+	}
+	if opts.NeedsImpact() && opts.Impact == nil {
+		opts.Impact = ComputeImpact(all)
+	}
+	sortTasks(items, opts)
+
+	switch opts.Format {
+	case ExportJSONL:
+		return exportJSONL(w, items)
+	case ExportCSV:
+		return exportCSV(w, items, opts)
+	default:
+		return exportMarkdown(w, items, all, opts)
+	}
+}
+
+func exportJSONL(w io.Writer, tasks []*Task) error {
+	enc := json.NewEncoder(w)
+	for _, t := range tasks {
+		snapshot, err := snapshotFromTask(t)
+		if err != nil {
+			return err
+		}
+		_, body, _, err := splitFrontmatter(snapshot.Content)
+		if err != nil {
+			return fmt.Errorf("task %s: %w", t.ID, err)
+		}
+		if err := enc.Encode(ExportRecord{TaskSnapshot: *snapshot, Body: body}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func exportCSV(w io.Writer, tasks []*Task, opts ListOptions) error {
+	columns := normalizeColumns(opts.Columns, DefaultExportColumns)
+	cw := csv.NewWriter(w)
+	if err := cw.Write(columns); err != nil {
+		return err
+	}
+	for _, row := range toListRows(tasks, opts.Impact) {
+		values := make([]string, 0, len(columns))
+		for _, col := range columns {
+			values = append(values, columnValue(row, col, false))
+		}
+		if err := cw.Write(values); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// exportMarkdown writes one section per epic, in the order the epics' first
+// tasks were sorted, followed by an "Other" section for tasks no epic owns.
+func exportMarkdown(w io.Writer, tasks []*Task, all map[string]*Task, opts ListOptions) error {
+	var order []string
+	groups := make(map[string][]*Task)
+	var other []*Task
+	for _, t := range tasks {
+		epicID := owningEpic(t, all)
+		if epicID == "" {
+			other = append(other, t)
+			continue
+		}
+		if _, ok := groups[epicID]; !ok {
+			order = append(order, epicID)
+		}
+		groups[epicID] = append(groups[epicID], t)
+	}
+
+	columns := normalizeColumns(opts.Columns, []string{"status", "priority", "role", "blockers"})
+	fmt.Fprintln(w, "# Task Export")
+	for _, epicID := range order {
+		fmt.Fprintf(w, "\n## %s (`%s`)\n", exportTitle(all[epicID], epicID), ShortID(epicID))
+		children := make([]*Task, 0, len(groups[epicID]))
+		for _, t := range groups[epicID] {
+			if t.ID == epicID {
+				writeExportedTask(w, t, columns, opts)
+				continue
+			}
+			children = append(children, t)
+		}
+		writeExportedSection(w, children, columns, opts)
+	}
+	if len(other) > 0 {
+		fmt.Fprintln(w, "\n## Other")
+		writeExportedSection(w, other, columns, opts)
+	}
+	return nil
+}
+
+// writeExportedSection writes the completed count and then each task under
+// its own heading.
+func writeExportedSection(w io.Writer, tasks []*Task, columns []string, opts ListOptions) {
+	if len(tasks) == 0 {
+		return
+	}
+	done := 0
+	for _, t := range tasks {
+		if t.Meta.Completed {
+			done++
+		}
+	}
+	fmt.Fprintf(w, "\n%d of %d tasks completed.\n", done, len(tasks))
+	for _, t := range tasks {
+		fmt.Fprintf(w, "\n### %s (`%s`)\n", t.Title(), ShortID(t.ID))
+		writeExportedTask(w, t, columns, opts)
+	}
+}
+
+func writeExportedTask(w io.Writer, t *Task, columns []string, opts ListOptions) {
+	row := toListRows([]*Task{t}, opts.Impact)[0]
+	if parts := listMetadataParts(row, columns, ListOptions{}); len(parts) > 0 {
+		fmt.Fprintf(w, "\n%s\n", strings.Join(parts, " · "))
+	}
+	if body := strings.TrimSpace(t.BodyContent); body != "" {
+		fmt.Fprintf(w, "\n%s\n", body)
+	}
+	if len(t.TodoItems) > 0 {
+		fmt.Fprintln(w)
+		for _, todo := range t.TodoItems {
+			mark := " "
+			if todo.Checked {
+				mark = "x"
+			}
+			fmt.Fprintf(w, "- [%s] %s\n", mark, todo.Text)
+		}
+	}
+}
+
+// owningEpic returns the nearest epic among t and its local ancestors, or ""
+// when none is an epic. It stops at remote or missing parents and at cycles.
+func owningEpic(t *Task, all map[string]*Task) string {
+	seen := make(map[string]bool)
+	for current := t; current != nil && !seen[current.ID]; current = all[current.Meta.Parent] {
+		if isEpic(current) {
+			return current.ID
+		}
+		seen[current.ID] = true
+	}
+	return ""
+}
+
+// isEpic reports whether t was created from an epic template. Tasks without
+// a recorded type fall back to the default epic ID prefix, "E".
+func isEpic(t *Task) bool {
+	if t.Meta.Type != "" {
+		return strings.Contains(strings.ToLower(t.Meta.Type), "epic")
+	}
+	return strings.HasPrefix(t.ID, "E")
+}
+
+func exportTitle(t *Task, id string) string {
+	if t == nil || t.Title() == "" {
+		return id
+	}
+	return t.Title()
+}
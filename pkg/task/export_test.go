@@ -0,0 +1,131 @@
+package task
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func setupExportTasks(t *testing.T) string {
+	t.Helper()
+	db, tasksRoot := setupTestDB(t)
+	createTaskFile(t, tasksRoot, "E1aaa-auth-epic", "Auth Epic")
+	createTaskFile(t, tasksRoot, "T1aaa-login-form", "Login Form")
+	createTaskFile(t, tasksRoot, "T2aaa-session-store", "Session Store")
+	createTaskFile(t, tasksRoot, "T3aaa-fix-typo", "Fix Typo")
+	if err := db.LoadAll(); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"T1aaa-login-form", "T2aaa-session-store"} {
+		if err := db.SetParent(id, "E1aaa-auth-epic"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.SetCompleted("T2aaa-session-store", true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.SaveDirty(); err != nil {
+		t.Fatal(err)
+	}
+	return tasksRoot
+}
+
+func TestExportJSONL(t *testing.T) {
+	tasksRoot := setupExportTasks(t)
+
+	var out bytes.Buffer
+	if err := ExportTasks(&out, tasksRoot, ListOptions{Format: ExportJSONL, Sort: "id"}); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("got %d lines, want 4:\n%s", len(lines), out.String())
+	}
+	var record ExportRecord
+	if err := json.Unmarshal([]byte(lines[1]), &record); err != nil {
+		t.Fatal(err)
+	}
+	if record.ID != "T1aaa-login-form" || record.Meta.Parent != "E1aaa-auth-epic" {
+		t.Errorf("record = %+v", record.TaskSnapshot)
+	}
+	if !strings.HasPrefix(record.Body, "# Login Form") || strings.Contains(record.Body, "---") {
+		t.Errorf("body should be the markdown without frontmatter: %q", record.Body)
+	}
+}
+
+func TestExportCSVUsesListColumns(t *testing.T) {
+	tasksRoot := setupExportTasks(t)
+
+	var out bytes.Buffer
+	opts := ListOptions{Format: ExportCSV, Sort: "id", Completed: boolPtr(false), Columns: []string{"id", "title", "parent"}}
+	if err := ExportTasks(&out, tasksRoot, opts); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"id", "title", "parent"},
+		{"E1aaa", "Auth Epic", ""},
+		{"T1aaa", "Login Form", "E1aaa"},
+		{"T3aaa", "Fix Typo", ""},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("records = %v, want %v", records, want)
+	}
+	for i := range want {
+		if strings.Join(records[i], "|") != strings.Join(want[i], "|") {
+			t.Errorf("row %d = %v, want %v", i, records[i], want[i])
+		}
+	}
+}
+
+func TestExportMarkdownGroupsByEpic(t *testing.T) {
+	tasksRoot := setupExportTasks(t)
+	// A grandchild stays with its epic; a child of a non-epic root goes to Other.
+	createTaskFile(t, tasksRoot, "T4aaa-remember-me", "Remember Me")
+	createTaskFile(t, tasksRoot, "T5aaa-fix-more-typos", "Fix More Typos")
+	db := NewTaskDB(tasksRoot)
+	if err := db.LoadAll(); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SetParent("T4aaa-remember-me", "T1aaa-login-form"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SetParent("T5aaa-fix-more-typos", "T3aaa-fix-typo"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.SaveDirty(); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := ExportTasks(&out, tasksRoot, ListOptions{Format: ExportMarkdown, Sort: "id"}); err != nil {
+		t.Fatal(err)
+	}
+	report := out.String()
+	for _, want := range []string{
+		"## Auth Epic (`E1aaa`)",
+		"1 of 3 tasks completed.",
+		"### Login Form (`T1aaa`)",
+		"### Session Store (`T2aaa`)",
+		"### Remember Me (`T4aaa`)",
+		"## Other",
+		"0 of 2 tasks completed.",
+		"### Fix Typo (`T3aaa`)",
+		"### Fix More Typos (`T5aaa`)",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report missing %q:\n%s", want, report)
+		}
+	}
+	if strings.Count(report, "\n## ") != 2 {
+		t.Errorf("want exactly the epic and Other sections:\n%s", report)
+	}
+	if strings.Index(report, "### Remember Me") > strings.Index(report, "## Other") {
+		t.Errorf("epic descendants should stay in the epic section:\n%s", report)
+	}
+}
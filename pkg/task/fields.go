@@ -0,0 +1,340 @@
+package task
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Custom field types.
+const (
+	FieldString = "string"
+	FieldNumber = "number"
+	FieldBool   = "bool"
+	FieldEnum   = "enum"
+	FieldDate   = "date"
+)
+
+// FieldDateLayout is the format of date field values.
+const FieldDateLayout = "2006-01-02"
+
+var fieldNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// FieldSpec declares a custom frontmatter field.
+type FieldSpec struct {
+	// Type is one of the Field* constants; empty means string.
+	Type string `yaml:"type"`
+	// Values lists the allowed values of an enum field.
+	Values []string `yaml:"values"`
+	// RequiredFor lists the templates whose tasks must set the field; "*"
+	// matches every template.
+	RequiredFor []string `yaml:"required_for"`
+	// Help describes the field.
+	Help string `yaml:"help"`
+}
+
+// FieldSchema maps custom field names to their specs.
+type FieldSchema map[string]FieldSpec
+
+// Kind returns the field type, defaulting to string.
+func (s FieldSpec) Kind() string {
+	if s.Type == "" {
+		return FieldString
+	}
+	return s.Type
+}
+
+// RequiredForType reports whether tasks created from the template taskType
+// must set the field.
+func (s FieldSpec) RequiredForType(taskType string) bool {
+	for _, name := range s.RequiredFor {
+		if name == "*" || strings.EqualFold(name, taskType) {
+			return true
+		}
+	}
+	return false
+}
+
+// Check reports whether a frontmatter value is valid for the field.
+func (s FieldSpec) Check(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case map[string]interface{}, []interface{}:
+		return fmt.Errorf("expected a single %s value", s.Kind())
+	case string:
+		_, err := s.Parse(v)
+		return err
+	}
+	switch s.Kind() {
+	case FieldNumber:
+		switch value.(type) {
+		case int, int64, uint64, float64:
+			return nil
+		}
+		return fmt.Errorf("%v is not a number", value)
+	case FieldBool:
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%v is not true or false", value)
+		}
+		return nil
+	case FieldDate:
+		if _, ok := value.(time.Time); !ok {
+			return fmt.Errorf("%v is not a date (expected %s)", value, FieldDateLayout)
+		}
+		return nil
+	default:
+		_, err := s.Parse(FormatFieldValue(value))
+		return err
+	}
+}
+
+// Parse converts a command-line value to the value stored in frontmatter.
+func (s FieldSpec) Parse(raw string) (interface{}, error) {
+	raw = strings.TrimSpace(raw)
+	switch s.Kind() {
+	case FieldNumber:
+		if n, err := strconv.Atoi(raw); err == nil {
+			return n, nil
+		}
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", raw)
+		}
+		return f, nil
+	case FieldBool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not true or false", raw)
+		}
+		return b, nil
+	case FieldEnum:
+		for _, allowed := range s.Values {
+			if raw == allowed {
+				return raw, nil
+			}
+		}
+		return nil, fmt.Errorf("%q is not one of %s", raw, strings.Join(s.Values, ", "))
+	case FieldDate:
+		if _, err := time.Parse(FieldDateLayout, raw); err != nil {
+			return nil, fmt.Errorf("%q is not a date (expected %s)", raw, FieldDateLayout)
+		}
+		return raw, nil
+	default:
+		return raw, nil
+	}
+}
+
+// Validate checks the schema itself: names, types and enum values.
+func (s FieldSchema) Validate() error {
+	reserved := reservedFieldNames()
+	for _, name := range s.Names() {
+		spec := s[name]
+		switch {
+		case !fieldNamePattern.MatchString(name):
+			return fmt.Errorf("fields.%s: invalid field name (expected lowercase letters, digits and _)", name)
+		case reserved[name]:
+			return fmt.Errorf("fields.%s: %s is a built-in task field", name, name)
+		}
+		switch spec.Kind() {
+		case FieldString, FieldNumber, FieldBool, FieldDate:
+			if len(spec.Values) > 0 {
+				return fmt.Errorf("fields.%s: values are only allowed for enum fields", name)
+			}
+		case FieldEnum:
+			if len(spec.Values) == 0 {
+				return fmt.Errorf("fields.%s: enum field needs values", name)
+			}
+		default:
+			return fmt.Errorf("fields.%s: invalid type %q (expected string, number, bool, enum, or date)", name, spec.Type)
+		}
+	}
+	return nil
+}
+
+// ParseValues converts command-line values for a new task of taskType to
+// frontmatter values. Undeclared fields, invalid values and missing required
+// fields are errors.
+func (s FieldSchema) ParseValues(taskType string, values map[string]string) (map[string]interface{}, error) {
+	out := make(map[string]interface{}, len(values))
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		spec, ok := s[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown field %q (declared: %s)", name, strings.Join(s.Names(), ", "))
+		}
+		if strings.TrimSpace(values[name]) == "" {
+			continue
+		}
+		value, err := spec.Parse(values[name])
+		if err != nil {
+			return nil, fmt.Errorf("invalid field %s: %w", name, err)
+		}
+		out[name] = value
+	}
+	var missing []string
+	for _, name := range s.Names() {
+		if _, ok := out[name]; !ok && s[name].RequiredForType(taskType) {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%s tasks require field(s) %s (use --field name=value)", taskType, strings.Join(missing, ", "))
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return out, nil
+}
+
+// Names returns the declared field names in sorted order.
+func (s FieldSchema) Names() []string {
+	names := make([]string, 0, len(s))
+	for name := range s {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Has reports whether name is a declared field.
+func (s FieldSchema) Has(name string) bool {
+	_, ok := s[name]
+	return ok
+}
+
+// CheckTask returns a message for each declared field t sets to an invalid
+// value or leaves unset although its template requires it.
+func (s FieldSchema) CheckTask(t *Task) []string {
+	var problems []string
+	for _, name := range s.Names() {
+		spec := s[name]
+		value, ok := t.Meta.Fields[name]
+		if !ok || value == nil || FormatFieldValue(value) == "" {
+			if spec.RequiredForType(t.Meta.Type) {
+				problems = append(problems, fmt.Sprintf("missing field %s (required for %s tasks)", name, t.Meta.Type))
+			}
+			continue
+		}
+		if err := spec.Check(value); err != nil {
+			problems = append(problems, fmt.Sprintf("invalid field %s: %v", name, err))
+		}
+	}
+	return problems
+}
+
+// Field returns the display value of a custom frontmatter field, or "".
+func (t *Task) Field(name string) string {
+	return FormatFieldValue(t.Meta.Fields[name])
+}
+
+// SetField sets a custom frontmatter field; a nil value removes it.
+func (t *Task) SetField(name string, value interface{}) error {
+	if reservedFieldNames()[name] {
+		return fmt.Errorf("%s is a built-in task field", name)
+	}
+	current, ok := t.Meta.Fields[name]
+	if value == nil {
+		if !ok {
+			return nil
+		}
+		delete(t.Meta.Fields, name)
+		t.MarkDirty()
+		return nil
+	}
+	if ok && reflect.DeepEqual(current, value) {
+		return nil
+	}
+	if t.Meta.Fields == nil {
+		t.Meta.Fields = map[string]interface{}{}
+	}
+	t.Meta.Fields[name] = value
+	t.MarkDirty()
+	return nil
+}
+
+// FormatFieldValue renders a frontmatter value for display and comparison.
+func FormatFieldValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case time.Time:
+		if v.Equal(v.Truncate(24 * time.Hour)) {
+			return v.Format(FieldDateLayout)
+		}
+		return v.Format(time.RFC3339)
+	case []interface{}:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			parts = append(parts, FormatFieldValue(item))
+		}
+		return strings.Join(parts, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// compareFieldValues orders two display values of a field, numerically or
+// chronologically when the spec says so. Empty values sort last.
+func compareFieldValues(spec FieldSpec, a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+	if spec.Kind() == FieldNumber {
+		fa, errA := strconv.ParseFloat(a, 64)
+		fb, errB := strconv.ParseFloat(b, 64)
+		if errA == nil && errB == nil && fa != fb {
+			if fa < fb {
+				return -1
+			}
+			return 1
+		}
+	}
+	if spec.Kind() == FieldEnum {
+		ia, ib := indexOf(spec.Values, a), indexOf(spec.Values, b)
+		if ia >= 0 && ib >= 0 && ia != ib {
+			if ia < ib {
+				return -1
+			}
+			return 1
+		}
+	}
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+func indexOf(values []string, value string) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+	return -1
+}
+
+// reservedFieldNames returns the frontmatter keys of the built-in Metadata fields.
+func reservedFieldNames() map[string]bool {
+	names := make(map[string]bool)
+	typ := reflect.TypeOf(Metadata{})
+	for i := 0; i < typ.NumField(); i++ {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("yaml"), ",")
+		if name != "" {
+			names[name] = true
+		}
+	}
+	return names
+}
@@ -0,0 +1,168 @@
+package task
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnknownFrontmatterKeysSurviveRewrite(t *testing.T) {
+	content := "---\ntype: task\nrole: developer\npriority: high\ncustomer: Acme Corp\nestimate: 5\nlinks:\n    - a\n    - b\n---\n\n# Keep fields\n\nBody.\n"
+	tsk, err := NewParser().ParseString(content, "T1aaa-keep-fields")
+	if err != nil {
+		t.Fatalf("ParseString: %v", err)
+	}
+	if got := tsk.Field("customer"); got != "Acme Corp" {
+		t.Errorf("expected customer field, got %q", got)
+	}
+	if got := tsk.Field("estimate"); got != "5" {
+		t.Errorf("expected estimate 5, got %q", got)
+	}
+
+	tsk.SetTitle("Keep fields renamed")
+	out := tsk.Content()
+	for _, want := range []string{"customer: Acme Corp\n", "estimate: 5\n", "links:\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q to survive rewrite:\n%s", want, out)
+		}
+	}
+
+	reparsed, err := NewParser().ParseString(out, tsk.ID)
+	if err != nil {
+		t.Fatalf("ParseString after rewrite: %v", err)
+	}
+	if reparsed.Field("links") != "a,b" {
+		t.Errorf("expected list field to round-trip, got %q", reparsed.Field("links"))
+	}
+}
+
+func TestFieldSchemaValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema FieldSchema
+		want   string
+	}{
+		{"valid", FieldSchema{"risk": {Type: FieldEnum, Values: []string{"low", "high"}}, "estimate": {Type: FieldNumber}}, ""},
+		{"reserved", FieldSchema{"priority": {}}, "built-in"},
+		{"bad name", FieldSchema{"Risk": {}}, "invalid field name"},
+		{"bad type", FieldSchema{"risk": {Type: "colour"}}, "invalid type"},
+		{"enum without values", FieldSchema{"risk": {Type: FieldEnum}}, "needs values"},
+		{"values on string", FieldSchema{"risk": {Values: []string{"x"}}}, "only allowed for enum"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.schema.Validate()
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestValidatorChecksCustomFields(t *testing.T) {
+	schema := FieldSchema{
+		"risk":     {Type: FieldEnum, Values: []string{"low", "high"}},
+		"estimate": {Type: FieldNumber},
+		"customer": {RequiredFor: []string{"bug"}},
+		"due":      {Type: FieldDate},
+	}
+	parse := func(id, frontmatter string) *Task {
+		tsk, err := NewParser().ParseString("---\n"+frontmatter+"---\n\n# "+id+"\n", id)
+		if err != nil {
+			t.Fatalf("ParseString: %v", err)
+		}
+		return tsk
+	}
+	good := parse("T1aaa-good", "type: bug\nrisk: low\nestimate: 2.5\ncustomer: Acme\ndue: 2026-03-01\n")
+	bad := parse("T2bbb-bad", "type: bug\nrisk: extreme\nestimate: lots\ndue: soon\n")
+
+	if problems := schema.CheckTask(good); len(problems) != 0 {
+		t.Fatalf("expected valid task, got %v", problems)
+	}
+	problems := strings.Join(schema.CheckTask(bad), "\n")
+	for _, want := range []string{"invalid field risk", "invalid field estimate", "invalid field due", "missing field customer"} {
+		if !strings.Contains(problems, want) {
+			t.Errorf("expected %q in problems:\n%s", want, problems)
+		}
+	}
+}
+
+func TestFieldSchemaParseValues(t *testing.T) {
+	schema := FieldSchema{
+		"estimate": {Type: FieldNumber},
+		"customer": {RequiredFor: []string{"*"}},
+	}
+	values, err := schema.ParseValues("task", map[string]string{"estimate": "3", "customer": "Acme"})
+	if err != nil {
+		t.Fatalf("ParseValues: %v", err)
+	}
+	if values["estimate"] != 3 || values["customer"] != "Acme" {
+		t.Fatalf("unexpected values: %#v", values)
+	}
+	if _, err := schema.ParseValues("task", map[string]string{"estimate": "3"}); err == nil || !strings.Contains(err.Error(), "customer") {
+		t.Fatalf("expected missing required field error, got %v", err)
+	}
+	if _, err := schema.ParseValues("task", map[string]string{"customer": "x", "colour": "red"}); err == nil || !strings.Contains(err.Error(), "unknown field") {
+		t.Fatalf("expected unknown field error, got %v", err)
+	}
+}
+
+func TestListSortsGroupsAndFiltersByField(t *testing.T) {
+	schema := FieldSchema{"risk": {Type: FieldEnum, Values: []string{"low", "medium", "high"}}, "estimate": {Type: FieldNumber}}
+	tasks := map[string]*Task{}
+	for _, fm := range []struct{ id, risk, estimate string }{
+		{"T1aaa-one", "high", "10"},
+		{"T2bbb-two", "low", "2"},
+		{"T3ccc-three", "medium", "9"},
+	} {
+		tsk, err := NewParser().ParseString("---\nrole: developer\nrisk: "+fm.risk+"\nestimate: "+fm.estimate+"\n---\n\n# "+fm.id+"\n", fm.id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tasks[fm.id] = tsk
+	}
+
+	opts := ListOptions{Sort: "estimate", FieldSchema: schema}
+	if err := ValidateListFilters(opts); err != nil {
+		t.Fatalf("ValidateListFilters: %v", err)
+	}
+	items, err := filterTasks("", tasks, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sortTasks(items, opts)
+	if items[0].ID != "T2bbb-two" || items[1].ID != "T3ccc-three" || items[2].ID != "T1aaa-one" {
+		t.Fatalf("expected numeric estimate order, got %s %s %s", items[0].ID, items[1].ID, items[2].ID)
+	}
+
+	opts.Sort = "risk"
+	sortTasks(items, opts)
+	if items[0].ID != "T2bbb-two" || items[2].ID != "T1aaa-one" {
+		t.Fatalf("expected enum order low, medium, high, got %s %s %s", items[0].ID, items[1].ID, items[2].ID)
+	}
+
+	out, err := FormatList(items, ListOptions{Format: "md", Group: "risk", FieldSchema: schema, Columns: []string{"id", "title", "estimate"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "## high") || !strings.Contains(out, "estimate: 10") {
+		t.Fatalf("expected grouped output with estimate column:\n%s", out)
+	}
+
+	filtered, err := filterTasks("", tasks, ListOptions{Fields: map[string]string{"risk": "HIGH"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filtered) != 1 || filtered[0].ID != "T1aaa-one" {
+		t.Fatalf("expected field filter to match T1aaa-one, got %d tasks", len(filtered))
+	}
+
+	if err := ValidateListFilters(ListOptions{Sort: "customer", FieldSchema: schema}); err == nil {
+		t.Fatal("expected undeclared sort field to be rejected")
+	}
+}
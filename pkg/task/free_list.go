@@ -2,10 +2,18 @@ package task
 
 import (
 	"path/filepath"
-	"regexp"
 	"strings"
 )
 
+// StaleListMarker heads a master list that a merge could not regenerate.
+// Commands that read the lists regenerate them when they find it.
+const StaleListMarker = "<!-- strand: stale after merge, regenerated by strand repair -->"
+
+// IsStaleList reports whether master list content carries StaleListMarker.
+func IsStaleList(content string) bool {
+	return strings.HasPrefix(content, StaleListMarker)
+}
+
 // FreeListParse represents parsed free-tasks.md data.
 type FreeListParse struct {
 	Title   string
@@ -68,10 +76,9 @@ func resolveTaskIDFromListPath(path string, tasks map[string]*Task) string {
 func extractTaskIDFromPathLast(path string) string {
 	path = filepath.Clean(path)
 	parts := strings.Split(filepath.ToSlash(path), "/")
-	idPattern := regexp.MustCompile(`^[A-Z][0-9a-z]{4,6}-[a-zA-Z0-9-]+$`)
 	match := ""
 	for _, part := range parts {
-		if idPattern.MatchString(part) {
+		if fullIDPattern.MatchString(part) {
 			match = part
 		}
 	}
@@ -0,0 +1,561 @@
+package task
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	// EdgeKindBlocker is an explicit blockers/blocks relationship.
+	EdgeKindBlocker = "blocker"
+	// EdgeKindParent links a child task to the parent it blocks.
+	EdgeKindParent = "parent"
+)
+
+// GraphOptions selects which tasks appear in a dependency graph.
+type GraphOptions struct {
+	// Root limits the graph to a task and its descendants. Short IDs are accepted.
+	Root string
+	// Status keeps only tasks matching the status, using the same rules as ListOptions.
+	Status string
+	// Remote resolves cross-project parents and blockers of the selected tasks.
+	// They always appear as remote nodes; without Remote their title and status
+	// are unknown.
+	Remote *RemoteTasks
+}
+
+// GraphNode is a task in the dependency graph.
+type GraphNode struct {
+	ID       string `json:"id"`
+	ShortID  string `json:"short_id"`
+	Title    string `json:"title"`
+	Role     string `json:"role"`
+	Priority string `json:"priority"`
+	Status   string `json:"status"`
+	Free     bool   `json:"free"`
+	Critical bool   `json:"critical"`
+	InCycle  bool   `json:"in_cycle"`
+	// Project is set on tasks from other projects, whose ID is project:TaskID.
+	Project string `json:"project,omitempty"`
+	// Problem explains why a cross-project reference is broken.
+	Problem string `json:"problem,omitempty"`
+}
+
+// GraphEdge points from a blocking task to the task it blocks.
+type GraphEdge struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Kind     string `json:"kind"`
+	Critical bool   `json:"critical"`
+}
+
+// DependencyGraph is the task dependency graph built from blocker and parent relationships.
+// Nodes and edges are sorted by ID so rendered output is deterministic.
+type DependencyGraph struct {
+	Nodes        []GraphNode `json:"nodes"`
+	Edges        []GraphEdge `json:"edges"`
+	CriticalPath []string    `json:"critical_path"`
+	Cycles       [][]string  `json:"cycles"`
+}
+
+// BuildDependencyGraph builds the dependency graph for the selected tasks.
+// Edges are only kept when both ends are selected.
+func BuildDependencyGraph(tasks map[string]*Task, opts GraphOptions) (*DependencyGraph, error) {
+	if opts.Status != "" && !IsValidStatus(opts.Status) {
+		return nil, fmt.Errorf("%s", FormatStatusErrorMessage(opts.Status))
+	}
+
+	selected := make(map[string]*Task, len(tasks))
+	for id, t := range tasks {
+		selected[id] = t
+	}
+
+	if strings.TrimSpace(opts.Root) != "" {
+		rootID, err := ResolveTaskID(tasks, opts.Root)
+		if err != nil {
+			return nil, fmt.Errorf("root task not found: %w", err)
+		}
+		selected = descendantsOf(tasks, rootID)
+	}
+
+	if opts.Status != "" {
+		for id, t := range selected {
+			if !matchesStatus(t, opts.Status) {
+				delete(selected, id)
+			}
+		}
+	}
+
+	remote := remoteGraphTasks(selected, opts.Remote)
+	edges := dependencyEdges(selected, remote)
+	adjacency := edgeAdjacency(edges)
+
+	cycles := findCycles(selected, adjacency)
+	inCycle := make(map[string]bool)
+	for _, cycle := range cycles {
+		for _, id := range cycle {
+			inCycle[id] = true
+		}
+	}
+
+	criticalPath := longestActivePath(selected, adjacency, inCycle)
+	critical := make(map[string]bool, len(criticalPath))
+	criticalEdge := make(map[[2]string]bool, len(criticalPath))
+	for i, id := range criticalPath {
+		critical[id] = true
+		if i > 0 {
+			criticalEdge[[2]string{criticalPath[i-1], id}] = true
+		}
+	}
+
+	graph := &DependencyGraph{
+		Nodes:        make([]GraphNode, 0, len(selected)),
+		Edges:        make([]GraphEdge, 0, len(edges)),
+		CriticalPath: criticalPath,
+		Cycles:       cycles,
+	}
+	for _, id := range sortedTaskIDs(selected) {
+		t := selected[id]
+		graph.Nodes = append(graph.Nodes, GraphNode{
+			ID:       t.ID,
+			ShortID:  ShortID(t.ID),
+			Title:    t.Title(),
+			Role:     t.GetEffectiveRole(),
+			Priority: NormalizePriority(t.Meta.Priority),
+			Status:   t.Meta.Status,
+			Free:     isFreeTask(t),
+			Critical: critical[t.ID],
+			InCycle:  inCycle[t.ID],
+		})
+	}
+	remoteRefs := mapKeys(remote)
+	sort.Strings(remoteRefs)
+	for _, ref := range remoteRefs {
+		r := remote[ref]
+		graph.Nodes = append(graph.Nodes, GraphNode{
+			ID:      ref,
+			ShortID: graphShortID(ref),
+			Title:   r.Title,
+			Status:  r.Status,
+			Project: r.Project,
+			Problem: r.Problem,
+		})
+	}
+	for _, edge := range edges {
+		edge.Critical = criticalEdge[[2]string{edge.From, edge.To}]
+		graph.Edges = append(graph.Edges, edge)
+	}
+
+	return graph, nil
+}
+
+// descendantsOf returns the root task and every task below it in the parent hierarchy.
+func descendantsOf(tasks map[string]*Task, rootID string) map[string]*Task {
+	children := make(map[string][]string)
+	for id, t := range tasks {
+		if t.Meta.Parent != "" {
+			children[t.Meta.Parent] = append(children[t.Meta.Parent], id)
+		}
+	}
+
+	selected := make(map[string]*Task)
+	queue := []string{rootID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if _, seen := selected[id]; seen {
+			continue
+		}
+		t, ok := tasks[id]
+		if !ok {
+			continue
+		}
+		selected[id] = t
+		queue = append(queue, children[id]...)
+	}
+	return selected
+}
+
+// remoteGraphTasks resolves the cross-project parents and blockers of the
+// given tasks, keyed by the reference as written.
+func remoteGraphTasks(tasks map[string]*Task, resolver *RemoteTasks) map[string]RemoteTask {
+	remote := make(map[string]RemoteTask)
+	add := func(ref string) {
+		if !IsRemoteRef(ref) {
+			return
+		}
+		if _, ok := remote[ref]; ok {
+			return
+		}
+		if resolver != nil {
+			resolved := resolver.Lookup(ref)
+			resolved.Ref = ref
+			remote[ref] = resolved
+			return
+		}
+		r := RemoteTask{Ref: ref}
+		if parsed, err := ParseRemoteRef(ref); err == nil {
+			r.Project, r.ID, r.ShortID = parsed.Project, parsed.ID, ShortID(parsed.ID)
+		} else {
+			r.Problem = err.Error()
+		}
+		remote[ref] = r
+	}
+	for _, t := range tasks {
+		add(t.Meta.Parent)
+		for _, blocker := range t.Meta.Blockers {
+			add(blocker)
+		}
+	}
+	return remote
+}
+
+// dependencyEdges collects blocker and parent edges between the given tasks
+// and their cross-project references.
+// Blockers and Blocks are unioned so one-sided relationships still appear.
+func dependencyEdges(tasks map[string]*Task, remote map[string]RemoteTask) []GraphEdge {
+	kinds := make(map[[2]string]string)
+	known := func(id string) bool {
+		if _, ok := tasks[id]; ok {
+			return true
+		}
+		_, ok := remote[id]
+		return ok
+	}
+	add := func(from, to, kind string) {
+		if from == to {
+			return
+		}
+		if !known(from) || !known(to) {
+			return
+		}
+		key := [2]string{from, to}
+		if kinds[key] != EdgeKindParent {
+			kinds[key] = kind
+		}
+	}
+
+	for id, t := range tasks {
+		for _, blockerID := range t.Meta.Blockers {
+			add(blockerID, id, EdgeKindBlocker)
+		}
+		for _, blockedID := range t.Meta.Blocks {
+			add(id, blockedID, EdgeKindBlocker)
+		}
+		if t.Meta.Parent != "" {
+			add(id, t.Meta.Parent, EdgeKindParent)
+		}
+	}
+
+	edges := make([]GraphEdge, 0, len(kinds))
+	for key, kind := range kinds {
+		edges = append(edges, GraphEdge{From: key[0], To: key[1], Kind: kind})
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].From != edges[j].From {
+			return edges[i].From < edges[j].From
+		}
+		return edges[i].To < edges[j].To
+	})
+	return edges
+}
+
+// edgeAdjacency maps each task to the sorted list of tasks it blocks.
+func edgeAdjacency(edges []GraphEdge) map[string][]string {
+	adjacency := make(map[string][]string)
+	for _, edge := range edges {
+		adjacency[edge.From] = append(adjacency[edge.From], edge.To)
+	}
+	for from := range adjacency {
+		sort.Strings(adjacency[from])
+	}
+	return adjacency
+}
+
+// findCycles returns every strongly connected component with more than one task,
+// each sorted by ID, using Tarjan's algorithm.
+func findCycles(tasks map[string]*Task, adjacency map[string][]string) [][]string {
+	index := 0
+	indices := make(map[string]int)
+	lowlink := make(map[string]int)
+	onStack := make(map[string]bool)
+	stack := []string{}
+	cycles := [][]string{}
+
+	var visit func(id string)
+	visit = func(id string) {
+		indices[id] = index
+		lowlink[id] = index
+		index++
+		stack = append(stack, id)
+		onStack[id] = true
+
+		for _, next := range adjacency[id] {
+			if _, seen := indices[next]; !seen {
+				visit(next)
+				lowlink[id] = min(lowlink[id], lowlink[next])
+			} else if onStack[next] {
+				lowlink[id] = min(lowlink[id], indices[next])
+			}
+		}
+
+		if lowlink[id] != indices[id] {
+			return
+		}
+		component := []string{}
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			component = append(component, top)
+			if top == id {
+				break
+			}
+		}
+		if len(component) > 1 {
+			sort.Strings(component)
+			cycles = append(cycles, component)
+		}
+	}
+
+	for _, id := range sortedTaskIDs(tasks) {
+		if _, seen := indices[id]; !seen {
+			visit(id)
+		}
+	}
+
+	sort.Slice(cycles, func(i, j int) bool { return cycles[i][0] < cycles[j][0] })
+	return cycles
+}
+
+// longestActivePath returns the longest chain of active tasks, skipping tasks in cycles.
+// Ties are broken by the lowest task ID at each step.
+func longestActivePath(tasks map[string]*Task, adjacency map[string][]string, inCycle map[string]bool) []string {
+	length := activeChainLengths(tasks, adjacency, inCycle)
+
+	start := ""
+	for _, id := range sortedTaskIDs(tasks) {
+		if length[id] == 0 {
+			continue
+		}
+		if start == "" || length[id] > length[start] {
+			start = id
+		}
+	}
+	if start == "" {
+		return []string{}
+	}
+
+	path := []string{start}
+	for current := start; ; {
+		next := ""
+		for _, candidate := range adjacency[current] {
+			if length[candidate] > 0 && length[candidate] == length[current]-1 {
+				next = candidate
+				break
+			}
+		}
+		if next == "" {
+			break
+		}
+		path = append(path, next)
+		current = next
+	}
+	return path
+}
+
+// activeChainLengths returns, for every active task outside a cycle, the number of
+// tasks in the longest chain of such tasks that starts with it.
+func activeChainLengths(tasks map[string]*Task, adjacency map[string][]string, inCycle map[string]bool) map[string]int {
+	usable := func(id string) bool {
+		t, ok := tasks[id]
+		return ok && !inCycle[id] && !t.Meta.Completed && t.Meta.IsActive()
+	}
+
+	length := make(map[string]int)
+	var chain func(id string) int
+	chain = func(id string) int {
+		if l, ok := length[id]; ok {
+			return l
+		}
+		best := 0
+		for _, next := range adjacency[id] {
+			if usable(next) {
+				best = max(best, chain(next))
+			}
+		}
+		length[id] = best + 1
+		return best + 1
+	}
+
+	for _, id := range sortedTaskIDs(tasks) {
+		if usable(id) {
+			chain(id)
+		}
+	}
+	return length
+}
+
+// isFreeTask reports whether a task would appear in free-tasks.md.
+func isFreeTask(t *Task) bool {
+	return len(t.Meta.Blockers) == 0 && !t.Meta.Completed && IsActiveStatus(t.Meta.Status)
+}
+
+func mapKeys[V any](items map[string]V) []string {
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// graphShortID shortens a task ID, keeping the project of a cross-project
+// reference: "backend:T1abc-api" becomes "backend:T1abc".
+func graphShortID(id string) string {
+	if ref, err := ParseRemoteRef(id); err == nil {
+		return ref.Project + ":" + ShortID(ref.ID)
+	}
+	return ShortID(id)
+}
+
+func sortedTaskIDs(tasks map[string]*Task) []string {
+	ids := make([]string, 0, len(tasks))
+	for id := range tasks {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Mermaid renders the graph as a Mermaid flowchart.
+// Parent edges are dotted and critical-path edges are thick.
+func (g *DependencyGraph) Mermaid() string {
+	var sb strings.Builder
+	sb.WriteString("```mermaid\n")
+	sb.WriteString("graph TD\n")
+
+	for _, node := range g.Nodes {
+		fmt.Fprintf(&sb, "    %s[\"%s\"]\n", mermaidNodeID(node.ID), escapeMermaidLabel(graphNodeLabel(node)))
+	}
+	for _, edge := range g.Edges {
+		arrow := "-->"
+		switch {
+		case edge.Critical:
+			arrow = "==>"
+		case edge.Kind == EdgeKindParent:
+			arrow = "-.->"
+		}
+		fmt.Fprintf(&sb, "    %s %s %s\n", mermaidNodeID(edge.From), arrow, mermaidNodeID(edge.To))
+	}
+
+	sb.WriteString("\n")
+	sb.WriteString("    classDef free fill:#99ff99\n")
+	sb.WriteString("    classDef critical stroke:#ff0000,stroke-width:3px\n")
+	sb.WriteString("    classDef cycle fill:#ff9999\n")
+	sb.WriteString("    classDef inactive fill:#e0e0e0,color:#808080\n")
+	sb.WriteString("    classDef remote stroke-dasharray:5 5\n")
+	sb.WriteString("    classDef broken fill:#ffcc66\n")
+	for _, node := range g.Nodes {
+		for _, class := range graphNodeClasses(node) {
+			fmt.Fprintf(&sb, "    class %s %s\n", mermaidNodeID(node.ID), class)
+		}
+	}
+
+	sb.WriteString("```")
+	return sb.String()
+}
+
+// DOT renders the graph in Graphviz DOT format.
+func (g *DependencyGraph) DOT() string {
+	var sb strings.Builder
+	sb.WriteString("digraph tasks {\n")
+	sb.WriteString("    rankdir=LR;\n")
+	sb.WriteString("    node [shape=box];\n")
+
+	for _, node := range g.Nodes {
+		attrs := []string{fmt.Sprintf("label=%q", graphNodeLabel(node))}
+		for _, class := range graphNodeClasses(node) {
+			switch class {
+			case "free":
+				attrs = append(attrs, `style=filled`, `fillcolor="#99ff99"`)
+			case "cycle":
+				attrs = append(attrs, `style=filled`, `fillcolor="#ff9999"`)
+			case "inactive":
+				attrs = append(attrs, `fontcolor="#808080"`)
+			case "critical":
+				attrs = append(attrs, `color=red`, `penwidth=2`)
+			case "remote":
+				attrs = append(attrs, `style=dashed`)
+			case "broken":
+				attrs = append(attrs, `color=orange`)
+			}
+		}
+		fmt.Fprintf(&sb, "    %q [%s];\n", node.ShortID, strings.Join(attrs, ", "))
+	}
+	for _, edge := range g.Edges {
+		attrs := []string{}
+		if edge.Kind == EdgeKindParent {
+			attrs = append(attrs, "style=dashed")
+		}
+		if edge.Critical {
+			attrs = append(attrs, "color=red", "penwidth=2")
+		}
+		if len(attrs) == 0 {
+			fmt.Fprintf(&sb, "    %q -> %q;\n", graphShortID(edge.From), graphShortID(edge.To))
+			continue
+		}
+		fmt.Fprintf(&sb, "    %q -> %q [%s];\n", graphShortID(edge.From), graphShortID(edge.To), strings.Join(attrs, ", "))
+	}
+
+	sb.WriteString("}")
+	return sb.String()
+}
+
+// JSON renders the graph as indented JSON.
+func (g *DependencyGraph) JSON() (string, error) {
+	b, err := json.MarshalIndent(g, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func graphNodeLabel(node GraphNode) string {
+	if node.Title == "" {
+		return node.ShortID
+	}
+	return node.ShortID + ": " + node.Title
+}
+
+func graphNodeClasses(node GraphNode) []string {
+	classes := []string{}
+	if node.Project != "" || node.Problem != "" {
+		classes = append(classes, "remote")
+		if node.Problem != "" {
+			classes = append(classes, "broken")
+		}
+	}
+	switch {
+	case node.Problem != "":
+	case node.InCycle:
+		classes = append(classes, "cycle")
+	case node.Free:
+		classes = append(classes, "free")
+	case !IsActiveStatus(node.Status):
+		classes = append(classes, "inactive")
+	}
+	if node.Critical {
+		classes = append(classes, "critical")
+	}
+	return classes
+}
+
+func mermaidNodeID(id string) string {
+	return strings.NewReplacer("-", "_", ":", "__").Replace(graphShortID(id))
+}
+
+func escapeMermaidLabel(label string) string {
+	return strings.ReplaceAll(label, `"`, "#quot;")
+}
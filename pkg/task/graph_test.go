@@ -0,0 +1,147 @@
+package task
+
+import (
+	"strings"
+	"testing"
+)
+
+func graphTestTasks() map[string]*Task {
+	newTask := func(id, parent string, blockers []string, status string) *Task {
+		return &Task{
+			ID:           id,
+			TitleContent: "Task " + id,
+			Meta: Metadata{
+				Role:     "developer",
+				Priority: PriorityMedium,
+				Parent:   parent,
+				Blockers: blockers,
+				Status:   status,
+			},
+		}
+	}
+	tasks := map[string]*Task{
+		"E1aaa-epic":   newTask("E1aaa-epic", "", []string{"T1aaa-design", "T2aaa-build"}, StatusOpen),
+		"T1aaa-design": newTask("T1aaa-design", "E1aaa-epic", nil, StatusOpen),
+		"T2aaa-build":  newTask("T2aaa-build", "E1aaa-epic", []string{"T1aaa-design"}, StatusOpen),
+		"T3aaa-other":  newTask("T3aaa-other", "", nil, StatusOpen),
+		"T4aaa-done":   newTask("T4aaa-done", "", nil, StatusDone),
+		"T5aaa-loopa":  newTask("T5aaa-loopa", "", []string{"T6aaa-loopb"}, StatusOpen),
+		"T6aaa-loopb":  newTask("T6aaa-loopb", "", []string{"T5aaa-loopa"}, StatusOpen),
+	}
+	tasks["T4aaa-done"].Meta.Completed = true
+	return tasks
+}
+
+func TestBuildDependencyGraph(t *testing.T) {
+	graph, err := BuildDependencyGraph(graphTestTasks(), GraphOptions{})
+	if err != nil {
+		t.Fatalf("BuildDependencyGraph failed: %v", err)
+	}
+
+	if len(graph.Nodes) != 7 {
+		t.Fatalf("expected 7 nodes, got %d", len(graph.Nodes))
+	}
+
+	wantPath := "T1aaa-design,T2aaa-build,E1aaa-epic"
+	if got := strings.Join(graph.CriticalPath, ","); got != wantPath {
+		t.Fatalf("critical path = %s, want %s", got, wantPath)
+	}
+
+	if len(graph.Cycles) != 1 || strings.Join(graph.Cycles[0], ",") != "T5aaa-loopa,T6aaa-loopb" {
+		t.Fatalf("unexpected cycles: %v", graph.Cycles)
+	}
+
+	nodes := map[string]GraphNode{}
+	for _, node := range graph.Nodes {
+		nodes[node.ID] = node
+	}
+	if !nodes["T1aaa-design"].Free || !nodes["T3aaa-other"].Free {
+		t.Errorf("expected unblocked active tasks to be free: %+v", nodes)
+	}
+	if nodes["T2aaa-build"].Free || nodes["T4aaa-done"].Free {
+		t.Errorf("blocked and completed tasks must not be free: %+v", nodes)
+	}
+	if !nodes["T5aaa-loopa"].InCycle || nodes["T3aaa-other"].InCycle {
+		t.Errorf("unexpected cycle membership: %+v", nodes)
+	}
+
+	kinds := map[string]string{}
+	for _, edge := range graph.Edges {
+		kinds[edge.From+"->"+edge.To] = edge.Kind
+	}
+	if kinds["T1aaa-design->T2aaa-build"] != EdgeKindBlocker {
+		t.Errorf("expected blocker edge design->build, got %q", kinds["T1aaa-design->T2aaa-build"])
+	}
+	if kinds["T2aaa-build->E1aaa-epic"] != EdgeKindParent {
+		t.Errorf("expected parent edge build->epic, got %q", kinds["T2aaa-build->E1aaa-epic"])
+	}
+}
+
+func TestBuildDependencyGraphFilters(t *testing.T) {
+	graph, err := BuildDependencyGraph(graphTestTasks(), GraphOptions{Root: "E1aaa", Status: "open"})
+	if err != nil {
+		t.Fatalf("BuildDependencyGraph failed: %v", err)
+	}
+	ids := []string{}
+	for _, node := range graph.Nodes {
+		ids = append(ids, node.ID)
+	}
+	if got := strings.Join(ids, ","); got != "E1aaa-epic,T1aaa-design,T2aaa-build" {
+		t.Fatalf("unexpected nodes for root filter: %s", got)
+	}
+
+	graph, err = BuildDependencyGraph(graphTestTasks(), GraphOptions{Status: "done"})
+	if err != nil {
+		t.Fatalf("BuildDependencyGraph failed: %v", err)
+	}
+	if len(graph.Nodes) != 1 || len(graph.Edges) != 0 || len(graph.CriticalPath) != 0 {
+		t.Fatalf("unexpected graph for done filter: %+v", graph)
+	}
+
+	if _, err := BuildDependencyGraph(graphTestTasks(), GraphOptions{Status: "completed"}); err == nil {
+		t.Fatal("expected error for invalid status")
+	}
+	if _, err := BuildDependencyGraph(graphTestTasks(), GraphOptions{Root: "T9zzz"}); err == nil {
+		t.Fatal("expected error for unknown root")
+	}
+}
+
+func TestDependencyGraphRendering(t *testing.T) {
+	graph, err := BuildDependencyGraph(graphTestTasks(), GraphOptions{Root: "E1aaa"})
+	if err != nil {
+		t.Fatalf("BuildDependencyGraph failed: %v", err)
+	}
+
+	mermaid := graph.Mermaid()
+	for _, want := range []string{
+		"graph TD",
+		`T1aaa["T1aaa: Task T1aaa-design"]`,
+		"T1aaa ==> T2aaa",
+		"T2aaa ==> E1aaa",
+		"class T1aaa free",
+		"class T1aaa critical",
+	} {
+		if !strings.Contains(mermaid, want) {
+			t.Errorf("mermaid output missing %q:\n%s", want, mermaid)
+		}
+	}
+
+	dot := graph.DOT()
+	for _, want := range []string{
+		"digraph tasks {",
+		`"T1aaa" -> "E1aaa" [style=dashed];`,
+		`"T1aaa" -> "T2aaa" [color=red, penwidth=2];`,
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("dot output missing %q:\n%s", want, dot)
+		}
+	}
+
+	out, err := graph.JSON()
+	if err != nil {
+		t.Fatalf("JSON failed: %v", err)
+	}
+	if !strings.Contains(out, `"critical_path": [`) {
+		t.Errorf("json output missing critical_path:\n%s", out)
+	}
+}
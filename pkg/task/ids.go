@@ -7,11 +7,21 @@ import (
 	"strings"
 )
 
+// idPrefixExpr matches a task ID prefix: one to five uppercase letters. The
+// lowercase token that follows keeps multi-letter prefixes unambiguous.
+const idPrefixExpr = `[A-Z]{1,5}`
+
 var (
-	shortIDPattern = regexp.MustCompile(`^[A-Z][0-9a-z]{4,6}$`)
-	fullIDPattern  = regexp.MustCompile(`^([A-Z][0-9a-z]{4,6})-[a-zA-Z0-9-]+$`)
+	idPrefixPattern = regexp.MustCompile(`^` + idPrefixExpr + `$`)
+	shortIDPattern  = regexp.MustCompile(`^` + idPrefixExpr + `[0-9a-z]{4,6}$`)
+	fullIDPattern   = regexp.MustCompile(`^(` + idPrefixExpr + `[0-9a-z]{4,6})-[a-zA-Z0-9-]+$`)
 )
 
+// IsValidIDPrefix reports whether prefix can start a task ID, e.g. "T" or "BUG".
+func IsValidIDPrefix(prefix string) bool {
+	return idPrefixPattern.MatchString(prefix)
+}
+
 // IsValidTaskID validates whether a string is a valid task ID (either short or full format).
 func IsValidTaskID(id string) bool {
 	id = strings.TrimSpace(id)
@@ -10,6 +10,7 @@ func TestShortID(t *testing.T) {
 	}{
 		{name: "full id", input: "T3k7x-example", want: "T3k7x"},
 		{name: "short id", input: "E1a1a", want: "E1a1a"},
+		{name: "multi-letter prefix", input: "BUGa1b2-crash", want: "BUGa1b2"},
 		{name: "other", input: "not-an-id", want: "not-an-id"},
 	}
 
@@ -53,6 +54,15 @@ func TestResolveTaskID(t *testing.T) {
 		t.Fatalf("ResolveTaskID(path) = %q, want %q", resolved, "T1a1a-foo")
 	}
 
+	tasks["BUGa1b2-crash"] = &Task{ID: "BUGa1b2-crash"}
+	resolved, err = ResolveTaskID(tasks, "BUGa1b2")
+	if err != nil {
+		t.Fatalf("ResolveTaskID(multi-letter) error: %v", err)
+	}
+	if resolved != "BUGa1b2-crash" {
+		t.Fatalf("ResolveTaskID(multi-letter) = %q, want %q", resolved, "BUGa1b2-crash")
+	}
+
 	_, err = ResolveTaskID(tasks, "T9z9z")
 	if err == nil {
 		t.Fatalf("ResolveTaskID(missing) expected error")
@@ -79,6 +89,9 @@ func TestIsValidTaskID(t *testing.T) {
 		{name: "random text", input: "not-an-id", want: false},
 		{name: "number start", input: "13k7x-example", want: false},
 		{name: "valid with whitespace", input: "  T3k7x-example  ", want: true},
+		{name: "multi-letter prefix", input: "BUGa1b2-crash", want: true},
+		{name: "five-letter prefix", input: "ABCDEa1b2", want: true},
+		{name: "six-letter prefix", input: "ABCDEFa1b2-x", want: false},
 	}
 
 	for _, tc := range cases {
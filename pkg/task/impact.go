@@ -0,0 +1,84 @@
+package task
+
+import "fmt"
+
+// TaskImpact measures how much downstream work depends on a task.
+type TaskImpact struct {
+	// Blocked is the number of active tasks transitively blocked by this task.
+	Blocked int `json:"blocked"`
+	// Chain is the number of tasks in the longest chain of active tasks that
+	// starts with this one. It is zero for inactive tasks and tasks in a cycle.
+	Chain int `json:"chain"`
+}
+
+// String formats the impact for table and markdown output.
+func (i TaskImpact) String() string {
+	return fmt.Sprintf("%d (chain %d)", i.Blocked, i.Chain)
+}
+
+// Less reports whether i has less impact than other: fewer blocked tasks,
+// then a shorter chain.
+func (i TaskImpact) Less(other TaskImpact) bool {
+	if i.Blocked != other.Blocked {
+		return i.Blocked < other.Blocked
+	}
+	return i.Chain < other.Chain
+}
+
+// ComputeImpact computes blocker impact for every task using the same edges as
+// the dependency graph. Completed and inactive tasks block nothing.
+func ComputeImpact(tasks map[string]*Task) map[string]TaskImpact {
+	active := make(map[string]*Task, len(tasks))
+	for id, t := range tasks {
+		if !t.Meta.Completed && t.Meta.IsActive() {
+			active[id] = t
+		}
+	}
+
+	adjacency := edgeAdjacency(dependencyEdges(active, nil))
+	inCycle := make(map[string]bool)
+	for _, cycle := range findCycles(active, adjacency) {
+		for _, id := range cycle {
+			inCycle[id] = true
+		}
+	}
+	chains := activeChainLengths(active, adjacency, inCycle)
+
+	impact := make(map[string]TaskImpact, len(tasks))
+	for id := range tasks {
+		if _, ok := active[id]; !ok {
+			impact[id] = TaskImpact{}
+			continue
+		}
+		impact[id] = TaskImpact{
+			Blocked: countReachable(id, adjacency),
+			Chain:   chains[id],
+		}
+	}
+	return impact
+}
+
+// Impact computes blocker impact for all loaded tasks.
+func (db *TaskDB) Impact() map[string]TaskImpact {
+	return ComputeImpact(db.tasks)
+}
+
+// countReachable returns the number of distinct tasks reachable from id, excluding id.
+func countReachable(id string, adjacency map[string][]string) int {
+	seen := map[string]bool{id: true}
+	queue := []string{id}
+	count := 0
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, next := range adjacency[current] {
+			if seen[next] {
+				continue
+			}
+			seen[next] = true
+			count++
+			queue = append(queue, next)
+		}
+	}
+	return count
+}
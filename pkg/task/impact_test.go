@@ -0,0 +1,47 @@
+package task
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestComputeImpact(t *testing.T) {
+	impact := ComputeImpact(graphTestTasks())
+
+	cases := map[string]TaskImpact{
+		"T1aaa-design": {Blocked: 2, Chain: 3},
+		"T2aaa-build":  {Blocked: 1, Chain: 2},
+		"E1aaa-epic":   {Blocked: 0, Chain: 1},
+		"T3aaa-other":  {Blocked: 0, Chain: 1},
+		"T4aaa-done":   {Blocked: 0, Chain: 0},
+		"T5aaa-loopa":  {Blocked: 1, Chain: 0},
+	}
+	for id, want := range cases {
+		if got := impact[id]; got != want {
+			t.Errorf("impact[%s] = %+v, want %+v", id, got, want)
+		}
+	}
+}
+
+func TestSortByImpact(t *testing.T) {
+	tasks := graphTestTasks()
+	items := []*Task{tasks["T3aaa-other"], tasks["E1aaa-epic"], tasks["T2aaa-build"], tasks["T1aaa-design"]}
+	sortTasks(items, ListOptions{Sort: "impact", Impact: ComputeImpact(tasks)})
+
+	got := make([]string, 0, len(items))
+	for _, item := range items {
+		got = append(got, item.ID)
+	}
+	want := "T1aaa-design,T2aaa-build,E1aaa-epic,T3aaa-other"
+	if strings.Join(got, ",") != want {
+		t.Fatalf("unexpected impact order\n got: %v\nwant: %v", got, want)
+	}
+
+	out, err := FormatList(items[:1], ListOptions{Format: "md", Columns: []string{"id", "impact"}, Impact: ComputeImpact(tasks)})
+	if err != nil {
+		t.Fatalf("FormatList failed: %v", err)
+	}
+	if !strings.Contains(out, "impact: 2 (chain 3)") {
+		t.Fatalf("expected impact column in output, got: %s", out)
+	}
+}
@@ -13,17 +13,25 @@ import (
 
 // ListOptions defines filters and output parameters for listing tasks.
 type ListOptions struct {
-	Scope          string
-	Parent         string
-	Path           string
-	Role           string
-	Priority       string
-	Completed      *bool
-	Blocked        *bool
-	Blocks         *bool
-	OwnerApproval  *bool
-	Status         string
-	Label          string
+	Scope         string
+	Parent        string
+	Path          string
+	Role          string
+	Priority      string
+	Completed     *bool
+	Blocked       *bool
+	Blocks        *bool
+	OwnerApproval *bool
+	Status        string
+	Label         string
+	// Vars keeps tasks whose template vars match every key/value pair.
+	Vars map[string]string
+	// Fields keeps tasks whose custom frontmatter fields match every
+	// key/value pair.
+	Fields map[string]string
+	// FieldSchema declares the custom fields that can be used with Sort and
+	// Group; they can always be shown as columns.
+	FieldSchema    FieldSchema
 	Sort           string
 	Order          string
 	Format         string
@@ -32,23 +40,56 @@ type ListOptions struct {
 	MdTable        bool
 	UseMasterLists bool
 	Color          bool
+	// Impact holds precomputed blocker impact for --sort impact and the impact
+	// column, keyed by Task.Key. ListTasks computes it for sorting when it is
+	// nil.
+	Impact map[string]TaskImpact
 }
 
+// NeedsImpact reports whether the options sort by or display blocker impact.
+func (opts ListOptions) NeedsImpact() bool {
+	if strings.EqualFold(strings.TrimSpace(opts.Sort), "impact") {
+		return true
+	}
+	for _, col := range opts.Columns {
+		if strings.EqualFold(strings.TrimSpace(col), "impact") {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterError reports list options that match nothing in the loaded tasks,
+// such as an unknown parent, as opposed to a failure to load them.
+type FilterError struct {
+	Err error
+}
+
+func (e *FilterError) Error() string { return e.Err.Error() }
+
+func (e *FilterError) Unwrap() error { return e.Err }
+
 // ListTasks loads tasks and returns a filtered, deterministically sorted list.
-func ListTasks(tasksRoot string, opts ListOptions) ([]*Task, error) {
+// When opts need impact, it also returns the impact of every loaded task,
+// computed from the same tasks unless opts.Impact is already set, so callers
+// can pass it on to FormatList.
+func ListTasks(tasksRoot string, opts ListOptions) ([]*Task, map[string]TaskImpact, error) {
 	parser := NewParser()
 	tasks, err := parser.LoadTasks(tasksRoot)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	items, err := filterTasks(tasksRoot, tasks, opts)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
+	if opts.NeedsImpact() && opts.Impact == nil {
+		opts.Impact = ComputeImpact(tasks)
+	}
 	sortTasks(items, opts)
-	return items, nil
+	return items, opts.Impact, nil
 }
 
 // FormatList formats tasks according to the requested output format.
@@ -65,6 +106,60 @@ func FormatList(tasks []*Task, opts ListOptions) (string, error) {
 	}
 }
 
+// ValidateListFilters checks the filter and sort options shared by every
+// caller of ListTasks, so the CLI and the web API reject the same inputs.
+func ValidateListFilters(opts ListOptions) error {
+	scope := opts.Scope
+	switch scope {
+	case "":
+		scope = "all"
+	case "all", "root", "free":
+	default:
+		return fmt.Errorf("invalid scope %q (expected all, root, or free)", opts.Scope)
+	}
+	if opts.Priority != "" && !IsValidPriority(opts.Priority) {
+		return fmt.Errorf("invalid priority %q (expected high, medium, or low)", opts.Priority)
+	}
+	if opts.Status != "" && !IsValidStatus(opts.Status) {
+		return fmt.Errorf("%s", FormatStatusErrorMessage(opts.Status))
+	}
+	switch opts.Sort {
+	case "", "id", "priority", "created", "edited", "role", "impact":
+	default:
+		if !opts.FieldSchema.Has(opts.Sort) {
+			return fmt.Errorf("invalid sort %q (expected id, priority, created, edited, role, impact%s)", opts.Sort, fieldChoices(opts.FieldSchema))
+		}
+	}
+	switch opts.Group {
+	case "", "none", "priority", "parent", "role":
+	default:
+		if !opts.FieldSchema.Has(opts.Group) {
+			return fmt.Errorf("invalid group %q (expected none, priority, parent, role%s)", opts.Group, fieldChoices(opts.FieldSchema))
+		}
+	}
+	switch opts.Order {
+	case "", "asc", "desc":
+	default:
+		return fmt.Errorf("invalid order %q (expected asc or desc)", opts.Order)
+	}
+	if scope == "free" && opts.Parent != "" {
+		return fmt.Errorf("invalid flag combination: --scope free cannot be used with --children")
+	}
+	if opts.Parent != "" && scope != "all" {
+		return fmt.Errorf("invalid flag combination: --children cannot be used with --scope %s", scope)
+	}
+	return nil
+}
+
+// fieldChoices lists the declared custom fields for an error message.
+func fieldChoices(schema FieldSchema) string {
+	names := schema.Names()
+	if len(names) == 0 {
+		return ""
+	}
+	return ", or a custom field: " + strings.Join(names, ", ")
+}
+
 func filterTasks(tasksRoot string, tasks map[string]*Task, opts ListOptions) ([]*Task, error) {
 	items := make([]*Task, 0, len(tasks))
 	for _, t := range tasks {
@@ -74,7 +169,7 @@ func filterTasks(tasksRoot string, tasks map[string]*Task, opts ListOptions) ([]
 	if opts.Parent != "" {
 		resolved, err := ResolveTaskID(tasks, opts.Parent)
 		if err != nil {
-			return nil, fmt.Errorf("parent task not found: %w", err)
+			return nil, &FilterError{Err: fmt.Errorf("parent task not found: %w", err)}
 		}
 		opts.Parent = resolved
 	}
@@ -123,12 +218,36 @@ func filterTasks(tasksRoot string, tasks map[string]*Task, opts ListOptions) ([]
 		if opts.Status != "" && !matchesStatus(t, opts.Status) {
 			continue
 		}
+		if !matchesVars(t, opts.Vars) {
+			continue
+		}
+		if !matchesFields(t, opts.Fields) {
+			continue
+		}
 		filtered = append(filtered, t)
 	}
 
 	return filtered, nil
 }
 
+func matchesVars(t *Task, vars map[string]string) bool {
+	for name, want := range vars {
+		if !strings.EqualFold(t.Meta.Vars[name], want) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesFields(t *Task, fields map[string]string) bool {
+	for name, want := range fields {
+		if !strings.EqualFold(t.Field(name), want) {
+			return false
+		}
+	}
+	return true
+}
+
 func isUnderPath(path, root string) bool {
 	path = filepath.Clean(path)
 	root = filepath.Clean(root)
@@ -178,6 +297,12 @@ func matchesStatus(t *Task, status string) bool {
 	}
 }
 
+// SortTasks orders tasks merged from several ListTasks or SearchTasks calls
+// the way ListTasks orders a single project's tasks.
+func SortTasks(items []*Task, opts ListOptions) {
+	sortTasks(items, opts)
+}
+
 func sortTasks(items []*Task, opts ListOptions) {
 	sortKey := strings.ToLower(strings.TrimSpace(opts.Sort))
 	order := strings.ToLower(strings.TrimSpace(opts.Order))
@@ -185,7 +310,12 @@ func sortTasks(items []*Task, opts ListOptions) {
 
 	sort.SliceStable(items, func(i, j int) bool {
 		a, b := items[i], items[j]
-		less := compareTasks(a, b, sortKey)
+		var less bool
+		if spec, ok := opts.FieldSchema[sortKey]; ok {
+			less = compareByField(a, b, sortKey, spec)
+		} else {
+			less = compareTasks(a, b, sortKey, opts.Impact)
+		}
 		if desc {
 			return !less
 		}
@@ -193,7 +323,7 @@ func sortTasks(items []*Task, opts ListOptions) {
 	})
 }
 
-func compareTasks(a, b *Task, sortKey string) bool {
+func compareTasks(a, b *Task, sortKey string, impact map[string]TaskImpact) bool {
 	switch sortKey {
 	case "id":
 		return a.ID < b.ID
@@ -213,6 +343,16 @@ func compareTasks(a, b *Task, sortKey string) bool {
 			return roleA < roleB
 		}
 		return a.ID < b.ID
+	case "impact":
+		// Highest impact first, then priority, then ID.
+		impactA, impactB := impact[a.Key()], impact[b.Key()]
+		if impactA != impactB {
+			return impactB.Less(impactA)
+		}
+		if PriorityRank(a.Meta.Priority) != PriorityRank(b.Meta.Priority) {
+			return PriorityRank(a.Meta.Priority) < PriorityRank(b.Meta.Priority)
+		}
+		return a.ID < b.ID
 	default:
 		// Default sort: priority, completed, ID.
 		if PriorityRank(a.Meta.Priority) != PriorityRank(b.Meta.Priority) {
@@ -225,6 +365,13 @@ func compareTasks(a, b *Task, sortKey string) bool {
 	}
 }
 
+func compareByField(a, b *Task, name string, spec FieldSpec) bool {
+	if c := compareFieldValues(spec, a.Field(name), b.Field(name)); c != 0 {
+		return c < 0
+	}
+	return a.ID < b.ID
+}
+
 func compareTime(a, b time.Time, ida, idb string) bool {
 	if !a.Equal(b) {
 		return a.Before(b)
@@ -233,27 +380,33 @@ func compareTime(a, b time.Time, ida, idb string) bool {
 }
 
 type listRow struct {
-	ID          string   `json:"id"`
-	Title       string   `json:"title"`
-	Role        string   `json:"role"`
-	Priority    string   `json:"priority"`
-	Parent      string   `json:"parent"`
-	Completed   bool     `json:"completed"`
-	Status      string   `json:"status"`
-	Blockers    []string `json:"blockers"`
-	Blocks      []string `json:"blocks"`
-	Path        string   `json:"path"`
-	DateCreated string   `json:"date_created"`
-	DateEdited  string   `json:"date_edited"`
-}
-
-func toListRows(tasks []*Task) []listRow {
+	Project     string      `json:"project,omitempty"`
+	ID          string      `json:"id"`
+	Title       string      `json:"title"`
+	Role        string      `json:"role"`
+	Priority    string      `json:"priority"`
+	Parent      string      `json:"parent"`
+	Completed   bool        `json:"completed"`
+	Status      string      `json:"status"`
+	ClaimedBy   string      `json:"claimed_by,omitempty"`
+	Blockers    []string    `json:"blockers"`
+	Blocks      []string    `json:"blocks"`
+	Path        string      `json:"path"`
+	DateCreated string      `json:"date_created"`
+	DateEdited  string      `json:"date_edited"`
+	Impact      *TaskImpact `json:"impact,omitempty"`
+	// Fields holds the display values of the task's custom fields.
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+func toListRows(tasks []*Task, impact map[string]TaskImpact) []listRow {
 	rows := make([]listRow, 0, len(tasks))
 	for _, t := range tasks {
 		shortParent := ShortID(t.Meta.Parent)
 		shortBlockers := shortenTaskIDs(t.Meta.Blockers)
 		shortBlocks := shortenTaskIDs(t.Meta.Blocks)
 		rows = append(rows, listRow{
+			Project:     t.Project,
 			ID:          ShortID(t.ID),
 			Title:       t.Title(),
 			Role:        t.GetEffectiveRole(),
@@ -261,12 +414,23 @@ func toListRows(tasks []*Task) []listRow {
 			Parent:      shortParent,
 			Completed:   t.Meta.Completed,
 			Status:      t.Meta.Status,
+			ClaimedBy:   t.Meta.ClaimedBy,
 			Blockers:    shortBlockers,
 			Blocks:      shortBlocks,
 			Path:        filepath.ToSlash(t.FilePath),
 			DateCreated: t.Meta.DateCreated.Format(time.RFC3339),
 			DateEdited:  t.Meta.DateEdited.Format(time.RFC3339),
 		})
+		if ti, ok := impact[t.Key()]; ok {
+			rows[len(rows)-1].Impact = &ti
+		}
+		if len(t.Meta.Fields) > 0 {
+			fields := make(map[string]string, len(t.Meta.Fields))
+			for name := range t.Meta.Fields {
+				fields[name] = t.Field(name)
+			}
+			rows[len(rows)-1].Fields = fields
+		}
 	}
 	return rows
 }
@@ -283,7 +447,7 @@ func shortenTaskIDs(ids []string) []string {
 }
 
 func formatTable(tasks []*Task, opts ListOptions) (string, error) {
-	rows := toListRows(tasks)
+	rows := toListRows(tasks, opts.Impact)
 	columns := defaultColumnsForRows(opts, rows, []string{"id", "title", "priority", "role", "status", "completed", "blockers"}, true)
 
 	if len(rows) == 0 {
@@ -344,7 +508,7 @@ func formatTable(tasks []*Task, opts ListOptions) (string, error) {
 }
 
 func formatMarkdown(tasks []*Task, opts ListOptions) (string, error) {
-	rows := toListRows(tasks)
+	rows := toListRows(tasks, opts.Impact)
 	if opts.Group != "" && opts.Group != "none" {
 		grouped := groupRows(rows, opts.Group)
 		return formatMarkdownGrouped(grouped, opts)
@@ -410,7 +574,7 @@ func formatMarkdownList(rows []listRow, opts ListOptions) string {
 }
 
 func formatJSON(tasks []*Task, opts ListOptions) (string, error) {
-	rows := toListRows(tasks)
+	rows := toListRows(tasks, opts.Impact)
 	if opts.Group != "" && opts.Group != "none" {
 		grouped := groupRows(rows, opts.Group)
 		ordered := map[string][]listRow{}
@@ -461,6 +625,13 @@ func defaultColumnsForRows(opts ListOptions, rows []listRow, defaults []string,
 	if len(opts.Columns) > 0 {
 		return normalizeColumns(opts.Columns, defaults)
 	}
+	for _, row := range rows {
+		if row.Project != "" {
+			// Rows merged from several projects lead with their project.
+			defaults = append([]string{"project"}, defaults...)
+			break
+		}
+	}
 	columns := normalizeColumns(nil, defaults)
 	return filterConstantColumns(rows, columns, numericForCounts)
 }
@@ -469,7 +640,7 @@ func filterConstantColumns(rows []listRow, columns []string, numericForCounts bo
 	if len(rows) == 0 {
 		return columns
 	}
-	alwaysKeep := map[string]bool{"id": true, "title": true}
+	alwaysKeep := map[string]bool{"project": true, "id": true, "title": true}
 	out := make([]string, 0, len(columns))
 	for _, col := range columns {
 		if alwaysKeep[col] {
@@ -494,6 +665,8 @@ func filterConstantColumns(rows []listRow, columns []string, numericForCounts bo
 
 func columnValue(row listRow, col string, numericForCounts bool) string {
 	switch col {
+	case "project":
+		return row.Project
 	case "id":
 		return row.ID
 	case "title":
@@ -508,6 +681,8 @@ func columnValue(row listRow, col string, numericForCounts bool) string {
 		return fmt.Sprintf("%t", row.Completed)
 	case "status":
 		return row.Status
+	case "claimed_by":
+		return row.ClaimedBy
 	case "blockers":
 		return formatListValue(row.Blockers, numericForCounts)
 	case "blocks":
@@ -518,8 +693,13 @@ func columnValue(row listRow, col string, numericForCounts bool) string {
 		return row.DateCreated
 	case "date_edited":
 		return row.DateEdited
+	case "impact":
+		if row.Impact == nil {
+			return ""
+		}
+		return row.Impact.String()
 	default:
-		return ""
+		return row.Fields[col]
 	}
 }
 
@@ -584,6 +764,8 @@ func columnLabel(col string) string {
 		return "completed"
 	case "status":
 		return "status"
+	case "claimed_by":
+		return "claimed by"
 	case "blockers":
 		return "blockers"
 	case "blocks":
@@ -596,8 +778,10 @@ func columnLabel(col string) string {
 		return "created"
 	case "date_edited":
 		return "edited"
+	case "impact":
+		return "impact"
 	default:
-		return ""
+		return col
 	}
 }
 
@@ -638,7 +822,10 @@ func groupRows(rows []listRow, group string) map[string][]listRow {
 				key = "(unassigned)"
 			}
 		default:
-			key = "(other)"
+			key = row.Fields[group]
+			if strings.TrimSpace(key) == "" {
+				key = "(none)"
+			}
 		}
 		grouped[key] = append(grouped[key], row)
 	}
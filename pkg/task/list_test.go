@@ -85,7 +85,7 @@ func TestListFilteringAndSorting(t *testing.T) {
 		tc := tc
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel()
-			tasks, err := ListTasks(root, tc.opts)
+			tasks, _, err := ListTasks(root, tc.opts)
 			if err != nil {
 				t.Fatalf("ListTasks failed: %v", err)
 			}
@@ -100,6 +100,30 @@ func TestListFilteringAndSorting(t *testing.T) {
 	}
 }
 
+func TestListTasksReturnsImpact(t *testing.T) {
+	fixture := setupListFixture(t)
+
+	_, impact, err := ListTasks(fixture.Root, ListOptions{Scope: "all"})
+	if err != nil {
+		t.Fatalf("ListTasks failed: %v", err)
+	}
+	if impact != nil {
+		t.Fatalf("expected no impact when options do not need it, got %v", impact)
+	}
+
+	tasks, impact, err := ListTasks(fixture.Root, ListOptions{Scope: "all", Role: fixture.Roles.Design, Sort: "impact"})
+	if err != nil {
+		t.Fatalf("ListTasks failed: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].ID != "T2a1a-free" {
+		t.Fatalf("expected only T2a1a-free, got %d tasks", len(tasks))
+	}
+	// Impact covers every loaded task, not just the ones the filters kept.
+	if got := impact["T5a1a-blocks"]; got.Blocked != 1 {
+		t.Fatalf("impact[T5a1a-blocks] = %+v, want 1 blocked", got)
+	}
+}
+
 func TestFormatOutputs(t *testing.T) {
 	fixture := setupListFixture(t)
 	root := fixture.Root
@@ -110,7 +134,7 @@ func TestFormatOutputs(t *testing.T) {
 		"<ROLE_REVIEW>": fixture.Roles.Review,
 	}
 
-	tasks, err := ListTasks(root, ListOptions{Scope: "all"})
+	tasks, _, err := ListTasks(root, ListOptions{Scope: "all"})
 	if err != nil {
 		t.Fatalf("ListTasks failed: %v", err)
 	}
@@ -393,7 +417,7 @@ func TestStatusFilteringWithExplicitStatuses(t *testing.T) {
 		tc := tc
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel()
-			tasks, err := ListTasks(root, ListOptions{Status: tc.status})
+			tasks, _, err := ListTasks(root, ListOptions{Status: tc.status})
 			if err != nil {
 				t.Fatalf("ListTasks failed: %v", err)
 			}
@@ -0,0 +1,337 @@
+package task
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MergeResult is the outcome of a three-way task file merge.
+type MergeResult struct {
+	// Content is the merged file; it holds conflict markers when Conflicts
+	// is non-empty.
+	Content string
+	// Conflicts describes the parts that could not be merged.
+	Conflicts []string
+	// Notes describes conflicting changes resolved automatically.
+	Notes []string
+}
+
+// MergeTaskFiles three-way merges two versions of a task file against their
+// common ancestor. Frontmatter is merged key by key: blockers and blocks
+// take the union of both sides' changes, status prefers done and otherwise
+// takes the most recently edited side, which also wins any other key both
+// sides changed. TODO and subtask lists are merged item by item, the
+// progress log keeps lines from both sides, and the remaining body is merged
+// line by line with git merge-file. The result keeps the formatting of ours.
+func MergeTaskFiles(base, ours, theirs string) (MergeResult, error) {
+	parser := NewParser()
+	b, err := parser.ParseString(base, "")
+	if err != nil {
+		return MergeResult{}, fmt.Errorf("base: %w", err)
+	}
+	o, err := parser.ParseString(ours, "")
+	if err != nil {
+		return MergeResult{}, fmt.Errorf("ours: %w", err)
+	}
+	th, err := parser.ParseString(theirs, "")
+	if err != nil {
+		return MergeResult{}, fmt.Errorf("theirs: %w", err)
+	}
+
+	var result MergeResult
+	theirsLatest := th.Meta.DateEdited.After(o.Meta.DateEdited)
+	latest := "ours"
+	if theirsLatest {
+		latest = "theirs"
+	}
+
+	meta, notes, err := mergeMetadata(b.Meta, o.Meta, th.Meta, theirsLatest)
+	if err != nil {
+		return MergeResult{}, err
+	}
+	for _, key := range notes {
+		result.Notes = append(result.Notes, fmt.Sprintf("%s changed on both sides; kept %s (latest edit)", key, latest))
+	}
+	o.Meta = meta
+
+	if title, conflict := merge3(b.TitleContent, o.TitleContent, th.TitleContent); conflict {
+		if theirsLatest {
+			o.TitleContent = th.TitleContent
+		}
+		result.Notes = append(result.Notes, fmt.Sprintf("title changed on both sides; kept %s (latest edit)", latest))
+	} else {
+		o.TitleContent = title
+	}
+
+	o.TodoItems = mergeItems(b.TodoItems, o.TodoItems, th.TodoItems, func(item TaskItem) string {
+		return strings.TrimSpace(item.Text)
+	})
+	o.SubsItems = mergeItems(b.SubsItems, o.SubsItems, th.SubsItems, func(item TaskItem) string {
+		return ShortID(item.SubtaskID)
+	})
+
+	if body, conflict := merge3(b.BodyContent, o.BodyContent, th.BodyContent); conflict {
+		merged, conflicts, err := mergeText(b.BodyContent, o.BodyContent, th.BodyContent, false)
+		if err != nil {
+			return MergeResult{}, err
+		}
+		o.BodyContent = merged
+		if conflicts {
+			result.Conflicts = append(result.Conflicts, "body changed on both sides")
+		}
+	} else {
+		o.BodyContent = body
+	}
+
+	if progress, conflict := merge3(b.ProgressContent, o.ProgressContent, th.ProgressContent); conflict {
+		merged, _, err := mergeText(b.ProgressContent, o.ProgressContent, th.ProgressContent, true)
+		if err != nil {
+			return MergeResult{}, err
+		}
+		o.ProgressContent = merged
+	} else {
+		o.ProgressContent = progress
+	}
+
+	result.Content = o.Content()
+	return result, nil
+}
+
+// merge3 returns the merged value of a field and whether both sides changed
+// it differently.
+func merge3(base, ours, theirs string) (string, bool) {
+	switch {
+	case ours == theirs, theirs == base:
+		return ours, false
+	case ours == base:
+		return theirs, false
+	default:
+		return ours, true
+	}
+}
+
+// mergeMetadata merges frontmatter key by key and returns the keys whose
+// conflicting changes were resolved in favour of the latest side.
+func mergeMetadata(base, ours, theirs Metadata, theirsLatest bool) (Metadata, []string, error) {
+	baseValues, _, err := encodeMeta(base)
+	if err != nil {
+		return Metadata{}, nil, err
+	}
+	oursValues, oursNodes, err := encodeMeta(ours)
+	if err != nil {
+		return Metadata{}, nil, err
+	}
+	theirsValues, theirsNodes, err := encodeMeta(theirs)
+	if err != nil {
+		return Metadata{}, nil, err
+	}
+
+	special := map[string]bool{
+		"blockers": true, "blocks": true, "status": true, "completed": true, "date_edited": true,
+	}
+	seen := make(map[string]bool)
+	var order []string
+	for _, list := range [][]*yaml.Node{oursNodes, theirsNodes} {
+		for i := 0; i+1 < len(list); i += 2 {
+			if key := list[i].Value; !seen[key] {
+				seen[key] = true
+				order = append(order, key)
+			}
+		}
+	}
+	pick := func(list []*yaml.Node, key string) [2]*yaml.Node {
+		for i := 0; i+1 < len(list); i += 2 {
+			if list[i].Value == key {
+				return [2]*yaml.Node{list[i], list[i+1]}
+			}
+		}
+		return [2]*yaml.Node{}
+	}
+
+	var conflicts []string
+	mapping := &yaml.Node{Kind: yaml.MappingNode}
+	for _, key := range order {
+		side := oursNodes
+		if !special[key] {
+			_, conflict := merge3(baseValues[key], oursValues[key], theirsValues[key])
+			switch {
+			case conflict:
+				conflicts = append(conflicts, key)
+				if theirsLatest {
+					side = theirsNodes
+				}
+			case oursValues[key] == baseValues[key]:
+				side = theirsNodes
+			}
+		}
+		pair := pick(side, key)
+		if pair[0] == nil {
+			continue
+		}
+		mapping.Content = append(mapping.Content, pair[0], pair[1])
+	}
+
+	var merged Metadata
+	if err := mapping.Decode(&merged); err != nil {
+		return Metadata{}, nil, err
+	}
+	merged.Blockers = mergeSet(base.Blockers, ours.Blockers, theirs.Blockers)
+	merged.Blocks = mergeSet(base.Blocks, ours.Blocks, theirs.Blocks)
+
+	status, conflict := merge3(NormalizeStatus(base.Status), NormalizeStatus(ours.Status), NormalizeStatus(theirs.Status))
+	merged.Status = ours.Status
+	if conflict {
+		switch {
+		case NormalizeStatus(ours.Status) == StatusDone:
+		case NormalizeStatus(theirs.Status) == StatusDone, theirsLatest:
+			merged.Status = theirs.Status
+		}
+	} else if status != NormalizeStatus(ours.Status) {
+		merged.Status = theirs.Status
+	}
+	merged.Completed = ours.Completed
+	if ours.Completed == base.Completed {
+		merged.Completed = theirs.Completed
+	}
+	if NormalizeStatus(merged.Status) == StatusDone {
+		merged.Completed = true
+	}
+
+	merged.DateEdited = ours.DateEdited
+	if theirsLatest {
+		merged.DateEdited = theirs.DateEdited
+	}
+	return merged, conflicts, nil
+}
+
+// mergeSet keeps the IDs both sides kept and adds the IDs either side added,
+// in the order of ours followed by theirs.
+func mergeSet(base, ours, theirs []string) []string {
+	in := func(list []string, id string) bool {
+		for _, v := range list {
+			if v == id {
+				return true
+			}
+		}
+		return false
+	}
+	var out []string
+	for _, list := range [][]string{ours, theirs} {
+		for _, id := range list {
+			if in(out, id) {
+				continue
+			}
+			if in(base, id) && !(in(ours, id) && in(theirs, id)) {
+				continue
+			}
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// mergeItems merges task lists item by item, matching items by key. Items
+// deleted on one side stay deleted unless the other side changed them; items
+// added by theirs are placed after the item they followed.
+func mergeItems(base, ours, theirs []TaskItem, key func(TaskItem) string) []TaskItem {
+	index := func(items []TaskItem) map[string]TaskItem {
+		m := make(map[string]TaskItem, len(items))
+		for _, item := range items {
+			m[key(item)] = item
+		}
+		return m
+	}
+	baseItems, oursItems, theirsItems := index(base), index(ours), index(theirs)
+
+	var out []TaskItem
+	for _, o := range ours {
+		k := key(o)
+		b, inBase := baseItems[k]
+		th, inTheirs := theirsItems[k]
+		if !inTheirs {
+			if inBase && o == b {
+				continue
+			}
+			out = append(out, o)
+			continue
+		}
+		out = append(out, mergeItem(b, o, th))
+	}
+
+	for i, th := range theirs {
+		k := key(th)
+		if _, inOurs := oursItems[k]; inOurs {
+			continue
+		}
+		if b, inBase := baseItems[k]; inBase && th == b {
+			continue
+		}
+		at := 0
+		for j := i - 1; j >= 0; j-- {
+			if pos := itemIndex(out, key(theirs[j]), key); pos >= 0 {
+				at = pos + 1
+				break
+			}
+		}
+		out = append(out[:at], append([]TaskItem{th}, out[at:]...)...)
+	}
+	return out
+}
+
+func itemIndex(items []TaskItem, k string, key func(TaskItem) string) int {
+	for i, item := range items {
+		if key(item) == k {
+			return i
+		}
+	}
+	return -1
+}
+
+// mergeItem merges one item field by field.
+func mergeItem(base, ours, theirs TaskItem) TaskItem {
+	out := ours
+	out.Role, _ = merge3(base.Role, ours.Role, theirs.Role)
+	out.Report, _ = merge3(base.Report, ours.Report, theirs.Report)
+	if ours.Checked == base.Checked {
+		out.Checked = theirs.Checked
+	}
+	return out
+}
+
+// mergeText merges text line by line with git merge-file, reporting whether
+// conflict markers were left. With union, conflicting lines from both sides
+// are kept instead.
+func mergeText(base, ours, theirs string, union bool) (string, bool, error) {
+	dir, err := os.MkdirTemp("", "strand-merge-")
+	if err != nil {
+		return "", false, err
+	}
+	defer os.RemoveAll(dir)
+
+	paths := make([]string, 3)
+	for i, text := range []string{ours, base, theirs} {
+		paths[i] = filepath.Join(dir, []string{"ours", "base", "theirs"}[i])
+		if err := os.WriteFile(paths[i], []byte(text+"\n"), 0o644); err != nil {
+			return "", false, err
+		}
+	}
+	args := []string{"merge-file", "-p", "-L", "ours", "-L", "base", "-L", "theirs"}
+	if union {
+		args = append(args, "--union")
+	}
+	args = append(args, paths...)
+	cmd := exec.Command("git", args...)
+	out, err := cmd.Output()
+	conflicts := false
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() > 0 && exitErr.ExitCode() < 128 {
+		conflicts = true
+	} else if err != nil {
+		return "", false, fmt.Errorf("git merge-file: %w", err)
+	}
+	return strings.TrimRight(string(out), "\n"), conflicts, nil
+}
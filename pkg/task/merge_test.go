@@ -0,0 +1,192 @@
+package task
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const mergeBase = `---
+type: ""
+role: developer
+priority: medium
+parent: ""
+blockers:
+  - T1aaa
+blocks: []
+date_created: 2026-03-01T00:00:00Z
+date_edited: 2026-03-01T00:00:00Z
+owner_approval: false
+completed: false
+status: open
+description: ""
+---
+
+# Merge me
+
+Shared context.
+
+## TODOs
+- [ ] (role: developer) First step
+- [ ] (role: developer) Second step
+- [ ] (role: reviewer) Review
+
+## Progress
+- 2026-03-01: started
+`
+
+func editFixture(t *testing.T, content string, replacements ...string) string {
+	t.Helper()
+	for i := 0; i+1 < len(replacements); i += 2 {
+		if !strings.Contains(content, replacements[i]) {
+			t.Fatalf("fixture does not contain %q", replacements[i])
+		}
+		content = strings.Replace(content, replacements[i], replacements[i+1], 1)
+	}
+	return content
+}
+
+func mustMerge(t *testing.T, base, ours, theirs string) (MergeResult, *Task) {
+	t.Helper()
+	result, err := MergeTaskFiles(base, ours, theirs)
+	if err != nil {
+		t.Fatalf("MergeTaskFiles: %v", err)
+	}
+	merged, err := NewParser().ParseString(result.Content, "T1abc")
+	if err != nil {
+		t.Fatalf("merged file does not parse: %v\n%s", err, result.Content)
+	}
+	return result, merged
+}
+
+func TestMergeTaskFilesFrontmatter(t *testing.T) {
+	ours := editFixture(t, mergeBase,
+		"  - T1aaa\n", "  - T1aaa\n  - T2bbb\n",
+		"date_edited: 2026-03-01T00:00:00Z", "date_edited: 2026-03-02T00:00:00Z",
+		"status: open", "status: done",
+		"completed: false", "completed: true",
+		"priority: medium", "priority: high",
+	)
+	theirs := editFixture(t, mergeBase,
+		"blockers:\n  - T1aaa\n", "blockers:\n  - T3ccc\n",
+		"date_edited: 2026-03-01T00:00:00Z", "date_edited: 2026-03-03T00:00:00Z",
+		"status: open", "status: in_progress",
+		"priority: medium", "priority: low",
+		"role: developer", "role: designer",
+	)
+
+	result, merged := mustMerge(t, mergeBase, ours, theirs)
+	if len(result.Conflicts) != 0 {
+		t.Fatalf("unexpected conflicts: %v", result.Conflicts)
+	}
+	if want := []string{"T2bbb", "T3ccc"}; !reflect.DeepEqual(merged.Meta.Blockers, want) {
+		t.Errorf("blockers = %v, want %v", merged.Meta.Blockers, want)
+	}
+	if merged.Meta.Status != StatusDone || !merged.Meta.Completed {
+		t.Errorf("status = %q completed = %v, want done and completed", merged.Meta.Status, merged.Meta.Completed)
+	}
+	if merged.Meta.Priority != "low" {
+		t.Errorf("priority = %q, want latest edit low", merged.Meta.Priority)
+	}
+	if merged.Meta.Role != "designer" {
+		t.Errorf("role = %q, want designer changed on one side", merged.Meta.Role)
+	}
+	if got := merged.Meta.DateEdited.Format("2006-01-02"); got != "2026-03-03" {
+		t.Errorf("date_edited = %s, want the later edit", got)
+	}
+	if len(result.Notes) != 1 || !strings.Contains(result.Notes[0], "priority") {
+		t.Errorf("notes = %v, want a note about priority", result.Notes)
+	}
+}
+
+func TestMergeTaskFilesStatusLatestWins(t *testing.T) {
+	ours := editFixture(t, mergeBase,
+		"date_edited: 2026-03-01T00:00:00Z", "date_edited: 2026-03-05T00:00:00Z",
+		"status: open", "status: cancelled",
+	)
+	theirs := editFixture(t, mergeBase,
+		"date_edited: 2026-03-01T00:00:00Z", "date_edited: 2026-03-02T00:00:00Z",
+		"status: open", "status: in_progress",
+	)
+	_, merged := mustMerge(t, mergeBase, ours, theirs)
+	if merged.Meta.Status != StatusCancelled {
+		t.Errorf("status = %q, want latest edit cancelled", merged.Meta.Status)
+	}
+}
+
+func TestMergeTaskFilesItemsAndBody(t *testing.T) {
+	ours := editFixture(t, mergeBase,
+		"- [ ] (role: developer) First step", "- [x] (role: developer) First step",
+		"- [ ] (role: reviewer) Review\n", "- [ ] (role: reviewer) Review\n- [ ] (role: developer) Ours extra\n",
+		"- 2026-03-01: started\n", "- 2026-03-01: started\n- 2026-03-02: ours\n",
+		"Shared context.", "Shared context, ours.",
+	)
+	theirs := editFixture(t, mergeBase,
+		"- [ ] (role: developer) Second step\n", "- [x] (role: developer) Second step\n- [ ] (role: developer) Theirs extra\n",
+		"- 2026-03-01: started\n", "- 2026-03-01: started\n- 2026-03-03: theirs\n",
+	)
+
+	result, merged := mustMerge(t, mergeBase, ours, theirs)
+	if len(result.Conflicts) != 0 {
+		t.Fatalf("unexpected conflicts: %v", result.Conflicts)
+	}
+	var got []string
+	for _, item := range merged.TodoItems {
+		mark := " "
+		if item.Checked {
+			mark = "x"
+		}
+		got = append(got, mark+" "+item.Text)
+	}
+	want := []string{"x First step", "x Second step", "  Theirs extra", "  Review", "  Ours extra"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("todos = %v, want %v", got, want)
+	}
+	if !strings.Contains(merged.ProgressContent, "ours") || !strings.Contains(merged.ProgressContent, "theirs") {
+		t.Errorf("progress = %q, want both entries", merged.ProgressContent)
+	}
+	if merged.BodyContent != "Shared context, ours." {
+		t.Errorf("body = %q", merged.BodyContent)
+	}
+}
+
+func TestMergeTaskFilesRemovedItemAndBodyConflict(t *testing.T) {
+	ours := editFixture(t, mergeBase,
+		"- [ ] (role: developer) Second step\n", "",
+		"Shared context.", "Context from ours.",
+	)
+	theirs := editFixture(t, mergeBase,
+		"Shared context.", "Context from theirs.",
+	)
+
+	result, merged := mustMerge(t, mergeBase, ours, theirs)
+	if len(merged.TodoItems) != 2 {
+		t.Errorf("todos = %v, want the removed step to stay removed", merged.TodoItems)
+	}
+	if len(result.Conflicts) != 1 {
+		t.Fatalf("conflicts = %v, want a body conflict", result.Conflicts)
+	}
+	for _, marker := range []string{"<<<<<<< ours", "Context from ours.", "=======", "Context from theirs.", ">>>>>>> theirs"} {
+		if !strings.Contains(result.Content, marker) {
+			t.Errorf("merged content missing %q:\n%s", marker, result.Content)
+		}
+	}
+}
+
+func TestMergeTaskFilesKeepsOursFormatting(t *testing.T) {
+	base := editFixture(t, mergeBase, "priority: medium", "priority: medium # triaged")
+	ours := editFixture(t, base, "Shared context.", "Shared   context.")
+	theirs := editFixture(t, base,
+		"status: open", "status: in_progress",
+		"date_edited: 2026-03-01T00:00:00Z", "date_edited: 2026-03-02T00:00:00Z",
+	)
+
+	result, _ := mustMerge(t, base, ours, theirs)
+	want := editFixture(t, ours,
+		"status: open", "status: in_progress",
+		"date_edited: 2026-03-01T00:00:00Z", "date_edited: 2026-03-02T00:00:00Z",
+	)
+	if result.Content != want {
+		t.Errorf("merged content:\n%s\nwant:\n%s", result.Content, want)
+	}
+}
@@ -137,6 +137,9 @@ func (p *Parser) ParseString(content string, id string) (*Task, error) {
 		}
 	}
 	t.BodyContent = strings.TrimSpace(t.BodyContent)
+	if hasFrontmatter {
+		t.source = newSource(content, t)
+	}
 
 	return t, nil
 }
@@ -468,6 +468,89 @@ func isHeadValid(repoPath string) bool {
 	return true
 }
 
+// RecurrenceRuleError reports a malformed recurrence rule along with an
+// example of a valid one.
+type RecurrenceRuleError struct {
+	Rule string
+	Err  error
+	Hint string
+}
+
+func (e *RecurrenceRuleError) Error() string {
+	return fmt.Sprintf("invalid recurrence rule %q: %v", e.Rule, e.Err)
+}
+
+func (e *RecurrenceRuleError) Unwrap() error {
+	return e.Err
+}
+
+// ValidateEvery validates recurrence rules of the form
+// "<amount> <metric> [from|after <anchor>]" and returns them with anchors
+// resolved. Rules using "after" are advanced by one interval.
+func ValidateEvery(every []string, repoPath string, tasks map[string]*Task) ([]string, error) {
+	if len(every) == 0 {
+		return nil, nil
+	}
+
+	resolvedEvery := make([]string, 0, len(every))
+	for _, value := range every {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		parts := strings.Fields(value)
+		if len(parts) < 2 {
+			return nil, &RecurrenceRuleError{Rule: value, Err: fmt.Errorf(`expected format "<amount> <metric> [from <anchor>]"`), Hint: "10 days"}
+		}
+
+		amount := parts[0]
+		if _, err := strconv.Atoi(amount); err != nil {
+			return nil, &RecurrenceRuleError{Rule: value, Err: fmt.Errorf("amount must be an integer"), Hint: "10 days"}
+		}
+
+		metric := parts[1]
+		switch metric {
+		case "days", "weeks", "months", "commits", "lines_changed", "tasks_completed":
+		default:
+			return nil, &RecurrenceRuleError{Rule: value, Err: fmt.Errorf("unsupported metric %q", metric), Hint: "10 days"}
+		}
+
+		if len(parts) >= 4 && (parts[2] == "from" || parts[2] == "after") {
+			keyword := parts[2]
+			anchor := strings.Join(parts[3:], " ")
+			resolved, err := ValidateAnchor(metric, anchor, repoPath, tasks)
+			if err != nil {
+				return nil, &RecurrenceRuleError{Rule: value, Err: err, Hint: anchorHint(metric)}
+			}
+
+			if keyword == "after" {
+				amountInt, _ := strconv.Atoi(amount)
+				resolved, err = UpdateAnchor(repoPath, repoPath, metric, resolved, amountInt)
+				if err != nil {
+					return nil, fmt.Errorf("failed to calculate 'after' anchor: %w", err)
+				}
+			}
+
+			value = fmt.Sprintf("%s %s from %s", amount, metric, resolved)
+		}
+		resolvedEvery = append(resolvedEvery, value)
+	}
+
+	return resolvedEvery, nil
+}
+
+func anchorHint(metric string) string {
+	switch metric {
+	case "commits", "lines_changed":
+		return "50 commits from HEAD"
+	case "tasks_completed":
+		return "20 tasks_completed from T1a1a"
+	default:
+		return "10 days from Jan 28 2026 09:00 UTC"
+	}
+}
+
 // tempGitRepo creates a temporary git repository for testing.
 func tempGitRepo() (string, func(), error) {
 	tmpDir, err := os.MkdirTemp("", "git-test-repo-")
@@ -0,0 +1,212 @@
+package task
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RemoteRef is a reference to a task in another project, written
+// "project:TaskID" in a task's parent or blockers.
+type RemoteRef struct {
+	Project string
+	ID      string
+}
+
+func (r RemoteRef) String() string {
+	return r.Project + ":" + r.ID
+}
+
+// IsRemoteRef reports whether id refers to a task in another project.
+// Task IDs never contain a colon, so any reference that does is remote.
+func IsRemoteRef(id string) bool {
+	return strings.Contains(id, ":")
+}
+
+// ParseRemoteRef parses a "project:TaskID" reference. The task ID may be a
+// short ID; it is resolved against the other project's tasks.
+func ParseRemoteRef(ref string) (RemoteRef, error) {
+	ref = strings.TrimSpace(ref)
+	idx := strings.LastIndex(ref, ":")
+	if idx < 0 {
+		return RemoteRef{}, fmt.Errorf("%q is not a project:TaskID reference", ref)
+	}
+	project, id := strings.TrimSpace(ref[:idx]), strings.TrimSpace(ref[idx+1:])
+	if project == "" {
+		return RemoteRef{}, fmt.Errorf("reference %q has no project", ref)
+	}
+	if !IsValidTaskID(id) {
+		return RemoteRef{}, fmt.Errorf("reference %q has malformed task ID %q", ref, id)
+	}
+	return RemoteRef{Project: project, ID: id}, nil
+}
+
+// RemoteTask is what a cross-project reference resolved to. Problem is set
+// when the reference is broken; the other fields are then partly empty.
+type RemoteTask struct {
+	Ref       string `json:"ref"`
+	Project   string `json:"project"`
+	ID        string `json:"id"`
+	ShortID   string `json:"short_id"`
+	Title     string `json:"title,omitempty"`
+	Status    string `json:"status,omitempty"`
+	Completed bool   `json:"completed"`
+	Problem   string `json:"problem,omitempty"`
+}
+
+// Done reports whether the remote task no longer blocks anything.
+func (r RemoteTask) Done() bool {
+	return r.Problem == "" && (r.Completed || !IsActiveStatus(r.Status))
+}
+
+// RemoteTasks resolves cross-project references, loading each referenced
+// project's tasks once.
+type RemoteTasks struct {
+	locate func(project string) (tasksDir string, err error)
+	dbs    map[string]*TaskDB
+	errs   map[string]error
+}
+
+// NewRemoteTasks returns a resolver that finds a project's tasks directory
+// with locate, usually through the project registry.
+func NewRemoteTasks(locate func(project string) (tasksDir string, err error)) *RemoteTasks {
+	return &RemoteTasks{
+		locate: locate,
+		dbs:    make(map[string]*TaskDB),
+		errs:   make(map[string]error),
+	}
+}
+
+func (r *RemoteTasks) project(name string) (*TaskDB, error) {
+	if db, ok := r.dbs[name]; ok {
+		return db, nil
+	}
+	if err, ok := r.errs[name]; ok {
+		return nil, err
+	}
+	tasksDir, err := r.locate(name)
+	if err == nil {
+		db := NewTaskDB(tasksDir)
+		if err = db.LoadAll(); err == nil {
+			r.dbs[name] = db
+			return db, nil
+		}
+	}
+	r.errs[name] = err
+	return nil, err
+}
+
+// Lookup resolves ref. Ref is rewritten to the canonical "project:FullID"
+// form when the task is found.
+func (r *RemoteTasks) Lookup(ref string) RemoteTask {
+	result := RemoteTask{Ref: ref}
+	parsed, err := ParseRemoteRef(ref)
+	if err != nil {
+		result.Problem = err.Error()
+		return result
+	}
+	result.Project, result.ID, result.ShortID = parsed.Project, parsed.ID, ShortID(parsed.ID)
+
+	db, err := r.project(parsed.Project)
+	if err != nil {
+		result.Problem = fmt.Sprintf("project %s is not available: %v", parsed.Project, err)
+		return result
+	}
+	id, err := ResolveTaskID(db.GetAll(), parsed.ID)
+	if err != nil {
+		result.Problem = fmt.Sprintf("task %s not found in project %s", parsed.ID, parsed.Project)
+		return result
+	}
+	t := db.GetAll()[id]
+	result.Ref = RemoteRef{Project: parsed.Project, ID: id}.String()
+	result.ID, result.ShortID = id, ShortID(id)
+	result.Title = t.Title()
+	result.Status = t.Meta.Status
+	result.Completed = t.Meta.Completed
+	return result
+}
+
+// RemoteRefs resolves every cross-project parent and blocker of t.
+func (r *RemoteTasks) RemoteRefs(t *Task) []RemoteTask {
+	var refs []RemoteTask
+	if IsRemoteRef(t.Meta.Parent) {
+		refs = append(refs, r.Lookup(t.Meta.Parent))
+	}
+	for _, blocker := range t.Meta.Blockers {
+		if IsRemoteRef(blocker) {
+			refs = append(refs, r.Lookup(blocker))
+		}
+	}
+	return refs
+}
+
+// ReconcileRemoteReferences checks every cross-project parent and blocker.
+// Blockers whose remote task is done are dropped, just as completed local
+// blockers are, and short references are rewritten to canonical form.
+// Broken references are kept and returned as problems so an unavailable
+// project does not silently unblock anything.
+//
+// Returns the number of tasks marked dirty.
+func ReconcileRemoteReferences(tasks map[string]*Task, remote *RemoteTasks) (int, []ValidationError) {
+	updated := 0
+	problems := []ValidationError{}
+	for _, id := range sortedTaskIDs(tasks) {
+		t := tasks[id]
+		changed := false
+
+		if IsRemoteRef(t.Meta.Parent) {
+			resolved := remote.Lookup(t.Meta.Parent)
+			if resolved.Problem != "" {
+				problems = append(problems, ValidationError{
+					TaskID:  id,
+					File:    t.FilePath,
+					Message: fmt.Sprintf("parent %s: %s", t.Meta.Parent, resolved.Problem),
+				})
+			} else if resolved.Ref != t.Meta.Parent {
+				t.Meta.Parent = resolved.Ref
+				changed = true
+			}
+		}
+
+		if !t.Meta.Completed {
+			blockers := make([]string, 0, len(t.Meta.Blockers))
+			for _, blocker := range t.Meta.Blockers {
+				if !IsRemoteRef(blocker) {
+					blockers = append(blockers, blocker)
+					continue
+				}
+				resolved := remote.Lookup(blocker)
+				switch {
+				case resolved.Problem != "":
+					problems = append(problems, ValidationError{
+						TaskID:  id,
+						File:    t.FilePath,
+						Message: fmt.Sprintf("blocker %s: %s", blocker, resolved.Problem),
+					})
+					blockers = append(blockers, blocker)
+				case resolved.Done():
+					changed = true
+				default:
+					blockers = append(blockers, resolved.Ref)
+					changed = changed || resolved.Ref != blocker
+				}
+			}
+			if changed {
+				t.Meta.Blockers = sortedKeys(setOf(blockers))
+			}
+		}
+
+		if changed {
+			t.MarkDirty()
+			updated++
+		}
+	}
+	return updated, problems
+}
+
+func setOf(items []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(items))
+	for _, item := range items {
+		set[item] = struct{}{}
+	}
+	return set
+}
@@ -0,0 +1,163 @@
+package task
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseRemoteRef(t *testing.T) {
+	ref, err := ParseRemoteRef("backend:T1abc-auth-api")
+	if err != nil || ref != (RemoteRef{Project: "backend", ID: "T1abc-auth-api"}) {
+		t.Fatalf("ParseRemoteRef = %+v, %v", ref, err)
+	}
+	if ref, err := ParseRemoteRef("backend:T1abc"); err != nil || ref.ID != "T1abc" {
+		t.Errorf("short ID ref = %+v, %v", ref, err)
+	}
+	for _, bad := range []string{"T1abc-local", ":T1abc", "backend:", "backend:not-an-id"} {
+		if _, err := ParseRemoteRef(bad); err == nil {
+			t.Errorf("ParseRemoteRef(%q) should fail", bad)
+		}
+	}
+}
+
+// remoteBackend creates a backend project with an open and a completed task
+// and returns a resolver that knows only that project.
+func remoteBackend(t *testing.T) *RemoteTasks {
+	t.Helper()
+	db, tasksRoot := setupTestDB(t)
+	createTaskFile(t, tasksRoot, "T1abc-open-api", "Open API")
+	createTaskFile(t, tasksRoot, "T2abc-done-api", "Done API")
+	if err := db.LoadAll(); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SetCompleted("T2abc-done-api", true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.SaveDirty(); err != nil {
+		t.Fatal(err)
+	}
+	return NewRemoteTasks(func(project string) (string, error) {
+		if project != "backend" {
+			return "", fmt.Errorf("project not found: %s", project)
+		}
+		return tasksRoot, nil
+	})
+}
+
+func TestReconcileRemoteReferences(t *testing.T) {
+	remote := remoteBackend(t)
+	frontend := &Task{ID: "T9xyz-ui", Meta: Metadata{
+		Parent:   "backend:T1abc",
+		Blockers: []string{"T8xyz-local", "backend:T1abc", "backend:T2abc-done-api", "backend:T7zzz-gone", "mobile:T1abc-x"},
+	}}
+	tasks := map[string]*Task{"T9xyz-ui": frontend}
+
+	updated, problems := ReconcileRemoteReferences(tasks, remote)
+	if updated != 1 || !frontend.Dirty {
+		t.Errorf("updated = %d, want the frontend task marked dirty", updated)
+	}
+	if frontend.Meta.Parent != "backend:T1abc-open-api" {
+		t.Errorf("parent = %q, want canonical ref", frontend.Meta.Parent)
+	}
+	wantBlockers := []string{"T8xyz-local", "backend:T1abc-open-api", "backend:T7zzz-gone", "mobile:T1abc-x"}
+	if !slices.Equal(frontend.Meta.Blockers, wantBlockers) {
+		t.Errorf("blockers = %v, want %v (done blocker dropped, broken ones kept)", frontend.Meta.Blockers, wantBlockers)
+	}
+	var messages []string
+	for _, p := range problems {
+		messages = append(messages, p.Message)
+	}
+	joined := strings.Join(messages, "\n")
+	if len(problems) != 2 || !strings.Contains(joined, "T7zzz-gone not found in project backend") || !strings.Contains(joined, "project mobile is not available") {
+		t.Errorf("problems = %v", messages)
+	}
+}
+
+func TestReconcileBlockerRelationshipsKeepsRemoteBlockers(t *testing.T) {
+	tasks := map[string]*Task{
+		"T1aaa-ui":  {ID: "T1aaa-ui", Meta: Metadata{Blockers: []string{"backend:T1abc-api"}}},
+		"T2aaa-old": {ID: "T2aaa-old", Meta: Metadata{Blockers: []string{"backend:T1abc-api"}, Completed: true}},
+	}
+	if _, err := ReconcileBlockerRelationships(tasks); err != nil {
+		t.Fatal(err)
+	}
+	if got := tasks["T1aaa-ui"].Meta.Blockers; !slices.Equal(got, []string{"backend:T1abc-api"}) {
+		t.Errorf("open task blockers = %v, want remote blocker kept", got)
+	}
+	if got := tasks["T2aaa-old"].Meta.Blockers; len(got) != 0 {
+		t.Errorf("completed task blockers = %v, want none", got)
+	}
+
+	v := NewValidator(tasks)
+	tasks["T1aaa-ui"].Meta.Blocks = []string{"backend:T2abc-x"}
+	notices := v.FixMissingReferences()
+	if len(notices) != 1 || !strings.Contains(notices[0].Message, "in another project") {
+		t.Errorf("notices = %v, want one cross-project blocks notice", notices)
+	}
+	if got := tasks["T1aaa-ui"].Meta.Blockers; !slices.Equal(got, []string{"backend:T1abc-api"}) {
+		t.Errorf("FixMissingReferences removed remote blocker: %v", got)
+	}
+}
+
+func TestGraphIncludesRemoteNodes(t *testing.T) {
+	remote := remoteBackend(t)
+	tasks := map[string]*Task{
+		"T1aaa-ui": {ID: "T1aaa-ui", TitleContent: "UI", Meta: Metadata{
+			Status:   StatusOpen,
+			Blockers: []string{"backend:T1abc-open-api", "backend:T7zzz-gone"},
+		}},
+	}
+	graph, err := BuildDependencyGraph(tasks, GraphOptions{Remote: remote})
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes := map[string]GraphNode{}
+	for _, n := range graph.Nodes {
+		nodes[n.ID] = n
+	}
+	open := nodes["backend:T1abc-open-api"]
+	if open.Project != "backend" || open.Title != "Open API" || open.ShortID != "backend:T1abc" || open.Problem != "" {
+		t.Errorf("remote node = %+v", open)
+	}
+	if nodes["backend:T7zzz-gone"].Problem == "" {
+		t.Errorf("broken remote node should carry a problem: %+v", nodes["backend:T7zzz-gone"])
+	}
+	if len(graph.Edges) != 2 || graph.Edges[0].To != "T1aaa-ui" {
+		t.Errorf("edges = %+v", graph.Edges)
+	}
+	if mermaid := graph.Mermaid(); !strings.Contains(mermaid, "backend__T1abc --> T1aaa") || !strings.Contains(mermaid, "class backend__T7zzz broken") {
+		t.Errorf("mermaid output:\n%s", mermaid)
+	}
+}
+
+func TestTaskDBAcceptsRemoteReferences(t *testing.T) {
+	db, tasksRoot := setupTestDB(t)
+	createTaskFile(t, tasksRoot, "T1aaa-ui", "UI")
+	if _, err := db.ResolveIDs([]string{"backend:T1abc"}); err == nil {
+		t.Fatal("remote references should be rejected without a resolver")
+	}
+
+	db.SetRemote(remoteBackend(t))
+	ids, err := db.ResolveIDs([]string{"backend:T1abc"})
+	if err != nil || len(ids) != 1 || ids[0] != "backend:T1abc-open-api" {
+		t.Fatalf("ResolveIDs = %v, %v", ids, err)
+	}
+	if _, err := db.ResolveIDs([]string{"backend:T7zzz"}); err == nil {
+		t.Error("unknown remote task should not resolve")
+	}
+	if err := db.AddBlocker("T1aaa-ui", ids[0]); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SetParent("T1aaa-ui", ids[0]); err != nil {
+		t.Fatal(err)
+	}
+	ui, _ := db.Get("T1aaa-ui")
+	if !slices.Equal(ui.Meta.Blockers, ids) || ui.Meta.Parent != ids[0] {
+		t.Errorf("task meta = %+v", ui.Meta)
+	}
+	if err := db.AddBlocked("T1aaa-ui", ids[0]); err == nil {
+		t.Error("blocking a task in another project should fail")
+	}
+}
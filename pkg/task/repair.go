@@ -30,6 +30,7 @@ type Validator struct {
 	errors    []ValidationError
 	idPattern *regexp.Regexp
 	rolesDir  string
+	fields    FieldSchema
 }
 
 type listEntry struct {
@@ -46,19 +47,23 @@ func NewValidator(tasks map[string]*Task) *Validator {
 // NewValidatorWithRoles creates a validator with a custom roles directory.
 func NewValidatorWithRoles(tasks map[string]*Task, rolesDir string) *Validator {
 	// ID pattern: <PREFIX><4-lowercase-alphanumeric>-<slug>
-	// PREFIX is single uppercase letter
+	// PREFIX is one to five uppercase letters
 	// Token is 4 lowercase base36 characters (0-9, a-z)
 	// Slug is 1+ alphanumeric/hyphen characters
-	idPattern := regexp.MustCompile(`^[A-Z][0-9a-z]{4,6}-[a-zA-Z0-9-]+$`)
-
 	return &Validator{
 		tasks:     tasks,
 		errors:    []ValidationError{},
-		idPattern: idPattern,
+		idPattern: fullIDPattern,
 		rolesDir:  rolesDir,
 	}
 }
 
+// WithFields makes the validator check the custom fields declared in schema.
+func (v *Validator) WithFields(schema FieldSchema) *Validator {
+	v.fields = schema
+	return v
+}
+
 // Validate runs all validations, auto-fixes relationships, and returns errors
 func (v *Validator) ValidateAndRepair() []ValidationError {
 	v.errors = []ValidationError{}
@@ -72,6 +77,7 @@ func (v *Validator) ValidateAndRepair() []ValidationError {
 		v.verifyParent(id, task)
 		v.verifyTaskLinks(id, task)
 		v.verifyCompletedStatusConsistency(id, task)
+		v.verifyFields(id, task)
 	}
 
 	v.fixSubtaskTextTitles()
@@ -93,7 +99,7 @@ func (v *Validator) FixMissingReferences() []ValidationError {
 	for id, task := range v.tasks {
 		changed := false
 
-		if task.Meta.Parent != "" {
+		if task.Meta.Parent != "" && !IsRemoteRef(task.Meta.Parent) {
 			if _, exists := v.tasks[task.Meta.Parent]; !exists {
 				notices = append(notices, ValidationError{
 					TaskID:  id,
@@ -118,7 +124,21 @@ func (v *Validator) FixMissingReferences() []ValidationError {
 			changed = true
 		}
 
-		blocks, missingBlocks := filterExistingTaskIDs(task.Meta.Blocks, v.tasks)
+		// Blocks is the local mirror of other tasks' blockers, so it cannot
+		// name a task in another project.
+		localBlocks := make([]string, 0, len(task.Meta.Blocks))
+		for _, blocked := range task.Meta.Blocks {
+			if !IsRemoteRef(blocked) {
+				localBlocks = append(localBlocks, blocked)
+				continue
+			}
+			notices = append(notices, ValidationError{
+				TaskID:  id,
+				File:    task.FilePath,
+				Message: fmt.Sprintf("blocks %s in another project; list this task as a blocker there instead", blocked),
+			})
+		}
+		blocks, missingBlocks := filterExistingTaskIDs(localBlocks, v.tasks)
 		for _, blocked := range missingBlocks {
 			notices = append(notices, ValidationError{
 				TaskID:  id,
@@ -219,11 +239,25 @@ func (v *Validator) verifyStatusField(id string, task *Task) {
 	})
 }
 
+// verifyFields checks declared custom fields against the schema.
+func (v *Validator) verifyFields(id string, task *Task) {
+	for _, problem := range v.fields.CheckTask(task) {
+		v.errors = append(v.errors, ValidationError{
+			TaskID:  id,
+			File:    task.FilePath,
+			Message: problem,
+		})
+	}
+}
+
 // verifyParent checks if parent task exists
 func (v *Validator) verifyParent(id string, task *Task) {
 	if task.Meta.Parent == "" {
 		return // Root task, no parent to verify
 	}
+	if IsRemoteRef(task.Meta.Parent) {
+		return // Checked by ReconcileRemoteReferences
+	}
 
 	if _, exists := v.tasks[task.Meta.Parent]; !exists {
 		v.errors = append(v.errors, ValidationError{
@@ -300,6 +334,9 @@ func addUniqueSorted(slice []string, val string) ([]string, bool) {
 	return slice, true
 }
 
+// filterExistingTaskIDs splits ids into those present in tasks and those
+// missing. Cross-project references count as present; they are checked
+// separately by ReconcileRemoteReferences.
 func filterExistingTaskIDs(ids []string, tasks map[string]*Task) ([]string, []string) {
 	kept := []string{}
 	missing := []string{}
@@ -310,7 +347,7 @@ func filterExistingTaskIDs(ids []string, tasks map[string]*Task) ([]string, []st
 		if id == "" {
 			continue
 		}
-		if _, exists := tasks[id]; !exists {
+		if _, exists := tasks[id]; !exists && !IsRemoteRef(id) {
 			if _, ok := missingSeen[id]; !ok {
 				missing = append(missing, id)
 				missingSeen[id] = struct{}{}
@@ -339,12 +376,9 @@ func extractTaskIDFromPath(path string) string {
 	path = filepath.Clean(path)
 	parts := strings.Split(filepath.ToSlash(path), "/")
 
-	// Task ID pattern: <PREFIX><4-lowercase-alphanumeric>-<slug>
-	idPattern := regexp.MustCompile(`^[A-Z][0-9a-z]{4,6}-[a-zA-Z0-9-]+$`)
-
-	// Scan path components for ID pattern
+	// Scan path components for the task ID pattern
 	for _, part := range parts {
-		if idPattern.MatchString(part) {
+		if fullIDPattern.MatchString(part) {
 			return part
 		}
 	}
@@ -370,8 +404,8 @@ func GenerateMasterLists(tasks map[string]*Task, tasksRoot, rootsFile, freeFile
 			title = task.ID
 		}
 
-		// Root tasks have no parent and are not completed
-		if task.Meta.Parent == "" && !task.Meta.Completed && IsActiveStatus(task.Meta.Status) {
+		// Root tasks have no parent in this project and are not completed
+		if (task.Meta.Parent == "" || IsRemoteRef(task.Meta.Parent)) && !task.Meta.Completed && IsActiveStatus(task.Meta.Status) {
 			roots = append(roots, listEntry{TaskID: task.ID, Path: rel, Label: title})
 		}
 
@@ -496,6 +530,9 @@ func UpdateFreeListIncrementally(tasks map[string]*Task, freeFile string, update
 	if err != nil {
 		return fmt.Errorf("failed to read free tasks file: %w", err)
 	}
+	if IsStaleList(string(content)) {
+		return fmt.Errorf("free tasks file is stale after a merge")
+	}
 
 	parsed := ParseFreeList(string(content), tasks)
 
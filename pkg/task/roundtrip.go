@@ -0,0 +1,441 @@
+package task
+
+import (
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// source is the file a task was parsed from. Content rewrites only the
+// frontmatter keys and body sections that changed since parsing, so comments,
+// key order, unknown sections and formatting elsewhere survive untouched.
+type source struct {
+	// open, frontmatter and close are the opening delimiter line, the
+	// frontmatter lines and the closing delimiter line, each with their
+	// line endings; body is everything after the closing delimiter.
+	open, frontmatter, close, body string
+	// keys maps each top-level frontmatter key present in the file to the
+	// index of its key node in root.Content.
+	root *yaml.Node
+	keys map[string]int
+	// meta holds each encoded Metadata key as parsed, to detect changes.
+	meta map[string]string
+	// parts holds the body parts as parsed.
+	parts bodyParts
+}
+
+// bodyParts are the body pieces Content renders, in comparable form.
+type bodyParts struct {
+	title, body, todos, subs, progress, other string
+}
+
+func (t *Task) bodyParts() bodyParts {
+	return bodyParts{
+		title:    t.TitleContent,
+		body:     t.BodyContent,
+		todos:    FormatTodoItems(t.TodoItems),
+		subs:     FormatSubtaskItems(t.SubsItems),
+		progress: t.ProgressContent,
+		other:    t.OtherContent,
+	}
+}
+
+// newSource records content as the source of t, which was just parsed from
+// it. It returns nil when content has no frontmatter, so Content renders the
+// file from scratch.
+func newSource(content string, t *Task) *source {
+	lines := strings.SplitAfter(content, "\n")
+	if len(lines) == 0 || !isFrontmatterDelimiter(strings.TrimRight(lines[0], "\r\n")) {
+		return nil
+	}
+	end := -1
+	for i := 1; i < len(lines); i++ {
+		if isFrontmatterDelimiter(strings.TrimRight(lines[i], "\r\n")) {
+			end = i
+			break
+		}
+	}
+	if end == -1 {
+		return nil
+	}
+
+	src := &source{
+		open:        lines[0],
+		frontmatter: strings.Join(lines[1:end], ""),
+		close:       lines[end],
+		body:        strings.Join(lines[end+1:], ""),
+		keys:        make(map[string]int),
+		parts:       t.bodyParts(),
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(src.frontmatter), &doc); err != nil {
+		return nil
+	}
+	if len(doc.Content) > 0 {
+		if doc.Content[0].Kind != yaml.MappingNode {
+			return nil
+		}
+		src.root = doc.Content[0]
+		for i := 0; i+1 < len(src.root.Content); i += 2 {
+			src.keys[src.root.Content[i].Value] = i
+		}
+	}
+	meta, _, err := encodeMeta(t.Meta)
+	if err != nil {
+		return nil
+	}
+	src.meta = meta
+	return src
+}
+
+// encodeMeta encodes each Metadata key on its own, returning the values and
+// the nodes they came from in encoding order.
+func encodeMeta(meta Metadata) (map[string]string, []*yaml.Node, error) {
+	var node yaml.Node
+	if err := node.Encode(&meta); err != nil {
+		return nil, nil, err
+	}
+	values := make(map[string]string, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		out, err := yaml.Marshal(node.Content[i+1])
+		if err != nil {
+			return nil, nil, err
+		}
+		values[node.Content[i].Value] = string(out)
+	}
+	return values, node.Content, nil
+}
+
+// content returns the file for t, keeping everything from the source that
+// t has not changed.
+func (s *source) content(t *Task) (string, bool) {
+	frontmatter, ok := s.frontmatterFor(t.Meta)
+	if !ok {
+		return "", false
+	}
+	return s.open + frontmatter + s.close + s.bodyFor(t), true
+}
+
+// frontmatterFor splices the keys of meta that changed into the source
+// frontmatter. Changed keys are rewritten in place, new keys are appended
+// and removed keys are dropped.
+func (s *source) frontmatterFor(meta Metadata) (string, bool) {
+	values, nodes, err := encodeMeta(meta)
+	if err != nil {
+		return "", false
+	}
+
+	lines := strings.SplitAfter(s.frontmatter, "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	// replace maps the first line of a key's value to its new text ("" to
+	// drop it); skip marks the other lines of rewritten or dropped keys.
+	replace := make(map[int]string)
+	skip := make(map[int]bool)
+	var appended strings.Builder
+
+	changed := func(key string) bool {
+		return values[key] != s.meta[key]
+	}
+	for i := 0; i+1 < len(nodes); i += 2 {
+		key := nodes[i].Value
+		if !changed(key) {
+			continue
+		}
+		text, err := marshalPair(nodes[i], nodes[i+1], s.lineComment(key))
+		if err != nil {
+			return "", false
+		}
+		if _, present := s.keys[key]; !present {
+			appended.WriteString(text)
+			continue
+		}
+		start, end := s.keyLines(key, lines)
+		replace[start] = text
+		for l := start + 1; l < end; l++ {
+			skip[l] = true
+		}
+	}
+	for key := range s.meta {
+		if _, still := values[key]; still {
+			continue
+		}
+		if _, present := s.keys[key]; !present {
+			continue
+		}
+		start, end := s.keyLines(key, lines)
+		replace[start] = ""
+		for l := start + 1; l < end; l++ {
+			skip[l] = true
+		}
+	}
+
+	if len(replace) == 0 && appended.Len() == 0 {
+		return s.frontmatter, true
+	}
+	var sb strings.Builder
+	for i, line := range lines {
+		if text, ok := replace[i]; ok {
+			sb.WriteString(text)
+			continue
+		}
+		if skip[i] {
+			continue
+		}
+		sb.WriteString(line)
+		if i == len(lines)-1 && !strings.HasSuffix(line, "\n") {
+			sb.WriteString("\n")
+		}
+	}
+	sb.WriteString(appended.String())
+	return sb.String(), true
+}
+
+// keyLines returns the range of frontmatter lines holding key and its value.
+// Comment and blank lines before the next key belong to that key.
+func (s *source) keyLines(key string, lines []string) (int, int) {
+	idx := s.keys[key]
+	start := s.root.Content[idx].Line - 1
+	end := len(lines)
+	if idx+2 < len(s.root.Content) {
+		end = s.root.Content[idx+2].Line - 1
+	}
+	for end > start+1 {
+		trimmed := strings.TrimSpace(lines[end-1])
+		if trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			break
+		}
+		end--
+	}
+	return start, end
+}
+
+// lineComment returns the comment after key's value on its line, if any.
+func (s *source) lineComment(key string) string {
+	idx, ok := s.keys[key]
+	if !ok {
+		return ""
+	}
+	return s.root.Content[idx+1].LineComment
+}
+
+func marshalPair(key, value *yaml.Node, comment string) (string, error) {
+	if comment != "" && value.Kind == yaml.ScalarNode {
+		copied := *value
+		copied.LineComment = comment
+		value = &copied
+	}
+	out, err := yaml.Marshal(&yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{key, value}})
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// bodyChunk is a heading line and the raw text up to the next heading.
+type bodyChunk struct {
+	heading string
+	level   int
+	name    string
+	text    string
+}
+
+func splitBodyChunks(body string) []bodyChunk {
+	var chunks []bodyChunk
+	current := bodyChunk{}
+	for _, line := range strings.SplitAfter(body, "\n") {
+		trimmed := strings.TrimSpace(line)
+		level := 0
+		switch {
+		case strings.HasPrefix(trimmed, "# "):
+			level = 1
+		case strings.HasPrefix(trimmed, "## "):
+			level = 2
+		}
+		if level == 0 {
+			current.text += line
+			continue
+		}
+		if current.heading != "" || current.text != "" {
+			chunks = append(chunks, current)
+		}
+		current = bodyChunk{heading: line, level: level, name: strings.TrimSpace(trimmed[level+1:])}
+	}
+	if current.heading != "" || current.text != "" {
+		chunks = append(chunks, current)
+	}
+	return chunks
+}
+
+// bodyFor returns the body for t. Unchanged bodies are returned byte for
+// byte; a changed title, TODO list, subtask list or progress log replaces
+// only its own line or section, and appended content such as a completion
+// report goes at the end. Other body changes render the body afresh.
+func (s *source) bodyFor(t *Task) string {
+	parts := t.bodyParts()
+	if parts == s.parts {
+		return s.body
+	}
+	if parts.body != s.parts.body || !strings.HasPrefix(parts.other, s.parts.other) {
+		return t.renderBody()
+	}
+
+	chunks := splitBodyChunks(s.body)
+	find := func(names ...string) int {
+		found := -1
+		for i, c := range chunks {
+			if c.level != 2 {
+				continue
+			}
+			for _, name := range names {
+				if strings.EqualFold(c.name, name) {
+					if found != -1 {
+						return -2
+					}
+					found = i
+				}
+			}
+		}
+		return found
+	}
+
+	if parts.title != s.parts.title {
+		title := -1
+		for i, c := range chunks {
+			if c.level == 1 {
+				title = i
+				break
+			}
+		}
+		if title == -1 {
+			return t.renderBody()
+		}
+		ending := chunks[title].heading[len(strings.TrimRight(chunks[title].heading, "\r\n")):]
+		chunks[title].heading = "# " + parts.title + ending
+	}
+
+	todos, subs, progress := find("todos", "tasks"), find("subtasks"), find("progress")
+	if todos == -2 || subs == -2 || progress == -2 {
+		return t.renderBody()
+	}
+	// A TODO section that also lists subtasks cannot be rewritten on its own.
+	if todos >= 0 && (s.parts.subs != "" && subs == -1) {
+		return t.renderBody()
+	}
+
+	type edit struct {
+		index   int
+		heading string
+		content string
+		items   []TaskItem
+	}
+	var edits []edit
+	if parts.todos != s.parts.todos {
+		edits = append(edits, edit{todos, "## TODOs", parts.todos, t.TodoItems})
+	}
+	if parts.subs != s.parts.subs {
+		edits = append(edits, edit{subs, "## Subtasks", parts.subs, t.SubsItems})
+	}
+	if parts.progress != s.parts.progress {
+		edits = append(edits, edit{progress, "## Progress", parts.progress, nil})
+	}
+
+	removed := make(map[int]bool)
+	var appended []bodyChunk
+	for _, e := range edits {
+		switch {
+		case e.index >= 0 && e.content == "":
+			removed[e.index] = true
+		case e.index >= 0:
+			if text, ok := toggleItems(chunks[e.index].text, e.items); ok {
+				chunks[e.index].text = text
+			} else {
+				chunks[e.index].text = replaceChunkText(chunks[e.index].text, e.content)
+			}
+		case e.content != "":
+			appended = append(appended, bodyChunk{heading: e.heading + "\n", text: e.content + "\n"})
+		}
+	}
+
+	var sb strings.Builder
+	for i, c := range chunks {
+		if removed[i] {
+			continue
+		}
+		sb.WriteString(c.heading)
+		sb.WriteString(c.text)
+	}
+	if added := strings.TrimLeft(parts.other[len(s.parts.other):], "\n"); added != "" {
+		appended = append(appended, bodyChunk{text: added + "\n"})
+	}
+	for _, c := range appended {
+		out := sb.String()
+		if !strings.HasSuffix(out, "\n") {
+			sb.WriteString("\n")
+		}
+		if !strings.HasSuffix(out, "\n\n") {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(c.heading)
+		sb.WriteString(c.text)
+	}
+	return sb.String()
+}
+
+// replaceChunkText swaps the content of a section, keeping the blank lines
+// around it.
+func replaceChunkText(raw, content string) string {
+	lead := raw[:len(raw)-len(strings.TrimLeft(raw, "\r\n"))]
+	trail := raw[len(strings.TrimRight(raw, " \t\r\n")):]
+	if strings.TrimSpace(raw) == "" {
+		lead = ""
+	}
+	if !strings.Contains(trail, "\n") {
+		trail += "\n"
+	}
+	return lead + content + trail
+}
+
+// toggleItems flips the checkboxes of a list section in place when items
+// differ from the listed ones only in their checked state, so numbering and
+// spacing survive completing a TODO.
+func toggleItems(raw string, items []TaskItem) (string, bool) {
+	listed := ParseTaskItems(raw)
+	if len(items) == 0 || len(listed) != len(items) {
+		return "", false
+	}
+	for i, item := range items {
+		old := listed[i]
+		if old.Role != item.Role || old.Text != item.Text || old.Report != item.Report ||
+			ShortID(old.SubtaskID) != ShortID(item.SubtaskID) {
+			return "", false
+		}
+	}
+
+	lines := strings.SplitAfter(raw, "\n")
+	n := 0
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		match := itemPattern.FindStringSubmatch(trimmed)
+		if match == nil {
+			continue
+		}
+		if listed[n].Checked != items[n].Checked {
+			box := strings.Index(line, "["+match[1]+"]")
+			if match[1] == "" || box == -1 {
+				return "", false
+			}
+			mark := " "
+			if items[n].Checked {
+				mark = "x"
+			}
+			lines[i] = line[:box+1] + mark + line[box+2:]
+		}
+		n++
+	}
+	return strings.Join(lines, ""), true
+}
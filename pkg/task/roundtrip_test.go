@@ -0,0 +1,179 @@
+package task
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestContentRoundTripsUnchangedFiles(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "roundtrip", "*.md"))
+	if err != nil {
+		t.Fatalf("glob: %v", err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("no round-trip fixtures")
+	}
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("read fixture: %v", err)
+			}
+			parsed, err := NewParser().ParseString(string(data), "T1abc")
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			if got := parsed.Content(); got != string(data) {
+				t.Fatalf("content changed on round trip:\n%s", got)
+			}
+		})
+	}
+}
+
+func TestContentRewritesOnlyChangedParts(t *testing.T) {
+	edited := time.Date(2026, 4, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		fixture string
+		mutate  func(*Task)
+	}{
+		{
+			name:    "status",
+			fixture: "annotated.md",
+			mutate: func(task *Task) {
+				task.Meta.Status = StatusInProgress
+				task.Meta.DateEdited = edited
+			},
+		},
+		{
+			name:    "role-comment",
+			fixture: "annotated.md",
+			mutate: func(task *Task) {
+				task.Meta.Role = "reviewer"
+				task.Meta.Blockers = nil
+			},
+		},
+		{
+			name:    "fields",
+			fixture: "annotated.md",
+			mutate: func(task *Task) {
+				delete(task.Meta.Fields, "component")
+				task.Meta.Fields["severity"] = "sev1"
+				task.Meta.Fields["team"] = "storage"
+			},
+		},
+		{
+			name:    "complete-todo",
+			fixture: "annotated.md",
+			mutate: func(task *Task) {
+				task.TodoItems[0].Checked = true
+				task.ProgressContent += "\n- 2026-04-01: reproduced on a soft mount"
+			},
+		},
+		{
+			name:    "title",
+			fixture: "annotated.md",
+			mutate: func(task *Task) {
+				task.TitleContent = "Lock acquisition times out on NFS"
+			},
+		},
+		{
+			name:    "complete",
+			fixture: "todos.md",
+			mutate: func(task *Task) {
+				task.Meta.Completed = false
+				task.Meta.DateEdited = edited
+				task.TodoItems[1].Checked = true
+				task.ProgressContent = "- 2026-04-01: skeleton in place"
+			},
+		},
+		{
+			name:    "drop-subtasks",
+			fixture: "subtasks.md",
+			mutate: func(task *Task) {
+				task.SubsItems = task.SubsItems[:1]
+				task.TodoItems = nil
+			},
+		},
+		{
+			name:    "completion-report",
+			fixture: "annotated.md",
+			mutate: func(task *Task) {
+				task.Meta.Completed = true
+				task.OtherContent = "## Completion Report\nFixed by retrying with backoff."
+			},
+		},
+		{
+			name:    "body",
+			fixture: "todos.md",
+			mutate: func(task *Task) {
+				task.SetBody("## Summary\nRewritten summary.")
+				task.Meta.DateEdited = edited
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", "roundtrip", tt.fixture))
+			if err != nil {
+				t.Fatalf("read fixture: %v", err)
+			}
+			parsed, err := NewParser().ParseString(string(data), "T1abc")
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			tt.mutate(parsed)
+			got := parsed.Content()
+			assertExactGolden(t, filepath.Join("testdata", "roundtrip", tt.name+".golden"), got)
+
+			reparsed, err := NewParser().ParseString(got, "T1abc")
+			if err != nil {
+				t.Fatalf("reparse: %v", err)
+			}
+			if reparsed.Content() != got {
+				t.Fatalf("rewritten file does not round trip")
+			}
+		})
+	}
+}
+
+func TestWriteKeepsSourceForLaterEdits(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "roundtrip", "annotated.md"))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	parsed, err := NewParser().ParseString(string(data), "T1abc")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	parsed.FilePath = filepath.Join(t.TempDir(), "T1abc.md")
+	parsed.Meta.Priority = "low"
+	if err := parsed.Write(); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	parsed.Meta.Priority = "high"
+	if got := parsed.Content(); got != string(data) {
+		t.Fatalf("reverting the edit after a write should restore the file:\n%s", got)
+	}
+}
+
+// assertExactGolden compares got byte for byte, unlike assertGolden.
+func assertExactGolden(t *testing.T, path, got string) {
+	t.Helper()
+	if os.Getenv("UPDATE_GOLDEN") == "1" {
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("write golden: %v", err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden: %v", err)
+	}
+	if got != string(want) {
+		t.Fatalf("output mismatch for %s\n--- got ---\n%s\n--- want ---\n%s", path, got, want)
+	}
+}
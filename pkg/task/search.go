@@ -11,37 +11,41 @@ type SearchOptions struct {
 	ListOptions
 }
 
-// SearchTasks loads tasks and returns those matching the search query.
-func SearchTasks(tasksRoot string, opts SearchOptions) ([]*Task, error) {
+// SearchTasks loads tasks and returns those matching the search query. Like
+// ListTasks, it also returns task impact when opts need it.
+func SearchTasks(tasksRoot string, opts SearchOptions) ([]*Task, map[string]TaskImpact, error) {
 	query := strings.TrimSpace(opts.Query)
 	if query == "" {
-		return nil, fmt.Errorf("search query cannot be empty")
+		return nil, nil, fmt.Errorf("search query cannot be empty")
 	}
 
 	parser := NewParser()
 	tasks, err := parser.LoadTasks(tasksRoot)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	items, err := filterTasks(tasksRoot, tasks, opts.ListOptions)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	matched := make([]*Task, 0, len(items))
 	for _, t := range items {
 		ok, err := matchesQuery(t, query)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		if ok {
 			matched = append(matched, t)
 		}
 	}
 
+	if opts.NeedsImpact() && opts.Impact == nil {
+		opts.Impact = ComputeImpact(tasks)
+	}
 	sortTasks(matched, opts.ListOptions)
-	return matched, nil
+	return matched, opts.Impact, nil
 }
 
 func matchesQuery(t *Task, query string) (bool, error) {
@@ -62,7 +62,7 @@ func TestSearchTasksMatchesTitleBodyTodosAndSkipsSubtasks(t *testing.T) {
 		tc := tc
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel()
-			tasks, err := SearchTasks(root, SearchOptions{Query: tc.query})
+			tasks, _, err := SearchTasks(root, SearchOptions{Query: tc.query})
 			if err != nil {
 				t.Fatalf("SearchTasks failed: %v", err)
 			}
@@ -83,7 +83,7 @@ func TestSearchTasksRequiresQuery(t *testing.T) {
 		t.Fatalf("mkdir tasks root: %v", err)
 	}
 
-	_, err := SearchTasks(root, SearchOptions{Query: " "})
+	_, _, err := SearchTasks(root, SearchOptions{Query: " "})
 	if err == nil {
 		t.Fatalf("expected error for empty query")
 	}
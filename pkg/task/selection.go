@@ -0,0 +1,233 @@
+package task
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Names of the built-in selection policies used by `strand next`.
+const (
+	PolicyPriority   = "priority"
+	PolicyOldest     = "oldest"
+	PolicyAging      = "aging"
+	PolicyRoundRobin = "round-robin"
+	PolicyImpact     = "impact"
+)
+
+// DefaultAgingInterval is how long a task waits before the aging policy
+// raises it by one priority level.
+const DefaultAgingInterval = 7 * 24 * time.Hour
+
+// SelectionContext carries the project state a selection policy may consult.
+type SelectionContext struct {
+	// Now is the reference time for age-based policies.
+	Now time.Time
+	// Tasks holds every loaded task, not just the candidates, keyed by
+	// Task.Key.
+	Tasks map[string]*Task
+	// AgingInterval overrides DefaultAgingInterval when positive.
+	AgingInterval time.Duration
+}
+
+// SelectionPolicy decides which free task `strand next` hands out first.
+type SelectionPolicy interface {
+	// Name returns the identifier accepted by --policy and strand.yaml.
+	Name() string
+	// Less reports whether a should be selected before b. Ties are broken by
+	// SortCandidates, so policies only need to express their preference.
+	Less(a, b *Task) bool
+}
+
+var selectionPolicies = map[string]func(SelectionContext) SelectionPolicy{
+	PolicyPriority: func(SelectionContext) SelectionPolicy { return priorityPolicy{} },
+	PolicyOldest:   func(SelectionContext) SelectionPolicy { return oldestPolicy{} },
+	PolicyAging: func(ctx SelectionContext) SelectionPolicy {
+		interval := ctx.AgingInterval
+		if interval <= 0 {
+			interval = DefaultAgingInterval
+		}
+		return agingPolicy{now: ctx.Now, interval: interval}
+	},
+	PolicyRoundRobin: func(ctx SelectionContext) SelectionPolicy {
+		return roundRobinPolicy{lastServed: lastServedByParent(ctx.Tasks)}
+	},
+	PolicyImpact: func(ctx SelectionContext) SelectionPolicy {
+		return impactPolicy{impact: impactByKey(ctx.Tasks)}
+	},
+}
+
+// SelectionPolicyNames returns the names of the built-in policies in sorted order.
+func SelectionPolicyNames() []string {
+	names := make([]string, 0, len(selectionPolicies))
+	for name := range selectionPolicies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewSelectionPolicy returns the named policy. An empty name selects PolicyPriority.
+func NewSelectionPolicy(name string, ctx SelectionContext) (SelectionPolicy, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		name = PolicyPriority
+	}
+	build, ok := selectionPolicies[name]
+	if !ok {
+		return nil, fmt.Errorf("invalid policy: %s (must be one of: %s)", name, strings.Join(SelectionPolicyNames(), ", "))
+	}
+	return build(ctx), nil
+}
+
+// SortCandidates orders candidates by policy, breaking ties by task file path
+// and then ID so the selection is deterministic.
+func SortCandidates(candidates []*Task, policy SelectionPolicy) {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if policy.Less(a, b) {
+			return true
+		}
+		if policy.Less(b, a) {
+			return false
+		}
+		pa, pb := filepath.ToSlash(a.FilePath), filepath.ToSlash(b.FilePath)
+		if pa != pb {
+			return pa < pb
+		}
+		return a.ID < b.ID
+	})
+}
+
+// priorityPolicy picks the highest priority task, then the first in tree order.
+type priorityPolicy struct{}
+
+func (priorityPolicy) Name() string { return PolicyPriority }
+
+func (priorityPolicy) Less(a, b *Task) bool {
+	return PriorityRank(a.Meta.Priority) < PriorityRank(b.Meta.Priority)
+}
+
+// oldestPolicy picks the task created first. Tasks without a creation date go
+// last; ties fall back to priority.
+type oldestPolicy struct{}
+
+func (oldestPolicy) Name() string { return PolicyOldest }
+
+func (oldestPolicy) Less(a, b *Task) bool {
+	ca, cb := a.Meta.DateCreated, b.Meta.DateCreated
+	if !ca.Equal(cb) {
+		if ca.IsZero() || cb.IsZero() {
+			return cb.IsZero()
+		}
+		return ca.Before(cb)
+	}
+	return priorityPolicy{}.Less(a, b)
+}
+
+// agingPolicy raises a task by one priority level for every interval it has
+// existed, so old low-priority work eventually outranks fresh high-priority work.
+type agingPolicy struct {
+	now      time.Time
+	interval time.Duration
+}
+
+func (agingPolicy) Name() string { return PolicyAging }
+
+func (p agingPolicy) Less(a, b *Task) bool {
+	ra, rb := p.rank(a), p.rank(b)
+	if ra != rb {
+		return ra < rb
+	}
+	return oldestPolicy{}.Less(a, b)
+}
+
+func (p agingPolicy) rank(t *Task) int {
+	rank := PriorityRank(t.Meta.Priority)
+	if t.Meta.DateCreated.IsZero() || !p.now.After(t.Meta.DateCreated) {
+		return rank
+	}
+	return rank - int(p.now.Sub(t.Meta.DateCreated)/p.interval)
+}
+
+// roundRobinPolicy spreads work across parents: it prefers the parent whose
+// tasks were least recently picked up, then falls back to priority. Root tasks
+// share a single group.
+type roundRobinPolicy struct {
+	lastServed map[string]time.Time
+}
+
+func (roundRobinPolicy) Name() string { return PolicyRoundRobin }
+
+func (p roundRobinPolicy) Less(a, b *Task) bool {
+	ka, kb := parentKey(a), parentKey(b)
+	sa, sb := p.lastServed[ka], p.lastServed[kb]
+	if !sa.Equal(sb) {
+		return sa.Before(sb)
+	}
+	if ka != kb {
+		return ka < kb
+	}
+	return priorityPolicy{}.Less(a, b)
+}
+
+// parentKey names the group t shares with its siblings. Parents in different
+// projects are different groups even when their IDs match.
+func parentKey(t *Task) string {
+	if t.Project == "" || IsRemoteRef(t.Meta.Parent) {
+		return t.Meta.Parent
+	}
+	return RemoteRef{Project: t.Project, ID: t.Meta.Parent}.String()
+}
+
+// lastServedByParent returns, for each parentKey, the latest edit time of a child
+// that has been claimed or completed.
+func lastServedByParent(tasks map[string]*Task) map[string]time.Time {
+	served := make(map[string]time.Time)
+	for _, t := range tasks {
+		if !t.Meta.Completed && !t.Meta.IsInProgress() {
+			continue
+		}
+		if key := parentKey(t); t.Meta.DateEdited.After(served[key]) {
+			served[key] = t.Meta.DateEdited
+		}
+	}
+	return served
+}
+
+// impactPolicy keeps priority bands intact and, within a band, picks the task
+// that unblocks the most downstream work.
+type impactPolicy struct {
+	impact map[string]TaskImpact
+}
+
+func (impactPolicy) Name() string { return PolicyImpact }
+
+func (p impactPolicy) Less(a, b *Task) bool {
+	ra, rb := PriorityRank(a.Meta.Priority), PriorityRank(b.Meta.Priority)
+	if ra != rb {
+		return ra < rb
+	}
+	return p.impact[b.Key()].Less(p.impact[a.Key()])
+}
+
+// impactByKey computes impact separately for each project's tasks, since
+// blockers only name tasks in the same project, and keys it by Task.Key.
+func impactByKey(tasks map[string]*Task) map[string]TaskImpact {
+	byProject := make(map[string]map[string]*Task)
+	for _, t := range tasks {
+		if byProject[t.Project] == nil {
+			byProject[t.Project] = make(map[string]*Task)
+		}
+		byProject[t.Project][t.ID] = t
+	}
+	impact := make(map[string]TaskImpact, len(tasks))
+	for _, projectTasks := range byProject {
+		for id, ti := range ComputeImpact(projectTasks) {
+			impact[projectTasks[id].Key()] = ti
+		}
+	}
+	return impact
+}
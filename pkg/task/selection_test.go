@@ -0,0 +1,152 @@
+package task
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func selectionTestTasks(now time.Time) map[string]*Task {
+	newTask := func(id, parent, priority string, age time.Duration) *Task {
+		return &Task{
+			ID:       id,
+			FilePath: "tasks/" + id + ".md",
+			Meta: Metadata{
+				Role:        "developer",
+				Priority:    priority,
+				Parent:      parent,
+				Status:      StatusOpen,
+				DateCreated: now.Add(-age),
+				DateEdited:  now.Add(-age),
+			},
+		}
+	}
+	day := 24 * time.Hour
+	tasks := map[string]*Task{
+		"E1aaa-epic":  newTask("E1aaa-epic", "", PriorityMedium, 30*day),
+		"E2aaa-epic":  newTask("E2aaa-epic", "", PriorityMedium, 30*day),
+		"T1aaa-high":  newTask("T1aaa-high", "E1aaa-epic", PriorityHigh, 1*day),
+		"T2aaa-med":   newTask("T2aaa-med", "E1aaa-epic", PriorityMedium, 3*day),
+		"T3aaa-low":   newTask("T3aaa-low", "E2aaa-epic", PriorityLow, 20*day),
+		"T4aaa-block": newTask("T4aaa-block", "E2aaa-epic", PriorityMedium, 2*day),
+		"T5aaa-dep1":  newTask("T5aaa-dep1", "", PriorityMedium, 2*day),
+		"T6aaa-dep2":  newTask("T6aaa-dep2", "", PriorityMedium, 2*day),
+		"T7aaa-done":  newTask("T7aaa-done", "E1aaa-epic", PriorityMedium, 5*day),
+	}
+	tasks["T5aaa-dep1"].Meta.Blockers = []string{"T4aaa-block"}
+	tasks["T6aaa-dep2"].Meta.Blockers = []string{"T4aaa-block"}
+	tasks["T7aaa-done"].Meta.Completed = true
+	tasks["T7aaa-done"].Meta.Status = StatusDone
+	tasks["T7aaa-done"].Meta.DateEdited = now.Add(-time.Hour)
+	return tasks
+}
+
+func TestSelectionPolicies(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tasks := selectionTestTasks(now)
+	candidateIDs := []string{"T3aaa-low", "T2aaa-med", "T4aaa-block", "T1aaa-high"}
+
+	cases := []struct {
+		policy string
+		want   string
+	}{
+		{PolicyPriority, "T1aaa-high,T2aaa-med,T4aaa-block,T3aaa-low"},
+		{PolicyOldest, "T3aaa-low,T2aaa-med,T4aaa-block,T1aaa-high"},
+		// Twenty days at a seven day interval lifts low by two levels to high.
+		{PolicyAging, "T3aaa-low,T1aaa-high,T2aaa-med,T4aaa-block"},
+		// E1aaa-epic served an hour ago, so E2aaa-epic's children go first.
+		{PolicyRoundRobin, "T4aaa-block,T3aaa-low,T1aaa-high,T2aaa-med"},
+		// Priority comes first; unblocking two tasks lifts T4 within the medium band.
+		{PolicyImpact, "T1aaa-high,T4aaa-block,T2aaa-med,T3aaa-low"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.policy, func(t *testing.T) {
+			policy, err := NewSelectionPolicy(tc.policy, SelectionContext{Now: now, Tasks: tasks})
+			if err != nil {
+				t.Fatalf("NewSelectionPolicy failed: %v", err)
+			}
+			if policy.Name() != tc.policy {
+				t.Fatalf("Name() = %s, want %s", policy.Name(), tc.policy)
+			}
+			candidates := make([]*Task, 0, len(candidateIDs))
+			for _, id := range candidateIDs {
+				candidates = append(candidates, tasks[id])
+			}
+			SortCandidates(candidates, policy)
+			got := make([]string, 0, len(candidates))
+			for _, c := range candidates {
+				got = append(got, c.ID)
+			}
+			if strings.Join(got, ",") != tc.want {
+				t.Fatalf("unexpected order\n got: %v\nwant: %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSelectionPolicyTieBreak(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tasks := selectionTestTasks(now)
+	candidates := []*Task{tasks["T6aaa-dep2"], tasks["T5aaa-dep1"]}
+
+	for _, name := range SelectionPolicyNames() {
+		policy, err := NewSelectionPolicy(name, SelectionContext{Now: now, Tasks: tasks})
+		if err != nil {
+			t.Fatalf("NewSelectionPolicy(%s) failed: %v", name, err)
+		}
+		SortCandidates(candidates, policy)
+		if candidates[0].ID != "T5aaa-dep1" {
+			t.Errorf("%s: expected tie broken by path, got %s first", name, candidates[0].ID)
+		}
+	}
+}
+
+func TestSelectionPoliciesAcrossProjects(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	// Both projects have a T1aaa-shared; only the one in "web" blocks anything.
+	newTask := func(project, id, path string) *Task {
+		return &Task{
+			ID:       id,
+			Project:  project,
+			FilePath: path,
+			Meta: Metadata{
+				Role:        "developer",
+				Priority:    PriorityMedium,
+				Status:      StatusOpen,
+				DateCreated: now,
+				DateEdited:  now,
+			},
+		}
+	}
+	api := newTask("api", "T1aaa-shared", "api/tasks/T1aaa-shared.md")
+	web := newTask("web", "T1aaa-shared", "web/tasks/T1aaa-shared.md")
+	dep := newTask("web", "T2aaa-dep", "web/tasks/T2aaa-dep.md")
+	dep.Meta.Blockers = []string{"T1aaa-shared"}
+	tasks := map[string]*Task{}
+	for _, task := range []*Task{api, web, dep} {
+		tasks[task.Key()] = task
+	}
+
+	policy, err := NewSelectionPolicy(PolicyImpact, SelectionContext{Now: now, Tasks: tasks})
+	if err != nil {
+		t.Fatalf("NewSelectionPolicy failed: %v", err)
+	}
+	candidates := []*Task{api, web}
+	SortCandidates(candidates, policy)
+	if candidates[0] != web {
+		t.Fatalf("expected the web task with impact first, got %s", candidates[0].Key())
+	}
+}
+
+func TestNewSelectionPolicyDefaultsAndErrors(t *testing.T) {
+	policy, err := NewSelectionPolicy("", SelectionContext{})
+	if err != nil {
+		t.Fatalf("NewSelectionPolicy failed: %v", err)
+	}
+	if policy.Name() != PolicyPriority {
+		t.Fatalf("expected default policy %s, got %s", PolicyPriority, policy.Name())
+	}
+	if _, err := NewSelectionPolicy("random", SelectionContext{}); err == nil || !strings.Contains(err.Error(), "invalid policy") {
+		t.Fatalf("expected invalid policy error, got %v", err)
+	}
+}
@@ -11,19 +11,24 @@ import (
 
 // Metadata represents the YAML frontmatter of a task
 type Metadata struct {
-	Type          string    `yaml:"type"`
-	Role          string    `yaml:"role"`
-	Priority      string    `yaml:"priority"`
-	Parent        string    `yaml:"parent"`
-	Blockers      []string  `yaml:"blockers"`
-	Blocks        []string  `yaml:"blocks"`
-	DateCreated   time.Time `yaml:"date_created"`
-	DateEdited    time.Time `yaml:"date_edited"`
-	OwnerApproval bool      `yaml:"owner_approval"`
-	Completed     bool      `yaml:"completed"`
-	Status        string    `yaml:"status"`
-	Every         []string  `yaml:"every,omitempty"`
-	Description   string    `yaml:"description"`
+	Type          string            `yaml:"type"`
+	Role          string            `yaml:"role"`
+	Priority      string            `yaml:"priority"`
+	Parent        string            `yaml:"parent"`
+	Blockers      []string          `yaml:"blockers"`
+	Blocks        []string          `yaml:"blocks"`
+	DateCreated   time.Time         `yaml:"date_created"`
+	DateEdited    time.Time         `yaml:"date_edited"`
+	OwnerApproval bool              `yaml:"owner_approval"`
+	Completed     bool              `yaml:"completed"`
+	Status        string            `yaml:"status"`
+	ClaimedBy     string            `yaml:"claimed_by,omitempty"`
+	Every         []string          `yaml:"every,omitempty"`
+	Vars          map[string]string `yaml:"vars,omitempty"`
+	Description   string            `yaml:"description"`
+	// Fields holds every other frontmatter key, including the custom fields
+	// declared in config, so they survive a rewrite.
+	Fields map[string]interface{} `yaml:",inline"`
 }
 
 // Task represents a complete task with metadata and content
@@ -39,6 +44,12 @@ type Task struct {
 	ProgressContent string
 	OtherContent    string
 	Dirty           bool
+	// Project names the project the task was loaded from when tasks from
+	// several projects are listed together; it is empty otherwise.
+	Project string
+
+	// source is the file the task was parsed from, if any.
+	source *source
 }
 
 // SetTitle updates the task title.
@@ -96,6 +107,9 @@ func (t *Task) Write() error {
 	}
 
 	t.Dirty = false
+	if reparsed, err := NewParser().ParseString(newContent, t.ID); err == nil {
+		t.source = reparsed.source
+	}
 	return nil
 }
 
@@ -104,14 +118,32 @@ func (t *Task) Title() string {
 	return t.TitleContent
 }
 
+// Key identifies the task among tasks from several projects: its ID, written
+// as a project:ID reference when Project is set.
+func (t *Task) Key() string {
+	if t.Project == "" {
+		return t.ID
+	}
+	return RemoteRef{Project: t.Project, ID: t.ID}.String()
+}
+
 // Content returns the full task content as it would be written to file.
+// Tasks parsed from a file keep its untouched frontmatter keys, comments and
+// body bytes; only changed fields are rewritten.
 func (t *Task) Content() string {
-	var sb strings.Builder
-
+	if t.source != nil {
+		if content, ok := t.source.content(t); ok {
+			return content
+		}
+	}
 	frontmatterBytes, _ := yaml.Marshal(&t.Meta)
-	sb.WriteString("---\n")
-	sb.Write(frontmatterBytes)
-	sb.WriteString("---\n\n")
+	return "---\n" + string(frontmatterBytes) + "---\n" + t.renderBody()
+}
+
+// renderBody renders the body from scratch, starting with the blank line
+// after the frontmatter; it is empty when the task has no body.
+func (t *Task) renderBody() string {
+	var sb strings.Builder
 
 	if t.TitleContent != "" {
 		sb.WriteString("# ")
@@ -147,7 +179,11 @@ func (t *Task) Content() string {
 		sb.WriteString("\n")
 	}
 
-	return strings.TrimRight(sb.String(), "\n") + "\n"
+	body := strings.TrimRight(sb.String(), "\n")
+	if body == "" {
+		return ""
+	}
+	return "\n" + body + "\n"
 }
 
 // GetEffectiveRole returns the task's role, checking metadata first, then first TODO
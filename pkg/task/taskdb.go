@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"slices"
 	"sort"
+	"strings"
 )
 
 // TaskDB lazy-loads and manages tasks with strict relationship integrity.
@@ -15,6 +16,7 @@ type TaskDB struct {
 	tasksRoot string
 	parser    *Parser
 	tasks     map[string]*Task
+	remote    *RemoteTasks
 }
 
 // NewTaskDB creates a new TaskDB instance.
@@ -26,6 +28,12 @@ func NewTaskDB(tasksRoot string) *TaskDB {
 	}
 }
 
+// SetRemote lets the database accept project:TaskID references to tasks in
+// other projects as parents and blockers. Without it they are rejected.
+func (db *TaskDB) SetRemote(remote *RemoteTasks) {
+	db.remote = remote
+}
+
 // Get retrieves a task by ID, lazy-loading from disk if needed.
 func (db *TaskDB) Get(id string) (*Task, error) {
 	if task, ok := db.tasks[id]; ok {
@@ -102,6 +110,16 @@ func (db *TaskDB) SetParent(childID, parentID string) error {
 		return db.ClearParent(childID)
 	}
 
+	if IsRemoteRef(parentID) {
+		// Remote parents are not tracked locally, so there is no cycle to check
+		// and no TODO list to update.
+		if child.Meta.Parent != parentID {
+			child.Meta.Parent = parentID
+			child.MarkDirty()
+		}
+		return nil
+	}
+
 	parent, err := db.Get(parentID)
 	if err != nil {
 		return fmt.Errorf("parent task not found: %w", err)
@@ -205,11 +223,25 @@ func (db *TaskDB) AddBlocker(taskID, blockerID string) error {
 		return fmt.Errorf("task cannot block itself")
 	}
 
+	if IsRemoteRef(taskID) {
+		return fmt.Errorf("cannot block %s in another project; add the blocker in that project", taskID)
+	}
+
 	task, err := db.Get(taskID)
 	if err != nil {
 		return fmt.Errorf("task not found: %w", err)
 	}
 
+	if IsRemoteRef(blockerID) {
+		// A remote blocker has no local side to mirror into Blocks.
+		if !slices.Contains(task.Meta.Blockers, blockerID) {
+			task.Meta.Blockers = append(task.Meta.Blockers, blockerID)
+			sort.Strings(task.Meta.Blockers)
+			task.MarkDirty()
+		}
+		return nil
+	}
+
 	blocker, err := db.Get(blockerID)
 	if err != nil {
 		return fmt.Errorf("blocker task not found: %w", err)
@@ -378,6 +410,23 @@ func (db *TaskDB) ClaimTask(taskID string) error {
 	return db.SetStatusWithReport(taskID, StatusInProgress, "")
 }
 
+// ClaimTaskAs marks a task as in progress and records the claiming agent.
+// An empty agent clears any previous claimant.
+func (db *TaskDB) ClaimTaskAs(taskID, agent string) error {
+	if err := db.ClaimTask(taskID); err != nil {
+		return err
+	}
+	task, err := db.Get(taskID)
+	if err != nil {
+		return err
+	}
+	if task.Meta.ClaimedBy != agent {
+		task.Meta.ClaimedBy = agent
+		task.MarkDirty()
+	}
+	return nil
+}
+
 // ReconcileBlockerRelationships repairs blocker relationships in a single pass.
 // This keeps parent/child-derived blockers and explicit blocker edges in sync,
 // then rewrites Blockers and Blocks as sorted bidirectional sets.
@@ -455,7 +504,23 @@ func (db *TaskDB) ResolveID(input string) (string, error) {
 	if err := db.LoadAllIfEmpty(); err != nil {
 		return "", err
 	}
-	return ResolveTaskID(db.tasks, input)
+	return db.resolveID(input)
+}
+
+// resolveID resolves a local ID, or a project:TaskID reference when a remote
+// resolver is set.
+func (db *TaskDB) resolveID(input string) (string, error) {
+	if !IsRemoteRef(input) {
+		return ResolveTaskID(db.tasks, input)
+	}
+	if db.remote == nil {
+		return "", fmt.Errorf("cross-project reference %s is not supported here", strings.TrimSpace(input))
+	}
+	resolved := db.remote.Lookup(strings.TrimSpace(input))
+	if resolved.Problem != "" {
+		return "", fmt.Errorf("%s: %s", strings.TrimSpace(input), resolved.Problem)
+	}
+	return resolved.Ref, nil
 }
 
 // ResolveIDs resolves a list of task ID inputs, de-duplicates, and sorts them.
@@ -469,7 +534,7 @@ func (db *TaskDB) ResolveIDs(inputs []string) ([]string, error) {
 	seen := make(map[string]struct{})
 	resolved := make([]string, 0, len(inputs))
 	for _, input := range inputs {
-		id, err := ResolveTaskID(db.tasks, input)
+		id, err := db.resolveID(input)
 		if err != nil {
 			return nil, err
 		}
@@ -798,14 +863,14 @@ func (db *TaskDB) UpdateParentTodos(parentID string) (bool, error) {
 }
 
 // UpdateParentTodosForChild updates the parent's TODO entries after a child change.
-// If the child has no parent, this is a no-op.
+// If the child has no parent, or its parent is in another project, this is a no-op.
 // Returns true if the parent was modified.
 func (db *TaskDB) UpdateParentTodosForChild(childID string) (bool, error) {
 	task, err := db.Get(childID)
 	if err != nil {
 		return false, fmt.Errorf("task not found: %w", err)
 	}
-	if task.Meta.Parent == "" {
+	if task.Meta.Parent == "" || IsRemoteRef(task.Meta.Parent) {
 		return false, nil
 	}
 	return db.UpdateParentTodos(task.Meta.Parent)
@@ -0,0 +1,189 @@
+package task
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Scopes a work-in-progress limit can apply to.
+const (
+	WIPScopeRole  = "role"
+	WIPScopeAgent = "agent"
+)
+
+// WIPLimits caps how many tasks may be in progress at once. A zero limit
+// means unlimited.
+type WIPLimits struct {
+	// Roles maps a role name to its limit.
+	Roles map[string]int
+	// Agent is the default limit for every agent.
+	Agent int
+	// Agents overrides Agent for specific agents.
+	Agents map[string]int
+}
+
+// RoleLimit returns the limit for role, or zero when it is unlimited.
+func (l WIPLimits) RoleLimit(role string) int {
+	return l.Roles[role]
+}
+
+// AgentLimit returns the limit for agent, or zero when it is unlimited.
+// Anonymous claims are never limited per agent.
+func (l WIPLimits) AgentLimit(agent string) int {
+	if agent == "" {
+		return 0
+	}
+	if limit, ok := l.Agents[agent]; ok {
+		return limit
+	}
+	return l.Agent
+}
+
+// IsZero reports whether no limits are configured.
+func (l WIPLimits) IsZero() bool {
+	if l.Agent > 0 {
+		return false
+	}
+	for _, limit := range l.Roles {
+		if limit > 0 {
+			return false
+		}
+	}
+	for _, limit := range l.Agents {
+		if limit > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// WIPUsage is the number of in-progress tasks for one role or agent.
+type WIPUsage struct {
+	Scope      string `json:"scope"`
+	Name       string `json:"name"`
+	InProgress int    `json:"in_progress"`
+	Limit      int    `json:"limit"`
+}
+
+// Full reports whether another claim would exceed the limit.
+func (u WIPUsage) Full() bool {
+	return u.Limit > 0 && u.InProgress >= u.Limit
+}
+
+// Exceeded reports whether the limit is already exceeded.
+func (u WIPUsage) Exceeded() bool {
+	return u.Limit > 0 && u.InProgress > u.Limit
+}
+
+// String formats the usage as "role developer: 2/3", using "-" for no limit.
+func (u WIPUsage) String() string {
+	limit := "-"
+	if u.Limit > 0 {
+		limit = fmt.Sprintf("%d", u.Limit)
+	}
+	return fmt.Sprintf("%s %s: %d/%s", u.Scope, u.Name, u.InProgress, limit)
+}
+
+// WIPLimitError is returned when a claim would exceed a limit.
+type WIPLimitError struct {
+	Usage WIPUsage
+}
+
+func (e *WIPLimitError) Error() string {
+	return fmt.Sprintf("WIP limit reached for %s %s (%d/%d in progress)", e.Usage.Scope, e.Usage.Name, e.Usage.InProgress, e.Usage.Limit)
+}
+
+// ComputeWIPUsage reports usage for every limited role and agent and for every
+// role or agent with in-progress work, sorted roles first, then by name.
+func ComputeWIPUsage(tasks map[string]*Task, limits WIPLimits) []WIPUsage {
+	roles, agents := countInProgress(tasks, "")
+	for role, limit := range limits.Roles {
+		if limit > 0 {
+			if _, ok := roles[role]; !ok {
+				roles[role] = 0
+			}
+		}
+	}
+	for agent, limit := range limits.Agents {
+		if limit > 0 {
+			if _, ok := agents[agent]; !ok {
+				agents[agent] = 0
+			}
+		}
+	}
+
+	usage := make([]WIPUsage, 0, len(roles)+len(agents))
+	for role, count := range roles {
+		if role == "" {
+			continue
+		}
+		usage = append(usage, WIPUsage{Scope: WIPScopeRole, Name: role, InProgress: count, Limit: limits.RoleLimit(role)})
+	}
+	for agent, count := range agents {
+		usage = append(usage, WIPUsage{Scope: WIPScopeAgent, Name: agent, InProgress: count, Limit: limits.AgentLimit(agent)})
+	}
+	sort.Slice(usage, func(i, j int) bool {
+		if usage[i].Scope != usage[j].Scope {
+			return usage[i].Scope == WIPScopeRole
+		}
+		return usage[i].Name < usage[j].Name
+	})
+	return usage
+}
+
+// WIPViolations returns the usage entries that exceed their limit.
+func WIPViolations(tasks map[string]*Task, limits WIPLimits) []WIPUsage {
+	var violations []WIPUsage
+	for _, u := range ComputeWIPUsage(tasks, limits) {
+		if u.Exceeded() {
+			violations = append(violations, u)
+		}
+	}
+	return violations
+}
+
+// CheckWIPClaim returns a *WIPLimitError if agent claiming t would exceed the
+// limit for t's role or for agent. The role limit is checked first. t itself
+// is not counted, so re-claiming an in-progress task never fails.
+func CheckWIPClaim(tasks map[string]*Task, limits WIPLimits, t *Task, agent string) error {
+	roles, agents := countInProgress(tasks, t.ID)
+	return checkWIPClaim(roles, agents, limits, t, agent)
+}
+
+// CheckWorkspaceWIPClaim is CheckWIPClaim for a claim made across projects.
+// The role limit counts tasks, those of t's own project keyed by ID, while
+// the agent limit counts agent's tasks in all, every project's tasks keyed by
+// Task.Key.
+func CheckWorkspaceWIPClaim(tasks, all map[string]*Task, limits WIPLimits, t *Task, agent string) error {
+	roles, _ := countInProgress(tasks, t.ID)
+	_, agents := countInProgress(all, t.Key())
+	return checkWIPClaim(roles, agents, limits, t, agent)
+}
+
+func checkWIPClaim(roles, agents map[string]int, limits WIPLimits, t *Task, agent string) error {
+	role := t.GetEffectiveRole()
+	if u := (WIPUsage{Scope: WIPScopeRole, Name: role, InProgress: roles[role], Limit: limits.RoleLimit(role)}); u.Full() {
+		return &WIPLimitError{Usage: u}
+	}
+	if u := (WIPUsage{Scope: WIPScopeAgent, Name: agent, InProgress: agents[agent], Limit: limits.AgentLimit(agent)}); u.Full() {
+		return &WIPLimitError{Usage: u}
+	}
+	return nil
+}
+
+// countInProgress counts in-progress tasks per effective role and per claiming
+// agent, skipping excludeID.
+func countInProgress(tasks map[string]*Task, excludeID string) (map[string]int, map[string]int) {
+	roles := make(map[string]int)
+	agents := make(map[string]int)
+	for id, t := range tasks {
+		if id == excludeID || t.Meta.Completed || !t.Meta.IsInProgress() {
+			continue
+		}
+		roles[t.GetEffectiveRole()]++
+		if t.Meta.ClaimedBy != "" {
+			agents[t.Meta.ClaimedBy]++
+		}
+	}
+	return roles, agents
+}
@@ -0,0 +1,118 @@
+package task
+
+import (
+	"errors"
+	"testing"
+)
+
+func wipTestTasks() map[string]*Task {
+	newTask := func(id, role, status, agent string) *Task {
+		return &Task{ID: id, Meta: Metadata{Role: role, Status: status, ClaimedBy: agent}}
+	}
+	return map[string]*Task{
+		"T1aaa-dev1":   newTask("T1aaa-dev1", "developer", StatusInProgress, "alice"),
+		"T2aaa-dev2":   newTask("T2aaa-dev2", "developer", StatusInProgress, "bob"),
+		"T3aaa-dev3":   newTask("T3aaa-dev3", "developer", StatusOpen, ""),
+		"T4aaa-review": newTask("T4aaa-review", "reviewer", StatusOpen, ""),
+		"T5aaa-done":   newTask("T5aaa-done", "reviewer", StatusDone, "alice"),
+	}
+}
+
+func TestComputeWIPUsage(t *testing.T) {
+	limits := WIPLimits{
+		Roles:  map[string]int{"developer": 2, "reviewer": 1},
+		Agents: map[string]int{"carol": 1},
+	}
+	usage := ComputeWIPUsage(wipTestTasks(), limits)
+
+	want := []WIPUsage{
+		{Scope: WIPScopeRole, Name: "developer", InProgress: 2, Limit: 2},
+		{Scope: WIPScopeRole, Name: "reviewer", InProgress: 0, Limit: 1},
+		{Scope: WIPScopeAgent, Name: "alice", InProgress: 1, Limit: 0},
+		{Scope: WIPScopeAgent, Name: "bob", InProgress: 1, Limit: 0},
+		{Scope: WIPScopeAgent, Name: "carol", InProgress: 0, Limit: 1},
+	}
+	if len(usage) != len(want) {
+		t.Fatalf("unexpected usage: %+v", usage)
+	}
+	for i := range want {
+		if usage[i] != want[i] {
+			t.Errorf("usage[%d] = %+v, want %+v", i, usage[i], want[i])
+		}
+	}
+	if !usage[0].Full() || usage[0].Exceeded() {
+		t.Errorf("expected developer to be full but not exceeded: %+v", usage[0])
+	}
+	if got := usage[0].String(); got != "role developer: 2/2" {
+		t.Errorf("String() = %q", got)
+	}
+	if got := usage[2].String(); got != "agent alice: 1/-" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestCheckWIPClaim(t *testing.T) {
+	tasks := wipTestTasks()
+	limits := WIPLimits{Roles: map[string]int{"developer": 2}, Agent: 1}
+
+	var limitErr *WIPLimitError
+	err := CheckWIPClaim(tasks, limits, tasks["T3aaa-dev3"], "")
+	if !errors.As(err, &limitErr) || limitErr.Usage.Scope != WIPScopeRole {
+		t.Fatalf("expected role limit error, got %v", err)
+	}
+
+	err = CheckWIPClaim(tasks, limits, tasks["T4aaa-review"], "alice")
+	if !errors.As(err, &limitErr) || limitErr.Usage.Scope != WIPScopeAgent || limitErr.Usage.Name != "alice" {
+		t.Fatalf("expected agent limit error, got %v", err)
+	}
+
+	if err := CheckWIPClaim(tasks, limits, tasks["T4aaa-review"], "carol"); err != nil {
+		t.Fatalf("expected claim within limits, got %v", err)
+	}
+	if err := CheckWIPClaim(tasks, limits, tasks["T1aaa-dev1"], "alice"); err != nil {
+		t.Fatalf("expected re-claim of own task to succeed, got %v", err)
+	}
+}
+
+func TestCheckWorkspaceWIPClaim(t *testing.T) {
+	frontend := wipTestTasks()
+	backend := map[string]*Task{
+		"T6aaa-api": {ID: "T6aaa-api", Project: "backend", Meta: Metadata{Role: "developer", Status: StatusOpen}},
+	}
+	all := map[string]*Task{}
+	for _, tasks := range []map[string]*Task{frontend, backend} {
+		for _, tk := range tasks {
+			if tk.Project == "" {
+				tk.Project = "frontend"
+			}
+			all[tk.Key()] = tk
+		}
+	}
+	limits := WIPLimits{Roles: map[string]int{"developer": 2}, Agent: 1}
+
+	if err := CheckWorkspaceWIPClaim(backend, all, limits, backend["T6aaa-api"], "carol"); err != nil {
+		t.Fatalf("expected role limit to count only the task's project, got %v", err)
+	}
+	var limitErr *WIPLimitError
+	err := CheckWorkspaceWIPClaim(backend, all, limits, backend["T6aaa-api"], "alice")
+	if !errors.As(err, &limitErr) || limitErr.Usage.Scope != WIPScopeAgent || limitErr.Usage.Name != "alice" {
+		t.Fatalf("expected agent limit to count other projects, got %v", err)
+	}
+	if err := CheckWorkspaceWIPClaim(frontend, all, limits, frontend["T1aaa-dev1"], "alice"); err != nil {
+		t.Fatalf("expected re-claim of own task to succeed, got %v", err)
+	}
+}
+
+func TestWIPViolations(t *testing.T) {
+	tasks := wipTestTasks()
+	if v := WIPViolations(tasks, WIPLimits{Roles: map[string]int{"developer": 2}}); len(v) != 0 {
+		t.Fatalf("expected no violations, got %+v", v)
+	}
+	v := WIPViolations(tasks, WIPLimits{Roles: map[string]int{"developer": 1}})
+	if len(v) != 1 || v[0].Name != "developer" || !v[0].Exceeded() {
+		t.Fatalf("expected developer violation, got %+v", v)
+	}
+	if !(WIPLimits{}).IsZero() || (WIPLimits{Agent: 1}).IsZero() {
+		t.Fatal("unexpected IsZero result")
+	}
+}
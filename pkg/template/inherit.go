@@ -0,0 +1,279 @@
+package template
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+	"text/template/parse"
+)
+
+// PartialsDir is the subdirectory of the templates directory holding
+// partials. Each partials/<name>.md is included with {{ template "<name>" . }}.
+const PartialsDir = "partials"
+
+// superPrefix names the base template's version of a block from inside a
+// template that extends it: {{ template "super.todos" . }}.
+const superPrefix = "super."
+
+// loader resolves extends chains over a directory of templates.
+type loader struct {
+	templates map[string]*Template
+	// root holds the partials every template set starts from.
+	root     *texttemplate.Template
+	sources  map[string]source
+	partials map[string]bool
+}
+
+func newLoader(templatesDir string, templates map[string]*Template) (*loader, error) {
+	l := &loader{
+		templates: templates,
+		root:      texttemplate.New("").Option("missingkey=error").Funcs(FuncMap(Helpers{})),
+		sources:   make(map[string]source),
+		partials:  make(map[string]bool),
+	}
+
+	dir := filepath.Join(templatesDir, PartialsDir)
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read partials directory: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".md") {
+			continue
+		}
+		name := strings.TrimSuffix(entry.Name(), ".md")
+		path := filepath.Join(dir, entry.Name())
+		if _, clash := templates[name]; clash {
+			return nil, fmt.Errorf("%s: partial %q has the same name as a template", path, name)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read partial %s: %w", path, err)
+		}
+		// Partials keep their trailing newline so consecutive includes stay
+		// on separate lines.
+		l.sources[name] = source{path: path, line: 1}
+		if _, err := l.root.New(name).Parse(string(data)); err != nil {
+			return nil, lineError(l.sources, source{path: path, line: 1}, err)
+		}
+		l.partials[name] = true
+	}
+	return l, nil
+}
+
+// resolve compiles t, first resolving the template it extends. chain holds
+// the templates whose resolution led here, to detect cycles.
+func (l *loader) resolve(t *Template, chain []string) error {
+	if t.body != nil {
+		return nil
+	}
+	for _, id := range chain {
+		if id == t.ID {
+			return fmt.Errorf("%s: extends cycle: %s", t.Path, strings.Join(append(chain, t.ID), " -> "))
+		}
+	}
+
+	sources := make(map[string]source, len(l.sources)+1)
+	for name, src := range l.sources {
+		sources[name] = src
+	}
+
+	var base *Template
+	var set *texttemplate.Template
+	var err error
+	if name := strings.TrimSpace(t.Meta.Extends); name != "" {
+		base = l.templates[name]
+		if base == nil {
+			return fmt.Errorf("%s: extends unknown template %q", t.Path, name)
+		}
+		if err := l.resolve(base, append(chain, t.ID)); err != nil {
+			return err
+		}
+		inheritMeta(&t.Meta, base.Meta)
+		for name, src := range base.sources {
+			sources[name] = src
+		}
+		if set, err = base.body.Clone(); err != nil {
+			return err
+		}
+		// Keep the base's blocks reachable as <base>/<block> once t redefines them.
+		for _, def := range set.Templates() {
+			if def.Tree == nil || l.partials[def.Name()] || strings.Contains(def.Name(), "/") {
+				continue
+			}
+			if _, err := set.AddParseTree(base.ID+"/"+def.Name(), def.Tree); err != nil {
+				return err
+			}
+		}
+	} else if set, err = l.root.Clone(); err != nil {
+		return err
+	}
+
+	sources[t.ID] = source{path: t.Path, line: t.bodyLine}
+	t.sources = sources
+	if err := t.validateVars(); err != nil {
+		return err
+	}
+	if _, err := set.New(t.ID).Parse(t.BodyContent); err != nil {
+		return t.lineError(err)
+	}
+	if err := t.bindSuper(set, base); err != nil {
+		return err
+	}
+	// A template that only redefines blocks renders through its base's body.
+	if base != nil {
+		if own := set.Lookup(t.ID); own == nil || own.Tree == nil || parse.IsEmptyTree(own.Tree.Root) {
+			if _, err := set.AddParseTree(t.ID, set.Lookup(base.ID).Tree); err != nil {
+				return err
+			}
+		}
+	}
+	t.body = set.Lookup(t.ID)
+	return nil
+}
+
+// bindSuper points the super.<block> references in t's own source at the
+// base's version of each block.
+func (t *Template) bindSuper(set *texttemplate.Template, base *Template) error {
+	var bindErr error
+	for _, def := range set.Templates() {
+		if def.Tree == nil || def.Tree.ParseName != t.ID {
+			continue
+		}
+		tree := def.Tree
+		walkNodes(tree.Root, func(n parse.Node) {
+			node, ok := n.(*parse.TemplateNode)
+			if !ok || !strings.HasPrefix(node.Name, superPrefix) || bindErr != nil {
+				return
+			}
+			location, _ := tree.ErrorContext(node)
+			block := strings.TrimPrefix(node.Name, superPrefix)
+			if base == nil {
+				bindErr = t.lineError(fmt.Errorf("template: %s: %q used but %s does not extend a template", location, node.Name, t.ID))
+				return
+			}
+			target := base.ID + "/" + block
+			if set.Lookup(target) == nil {
+				bindErr = t.lineError(fmt.Errorf("template: %s: base template %s has no block %q", location, base.ID, block))
+				return
+			}
+			node.Name = target
+		})
+	}
+	return bindErr
+}
+
+// inheritMeta fills the fields child leaves unset from its base. Description
+// and id_prefix describe the template itself and are not inherited. Vars are
+// merged by name, the child's declaration winning.
+func inheritMeta(child *TemplateMetadata, base TemplateMetadata) {
+	if child.Type == "" {
+		child.Type = base.Type
+	}
+	if child.Role == "" {
+		child.Role = base.Role
+	}
+	if child.Priority == nil {
+		child.Priority = base.Priority
+	}
+	if child.Parent == "" {
+		child.Parent = base.Parent
+	}
+	if child.Blockers == nil {
+		child.Blockers = base.Blockers
+	}
+	if child.Blocks == nil {
+		child.Blocks = base.Blocks
+	}
+	if child.DateCreated == nil {
+		child.DateCreated = base.DateCreated
+	}
+	if child.DateEdited == nil {
+		child.DateEdited = base.DateEdited
+	}
+
+	vars := make([]Var, 0, len(base.Vars)+len(child.Vars))
+	index := make(map[string]int, len(base.Vars))
+	for _, v := range base.Vars {
+		index[v.Name] = len(vars)
+		vars = append(vars, v)
+	}
+	for _, v := range child.Vars {
+		if i, ok := index[v.Name]; ok {
+			vars[i] = v
+			continue
+		}
+		index[v.Name] = len(vars)
+		vars = append(vars, v)
+	}
+	if len(vars) > 0 {
+		child.Vars = vars
+	}
+}
+
+// walkNodes calls fn for node and every node beneath it.
+func walkNodes(node parse.Node, fn func(parse.Node)) {
+	switch n := node.(type) {
+	case nil:
+		return
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		fn(n)
+		for _, child := range n.Nodes {
+			walkNodes(child, fn)
+		}
+		return
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		fn(n)
+		for _, cmd := range n.Cmds {
+			walkNodes(cmd, fn)
+		}
+		return
+	}
+
+	fn(node)
+	switch n := node.(type) {
+	case *parse.ActionNode:
+		walkNodes(n.Pipe, fn)
+	case *parse.IfNode:
+		walkNodes(n.Pipe, fn)
+		walkNodes(n.List, fn)
+		walkNodes(n.ElseList, fn)
+	case *parse.RangeNode:
+		walkNodes(n.Pipe, fn)
+		walkNodes(n.List, fn)
+		walkNodes(n.ElseList, fn)
+	case *parse.WithNode:
+		walkNodes(n.Pipe, fn)
+		walkNodes(n.List, fn)
+		walkNodes(n.ElseList, fn)
+	case *parse.TemplateNode:
+		walkNodes(n.Pipe, fn)
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			walkNodes(arg, fn)
+		}
+	case *parse.ChainNode:
+		walkNodes(n.Node, fn)
+	}
+}
+
+func sortedIDs(templates map[string]*Template) []string {
+	ids := make([]string, 0, len(templates))
+	for id := range templates {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,163 @@
+package template
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const baseTemplate = `---
+type: task
+role: developer
+priority: high
+id_prefix: T
+vars:
+  - name: component
+    default: core
+---
+
+# {{ .Title }}
+
+## TODOs
+{{ block "todos" . }}1. [ ] (role: developer) Implement {{ .Vars.component }}
+{{ template "tail" . }}{{ end }}
+`
+
+func writeBaseWithTail(t *testing.T, dir string) {
+	t.Helper()
+	writeTemplate(t, dir, "task", baseTemplate)
+	if err := os.MkdirAll(filepath.Join(dir, PartialsDir), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeTemplate(t, filepath.Join(dir, PartialsDir), "tail", "2. [ ] (role: reviewer) Review\n3. [ ] (role: documentation) Document\n")
+}
+
+func TestExtendsInheritsMetaAndBody(t *testing.T) {
+	dir := t.TempDir()
+	writeBaseWithTail(t, dir)
+	writeTemplate(t, dir, "bug", "---\nextends: task\nid_prefix: B\nvars:\n  - name: severity\n---\n")
+
+	templates, err := LoadTemplates(dir)
+	if err != nil {
+		t.Fatalf("LoadTemplates: %v", err)
+	}
+	if _, ok := templates["tail"]; ok {
+		t.Error("partial should not be loaded as a template")
+	}
+	bug := templates["bug"]
+	if bug.Meta.Role != "developer" || bug.Meta.Priority != "high" || bug.Meta.Type != "task" {
+		t.Errorf("expected inherited role, priority and type, got %+v", bug.Meta)
+	}
+	if bug.Meta.IDPrefix != "B" {
+		t.Errorf("expected own id_prefix B, got %q", bug.Meta.IDPrefix)
+	}
+	if got := bug.varNames(); got != "component, severity" {
+		t.Errorf("expected merged vars, got %v", got)
+	}
+
+	out, err := bug.Preview()
+	if err != nil {
+		t.Fatalf("Preview: %v", err)
+	}
+	for _, want := range []string{"# <title>", "Implement core", "(role: reviewer) Review", "(role: documentation) Document"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+}
+
+func TestExtendsOverrideAndSuper(t *testing.T) {
+	dir := t.TempDir()
+	writeBaseWithTail(t, dir)
+	writeTemplate(t, dir, "spike", `---
+extends: task
+---
+{{ define "todos" }}1. [ ] (role: architect) Investigate
+{{ end }}
+`)
+	writeTemplate(t, dir, "feature", `---
+extends: task
+---
+{{ define "todos" }}{{ template "super.todos" . }}4. [ ] (role: owner) Announce
+{{ end }}
+`)
+
+	templates, err := LoadTemplates(dir)
+	if err != nil {
+		t.Fatalf("LoadTemplates: %v", err)
+	}
+
+	spike, err := templates["spike"].Preview()
+	if err != nil {
+		t.Fatalf("Preview spike: %v", err)
+	}
+	if !strings.Contains(spike, "(role: architect) Investigate") || strings.Contains(spike, "Review") {
+		t.Errorf("expected todos block to be replaced:\n%s", spike)
+	}
+
+	feature, err := templates["feature"].Preview()
+	if err != nil {
+		t.Fatalf("Preview feature: %v", err)
+	}
+	implement := strings.Index(feature, "Implement core")
+	announce := strings.Index(feature, "(role: owner) Announce")
+	if implement < 0 || announce < implement || !strings.Contains(feature, "Review") {
+		t.Errorf("expected base todos followed by appended step:\n%s", feature)
+	}
+
+	base, err := templates["task"].Preview()
+	if err != nil {
+		t.Fatalf("Preview task: %v", err)
+	}
+	if strings.Contains(base, "Announce") || strings.Contains(base, "Investigate") {
+		t.Errorf("child overrides leaked into base:\n%s", base)
+	}
+}
+
+func TestExtendsErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{
+			name:  "unknown base",
+			files: map[string]string{"bug": "---\nextends: nope\n---\n"},
+			want:  `extends unknown template "nope"`,
+		},
+		{
+			name: "cycle",
+			files: map[string]string{
+				"a": "---\nextends: b\n---\n",
+				"b": "---\nextends: a\n---\n",
+			},
+			want: "extends cycle: a -> b -> a",
+		},
+		{
+			name:  "super without base",
+			files: map[string]string{"bug": "---\nrole: developer\n---\n\n{{ template \"super.todos\" . }}\n"},
+			want:  "bug.md:5:",
+		},
+		{
+			name: "unknown super block",
+			files: map[string]string{
+				"task": "---\nrole: developer\n---\n\n# {{ .Title }}\n",
+				"bug":  "---\nextends: task\n---\n{{ define \"todos\" }}\n{{ template \"super.todos\" . }}{{ end }}\n",
+			},
+			want: `base template task has no block "todos"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				writeTemplate(t, dir, name, content)
+			}
+			_, err := LoadTemplates(dir)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
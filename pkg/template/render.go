@@ -0,0 +1,191 @@
+package template
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+	"text/template/parse"
+	"time"
+
+	"github.com/ricochet1k/strandyard/pkg/idgen"
+)
+
+// RenderData is the data available to a template body.
+type RenderData struct {
+	ID                  string
+	Title               string
+	Body                string
+	SuggestedSubtaskDir string
+	Role                string
+	Priority            string
+	Parent              string
+	Vars                map[string]string
+	Now                 time.Time
+}
+
+// Helpers supplies the project lookups behind the parentTitle and roleDoc
+// template functions. Nil helpers render as empty strings.
+type Helpers struct {
+	ParentTitle func() string
+	RoleDoc     func(name string) (string, error)
+}
+
+// execErrPattern matches the "template: <name>:<line>[:<col>]: " prefix
+// text/template puts on parse and exec errors.
+var execErrPattern = regexp.MustCompile(`^template: ([^:]*):(\d+)(?::\d+)?: (.*)$`)
+
+// FuncMap returns the functions available to template bodies:
+//
+//	now                   current time
+//	date LAYOUT TIME      format TIME with a Go layout, e.g. {{ date "2006-01-02" now }}
+//	slugify S             the slug strand would use for an ID
+//	default DEF VAL       VAL, or DEF when VAL is empty
+//	lower / upper / trim  string helpers
+//	parentTitle           title of the parent task, or ""
+//	roleDoc NAME          body of the role document NAME
+func FuncMap(h Helpers) texttemplate.FuncMap {
+	return texttemplate.FuncMap{
+		"now": func() time.Time { return time.Now().UTC() },
+		"date": func(layout string, t time.Time) string {
+			return t.Format(layout)
+		},
+		"slugify": idgen.Slugify,
+		"default": func(def, val string) string {
+			if strings.TrimSpace(val) == "" {
+				return def
+			}
+			return val
+		},
+		"lower": strings.ToLower,
+		"upper": strings.ToUpper,
+		"trim":  strings.TrimSpace,
+		"parentTitle": func() string {
+			if h.ParentTitle == nil {
+				return ""
+			}
+			return h.ParentTitle()
+		},
+		"roleDoc": func(name string) (string, error) {
+			if h.RoleDoc == nil {
+				return "", nil
+			}
+			return h.RoleDoc(name)
+		},
+	}
+}
+
+// parse checks the declared vars and compiles a template that was not
+// loaded by LoadTemplates, so has no partials or base to resolve.
+func (t *Template) parse() error {
+	if err := t.validateVars(); err != nil {
+		return err
+	}
+
+	body, err := texttemplate.New(t.ID).
+		Option("missingkey=error").
+		Funcs(FuncMap(Helpers{})).
+		Parse(t.BodyContent)
+	if err != nil {
+		return t.lineError(err)
+	}
+	t.body = body
+	return nil
+}
+
+// lineError rewrites a text/template error to point at the template file.
+func (t *Template) lineError(err error) error {
+	return lineError(t.sources, source{path: t.Path, line: t.bodyLine}, err)
+}
+
+// lineError rewrites a text/template error to point at the file the failing
+// template came from, falling back to fallback for unknown names.
+func lineError(sources map[string]source, fallback source, err error) error {
+	m := execErrPattern.FindStringSubmatch(err.Error())
+	if m == nil {
+		return fmt.Errorf("%s: %w", fallback.path, err)
+	}
+	src, ok := sources[m[1]]
+	if !ok {
+		src = fallback
+	}
+	line, _ := strconv.Atoi(m[2])
+	return fmt.Errorf("%s:%d: %s", src.path, src.line+line-1, m[3])
+}
+
+// Render executes the template body with data.
+func (t *Template) Render(data RenderData, h Helpers) (string, error) {
+	if t.body == nil {
+		if err := t.parse(); err != nil {
+			return "", err
+		}
+	}
+	body, err := t.body.Clone()
+	if err != nil {
+		return "", err
+	}
+	if data.Vars == nil {
+		data.Vars = map[string]string{}
+	}
+	if data.Now.IsZero() {
+		data.Now = time.Now().UTC()
+	}
+	var sb strings.Builder
+	if err := body.Funcs(FuncMap(h)).Execute(&sb, data); err != nil {
+		return "", t.lineError(err)
+	}
+	return sb.String(), nil
+}
+
+// Preview renders the body with placeholder values, showing the effective
+// content of a template (after extends and partials) without creating a task.
+func (t *Template) Preview() (string, error) {
+	vars := make(map[string]string, len(t.Meta.Vars))
+	for _, v := range t.Meta.Vars {
+		vars[v.Name] = v.Default
+	}
+	priority, _ := t.Meta.Priority.(string)
+	return t.Render(RenderData{
+		ID:                  "<id>",
+		Title:               "<title>",
+		SuggestedSubtaskDir: "<id>-subtask",
+		Role:                t.Meta.Role,
+		Priority:            priority,
+		Vars:                vars,
+	}, Helpers{})
+}
+
+// UsesField reports whether the body, including the blocks and partials it
+// invokes, references the top-level field name, e.g. UsesField("Body") for
+// {{ .Body }}.
+func (t *Template) UsesField(name string) bool {
+	if t.body == nil {
+		return strings.Contains(t.BodyContent, "."+name)
+	}
+	found := false
+	visited := make(map[string]bool)
+	var visit func(tmplName string)
+	visit = func(tmplName string) {
+		if found || visited[tmplName] {
+			return
+		}
+		visited[tmplName] = true
+		def := t.body.Lookup(tmplName)
+		if def == nil || def.Tree == nil {
+			return
+		}
+		walkNodes(def.Tree.Root, func(n parse.Node) {
+			switch n := n.(type) {
+			case *parse.FieldNode:
+				if len(n.Ident) > 0 && n.Ident[0] == name {
+					found = true
+				}
+			case *parse.TemplateNode:
+				visit(n.Name)
+			}
+		})
+	}
+	visit(t.ID)
+	return found
+}
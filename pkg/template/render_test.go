@@ -0,0 +1,116 @@
+package template
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeTemplate(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name+".md"), []byte(content), 0o644); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+}
+
+func TestLoadTemplatesReportsLineNumbers(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "broken", "---\nrole: developer\n---\n\n# {{ .Title }}\n\n{{ if .Parent }}\nno end\n")
+
+	_, err := LoadTemplates(dir)
+	if err == nil {
+		t.Fatal("expected parse error")
+	}
+	want := filepath.Join(dir, "broken.md") + ":8:"
+	if !strings.Contains(err.Error(), want) {
+		t.Fatalf("expected error to point at %s, got %v", want, err)
+	}
+
+	writeTemplate(t, dir, "broken", "---\nrole: developer\n---\n\n# {{ .Title }}\n{{ nosuchfunc }}\n")
+	_, err = LoadTemplates(dir)
+	if err == nil || !strings.Contains(err.Error(), "broken.md:6:") || !strings.Contains(err.Error(), "nosuchfunc") {
+		t.Fatalf("expected undefined function error on line 6, got %v", err)
+	}
+}
+
+func TestRenderWithVarsAndHelpers(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "fix", `---
+role: developer
+vars:
+  - name: component
+    default: core
+  - name: severity
+---
+
+# {{ .Title }}
+
+Component: {{ .Vars.component }}
+Severity: {{ .Vars.severity | default "unknown" }}
+{{ if .Parent }}Part of: {{ parentTitle }}{{ end }}
+Slug: {{ slugify .Title }}
+Date: {{ date "2006-01-02" .Now }}
+Role: {{ roleDoc .Role }}
+`)
+	templates, err := LoadTemplates(dir)
+	if err != nil {
+		t.Fatalf("LoadTemplates: %v", err)
+	}
+	tmpl := templates["fix"]
+	if tmpl.UsesField("Body") {
+		t.Error("expected template not to use .Body")
+	}
+	if !tmpl.UsesField("Vars") {
+		t.Error("expected template to use .Vars")
+	}
+
+	if _, err := tmpl.ResolveVars(map[string]string{"colour": "red"}, nil); err == nil || !strings.Contains(err.Error(), "colour") {
+		t.Fatalf("expected undeclared var error, got %v", err)
+	}
+	vars, err := tmpl.ResolveVars(map[string]string{"severity": "high"}, nil)
+	if err != nil {
+		t.Fatalf("ResolveVars: %v", err)
+	}
+
+	out, err := tmpl.Render(RenderData{
+		Title:  "Crash On Save",
+		Role:   "developer",
+		Parent: "E1a1a-epic",
+		Vars:   vars,
+		Now:    time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC),
+	}, Helpers{
+		ParentTitle: func() string { return "Storage epic" },
+		RoleDoc:     func(name string) (string, error) { return "writes " + name + " code", nil },
+	})
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	for _, want := range []string{
+		"# Crash On Save",
+		"Component: core",
+		"Severity: high",
+		"Part of: Storage epic",
+		"Slug: crash-on-save",
+		"Date: 2026-01-02",
+		"Role: writes developer code",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+}
+
+func TestRenderRejectsUndeclaredVar(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "task", "---\nrole: developer\n---\n\n# {{ .Title }}\n\n{{ .Vars.missing }}\n")
+	templates, err := LoadTemplates(dir)
+	if err != nil {
+		t.Fatalf("LoadTemplates: %v", err)
+	}
+	_, err = templates["task"].Render(RenderData{Title: "x"}, Helpers{})
+	if err == nil || !strings.Contains(err.Error(), "task.md:7:") {
+		t.Fatalf("expected missing key error on line 7, got %v", err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	texttemplate "text/template"
 
 	"gopkg.in/yaml.v3"
 )
@@ -12,6 +13,9 @@ import (
 // TemplateMetadata represents the YAML frontmatter of a template,
 // which may contain Go template expressions.
 type TemplateMetadata struct {
+	// Extends names a base template whose frontmatter defaults and body
+	// blocks this template inherits.
+	Extends       string      `yaml:"extends"`
 	Type          string      `yaml:"type"`
 	Role          string      `yaml:"role"`
 	Priority      interface{} `yaml:"priority"`
@@ -24,16 +28,31 @@ type TemplateMetadata struct {
 	Completed     bool        `yaml:"completed"`
 	Description   string      `yaml:"description"`
 	IDPrefix      string      `yaml:"id_prefix"`
+	Vars          []Var       `yaml:"vars"`
 }
 
 // Template represents a parsed task template.
 type Template struct {
 	ID          string
+	Path        string
 	Meta        TemplateMetadata
 	BodyContent string
+
+	// bodyLine is the line of Path on which BodyContent starts.
+	bodyLine int
+	body     *texttemplate.Template
+	// sources maps each template name in body's set to the file it came
+	// from, for error messages.
+	sources map[string]source
+}
+
+type source struct {
+	path string
+	line int
 }
 
-// LoadTemplates loads all templates from the given directory.
+// LoadTemplates loads all templates from the given directory, resolving
+// extends chains and the partials in its partials/ subdirectory.
 func LoadTemplates(templatesDir string) (map[string]*Template, error) {
 	entries, err := os.ReadDir(templatesDir)
 	if err != nil {
@@ -67,10 +86,28 @@ func LoadTemplates(templatesDir string) (map[string]*Template, error) {
 		id := strings.TrimSuffix(entry.Name(), ".md")
 		templates[id] = &Template{
 			ID:          id,
+			Path:        templatePath,
 			Meta:        meta,
 			BodyContent: strings.TrimSpace(parts[2]),
+			bodyLine:    bodyStartLine(content, parts[2]),
+		}
+	}
+
+	l, err := newLoader(templatesDir, templates)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range sortedIDs(templates) {
+		if err := l.resolve(templates[id], nil); err != nil {
+			return nil, err
 		}
 	}
 
 	return templates, nil
 }
+
+// bodyStartLine returns the line of content on which the trimmed body starts.
+func bodyStartLine(content, body string) int {
+	leading := len(body) - len(strings.TrimLeft(body, " \t\r\n"))
+	return strings.Count(content[:len(content)-len(body)+leading], "\n") + 1
+}
@@ -0,0 +1,209 @@
+package template
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Var types accepted in template frontmatter.
+const (
+	VarString = "string"
+	VarEnum   = "enum"
+	VarBool   = "bool"
+	VarTaskID = "task-id"
+)
+
+var varNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Var declares an input the template body reads as {{ .Vars.<name> }}.
+// Values are supplied with `strand add --var name=value` and stored in the
+// new task's vars metadata.
+type Var struct {
+	Name string `yaml:"name" json:"name"`
+	// Type is one of string (the default), enum, bool or task-id.
+	Type string `yaml:"type" json:"type,omitempty"`
+	// Values lists the allowed values of an enum.
+	Values   []string `yaml:"values" json:"values,omitempty"`
+	Required bool     `yaml:"required" json:"required,omitempty"`
+	Default  string   `yaml:"default" json:"default,omitempty"`
+	Help     string   `yaml:"help" json:"help,omitempty"`
+}
+
+// Kind returns the var's type, defaulting to string.
+func (v Var) Kind() string {
+	if strings.TrimSpace(v.Type) == "" {
+		return VarString
+	}
+	return v.Type
+}
+
+// Placeholder describes the accepted values, e.g. <low|high>.
+func (v Var) Placeholder() string {
+	switch v.Kind() {
+	case VarEnum:
+		return "<" + strings.Join(v.Values, "|") + ">"
+	case VarBool:
+		return "<true|false>"
+	case VarTaskID:
+		return "<task-id>"
+	default:
+		return "<value>"
+	}
+}
+
+// Usage returns the flag form of the var, e.g. --var severity=<low|high>.
+func (v Var) Usage() string {
+	return fmt.Sprintf("--var %s=%s", v.Name, v.Placeholder())
+}
+
+// Validate reports whether value is acceptable for the var. Task IDs are
+// only checked for being non-empty; ResolveVars resolves them.
+func (v Var) Validate(value string) error {
+	if v.Kind() == VarTaskID {
+		if strings.TrimSpace(value) == "" {
+			return fmt.Errorf("var %s: task ID is required", v.Name)
+		}
+		return nil
+	}
+	_, err := v.check(value)
+	return err
+}
+
+// check validates a value that is not a task ID and returns it normalized.
+func (v Var) check(value string) (string, error) {
+	switch v.Kind() {
+	case VarEnum:
+		for _, allowed := range v.Values {
+			if value == allowed {
+				return value, nil
+			}
+		}
+		return "", fmt.Errorf("var %s: invalid value %q (expected one of %s)", v.Name, value, strings.Join(v.Values, ", "))
+	case VarBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", fmt.Errorf("var %s: invalid value %q (expected true or false)", v.Name, value)
+		}
+		return strconv.FormatBool(b), nil
+	}
+	return value, nil
+}
+
+// MissingVarsError is returned when required vars have no value.
+type MissingVarsError struct {
+	Template string
+	Vars     []Var
+}
+
+func (e *MissingVarsError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "template %s requires var(s):", e.Template)
+	for _, v := range e.Vars {
+		fmt.Fprintf(&sb, "\n  %s", v.Usage())
+		if v.Help != "" {
+			fmt.Fprintf(&sb, "  %s", v.Help)
+		}
+	}
+	return sb.String()
+}
+
+func (t *Template) validateVars() error {
+	seen := make(map[string]bool, len(t.Meta.Vars))
+	for _, v := range t.Meta.Vars {
+		if !varNamePattern.MatchString(v.Name) {
+			return fmt.Errorf("%s: invalid var name %q (expected letters, digits and underscores)", t.Path, v.Name)
+		}
+		if seen[v.Name] {
+			return fmt.Errorf("%s: var %q declared more than once", t.Path, v.Name)
+		}
+		seen[v.Name] = true
+		switch v.Kind() {
+		case VarString, VarBool, VarTaskID:
+		case VarEnum:
+			if len(v.Values) == 0 {
+				return fmt.Errorf("%s: enum var %q has no values", t.Path, v.Name)
+			}
+		default:
+			return fmt.Errorf("%s: var %q has invalid type %q (expected string, enum, bool, or task-id)", t.Path, v.Name, v.Type)
+		}
+		if v.Default != "" && v.Kind() != VarTaskID {
+			if _, err := v.check(v.Default); err != nil {
+				return fmt.Errorf("%s: default: %w", t.Path, err)
+			}
+		}
+	}
+	return nil
+}
+
+// ResolveVars checks values against the declared vars and fills in
+// defaults. resolveTaskID, when set, resolves task-id values to full IDs.
+// Every declared var is present in the result, so templates can test
+// optional vars without tripping the missing-key check. Required vars
+// without a value are reported together in a *MissingVarsError.
+func (t *Template) ResolveVars(values map[string]string, resolveTaskID func(string) (string, error)) (map[string]string, error) {
+	declared := make(map[string]bool, len(t.Meta.Vars))
+	for _, v := range t.Meta.Vars {
+		declared[v.Name] = true
+	}
+	var unknown []string
+	for name := range values {
+		if !declared[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("template %s does not declare var(s) %s (declared: %s)", t.ID, strings.Join(unknown, ", "), t.varNames())
+	}
+
+	resolved := make(map[string]string, len(t.Meta.Vars))
+	var missing []Var
+	for _, v := range t.Meta.Vars {
+		value, ok := values[v.Name]
+		value = strings.TrimSpace(value)
+		if !ok || value == "" {
+			value = v.Default
+		}
+		if value == "" {
+			if v.Required {
+				missing = append(missing, v)
+			}
+			resolved[v.Name] = ""
+			continue
+		}
+		if v.Kind() == VarTaskID {
+			if resolveTaskID != nil {
+				id, err := resolveTaskID(value)
+				if err != nil {
+					return nil, fmt.Errorf("var %s: %w", v.Name, err)
+				}
+				value = id
+			}
+		} else {
+			checked, err := v.check(value)
+			if err != nil {
+				return nil, err
+			}
+			value = checked
+		}
+		resolved[v.Name] = value
+	}
+	if len(missing) > 0 {
+		return nil, &MissingVarsError{Template: t.ID, Vars: missing}
+	}
+	return resolved, nil
+}
+
+func (t *Template) varNames() string {
+	if len(t.Meta.Vars) == 0 {
+		return "none"
+	}
+	names := make([]string, 0, len(t.Meta.Vars))
+	for _, v := range t.Meta.Vars {
+		names = append(names, v.Name)
+	}
+	return strings.Join(names, ", ")
+}
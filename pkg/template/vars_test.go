@@ -0,0 +1,92 @@
+package template
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestLoadTemplatesValidatesVarDeclarations(t *testing.T) {
+	cases := map[string]string{
+		"bad type":        "vars:\n  - name: x\n    type: number\n",
+		"enum no values":  "vars:\n  - name: x\n    type: enum\n",
+		"bad default":     "vars:\n  - name: x\n    type: enum\n    values: [a, b]\n    default: c\n",
+		"bad bool":        "vars:\n  - name: x\n    type: bool\n    default: maybe\n",
+		"bad name":        "vars:\n  - name: has-dash\n",
+		"duplicate names": "vars:\n  - name: x\n  - name: x\n",
+	}
+	for name, vars := range cases {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTemplate(t, dir, "task", "---\nrole: developer\n"+vars+"---\n\n# {{ .Title }}\n")
+			if _, err := LoadTemplates(dir); err == nil || !strings.Contains(err.Error(), "task.md") {
+				t.Fatalf("expected error naming the template file, got %v", err)
+			}
+		})
+	}
+}
+
+func TestResolveVarsTypes(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "bug", `---
+role: developer
+vars:
+  - name: severity
+    type: enum
+    values: [low, high]
+    required: true
+    help: How bad it is
+  - name: regression
+    type: bool
+  - name: related
+    type: task-id
+  - name: component
+    required: true
+    default: core
+---
+
+# {{ .Title }}
+`)
+	templates, err := LoadTemplates(dir)
+	if err != nil {
+		t.Fatalf("LoadTemplates: %v", err)
+	}
+	tmpl := templates["bug"]
+	resolveID := func(id string) (string, error) {
+		if id == "T1a1a" {
+			return "T1a1a-related", nil
+		}
+		return "", fmt.Errorf("task %s not found", id)
+	}
+
+	_, err = tmpl.ResolveVars(nil, resolveID)
+	var missing *MissingVarsError
+	if !errors.As(err, &missing) || len(missing.Vars) != 1 || missing.Vars[0].Name != "severity" {
+		t.Fatalf("expected only severity to be missing, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "--var severity=<low|high>  How bad it is") {
+		t.Fatalf("expected usage listing, got %q", err.Error())
+	}
+
+	if _, err := tmpl.ResolveVars(map[string]string{"severity": "medium"}, resolveID); err == nil {
+		t.Fatal("expected invalid enum value to be rejected")
+	}
+	if _, err := tmpl.ResolveVars(map[string]string{"severity": "low", "regression": "sometimes"}, resolveID); err == nil {
+		t.Fatal("expected invalid bool to be rejected")
+	}
+	if _, err := tmpl.ResolveVars(map[string]string{"severity": "low", "related": "T9z9z"}, resolveID); err == nil {
+		t.Fatal("expected unknown task ID to be rejected")
+	}
+
+	vars, err := tmpl.ResolveVars(map[string]string{"severity": "high", "regression": "1", "related": "T1a1a"}, resolveID)
+	if err != nil {
+		t.Fatalf("ResolveVars: %v", err)
+	}
+	want := map[string]string{"severity": "high", "regression": "true", "related": "T1a1a-related", "component": "core"}
+	for name, value := range want {
+		if vars[name] != value {
+			t.Errorf("vars[%s] = %q, want %q", name, vars[name], value)
+		}
+	}
+}
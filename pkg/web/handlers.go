@@ -1,20 +1,22 @@
 package web
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gorilla/websocket"
-	"github.com/ricochet1k/strandyard/pkg/idgen"
+	"github.com/ricochet1k/strandyard/pkg/config"
+	"github.com/ricochet1k/strandyard/pkg/create"
 	rPkg "github.com/ricochet1k/strandyard/pkg/role"
 	"github.com/ricochet1k/strandyard/pkg/task"
 	"github.com/ricochet1k/strandyard/pkg/template"
@@ -41,6 +43,8 @@ type taskListItem struct {
 	Path        string   `json:"path"`
 	DateCreated string   `json:"date_created"`
 	DateEdited  string   `json:"date_edited"`
+	// Fields holds the display values of the task's custom fields.
+	Fields map[string]string `json:"fields,omitempty"`
 }
 
 type filePayload struct {
@@ -61,14 +65,17 @@ type taskUpdateRequest struct {
 }
 
 type taskCreateRequest struct {
-	TemplateName string   `json:"template_name"`
-	Title        string   `json:"title"`
-	Role         string   `json:"role,omitempty"`
-	Priority     string   `json:"priority,omitempty"`
-	Parent       string   `json:"parent,omitempty"`
-	Blockers     []string `json:"blockers,omitempty"`
-	Blocks       []string `json:"blocks,omitempty"`
-	Body         string   `json:"body,omitempty"`
+	TemplateName string            `json:"template_name"`
+	Title        string            `json:"title"`
+	Role         string            `json:"role,omitempty"`
+	Priority     string            `json:"priority,omitempty"`
+	Parent       string            `json:"parent,omitempty"`
+	Blockers     []string          `json:"blockers,omitempty"`
+	Blocks       []string          `json:"blocks,omitempty"`
+	Every        []string          `json:"every,omitempty"`
+	Vars         map[string]string `json:"vars,omitempty"`
+	Fields       map[string]string `json:"fields,omitempty"`
+	Body         string            `json:"body,omitempty"`
 }
 
 type taskDetailResponse struct {
@@ -86,6 +93,8 @@ type taskDetailResponse struct {
 	DateCreated string   `json:"date_created"`
 	DateEdited  string   `json:"date_edited"`
 	Body        string   `json:"body"`
+	// Remote describes the task's cross-project parent and blockers.
+	Remote []task.RemoteTask `json:"remote,omitempty"`
 }
 
 type projectResponse struct {
@@ -203,12 +212,92 @@ func (s *Server) handleTasks(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	items, err := s.listTasks(proj)
+	cfg, err := loadProjectConfig(proj)
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, err)
 		return
 	}
-	respondJSON(w, http.StatusOK, items)
+
+	query, err := parseTaskQuery(r.URL.Query(), cfg.Fields)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	items, err := s.listTasks(proj, query.Options)
+	if err != nil {
+		var filterErr *task.FilterError
+		if errors.As(err, &filterErr) {
+			respondError(w, http.StatusBadRequest, err)
+		} else {
+			respondError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	page, err := paginateTasks(items, query.Cursor, query.Limit)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	w.Header().Set("X-Total-Count", strconv.Itoa(page.Total))
+	if page.NextCursor != "" {
+		w.Header().Set("X-Next-Cursor", page.NextCursor)
+	}
+
+	if query.Options.Group != "" && query.Options.Group != "none" {
+		grouped := groupTaskItems(page.Items, query.Options.Group)
+		if len(query.Fields) == 0 {
+			respondJSON(w, http.StatusOK, grouped)
+			return
+		}
+		selected := make(map[string][]map[string]json.RawMessage, len(grouped))
+		for key, items := range grouped {
+			if selected[key], err = selectTaskFields(items, query.Fields); err != nil {
+				respondError(w, http.StatusInternalServerError, err)
+				return
+			}
+		}
+		respondJSON(w, http.StatusOK, selected)
+		return
+	}
+
+	if len(query.Fields) > 0 {
+		selected, err := selectTaskFields(page.Items, query.Fields)
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, err)
+			return
+		}
+		respondJSON(w, http.StatusOK, selected)
+		return
+	}
+	respondJSON(w, http.StatusOK, page.Items)
+}
+
+func (s *Server) handleGraph(w http.ResponseWriter, r *http.Request) {
+	proj, err := s.getProject(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db := task.NewTaskDB(proj.TasksRoot)
+	if err := db.LoadAll(); err != nil {
+		respondError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	graph, err := task.BuildDependencyGraph(db.GetAll(), task.GraphOptions{
+		Root:   strings.TrimSpace(r.URL.Query().Get("root")),
+		Status: strings.ToLower(strings.TrimSpace(r.URL.Query().Get("status"))),
+		Remote: s.remoteTasks(),
+	})
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, graph)
 }
 
 func (s *Server) handleRoles(w http.ResponseWriter, r *http.Request) {
@@ -528,6 +617,8 @@ func (s *Server) handleTaskGetOrUpdate(w http.ResponseWriter, r *http.Request, p
 		respondError(w, http.StatusInternalServerError, err)
 		return
 	}
+	remote := s.remoteTasks()
+	db.SetRemote(remote)
 
 	t, err := db.Get(taskID)
 	if err != nil {
@@ -537,7 +628,7 @@ func (s *Server) handleTaskGetOrUpdate(w http.ResponseWriter, r *http.Request, p
 
 	switch r.Method {
 	case http.MethodGet:
-		snapshot, err := taskToSnapshot(t, proj.StorageRoot)
+		snapshot, err := taskToSnapshot(t, proj.StorageRoot, remote)
 		if err != nil {
 			respondError(w, http.StatusInternalServerError, err)
 			return
@@ -613,13 +704,13 @@ func (s *Server) handleTaskGetOrUpdate(w http.ResponseWriter, r *http.Request, p
 					respondError(w, http.StatusBadRequest, err)
 					return
 				}
-				if oldParent != "" {
+				if oldParent != "" && !task.IsRemoteRef(oldParent) {
 					if _, err := db.UpdateParentTodos(oldParent); err != nil {
 						respondError(w, http.StatusInternalServerError, err)
 						return
 					}
 				}
-				if newParent != "" {
+				if newParent != "" && !task.IsRemoteRef(newParent) {
 					if _, err := db.UpdateParentTodos(newParent); err != nil {
 						respondError(w, http.StatusInternalServerError, err)
 						return
@@ -633,7 +724,7 @@ func (s *Server) handleTaskGetOrUpdate(w http.ResponseWriter, r *http.Request, p
 			return
 		}
 
-		snapshot, err := taskToSnapshot(t, proj.StorageRoot)
+		snapshot, err := taskToSnapshot(t, proj.StorageRoot, remote)
 		if err != nil {
 			respondError(w, http.StatusInternalServerError, err)
 			return
@@ -660,53 +751,55 @@ func (s *Server) handleTaskCreate(w http.ResponseWriter, r *http.Request, proj *
 		return
 	}
 
-	// Create a bytes buffer to capture output
-	var output strings.Builder
-
-	// Build addOptions from the request
-	opts := struct {
-		ProjectName       string
-		TemplateName      string
-		Title             string
-		Role              string
-		Priority          string
-		Parent            string
-		Blockers          []string
-		Blocks            []string
-		Every             []string
-		RoleSpecified     bool
-		PrioritySpecified bool
-		Body              string
-	}{
-		ProjectName:       proj.Name,
-		TemplateName:      req.TemplateName,
-		Title:             req.Title,
-		Role:              req.Role,
-		Priority:          req.Priority,
-		Parent:            req.Parent,
-		Blockers:          req.Blockers,
-		Blocks:            req.Blocks,
-		Every:             []string{},
-		RoleSpecified:     req.Role != "",
-		PrioritySpecified: req.Priority != "",
-		Body:              req.Body,
-	}
-
-	// We need to use the internal task creation logic
-	// For now, we'll shell out to the add command logic
-	// This is a simplified version - in production you'd refactor the add logic into a shared function
-	if err := s.createTask(&output, opts, proj); err != nil {
-		respondError(w, http.StatusBadRequest, fmt.Errorf("%s: %w", output.String(), err))
+	cfg, err := loadProjectConfig(proj)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	result, err := create.Task(create.Project{
+		BaseDir:      proj.StorageRoot,
+		TasksDir:     proj.TasksRoot,
+		TemplatesDir: proj.TemplatesRoot,
+		RolesDir:     proj.RolesRoot,
+		Remote:       s.remoteTasks(),
+	}, cfg, create.Request{
+		TemplateName: req.TemplateName,
+		Title:        req.Title,
+		Role:         req.Role,
+		Priority:     req.Priority,
+		Parent:       req.Parent,
+		Blockers:     req.Blockers,
+		Blocks:       req.Blocks,
+		Every:        req.Every,
+		Vars:         req.Vars,
+		Fields:       req.Fields,
+		Body:         req.Body,
+	})
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
 		return
 	}
 
 	respondJSON(w, http.StatusCreated, map[string]string{
 		"status":  "created",
-		"message": output.String(),
+		"id":      result.ID,
+		"message": fmt.Sprintf("✓ Task created: %s\n", result.ID),
 	})
 }
 
-func taskToSnapshot(t *task.Task, storageRoot string) (*taskDetailResponse, error) {
+// remoteTasks resolves project:TaskID references among the served projects.
+func (s *Server) remoteTasks() *task.RemoteTasks {
+	return task.NewRemoteTasks(func(name string) (string, error) {
+		proj, ok := s.projects[name]
+		if !ok {
+			return "", fmt.Errorf("project not found: %s", name)
+		}
+		return proj.TasksRoot, nil
+	})
+}
+
+func taskToSnapshot(t *task.Task, storageRoot string, remote *task.RemoteTasks) (*taskDetailResponse, error) {
 	return &taskDetailResponse{
 		ID:          t.ID,
 		ShortID:     task.ShortID(t.ID),
@@ -722,6 +815,7 @@ func taskToSnapshot(t *task.Task, storageRoot string) (*taskDetailResponse, erro
 		DateCreated: t.Meta.DateCreated.Format(time.RFC3339),
 		DateEdited:  t.Meta.DateEdited.Format(time.RFC3339),
 		Body:        t.BodyContent,
+		Remote:      remote.RemoteRefs(t),
 	}, nil
 }
 
@@ -886,9 +980,10 @@ func (s *Server) handleWS(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (s *Server) listTasks(proj *ProjectInfo) ([]taskListItem, error) {
-	parser := task.NewParser()
-	tasks, err := parser.LoadTasks(proj.TasksRoot)
+// listTasks returns the project's tasks filtered and sorted with the same
+// rules as `strand list`.
+func (s *Server) listTasks(proj *ProjectInfo, opts task.ListOptions) ([]taskListItem, error) {
+	tasks, _, err := task.ListTasks(proj.TasksRoot, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -911,18 +1006,27 @@ func (s *Server) listTasks(proj *ProjectInfo) ([]taskListItem, error) {
 			DateCreated: t.Meta.DateCreated.Format(time.RFC3339),
 			DateEdited:  t.Meta.DateEdited.Format(time.RFC3339),
 		})
-	}
-
-	sort.SliceStable(items, func(i, j int) bool {
-		if items[i].Priority != items[j].Priority {
-			return task.PriorityRank(items[i].Priority) < task.PriorityRank(items[j].Priority)
+		if len(t.Meta.Fields) > 0 {
+			fields := make(map[string]string, len(t.Meta.Fields))
+			for name := range t.Meta.Fields {
+				fields[name] = t.Field(name)
+			}
+			items[len(items)-1].Fields = fields
 		}
-		return items[i].ID < items[j].ID
-	})
+	}
 
 	return items, nil
 }
 
+// loadProjectConfig loads the strand.yaml layers that apply to proj.
+func loadProjectConfig(proj *ProjectInfo) (config.Config, error) {
+	userDir, err := config.UserDir()
+	if err != nil {
+		return config.Config{}, err
+	}
+	return config.Load(userDir, proj.StorageRoot)
+}
+
 func (s *Server) listFiles(proj *ProjectInfo, kind string) ([]fileEntry, error) {
 	var root string
 	switch kind {
@@ -1037,229 +1141,3 @@ func writeSSE(w http.ResponseWriter, event string, payload any) {
 	fmt.Fprintf(w, "event: %s\n", event)
 	fmt.Fprintf(w, "data: %s\n\n", data)
 }
-
-func (s *Server) createTask(w io.Writer, opts struct {
-	ProjectName       string
-	TemplateName      string
-	Title             string
-	Role              string
-	Priority          string
-	Parent            string
-	Blockers          []string
-	Blocks            []string
-	Every             []string
-	RoleSpecified     bool
-	PrioritySpecified bool
-	Body              string
-}, proj *ProjectInfo) error {
-	db := task.NewTaskDB(proj.TasksRoot)
-	if err := db.LoadAllIfEmpty(); err != nil {
-		return err
-	}
-
-	tmplName := strings.TrimSpace(opts.TemplateName)
-	if tmplName == "" {
-		return fmt.Errorf("template name is required")
-	}
-
-	templates, err := template.LoadTemplates(proj.TemplatesRoot)
-	if err != nil {
-		return err
-	}
-
-	tmpl, ok := templates[tmplName]
-	if !ok {
-		return fmt.Errorf("unknown template %q", tmplName)
-	}
-
-	title := strings.TrimSpace(opts.Title)
-	if title == "" {
-		return fmt.Errorf("title is required")
-	}
-
-	// Reject placeholder titles
-	invalidTitles := []string{"description", "task title", "new task", "title", "summary", "todo"}
-	lowerTitle := strings.ToLower(title)
-	for _, invalid := range invalidTitles {
-		if lowerTitle == invalid {
-			return fmt.Errorf("title %q looks like a placeholder; please provide a descriptive title", title)
-		}
-	}
-
-	roleName := strings.TrimSpace(opts.Role)
-	if !opts.RoleSpecified {
-		roleName = strings.TrimSpace(tmpl.Meta.Role)
-	}
-	if roleName == "" {
-		return fmt.Errorf("role is required")
-	}
-
-	roles, err := rPkg.LoadRoles(proj.RolesRoot)
-	if err != nil {
-		return err
-	}
-
-	if _, ok := roles[roleName]; !ok {
-		return fmt.Errorf("invalid role %q", roleName)
-	}
-
-	priority := task.NormalizePriority(opts.Priority)
-	if !opts.PrioritySpecified {
-		if pStr, ok := tmpl.Meta.Priority.(string); ok && pStr != "" {
-			priority = task.NormalizePriority(pStr)
-		}
-	}
-	if !task.IsValidPriority(priority) {
-		return fmt.Errorf("invalid priority: %s", priority)
-	}
-
-	parent := strings.TrimSpace(opts.Parent)
-	if parent != "" {
-		resolvedParent, err := db.ResolveID(parent)
-		if err != nil {
-			return fmt.Errorf("parent task %s does not exist: %w", parent, err)
-		}
-		parent = resolvedParent
-		_, err = db.Get(parent)
-		if err != nil {
-			return fmt.Errorf("parent task %s does not exist: %w", parent, err)
-		}
-	}
-
-	prefix := "T"
-	if strings.Contains(strings.ToLower(tmplName), "epic") {
-		prefix = "E"
-	}
-
-	id, err := idgen.GenerateID(prefix, title)
-	if err != nil {
-		return err
-	}
-
-	taskFile := filepath.Join(proj.TasksRoot, id+".md")
-	if _, err := os.Stat(taskFile); err == nil {
-		return fmt.Errorf("task file already exists: %s", taskFile)
-	}
-
-	blockers, err := db.ResolveIDs(normalizeTaskIDsWeb(opts.Blockers))
-	if err != nil {
-		return err
-	}
-	blocks, err := db.ResolveIDs(normalizeTaskIDsWeb(opts.Blocks))
-	if err != nil {
-		return err
-	}
-	now := time.Now().UTC()
-	meta := task.Metadata{
-		Type:          tmplName,
-		Role:          roleName,
-		Priority:      priority,
-		Parent:        parent,
-		Blockers:      []string{},
-		Blocks:        []string{},
-		DateCreated:   now,
-		DateEdited:    now,
-		OwnerApproval: false,
-		Completed:     false,
-		Every:         opts.Every,
-	}
-
-	body := renderTemplateBodyWeb(tmpl.BodyContent, map[string]string{
-		"Title":               title,
-		"SuggestedSubtaskDir": fmt.Sprintf("%s-subtask", id),
-		"Body":                opts.Body,
-	})
-	if opts.Body != "" && !strings.Contains(tmpl.BodyContent, "{{ .Body }}") {
-		if strings.TrimSpace(body) != "" {
-			body += "\n\n"
-		}
-		body += opts.Body
-	}
-	if err := writeTaskFileWeb(taskFile, meta, body); err != nil {
-		return err
-	}
-
-	fmt.Fprintf(w, "✓ Task created: %s\n", id)
-
-	// Load the new task and set up blocker/blocks relationships via TaskDB
-	if len(blockers) > 0 || len(blocks) > 0 {
-		if _, err := db.Load(id); err != nil {
-			return fmt.Errorf("failed to load new task: %w", err)
-		}
-		for _, blockerID := range blockers {
-			if err := db.AddBlocker(id, blockerID); err != nil {
-				return fmt.Errorf("failed to add blocker %s: %w", blockerID, err)
-			}
-		}
-		for _, blockedID := range blocks {
-			if err := db.AddBlocked(id, blockedID); err != nil {
-				return fmt.Errorf("failed to add blocked %s: %w", blockedID, err)
-			}
-		}
-		if _, err := db.SaveDirty(); err != nil {
-			return fmt.Errorf("failed to write blocker updates: %w", err)
-		}
-	}
-
-	if parent != "" {
-		if _, err := db.Load(id); err != nil {
-			return fmt.Errorf("failed to load new task: %w", err)
-		}
-		if _, err := db.UpdateParentTodos(parent); err != nil {
-			return fmt.Errorf("failed to update parent task TODO entries: %w", err)
-		}
-		if _, err := db.SaveDirty(); err != nil {
-			return fmt.Errorf("failed to write parent task updates: %w", err)
-		}
-	}
-
-	return nil
-}
-
-func normalizeTaskIDsWeb(items []string) []string {
-	seen := map[string]struct{}{}
-	var out []string
-	for _, item := range items {
-		parts := strings.Split(item, ",")
-		for _, part := range parts {
-			trimmed := strings.TrimSpace(part)
-			if trimmed == "" {
-				continue
-			}
-			if _, ok := seen[trimmed]; ok {
-				continue
-			}
-			seen[trimmed] = struct{}{}
-			out = append(out, trimmed)
-		}
-	}
-	sort.Strings(out)
-	return out
-}
-
-func renderTemplateBodyWeb(body string, data map[string]string) string {
-	out := body
-	for key, value := range data {
-		out = strings.ReplaceAll(out, "{{ ."+key+" }}", value)
-	}
-	return out
-}
-
-func writeTaskFileWeb(path string, meta task.Metadata, body string) error {
-	frontmatterBytes, err := yaml.Marshal(&meta)
-	if err != nil {
-		return fmt.Errorf("failed to marshal frontmatter: %w", err)
-	}
-	frontmatterBytes = bytes.TrimSpace(frontmatterBytes)
-
-	var sb strings.Builder
-	sb.WriteString("---\n")
-	sb.Write(frontmatterBytes)
-	sb.WriteString("\n---\n\n")
-	sb.WriteString(body)
-	if !strings.HasSuffix(body, "\n") {
-		sb.WriteString("\n")
-	}
-
-	return os.WriteFile(path, []byte(sb.String()), 0o644)
-}
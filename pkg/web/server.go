@@ -52,6 +52,7 @@ func Serve(ctx context.Context, cfg ServerConfig) error {
 	mux.HandleFunc("/api/state", server.withAuth(server.handleState))
 	mux.HandleFunc("/api/tasks", server.withAuth(server.handleTasks))
 	mux.HandleFunc("/api/task", server.withAuth(server.handleTask))
+	mux.HandleFunc("/api/graph", server.withAuth(server.handleGraph))
 	mux.HandleFunc("/api/roles", server.withAuth(server.handleRoles))
 	mux.HandleFunc("/api/role", server.withAuth(server.handleRole))
 	mux.HandleFunc("/api/templates", server.withAuth(server.handleTemplates))
@@ -162,6 +163,7 @@ func (s *Server) withCORS(next http.Handler) http.Handler {
 		w.Header().Set("Access-Control-Allow-Origin", allowedOrigin)
 		w.Header().Set("Access-Control-Allow-Methods", "GET, PUT, POST, OPTIONS")
 		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Expose-Headers", "X-Total-Count, X-Next-Cursor")
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusNoContent)
 			return
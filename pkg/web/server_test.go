@@ -316,3 +316,154 @@ id_prefix: T
 		}
 	}
 }
+
+func TestHandleTasksFilterAndPaginate(t *testing.T) {
+	tmpDir := t.TempDir()
+	tasksDir := filepath.Join(tmpDir, "tasks")
+	if err := os.MkdirAll(tasksDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	writeTask := func(id, role, priority, status string) {
+		content := "---\ntype: task\nrole: " + role + "\npriority: " + priority + "\nstatus: " + status + "\n---\n\n# " + id + "\n"
+		if err := os.WriteFile(filepath.Join(tasksDir, id+".md"), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeTask("T1aaa-one", "developer", "high", "open")
+	writeTask("T2bbb-two", "developer", "medium", "open")
+	writeTask("T3ccc-three", "developer", "low", "open")
+	writeTask("T4ddd-four", "reviewer", "high", "done")
+
+	proj := &ProjectInfo{Name: "test", StorageRoot: tmpDir, TasksRoot: tasksDir}
+	server := &Server{projects: map[string]*ProjectInfo{"test": proj}}
+
+	get := func(query string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest("GET", "/api/tasks?project=test&"+query, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := httptest.NewRecorder()
+		server.handleTasks(rr, req)
+		return rr
+	}
+
+	rr := get("role=developer&sort=id&limit=2")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %v: %s", rr.Code, rr.Body.String())
+	}
+	if got := rr.Header().Get("X-Total-Count"); got != "3" {
+		t.Errorf("expected total 3, got %q", got)
+	}
+	if got := rr.Header().Get("X-Next-Cursor"); got != "T2bbb-two" {
+		t.Errorf("expected next cursor T2bbb-two, got %q", got)
+	}
+	var page []taskListItem
+	if err := json.Unmarshal(rr.Body.Bytes(), &page); err != nil {
+		t.Fatal(err)
+	}
+	if len(page) != 2 || page[0].ID != "T1aaa-one" || page[1].ID != "T2bbb-two" {
+		t.Fatalf("unexpected first page: %+v", page)
+	}
+
+	rr = get("role=developer&sort=id&limit=2&cursor=T2bbb-two&fields=id,status")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %v: %s", rr.Code, rr.Body.String())
+	}
+	if got := rr.Header().Get("X-Next-Cursor"); got != "" {
+		t.Errorf("expected no next cursor on last page, got %q", got)
+	}
+	var selected []map[string]any
+	if err := json.Unmarshal(rr.Body.Bytes(), &selected); err != nil {
+		t.Fatal(err)
+	}
+	if len(selected) != 1 || selected[0]["id"] != "T3ccc-three" || len(selected[0]) != 2 {
+		t.Fatalf("unexpected second page: %+v", selected)
+	}
+
+	rr = get("status=done")
+	if err := json.Unmarshal(rr.Body.Bytes(), &page); err != nil {
+		t.Fatal(err)
+	}
+	if len(page) != 1 || page[0].ID != "T4ddd-four" {
+		t.Fatalf("unexpected status filter result: %+v", page)
+	}
+
+	for _, query := range []string{"scope=nope", "blocked=maybe", "limit=-1", "fields=bogus", "cursor=T9zzz-missing", "status=completed", "parent=T9zzz-missing"} {
+		if rr := get(query); rr.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %v", query, rr.Code)
+		}
+	}
+
+	proj.TasksRoot = filepath.Join(tmpDir, "missing")
+	if rr := get("sort=id"); rr.Code != http.StatusInternalServerError {
+		t.Errorf("unreadable tasks dir: expected 500, got %v", rr.Code)
+	}
+}
+
+func TestHandleTasksCustomFields(t *testing.T) {
+	t.Setenv("STRAND_CONFIG_DIR", t.TempDir())
+	tmpDir := t.TempDir()
+	tasksDir := filepath.Join(tmpDir, "tasks")
+	if err := os.MkdirAll(tasksDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	cfg := "fields:\n  component:\n    type: enum\n    values: [ui, core]\n  estimate:\n    type: number\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "strand.yaml"), []byte(cfg), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	writeTask := func(id, component, estimate string) {
+		content := "---\ntype: task\nrole: developer\ncomponent: " + component + "\nestimate: " + estimate + "\n---\n\n# " + id + "\n"
+		if err := os.WriteFile(filepath.Join(tasksDir, id+".md"), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeTask("T1aaa-one", "ui", "8")
+	writeTask("T2bbb-two", "core", "3")
+	writeTask("T3ccc-three", "ui", "13")
+
+	proj := &ProjectInfo{Name: "test", StorageRoot: tmpDir, TasksRoot: tasksDir}
+	server := &Server{projects: map[string]*ProjectInfo{"test": proj}}
+	get := func(query string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest("GET", "/api/tasks?project=test&"+query, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := httptest.NewRecorder()
+		server.handleTasks(rr, req)
+		return rr
+	}
+
+	rr := get("field=component=ui&sort=estimate&order=desc")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %v: %s", rr.Code, rr.Body.String())
+	}
+	var page []taskListItem
+	if err := json.Unmarshal(rr.Body.Bytes(), &page); err != nil {
+		t.Fatal(err)
+	}
+	if len(page) != 2 || page[0].ID != "T3ccc-three" || page[1].ID != "T1aaa-one" {
+		t.Fatalf("expected ui tasks by estimate descending, got %+v", page)
+	}
+	if page[0].Fields["estimate"] != "13" {
+		t.Errorf("expected estimate field in item, got %+v", page[0].Fields)
+	}
+
+	rr = get("group=component&fields=id")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %v: %s", rr.Code, rr.Body.String())
+	}
+	var grouped map[string][]map[string]any
+	if err := json.Unmarshal(rr.Body.Bytes(), &grouped); err != nil {
+		t.Fatal(err)
+	}
+	if len(grouped["ui"]) != 2 || len(grouped["core"]) != 1 {
+		t.Fatalf("unexpected groups: %+v", grouped)
+	}
+
+	for _, query := range []string{"sort=customer", "group=customer", "field=novalue"} {
+		if rr := get(query); rr.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %v", query, rr.Code)
+		}
+	}
+}
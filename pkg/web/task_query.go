@@ -0,0 +1,209 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/ricochet1k/strandyard/pkg/task"
+)
+
+// taskListFields lists the JSON keys of taskListItem that can be requested via ?fields=.
+var taskListFields = []string{
+	"id", "short_id", "title", "role", "priority", "completed", "status",
+	"parent", "blockers", "blocks", "path", "date_created", "date_edited",
+	"fields",
+}
+
+// taskQuery holds the parsed query parameters for /api/tasks.
+type taskQuery struct {
+	Options task.ListOptions
+	Cursor  string
+	Limit   int
+	Fields  []string
+}
+
+// taskPage is one page of a filtered, sorted task list.
+type taskPage struct {
+	Items      []taskListItem
+	Total      int
+	NextCursor string
+}
+
+func parseTaskQuery(values url.Values, schema task.FieldSchema) (taskQuery, error) {
+	q := taskQuery{
+		Options: task.ListOptions{
+			Scope:       strings.ToLower(strings.TrimSpace(values.Get("scope"))),
+			Parent:      strings.TrimSpace(values.Get("parent")),
+			Role:        strings.TrimSpace(values.Get("role")),
+			Priority:    strings.ToLower(strings.TrimSpace(values.Get("priority"))),
+			Status:      strings.ToLower(strings.TrimSpace(values.Get("status"))),
+			Sort:        strings.ToLower(strings.TrimSpace(values.Get("sort"))),
+			Order:       strings.ToLower(strings.TrimSpace(values.Get("order"))),
+			Group:       strings.ToLower(strings.TrimSpace(values.Get("group"))),
+			FieldSchema: schema,
+		},
+		Cursor: strings.TrimSpace(values.Get("cursor")),
+	}
+
+	boolFilters := []struct {
+		name   string
+		target **bool
+	}{
+		{"completed", &q.Options.Completed},
+		{"blocked", &q.Options.Blocked},
+		{"blocks", &q.Options.Blocks},
+	}
+	for _, f := range boolFilters {
+		raw := strings.TrimSpace(values.Get(f.name))
+		if raw == "" {
+			continue
+		}
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return q, fmt.Errorf("invalid %s %q (expected true or false)", f.name, raw)
+		}
+		*f.target = &v
+	}
+
+	for _, raw := range values["var"] {
+		name, value, ok := strings.Cut(raw, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return q, fmt.Errorf("invalid var %q (expected key=value)", raw)
+		}
+		if q.Options.Vars == nil {
+			q.Options.Vars = map[string]string{}
+		}
+		q.Options.Vars[name] = value
+	}
+
+	for _, raw := range values["field"] {
+		name, value, ok := strings.Cut(raw, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return q, fmt.Errorf("invalid field %q (expected key=value)", raw)
+		}
+		if q.Options.Fields == nil {
+			q.Options.Fields = map[string]string{}
+		}
+		q.Options.Fields[name] = value
+	}
+
+	if raw := strings.TrimSpace(values.Get("limit")); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 0 {
+			return q, fmt.Errorf("invalid limit %q (expected a non-negative integer)", raw)
+		}
+		q.Limit = limit
+	}
+
+	if raw := strings.TrimSpace(values.Get("fields")); raw != "" {
+		for _, field := range strings.Split(raw, ",") {
+			field = strings.ToLower(strings.TrimSpace(field))
+			if field == "" {
+				continue
+			}
+			if !isTaskListField(field) {
+				return q, fmt.Errorf("invalid field %q (expected %s)", field, strings.Join(taskListFields, ", "))
+			}
+			q.Fields = append(q.Fields, field)
+		}
+	}
+
+	if err := task.ValidateListFilters(q.Options); err != nil {
+		return q, err
+	}
+	return q, nil
+}
+
+func isTaskListField(field string) bool {
+	for _, f := range taskListFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// paginateTasks returns the page of items following cursor, which is the full
+// ID of the last item on the previous page. A limit of 0 returns every item.
+func paginateTasks(items []taskListItem, cursor string, limit int) (taskPage, error) {
+	page := taskPage{Total: len(items)}
+
+	start := 0
+	if cursor != "" {
+		found := false
+		for i, item := range items {
+			if item.ID == cursor {
+				start = i + 1
+				found = true
+				break
+			}
+		}
+		if !found {
+			return page, fmt.Errorf("invalid cursor %q: task not in result set", cursor)
+		}
+	}
+
+	end := len(items)
+	if limit > 0 && start+limit < end {
+		end = start + limit
+		page.NextCursor = items[end-1].ID
+	}
+	page.Items = items[start:end]
+	return page, nil
+}
+
+// selectTaskFields projects each item down to the requested JSON fields.
+func selectTaskFields(items []taskListItem, fields []string) ([]map[string]json.RawMessage, error) {
+	out := make([]map[string]json.RawMessage, 0, len(items))
+	for _, item := range items {
+		data, err := json.Marshal(item)
+		if err != nil {
+			return nil, err
+		}
+		var all map[string]json.RawMessage
+		if err := json.Unmarshal(data, &all); err != nil {
+			return nil, err
+		}
+		selected := make(map[string]json.RawMessage, len(fields))
+		for _, field := range fields {
+			selected[field] = all[field]
+		}
+		out = append(out, selected)
+	}
+	return out, nil
+}
+
+// groupTaskItems splits items by the value of group, keeping their order
+// within each group.
+func groupTaskItems(items []taskListItem, group string) map[string][]taskListItem {
+	grouped := make(map[string][]taskListItem)
+	for _, item := range items {
+		var key string
+		switch group {
+		case "priority":
+			key = item.Priority
+		case "parent":
+			key = item.Parent
+			if key == "" {
+				key = "(root)"
+			}
+		case "role":
+			key = strings.ToLower(item.Role)
+			if key == "" {
+				key = "(unassigned)"
+			}
+		default:
+			key = item.Fields[group]
+			if key == "" {
+				key = "(none)"
+			}
+		}
+		grouped[key] = append(grouped[key], item)
+	}
+	return grouped
+}
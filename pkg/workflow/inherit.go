@@ -0,0 +1,35 @@
+package workflow
+
+import (
+	"fmt"
+
+	"github.com/ricochet1k/strandyard/pkg/template"
+)
+
+// resolveTemplates replaces the raw frontmatter and TODO sequence of each
+// template with its effective one, after applying extends and partials.
+func resolveTemplates(templatesDir string, templates map[string]*Template) error {
+	if len(templates) == 0 {
+		return nil
+	}
+	resolved, err := template.LoadTemplates(templatesDir)
+	if err != nil {
+		return err
+	}
+	for name, t := range templates {
+		r, ok := resolved[name]
+		if !ok {
+			continue
+		}
+		t.Meta.Role = r.Meta.Role
+		if p, ok := r.Meta.Priority.(string); ok {
+			t.Meta.Priority = p
+		}
+		body, err := r.Preview()
+		if err != nil {
+			return fmt.Errorf("failed to resolve template %s: %w", name, err)
+		}
+		t.TodoSequence = extractTodoSequence(body)
+	}
+	return nil
+}
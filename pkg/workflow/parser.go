@@ -164,6 +164,10 @@ func (p *Parser) LoadTemplates(templatesDir string) (map[string]*Template, error
 		templates[template.Name] = template
 	}
 
+	if err := resolveTemplates(templatesDir, templates); err != nil {
+		return nil, err
+	}
+
 	return templates, nil
 }
 
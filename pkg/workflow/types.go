@@ -25,6 +25,7 @@ type Role struct {
 
 // TemplateMetadata represents the frontmatter of a template file
 type TemplateMetadata struct {
+	Extends  string `yaml:"extends,omitempty"`
 	Role     string `yaml:"role"`
 	Priority string `yaml:"priority,omitempty"`
 	IDPrefix string `yaml:"id_prefix,omitempty"`
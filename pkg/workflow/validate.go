@@ -2,6 +2,10 @@ package workflow
 
 import (
 	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ricochet1k/strandyard/pkg/task"
 )
 
 // Validate checks the workflow for common issues and returns validation results
@@ -111,6 +115,41 @@ func (g *WorkflowGraph) Validate() *ValidationResult {
 		}
 	}
 
+	// Check 7: id_prefix must be a valid prefix and unique across templates
+	templatesByPrefix := make(map[string][]string)
+	for name, template := range g.Templates {
+		prefix := strings.TrimSpace(template.Meta.IDPrefix)
+		if prefix == "" {
+			continue
+		}
+		if !task.IsValidIDPrefix(prefix) {
+			result.Errors = append(result.Errors, ValidationIssue{
+				Severity: "error",
+				Message:  fmt.Sprintf("Template '%s' has invalid id_prefix '%s' (expected 1-5 uppercase letters)", name, prefix),
+				Location: template.FilePath,
+			})
+			continue
+		}
+		templatesByPrefix[prefix] = append(templatesByPrefix[prefix], name)
+	}
+	prefixes := make([]string, 0, len(templatesByPrefix))
+	for prefix := range templatesByPrefix {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+	for _, prefix := range prefixes {
+		names := templatesByPrefix[prefix]
+		if len(names) < 2 {
+			continue
+		}
+		sort.Strings(names)
+		result.Errors = append(result.Errors, ValidationIssue{
+			Severity: "error",
+			Message:  fmt.Sprintf("ID prefix '%s' is used by multiple templates: %s", prefix, strings.Join(names, ", ")),
+			Location: "templates",
+		})
+	}
+
 	return result
 }
 